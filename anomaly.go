@@ -0,0 +1,47 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sensors
+
+import (
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACES
+
+// AnomalyDetector flags unusual readings in a series of values
+// and emits AnomalyEvent values through pubsub
+type AnomalyDetector interface {
+	gopi.Driver
+	gopi.Publisher
+
+	// Add a value to a named series, and return true if the
+	// value is an anomaly
+	Add(series string, ts time.Time, value float64) bool
+
+	// Reset the baseline for a named series
+	Reset(series string)
+}
+
+type AnomalyEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	Series() string
+	Value() float64
+
+	// Expected value and the number of standard deviations
+	// the value is from it
+	Mean() float64
+	Score() float64
+}
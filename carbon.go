@@ -0,0 +1,45 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sensors
+
+import (
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// CarbonIntensityPeriod is the carbon intensity of electricity
+// generation over a period, in gCO2/kWh
+type CarbonIntensityPeriod struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Forecast uint      `json:"forecast"`
+	Actual   uint      `json:"actual,omitempty"` // Zero when not yet known
+	Index    string    `json:"index"`            // very low, low, moderate, high or very high
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACES
+
+// CarbonIntensity is a virtual sensor which returns the carbon
+// intensity of electricity generation
+type CarbonIntensity interface {
+	gopi.Driver
+
+	// Return the intensity for the current period
+	Intensity() (CarbonIntensityPeriod, error)
+
+	// Return the forecast intensity for the next 24 hours
+	Forecast() ([]CarbonIntensityPeriod, error)
+}
@@ -0,0 +1,80 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sensors
+
+import (
+	"context"
+
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CC1101 INTERFACE
+
+// CC1101 is the Texas Instruments sub-GHz transceiver. The methods have
+// the same signatures and RFM types as the equivalent RFM69 methods, so
+// an RFM69 also satisfies this interface. The MiHome driver requires an
+// RFM69 and cannot use a CC1101
+type CC1101 interface {
+	gopi.Driver
+
+	// Mode and Modulation. Only RFM_MODULATION_FSK, RFM_MODULATION_FSK_BT_0P5
+	// (GFSK) and RFM_MODULATION_OOK are supported
+	Mode() RFMMode
+	SetMode(device_mode RFMMode) error
+	Modulation() RFMModulation
+	SetModulation(modulation RFMModulation) error
+
+	// Bitrate & Frequency
+	Bitrate() uint
+	FreqCarrier() uint
+	FreqDeviation() uint
+	SetBitrate(bits_per_second uint) error
+	SetFreqCarrier(hertz uint) error
+	SetFreqDeviation(hertz uint) error
+
+	// Packet Format. RFM_PACKET_CRC_AUTOCLEAR_ON flushes payloads which
+	// fail the CRC check from the FIFO
+	PacketFormat() RFMPacketFormat
+	PacketCoding() RFMPacketCoding
+	PacketCRC() RFMPacketCRC
+	SetPacketFormat(packet_format RFMPacketFormat) error
+	SetPacketCoding(packet_coding RFMPacketCoding) error
+	SetPacketCRC(packet_crc RFMPacketCRC) error
+
+	// Preamble in bytes, which is 2, 3, 4, 6, 8, 12, 16 or 24, and the
+	// payload size
+	PreambleSize() uint16
+	PayloadSize() uint8
+	SetPreambleSize(preamble_size uint16) error
+	SetPayloadSize(payload_size uint8) error
+
+	// Sync word, which is nil, two bytes, or four bytes where the
+	// second two repeat the first two
+	SyncWord() []byte
+	SetSyncWord(word []byte) error
+
+	// Output power in dBm, which is rounded down to a PA table setting
+	OutputPower() int
+	SetOutputPower(dbm int) error
+
+	// Payload, which is read when the GDO0 interrupt fires if
+	// SetInterrupt has been called
+	ClearFIFO() error
+	SetInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error
+	ReadPayload(ctx context.Context) ([]byte, bool, error)
+	WritePayload(data []byte, repeat uint) error
+
+	// Measure the current RSSI, in dBm
+	MeasureRSSI() (float32, error)
+
+	// Read the version register
+	ReadVersion() (uint8, error)
+}
@@ -0,0 +1,52 @@
+#!/bin/bash
+##############################################################
+# Build example applications
+##############################################################
+
+CURRENT_PATH="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
+GO=`which go`
+LDFLAGS="-w -s"
+TAGS="i2c spi rpi"
+cd "${CURRENT_PATH}/.."
+
+##############################################################
+# Sanity checks
+
+if [ ! -d ${CURRENT_PATH} ] ; then
+  echo "Not found: ${CURRENT_PATH}" >&2
+  exit -1
+fi
+if [ "${GO}" == "" ] || [ ! -x ${GO} ] ; then
+  echo "go not installed or executable" >&2
+  exit -1
+fi
+
+##############################################################
+# Dependencies which are only used by the examples
+
+DEPENDENCIES=(
+    github.com/eclipse/paho.mqtt.golang
+)
+
+for DEPENDENCY in ${DEPENDENCIES[@]}; do
+  echo "go get ${DEPENDENCY}"
+  go get -d "${DEPENDENCY}" || exit -1
+done
+
+##############################################################
+# Install
+
+COMMANDS=(
+    examples/weather_logger/*.go
+    examples/heating_controller/*.go
+    examples/mqtt_bridge/*.go
+)
+
+echo "tags=\"${TAGS}\""
+for FILES in ${COMMANDS[@]}; do
+  DIR=`dirname ${FILES}`
+  EXEC=`basename ${DIR}`
+  SOURCES=`basename ${FILES}`
+  echo "go install ${EXEC}"
+  go build -ldflags "${LDFLAGS}" -o "${GOBIN}/${EXEC}" -tags "${TAGS}" "${CURRENT_PATH}/${DIR}/"${SOURCES} || exit -1
+done
@@ -6,7 +6,7 @@
 CURRENT_PATH="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
 GO=`which go`
 LDFLAGS="-w -s"
-TAGS="spi rpi"
+TAGS="i2c spi rpi"
 cd "${CURRENT_PATH}/.."
 
 ##############################################################
@@ -33,8 +33,10 @@ COMMANDS=(
     ener314/*.go
     mihomectrl/*.go
     mihomereset/*.go
+    mihomecalibrate/*.go
     mihome_client/*.go
     mihome_gateway/*.go
+    otreport/*.go
 )
 
 echo "tags=\"${TAGS}\""
@@ -0,0 +1,188 @@
+/*
+   Go Language Raspberry Pi Interface
+   (c) Copyright David Thorpe 2016-2018
+   All Rights Reserved
+   Documentation http://djthorpe.github.io/gopi/
+   For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Example application which reads the room temperature from a BME280
+// sensor and switches a MiHome socket (with a heater attached) on and
+// off to maintain a target temperature
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+
+	// Register modules
+	_ "github.com/djthorpe/gopi/sys/hw/linux"
+	_ "github.com/djthorpe/gopi/sys/logger"
+	_ "github.com/djthorpe/sensors/hw/bme280"
+	_ "github.com/djthorpe/sensors/hw/energenie"
+	_ "github.com/djthorpe/sensors/hw/rfm69"
+	_ "github.com/djthorpe/sensors/protocol/openthings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Heater uint
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	MODULE_SENSOR = "sensors/bme280:i2c"
+	MODULE_SWITCH = "sensors/mihome"
+)
+
+const (
+	HEATER_UNKNOWN Heater = iota
+	HEATER_ON
+	HEATER_OFF
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONTROLLER
+
+// Return the next heater state for a temperature reading, which
+// switches on below (target - hysteresis) and off above (target + hysteresis)
+func nextState(current Heater, temperature, target, hysteresis float64) Heater {
+	switch {
+	case temperature < target-hysteresis:
+		return HEATER_ON
+	case temperature > target+hysteresis:
+		return HEATER_OFF
+	case current == HEATER_UNKNOWN:
+		// Within the band on startup, so heater is switched off
+		return HEATER_OFF
+	default:
+		return current
+	}
+}
+
+func ControlLoop(app *gopi.AppInstance, done <-chan struct{}) error {
+	sensor, ok := app.ModuleInstance(MODULE_SENSOR).(sensors.BME280)
+	if sensor == nil || ok == false {
+		return errors.New("Module not found: " + MODULE_SENSOR)
+	}
+	mihome, ok := app.ModuleInstance(MODULE_SWITCH).(sensors.MiHome)
+	if mihome == nil || ok == false {
+		return errors.New("Module not found: " + MODULE_SWITCH)
+	}
+
+	target, _ := app.AppFlags.GetFloat64("target")
+	hysteresis, _ := app.AppFlags.GetFloat64("hysteresis")
+	socket, _ := app.AppFlags.GetUint("socket")
+	interval, _ := app.AppFlags.GetDuration("interval")
+	if hysteresis < 0 {
+		return fmt.Errorf("Invalid -hysteresis flag: %v", hysteresis)
+	} else if interval < time.Second {
+		return fmt.Errorf("Invalid -interval flag: %v", interval)
+	}
+
+	// Reset the radio before use
+	if err := mihome.ResetRadio(); err != nil && err != gopi.ErrNotImplemented {
+		return err
+	}
+
+	state := HEATER_UNKNOWN
+	timer := time.NewTimer(100 * time.Millisecond)
+	defer timer.Stop()
+
+FOR_LOOP:
+	for {
+		select {
+		case <-done:
+			break FOR_LOOP
+		case <-timer.C:
+			if err := sensor.SetMode(sensors.BME280_MODE_FORCED); err != nil {
+				app.Logger.Error("SetMode: %v", err)
+			} else if temperature, _, _, err := sensor.ReadSample(); err != nil {
+				app.Logger.Error("ReadSample: %v", err)
+			} else if next := nextState(state, temperature, target, hysteresis); next != state {
+				app.Logger.Info("temperature=%.1fC target=%.1fC heater=%v", temperature, target, next)
+				if err := switchHeater(mihome, socket, next); err != nil {
+					app.Logger.Error("Switch: %v", err)
+				} else {
+					state = next
+				}
+			}
+			timer.Reset(interval)
+		}
+	}
+
+	// Switch off the heater on exit
+	if state != HEATER_OFF {
+		if err := switchHeater(mihome, socket, HEATER_OFF); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
+func switchHeater(device sensors.ENER314, socket uint, state Heater) error {
+	var sockets []uint
+	if socket > 0 {
+		sockets = []uint{socket}
+	}
+	switch state {
+	case HEATER_ON:
+		return device.On(sockets...)
+	case HEATER_OFF:
+		return device.Off(sockets...)
+	default:
+		return gopi.ErrBadParameter
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (h Heater) String() string {
+	switch h {
+	case HEATER_ON:
+		return "HEATER_ON"
+	case HEATER_OFF:
+		return "HEATER_OFF"
+	default:
+		return "HEATER_UNKNOWN"
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MAIN FUNCTION
+
+func MainLoop(app *gopi.AppInstance, done chan<- struct{}) error {
+	// Wait for CTRL+C
+	app.WaitForSignal()
+
+	// Exit
+	done <- gopi.DONE
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	// Create the configuration
+	config := gopi.NewAppConfig(MODULE_SENSOR, MODULE_SWITCH)
+
+	// Parameters
+	config.AppFlags.FlagFloat64("target", 20.0, "Target temperature (Celcius)")
+	config.AppFlags.FlagFloat64("hysteresis", 0.5, "Temperature hysteresis (Celcius)")
+	config.AppFlags.FlagUint("socket", 0, "Socket to switch (1-4, or 0 for all sockets)")
+	config.AppFlags.FlagDuration("interval", 30*time.Second, "Sample interval")
+
+	// Run the command line tool
+	os.Exit(gopi.CommandLineTool(config, MainLoop, ControlLoop))
+}
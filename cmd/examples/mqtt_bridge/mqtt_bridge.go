@@ -0,0 +1,192 @@
+/*
+   Go Language Raspberry Pi Interface
+   (c) Copyright David Thorpe 2016-2018
+   All Rights Reserved
+   Documentation http://djthorpe.github.io/gopi/
+   For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Example application which bridges MQTT messages to MiHome sockets.
+// Publish "ON" or "OFF" to <prefix>/<socket>/set (where socket is 1-4
+// or "all") and the state is published back to <prefix>/<socket>/state.
+// The MQTT client is github.com/eclipse/paho.mqtt.golang, which is fetched
+// by cmd/build-examples.sh
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/util/backoff"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	// Register modules
+	_ "github.com/djthorpe/gopi/sys/hw/linux"
+	_ "github.com/djthorpe/gopi/sys/logger"
+	_ "github.com/djthorpe/sensors/hw/energenie"
+	_ "github.com/djthorpe/sensors/hw/rfm69"
+	_ "github.com/djthorpe/sensors/protocol/openthings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type Request struct {
+	socket string
+	on     bool
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	MODULE_NAME = "sensors/mihome"
+	SOCKET_ALL  = "all"
+	QOS         = 1
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// MQTT
+
+// Parse a topic and payload into a request
+func parseRequest(prefix, topic string, payload []byte) (*Request, error) {
+	parts := strings.Split(strings.TrimPrefix(topic, prefix+"/"), "/")
+	if len(parts) != 2 || parts[1] != "set" {
+		return nil, fmt.Errorf("Invalid topic: %v", topic)
+	}
+	request := &Request{socket: parts[0]}
+	if request.socket != SOCKET_ALL {
+		if socket, err := strconv.ParseUint(request.socket, 10, 32); err != nil || socket < 1 || socket > 4 {
+			return nil, fmt.Errorf("Invalid socket: %v", request.socket)
+		}
+	}
+	switch strings.ToUpper(strings.TrimSpace(string(payload))) {
+	case "ON":
+		request.on = true
+	case "OFF":
+		request.on = false
+	default:
+		return nil, fmt.Errorf("Invalid payload: %v", string(payload))
+	}
+	return request, nil
+}
+
+// Perform the request on the device
+func (this *Request) Execute(device sensors.ENER314) error {
+	var sockets []uint
+	if this.socket != SOCKET_ALL {
+		if socket, err := strconv.ParseUint(this.socket, 10, 32); err != nil {
+			return err
+		} else {
+			sockets = []uint{uint(socket)}
+		}
+	}
+	if this.on {
+		return device.On(sockets...)
+	} else {
+		return device.Off(sockets...)
+	}
+}
+
+func (this *Request) State() string {
+	if this.on {
+		return "ON"
+	} else {
+		return "OFF"
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BRIDGE LOOP
+
+func BridgeLoop(app *gopi.AppInstance, done <-chan struct{}) error {
+	device, ok := app.ModuleInstance(MODULE_NAME).(sensors.MiHome)
+	if device == nil || ok == false {
+		return errors.New("Module not found: " + MODULE_NAME)
+	}
+
+	broker, _ := app.AppFlags.GetString("mqtt.broker")
+	client_id, _ := app.AppFlags.GetString("mqtt.client")
+	prefix, _ := app.AppFlags.GetString("mqtt.prefix")
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	// Connect to the broker, backing off between retries
+	policy := backoff.NETWORK
+	policy.Retries, _ = app.AppFlags.GetUint("mqtt.retries")
+	client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(broker).SetClientID(client_id))
+	if err := policy.Retry(context.Background(), func() error {
+		if token := client.Connect(); token.Wait() && token.Error() != nil {
+			app.Logger.Warn("Bridge: %v", token.Error())
+			return token.Error()
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	defer client.Disconnect(250)
+
+	// Requests are executed one at a time so that transmissions don't overlap
+	requests := make(chan *Request, 10)
+	if token := client.Subscribe(prefix+"/+/set", QOS, func(_ mqtt.Client, message mqtt.Message) {
+		if request, err := parseRequest(prefix, message.Topic(), message.Payload()); err != nil {
+			app.Logger.Warn("Bridge: %v", err)
+		} else {
+			requests <- request
+		}
+	}); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+
+FOR_LOOP:
+	for {
+		select {
+		case <-done:
+			break FOR_LOOP
+		case request := <-requests:
+			if err := request.Execute(device); err != nil {
+				app.Logger.Error("Bridge: %v", err)
+			} else {
+				topic := fmt.Sprintf("%v/%v/state", prefix, request.socket)
+				client.Publish(topic, QOS, true, request.State())
+			}
+		}
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MAIN FUNCTION
+
+func MainLoop(app *gopi.AppInstance, done chan<- struct{}) error {
+	// Wait for CTRL+C
+	app.WaitForSignal()
+
+	// Exit
+	done <- gopi.DONE
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	// Create the configuration
+	config := gopi.NewAppConfig(MODULE_NAME)
+
+	// Parameters
+	config.AppFlags.FlagString("mqtt.broker", "tcp://localhost:1883", "MQTT broker")
+	config.AppFlags.FlagString("mqtt.client", "mihome", "MQTT client ID")
+	config.AppFlags.FlagString("mqtt.prefix", "mihome/socket", "MQTT topic prefix")
+	config.AppFlags.FlagUint("mqtt.retries", backoff.NETWORK.Retries, "MQTT broker connection retries")
+
+	// Run the command line tool
+	os.Exit(gopi.CommandLineTool(config, MainLoop, BridgeLoop))
+}
@@ -0,0 +1,167 @@
+/*
+   Go Language Raspberry Pi Interface
+   (c) Copyright David Thorpe 2016-2018
+   All Rights Reserved
+   Documentation http://djthorpe.github.io/gopi/
+   For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Example application which periodically samples a BME280 sensor and
+// writes the measurements to an InfluxDB database using the line protocol
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/util/altimetry"
+
+	// Register modules
+	_ "github.com/djthorpe/gopi/sys/hw/linux"
+	_ "github.com/djthorpe/gopi/sys/logger"
+	_ "github.com/djthorpe/sensors/hw/bme280"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	MODULE_NAME      = "sensors/bme280:i2c"
+	INTERVAL_DEFAULT = 60 * time.Second
+	INTERVAL_MIN     = time.Second
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INFLUXDB
+
+// Return the URL used to write measurements
+func writeURL(app *gopi.AppInstance) (string, error) {
+	endpoint, _ := app.AppFlags.GetString("influx.url")
+	database, _ := app.AppFlags.GetString("influx.db")
+	if endpoint == "" || database == "" {
+		return "", errors.New("Missing -influx.url or -influx.db flag")
+	} else if u, err := url.Parse(endpoint); err != nil {
+		return "", err
+	} else {
+		u.Path = "/write"
+		u.RawQuery = url.Values{"db": []string{database}, "precision": []string{"s"}}.Encode()
+		return u.String(), nil
+	}
+}
+
+// Return a measurement in InfluxDB line protocol, with both the station
+// pressure and the sea-level pressure
+func lineProtocol(measurement, location string, temperature, pressure, sealevel, humidity float64, ts time.Time) string {
+	return fmt.Sprintf("%v,location=%v temperature=%.2f,pressure=%.2f,sealevel=%.2f,humidity=%.2f %v\n", measurement, location, temperature, pressure, sealevel, humidity, ts.Unix())
+}
+
+// Write a line to the database
+func writeLine(endpoint, line string) error {
+	if response, err := http.Post(endpoint, "text/plain", bytes.NewBufferString(line)); err != nil {
+		return err
+	} else {
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK {
+			return fmt.Errorf("InfluxDB: %v", response.Status)
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// SAMPLE LOOP
+
+func Sample(app *gopi.AppInstance, device sensors.BME280, endpoint string) error {
+	measurement, _ := app.AppFlags.GetString("influx.measurement")
+	location, _ := app.AppFlags.GetString("location")
+	elevation, _ := app.AppFlags.GetFloat64("elevation")
+
+	// Force a reading when the sensor isn't sampling continuously
+	if device.Mode() != sensors.BME280_MODE_NORMAL {
+		if err := device.SetMode(sensors.BME280_MODE_FORCED); err != nil {
+			return err
+		}
+	}
+
+	if temperature, pressure, humidity, err := device.ReadSample(); err != nil {
+		return err
+	} else {
+		sealevel := altimetry.QNH(pressure, elevation)
+		line := lineProtocol(measurement, location, temperature, pressure, sealevel, humidity, time.Now())
+		app.Logger.Debug("Sample: %v", line)
+		return writeLine(endpoint, line)
+	}
+}
+
+func SampleLoop(app *gopi.AppInstance, done <-chan struct{}) error {
+	device, ok := app.ModuleInstance(MODULE_NAME).(sensors.BME280)
+	if device == nil || ok == false {
+		return errors.New("Module not found: " + MODULE_NAME)
+	}
+	endpoint, err := writeURL(app)
+	if err != nil {
+		return err
+	}
+	interval, _ := app.AppFlags.GetDuration("interval")
+	if interval < INTERVAL_MIN {
+		return fmt.Errorf("Invalid -interval flag (minimum %v)", INTERVAL_MIN)
+	}
+
+	// Take a sample immediately, and then every interval
+	timer := time.NewTimer(100 * time.Millisecond)
+	defer timer.Stop()
+
+FOR_LOOP:
+	for {
+		select {
+		case <-done:
+			break FOR_LOOP
+		case <-timer.C:
+			if err := Sample(app, device, endpoint); err != nil {
+				app.Logger.Error("Sample: %v", err)
+			}
+			timer.Reset(interval)
+		}
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MAIN FUNCTION
+
+func MainLoop(app *gopi.AppInstance, done chan<- struct{}) error {
+	// Wait for CTRL+C
+	app.WaitForSignal()
+
+	// Exit
+	done <- gopi.DONE
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	// Create the configuration
+	config := gopi.NewAppConfig(MODULE_NAME)
+
+	// Parameters
+	config.AppFlags.FlagString("influx.url", "http://localhost:8086/", "InfluxDB endpoint")
+	config.AppFlags.FlagString("influx.db", "", "InfluxDB database name")
+	config.AppFlags.FlagString("influx.measurement", "weather", "InfluxDB measurement name")
+	config.AppFlags.FlagString("location", "default", "Location tag for measurements")
+	config.AppFlags.FlagFloat64("elevation", 0, "Station elevation (m), for sea-level pressure")
+	config.AppFlags.FlagDuration("interval", INTERVAL_DEFAULT, "Sample interval")
+
+	// Run the command line tool
+	os.Exit(gopi.CommandLineTool(config, MainLoop, SampleLoop))
+}
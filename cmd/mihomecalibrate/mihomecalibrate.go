@@ -0,0 +1,127 @@
+/*
+   Go Language Raspberry Pi Interface
+   (c) Copyright David Thorpe 2016-2018
+   All Rights Reserved
+   Documentation http://djthorpe.github.io/gopi/
+   For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Calibrate the RFM69 radio temperature against a BME280 reference
+// sensor, and write the offset to a calibration file which can be
+// used with the -mihome.calibration flag
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/hw/energenie"
+
+	// Register modules
+	_ "github.com/djthorpe/gopi/sys/hw/linux"
+	_ "github.com/djthorpe/gopi/sys/logger"
+	_ "github.com/djthorpe/sensors/hw/bme280"
+	_ "github.com/djthorpe/sensors/hw/rfm69"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	MODULE_RADIO     = "sensors/rfm69"
+	MODULE_REFERENCE = "sensors/bme280:i2c"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// SAMPLE
+
+// Return a pair of temperature samples from the radio and the reference
+func Sample(radio sensors.RFM69, reference sensors.BME280) (float32, float32, error) {
+	if err := reference.SetMode(sensors.BME280_MODE_FORCED); err != nil {
+		return 0, 0, err
+	} else if temperature, _, _, err := reference.ReadSample(); err != nil {
+		return 0, 0, err
+	} else if value, err := radio.MeasureTemperature(0); err != nil {
+		return 0, 0, err
+	} else {
+		return value, float32(temperature), nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MAIN FUNCTION
+
+func MainLoop(app *gopi.AppInstance, done chan<- struct{}) error {
+	radio, ok := app.ModuleInstance(MODULE_RADIO).(sensors.RFM69)
+	if radio == nil || ok == false {
+		return errors.New("Module not found: " + MODULE_RADIO)
+	}
+	reference, ok := app.ModuleInstance(MODULE_REFERENCE).(sensors.BME280)
+	if reference == nil || ok == false {
+		return errors.New("Module not found: " + MODULE_REFERENCE)
+	}
+
+	path, _ := app.AppFlags.GetString("mihome.calibration")
+	samples, _ := app.AppFlags.GetUint("samples")
+	interval, _ := app.AppFlags.GetDuration("interval")
+	if path == "" {
+		return errors.New("Missing -mihome.calibration flag")
+	} else if samples == 0 {
+		return fmt.Errorf("Invalid -samples flag: %v", samples)
+	}
+
+	// Radio needs to be in standby mode to measure the temperature
+	if err := radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return err
+	}
+
+	// Collect the samples, allowing the radio and reference
+	// to settle between each one
+	radio_values := make([]float32, 0, samples)
+	reference_values := make([]float32, 0, samples)
+	for i := uint(0); i < samples; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		if value, temperature, err := Sample(radio, reference); err != nil {
+			return err
+		} else {
+			app.Logger.Info("Sample %v of %v: radio=%.1fC reference=%.1fC", i+1, samples, value, temperature)
+			radio_values = append(radio_values, value)
+			reference_values = append(reference_values, temperature)
+		}
+	}
+
+	// Compute and write the calibration
+	if calibration, err := energenie.NewCalibration(radio_values, reference_values); err != nil {
+		return err
+	} else if err := energenie.WriteCalibration(path, calibration); err != nil {
+		return err
+	} else {
+		fmt.Printf("Temperature offset is %.2fC, written to %v\n", calibration.TempOffset, path)
+	}
+
+	// Exit
+	done <- gopi.DONE
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	// Create the configuration
+	config := gopi.NewAppConfig(MODULE_RADIO, MODULE_REFERENCE)
+
+	// Add on additional flags
+	config.AppFlags.FlagString("mihome.calibration", "", "Temperature Calibration File")
+	config.AppFlags.FlagUint("samples", 10, "Number of samples")
+	config.AppFlags.FlagDuration("interval", 30*time.Second, "Interval between samples")
+
+	// Run the command line tool
+	os.Exit(gopi.CommandLineTool(config, MainLoop))
+}
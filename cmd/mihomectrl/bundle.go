@@ -0,0 +1,157 @@
+/*
+   Go Language Raspberry Pi Interface
+   (c) Copyright David Thorpe 2016-2018
+   All Rights Reserved
+   Documentation http://djthorpe.github.io/gopi/
+   For Licensing and Usage information, please see LICENSE.md
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Registers which are redacted in the bundle
+	BUNDLE_REDACT_PREFIX = "RFM_REG_AESKEY"
+
+	// Default duration of the raw capture
+	BUNDLE_CAPTURE_DEFAULT = 10 * time.Second
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// DEBUG BUNDLE
+
+// CommandBundle writes a compressed archive for attaching to bug reports,
+// which contains the driver diagnostics, a register dump with the AES
+// key redacted and a short capture of received messages
+func CommandBundle(app *gopi.AppInstance) error {
+	path, _ := app.AppFlags.GetString("bundle")
+	if path == "" {
+		path = fmt.Sprintf("mihome-debug-%v.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	app.Logger.Info("Writing debug bundle to %v", path)
+
+	files := make(map[string][]byte)
+
+	// Driver diagnostics
+	if data, err := json.MarshalIndent(state.mihome.Diagnostics(), "", "  "); err != nil {
+		return err
+	} else {
+		files["diagnostics.json"] = data
+	}
+
+	// Register dump
+	if radio, ok := app.ModuleInstance("sensors/rfm69").(sensors.RFM69); ok == false || radio == nil {
+		files["registers.txt"] = []byte("Missing RFM69 module\n")
+	} else if values, err := radio.DumpRegisters(); err != nil {
+		files["registers.txt"] = []byte(fmt.Sprintf("DumpRegisters: %v\n", err))
+	} else {
+		files["registers.txt"] = bundleRegisters(values)
+	}
+
+	// Short capture of received messages
+	duration, _ := app.AppFlags.GetDuration("bundle.capture")
+	if duration == 0 {
+		duration = BUNDLE_CAPTURE_DEFAULT
+	}
+	files["capture.txt"] = bundleCapture(duration)
+
+	// Write the archive
+	return writeBundle(path, files)
+}
+
+// bundleRegisters formats a register dump, redacting the AES key
+func bundleRegisters(values []sensors.RFMRegisterValue) []byte {
+	buf := new(bytes.Buffer)
+	for _, value := range values {
+		if strings.HasPrefix(value.Name, BUNDLE_REDACT_PREFIX) {
+			fmt.Fprintf(buf, "0x%02X %-24s **\n", value.Register, value.Name)
+		} else {
+			fmt.Fprintf(buf, "0x%02X %-24s 0x%02X\n", value.Register, value.Name, value.Value)
+		}
+	}
+	return buf.Bytes()
+}
+
+// bundleCapture receives in monitor mode for a duration, and returns
+// the raw payload of each message and any decode errors
+func bundleCapture(duration time.Duration) []byte {
+	buf := new(bytes.Buffer)
+	events := state.mihome.Subscribe()
+	defer state.mihome.Unsubscribe(events)
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- state.mihome.Receive(ctx, sensors.MIHOME_MODE_MONITOR)
+	}()
+
+	for {
+		select {
+		case err := <-errs:
+			if err != nil {
+				fmt.Fprintf(buf, "Receive: %v\n", err)
+			}
+			return buf.Bytes()
+		case evt := <-events:
+			if otevent, ok := evt.(sensors.OTEvent); ok == false {
+				continue
+			} else if otevent.Reason() != nil {
+				fmt.Fprintf(buf, "%v error %v\n", otevent.Timestamp().Format(time.StampMilli), otevent.Reason())
+			} else if message := otevent.Message(); message != nil {
+				fmt.Fprintf(buf, "%v %v %v\n", otevent.Timestamp().Format(time.StampMilli), otevent.Decoder(), strings.ToUpper(hex.EncodeToString(message.Payload())))
+			}
+		}
+	}
+}
+
+// writeBundle writes files into a gzipped tar archive
+func writeBundle(path string, files map[string][]byte) error {
+	fh, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	gz := gzip.NewWriter(fh)
+	archive := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := archive.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		}); err != nil {
+			return err
+		} else if _, err := archive.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	} else if err := gz.Close(); err != nil {
+		return err
+	}
+
+	// Success
+	return nil
+}
@@ -55,6 +55,7 @@ var (
 		"rx":      &Command{"Receive Data Mode", CommandReceive},
 		"temp":    &Command{"Measure Temperature", CommandTemp},
 		"devices": &Command{"List Devices", CommandDevices},
+		"debug":   &Command{"Write a debug bundle for bug reports", CommandBundle},
 	}
 )
 
@@ -190,7 +191,10 @@ FOR_LOOP:
 		case <-done:
 			break FOR_LOOP
 		case e := <-events:
-			if err := ProcessEvent(e.(sensors.OTEvent)); err != nil {
+			if otevent, ok := e.(sensors.OTEvent); ok == false {
+				// Ignore reports and other events
+				continue
+			} else if err := ProcessEvent(otevent); err != nil {
 				return err
 			}
 		}
@@ -305,6 +309,10 @@ func main() {
 	// Timeout flag for receive timeout
 	config.AppFlags.FlagDuration("timeout", 0, "Timeout for receive mode")
 
+	// Debug bundle path and capture duration
+	config.AppFlags.FlagString("bundle", "", "Debug bundle path, or empty for a timestamped name")
+	config.AppFlags.FlagDuration("bundle.capture", BUNDLE_CAPTURE_DEFAULT, "Debug bundle capture duration")
+
 	// Create the application state
 	state = NewState()
 
@@ -0,0 +1,92 @@
+/*
+   Go Language Raspberry Pi Interface
+   (c) Copyright David Thorpe 2016-2018
+   All Rights Reserved
+   Documentation http://djthorpe.github.io/gopi/
+   For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Merge reports of unknown OpenThings records, collected with the
+// sensors/otreport module, into a parameter database file and list
+// the unknown records it contains
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/util/otreport"
+	"github.com/olekukonko/tablewriter"
+
+	// Register modules
+	_ "github.com/djthorpe/gopi/sys/logger"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// MAIN FUNCTION
+
+func MainLoop(app *gopi.AppInstance, done chan<- struct{}) error {
+	path, _ := app.AppFlags.GetString("db")
+	if path == "" {
+		return errors.New("Missing -db flag")
+	}
+
+	// Read the database, and merge in each report
+	db, err := otreport.ReadReport(path)
+	if err != nil {
+		return err
+	}
+	for _, arg := range app.AppFlags.Args() {
+		if report, err := otreport.ReadReport(arg); err != nil {
+			return err
+		} else {
+			app.Logger.Info("Merging %v records from %v", len(report.Records), arg)
+			db.Merge(report)
+		}
+	}
+
+	// Write the database when reports were merged
+	if len(app.AppFlags.Args()) > 0 {
+		if err := otreport.WriteReport(path, db); err != nil {
+			return err
+		}
+	}
+
+	// List the unknown records
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Manufacturer", "Product", "Parameter", "Type", "Size", "Count", "First Seen", "Last Seen"})
+	for _, record := range db.Records {
+		table.Append([]string{
+			fmt.Sprint(sensors.OTManufacturer(record.Manufacturer)),
+			fmt.Sprintf("0x%02X", record.Product),
+			fmt.Sprintf("0x%02X", record.Parameter),
+			fmt.Sprintf("0x%X", record.Type),
+			fmt.Sprint(record.Size),
+			fmt.Sprint(record.Count),
+			record.FirstSeen.Format("2006-01-02"),
+			record.LastSeen.Format("2006-01-02"),
+		})
+	}
+	table.Render()
+
+	// Exit
+	done <- gopi.DONE
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func main() {
+	// Create the configuration
+	config := gopi.NewAppConfig()
+
+	// Parameter database, which reports given as arguments are merged into
+	config.AppFlags.FlagString("db", "", "Parameter database file")
+
+	// Run the command line tool
+	os.Exit(gopi.CommandLineTool(config, MainLoop))
+}
@@ -29,6 +29,9 @@ var (
 		"Status":          Status,
 		"ReadTemperature": ReadTemperature,
 		"ReadRSSI":        ReadRSSI,
+		"ScanRSSI":        ScanRSSI,
+		"TransmitCarrier": TransmitCarrier,
+		"TransmitPN9":     TransmitPN9,
 	}
 )
 
@@ -110,6 +113,50 @@ func ReadRSSI(app *gopi.AppInstance, device sensors.RFM69) error {
 	return nil
 }
 
+func ScanRSSI(app *gopi.AppInstance, device sensors.RFM69) error {
+	from, _ := app.AppFlags.GetFloat64("scan_from")
+	to, _ := app.AppFlags.GetFloat64("scan_to")
+	step, _ := app.AppFlags.GetFloat64("scan_step")
+	if readings, err := device.ScanRSSI(uint(from*1000), uint(to*1000), uint(step*1000)); err != nil {
+		return err
+	} else {
+		// Output readings, marking the quietest frequency
+		quietest := 0
+		for i, reading := range readings {
+			if reading.RSSI < readings[quietest].RSSI {
+				quietest = i
+			}
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Frequency", "RSSI", ""})
+		for i, reading := range readings {
+			mark := ""
+			if i == quietest {
+				mark = "quietest"
+			}
+			table.Append([]string{freqToString(reading.FreqCarrier), fmt.Sprintf("%vdBm", reading.RSSI), mark})
+		}
+		table.Render()
+	}
+
+	// Success
+	return nil
+}
+
+func TransmitCarrier(app *gopi.AppInstance, device sensors.RFM69) error {
+	duration, _ := app.AppFlags.GetDuration("tx_duration")
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	return device.TransmitCarrier(ctx)
+}
+
+func TransmitPN9(app *gopi.AppInstance, device sensors.RFM69) error {
+	duration, _ := app.AppFlags.GetDuration("tx_duration")
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+	return device.TransmitPN9(ctx)
+}
+
 func ReadPayload(app *gopi.AppInstance, device sensors.RFM69) error {
 
 	// Put into RX mode
@@ -122,17 +169,20 @@ func ReadPayload(app *gopi.AppInstance, device sensors.RFM69) error {
 	// Read payload
 	timeout, _ := app.AppFlags.GetDuration("timeout")
 	ctx, _ := context.WithTimeout(context.Background(), timeout)
-	if data, crc_ok, err := device.ReadPayload(ctx); err != nil {
+	if packet, err := device.ReadPacket(ctx); err != nil {
 		return err
-	} else if data == nil {
+	} else if packet == nil {
 		return fmt.Errorf("Timeout waiting for payload")
 	} else {
 		// Output register information
 		table := tablewriter.NewWriter(os.Stdout)
 
 		table.SetHeader([]string{"Payload", "Value"})
-		table.Append([]string{"payload", fmt.Sprintf("%v", strings.ToUpper(hex.EncodeToString(data)))})
-		table.Append([]string{"crc_ok", fmt.Sprintf("%v", crc_ok)})
+		table.Append([]string{"payload", fmt.Sprintf("%v", strings.ToUpper(hex.EncodeToString(packet.Payload)))})
+		table.Append([]string{"crc_ok", fmt.Sprintf("%v", packet.CRCOk)})
+		if packet.Filtered {
+			table.Append([]string{"addr", fmt.Sprintf("0x%02X", packet.Address)})
+		}
 
 		table.Render()
 	}
@@ -153,14 +203,17 @@ func Status(app *gopi.AppInstance, device sensors.RFM69) error {
 	table.Append([]string{"listen", listenOnToString(device.ListenOn())})
 	table.Append([]string{"sequencer", sequencerEnabledToString(device.SequencerEnabled())})
 	table.Append([]string{"modulation", modulationToString(device.Modulation())})
-	table.Append([]string{"bitrate", bitrateToString(device.Bitrate())})
+	bitrate, bitrate_ppm := device.BitrateActual()
+	table.Append([]string{"bitrate", fmt.Sprintf("%v (%.2f bps, %+.0f ppm)", bitrateToString(device.Bitrate()), bitrate, bitrate_ppm)})
 	table.Append([]string{"freq_carrier", freqToString(device.FreqCarrier())})
-	table.Append([]string{"freq_dev", freqToString(device.FreqDeviation())})
+	deviation, deviation_ppm := device.FreqDeviationActual()
+	table.Append([]string{"freq_dev", fmt.Sprintf("%v (%.2f Hz, %+.0f ppm)", freqToString(device.FreqDeviation()), deviation, deviation_ppm)})
 
 	// Automatic Frequency Correction
 	table.Append([]string{"afc", fmt.Sprintf("%v Hz", device.AFC())})
 	table.Append([]string{"afc_mode", fmt.Sprint(device.AFCMode())})
 	table.Append([]string{"afc_routine", fmt.Sprint(device.AFCRoutine())})
+	table.Append([]string{"afc_offset", fmt.Sprintf("%v Hz", device.AFCLowBetaOffset())})
 
 	// Low Noise Amplifier Settings
 	table.Append([]string{"lna_impedance", fmt.Sprintf("%v", device.LNAImpedance())})
@@ -169,11 +222,23 @@ func Status(app *gopi.AppInstance, device sensors.RFM69) error {
 	} else {
 		table.Append([]string{"lna_gain", fmt.Sprintf("%v (%v)", device.LNAGain(), gain)})
 	}
+	table.Append([]string{"sensitivity_boost", fmt.Sprint(device.SensitivityBoost())})
+	table.Append([]string{"dagc", fmt.Sprint(device.DAGC())})
 
 	// RX Channel Filyer Parameters
 	table.Append([]string{"rxbw_frequency", fmt.Sprintf("%v", device.RXFilterFrequency())})
 	table.Append([]string{"rxbw_cutoff", fmt.Sprintf("%v", device.RXFilterCutoff())})
 
+	// Power Amplifier
+	table.Append([]string{"power", fmt.Sprintf("%v dBm", device.OutputPower())})
+	table.Append([]string{"pa_ramp", paRampToString(device.PARamp())})
+
+	// OOK Demodulator Parameters
+	ook_type, ook_step, ook_dec := device.OOKThreshold()
+	table.Append([]string{"ook_threshold", fmt.Sprintf("%v %v %v", ook_type, ook_step, ook_dec)})
+	table.Append([]string{"ook_fixed_threshold", fmt.Sprintf("%v dB", device.OOKFixedThreshold())})
+	table.Append([]string{"ook_average_filter", fmt.Sprintf("%v", device.OOKAverageFilter())})
+
 	// Packet parameters
 	table.Append([]string{"datamode", dataModeToString(device.DataMode())})
 
@@ -208,6 +273,18 @@ func Status(app *gopi.AppInstance, device sensors.RFM69) error {
 
 	// FIFO
 	table.Append([]string{"fifo_threshold", fmt.Sprintf("%v bytes", device.FIFOThreshold())})
+	if fifo, err := device.FIFOStatus(); err != nil {
+		return err
+	} else {
+		table.Append([]string{"fifo_status", fmt.Sprintf("full=%v not_empty=%v level=%v overrun=%v", fifo.Full, fifo.NotEmpty, fifo.Level, fifo.Overrun)})
+	}
+
+	// IRQ Flags
+	if flags, err := device.IRQFlags(); err != nil {
+		return err
+	} else {
+		table.Append([]string{"irq_flags", fmt.Sprint(flags)})
+	}
 
 	table.Render()
 	return nil
@@ -66,6 +66,25 @@ var (
 		"standard": sensors.RFM_AFCROUTINE_STANDARD,
 		"improved": sensors.RFM_AFCROUTINE_IMPROVED,
 	}
+
+	pa_ramp_map = map[string]sensors.RFMPARamp{
+		"3400us": sensors.RFM_PARAMP_3400US,
+		"2000us": sensors.RFM_PARAMP_2000US,
+		"1000us": sensors.RFM_PARAMP_1000US,
+		"500us":  sensors.RFM_PARAMP_500US,
+		"250us":  sensors.RFM_PARAMP_250US,
+		"125us":  sensors.RFM_PARAMP_125US,
+		"100us":  sensors.RFM_PARAMP_100US,
+		"62us":   sensors.RFM_PARAMP_62US,
+		"50us":   sensors.RFM_PARAMP_50US,
+		"40us":   sensors.RFM_PARAMP_40US,
+		"31us":   sensors.RFM_PARAMP_31US,
+		"25us":   sensors.RFM_PARAMP_25US,
+		"20us":   sensors.RFM_PARAMP_20US,
+		"15us":   sensors.RFM_PARAMP_15US,
+		"12us":   sensors.RFM_PARAMP_12US,
+		"10us":   sensors.RFM_PARAMP_10US,
+	}
 )
 
 /////////////////////////////////////////////////////////////////////
@@ -263,3 +282,23 @@ func stringToAFCRoutine(value string) (sensors.RFMAFCRoutine, error) {
 		return routine, nil
 	}
 }
+
+/////////////////////////////////////////////////////////////////////
+// POWER AMPLIFIER
+
+func stringToPARamp(value string) (sensors.RFMPARamp, error) {
+	if ramp, ok := pa_ramp_map[value]; ok == false {
+		return 0, fmt.Errorf("Invalid pa_ramp flag: %v", value)
+	} else {
+		return ramp, nil
+	}
+}
+
+func paRampToString(value sensors.RFMPARamp) string {
+	for k, v := range pa_ramp_map {
+		if value == v {
+			return k
+		}
+	}
+	return fmt.Sprint(value)
+}
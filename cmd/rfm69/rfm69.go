@@ -171,6 +171,12 @@ func setParametersAFC(app *gopi.AppInstance, device sensors.RFM69) error {
 		}
 	}
 
+	if value, exists := app.AppFlags.GetInt("afc_offset"); exists {
+		if err := device.SetAFCLowBetaOffset(value); err != nil {
+			return err
+		}
+	}
+
 	if value, exists := app.AppFlags.GetString("afc_routine"); exists {
 		if routine, err := stringToAFCRoutine(value); err != nil {
 			return err
@@ -183,6 +189,25 @@ func setParametersAFC(app *gopi.AppInstance, device sensors.RFM69) error {
 	return nil
 }
 
+func setParametersPower(app *gopi.AppInstance, device sensors.RFM69) error {
+	if value, exists := app.AppFlags.GetInt("power"); exists {
+		if err := device.SetOutputPower(value); err != nil {
+			return err
+		}
+	}
+
+	if value, exists := app.AppFlags.GetString("pa_ramp"); exists {
+		if ramp, err := stringToPARamp(value); err != nil {
+			return err
+		} else if err := device.SetPARamp(ramp); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
 func setParametersNodeBroadcastAddr(app *gopi.AppInstance, device sensors.RFM69) error {
 	if value, exists := app.AppFlags.GetString("node_addr"); exists {
 		if addr, err := hex.DecodeString(value); err != nil || len(addr) != 1 {
@@ -262,6 +287,23 @@ func setParametersFIFO(app *gopi.AppInstance, device sensors.RFM69) error {
 	return nil
 }
 
+func setParametersSensitivity(app *gopi.AppInstance, device sensors.RFM69) error {
+	if enabled, exists := app.AppFlags.GetBool("boost"); exists {
+		if err := device.SetSensitivityBoost(enabled); err != nil {
+			return err
+		}
+	}
+
+	if enabled, exists := app.AppFlags.GetBool("dagc"); exists {
+		if err := device.SetDAGC(enabled); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
 func setParameters(app *gopi.AppInstance, device sensors.RFM69) error {
 	if err := setParametersMode(app, device); err != nil {
 		return err
@@ -281,6 +323,12 @@ func setParameters(app *gopi.AppInstance, device sensors.RFM69) error {
 	if err := setParametersAFC(app, device); err != nil {
 		return err
 	}
+	if err := setParametersPower(app, device); err != nil {
+		return err
+	}
+	if err := setParametersSensitivity(app, device); err != nil {
+		return err
+	}
 	if err := setParametersNodeBroadcastAddr(app, device); err != nil {
 		return err
 	}
@@ -368,9 +416,18 @@ func main() {
 	config.AppFlags.FlagString("packet_crc", "", "Packet CRC (off, autoclear_off, autoclear_on)")
 	config.AppFlags.FlagString("afc_mode", "", "AFC Mode (off, on, autoclear), ")
 	config.AppFlags.FlagString("afc_routine", "", "AFC Routine (standard, improved)")
+	config.AppFlags.FlagInt("afc_offset", 0, "Low-beta AFC Offset (Hz), used by the improved routine")
+	config.AppFlags.FlagInt("power", 0, "Output Power (dBm)")
+	config.AppFlags.FlagString("pa_ramp", "", "PA Ramp Time (3400us,2000us,...,40us,...,10us)")
+	config.AppFlags.FlagBool("boost", false, "LNA Sensitivity Boost")
+	config.AppFlags.FlagBool("dagc", false, "Continuous DAGC")
 	config.AppFlags.FlagUint("fifo_threshold", 0, "FIFO Threshold (bytes)")
 	config.AppFlags.FlagDuration("timeout", 5*time.Second, "FIFO and Payload read timeout")
 	config.AppFlags.FlagFloat64("temp_calibration", 0, "Temperature Calibration Offset")
+	config.AppFlags.FlagFloat64("scan_from", 433050, "RSSI Scan Start Frequency (kHz)")
+	config.AppFlags.FlagFloat64("scan_to", 434790, "RSSI Scan End Frequency (kHz)")
+	config.AppFlags.FlagFloat64("scan_step", 25, "RSSI Scan Step (kHz)")
+	config.AppFlags.FlagDuration("tx_duration", 10*time.Second, "Carrier and PN9 test transmit duration")
 
 	// Run the command line tool
 	os.Exit(gopi.CommandLineTool(config, MainLoop))
@@ -10,6 +10,8 @@
 package sensors
 
 import (
+	"fmt"
+	"sync"
 	"time"
 	// Frameworks
 	"context"
@@ -27,6 +29,53 @@ type (
 	OTDataType     uint8
 )
 
+// MiHomeDiagnostics is a snapshot of the MiHome driver state
+type MiHomeDiagnostics struct {
+	Mode          string             `json:"mode"`
+	ModeHistory   []MiHomeModeChange `json:"mode_history"`
+	RadioMode     string             `json:"radio_mode"`
+	Modulation    string             `json:"modulation"`
+	FreqCarrier   uint               `json:"freq_carrier"`
+	FIFOThreshold uint8              `json:"fifo_threshold"`
+	CID           string             `json:"cid"`
+	Repeat        uint               `json:"repeat"`
+	Uptime        time.Duration      `json:"uptime"`
+	PacketsRX     uint64             `json:"packets_rx"`
+	PacketsTX     uint64             `json:"packets_tx"`
+	PacketErrors  uint64             `json:"packet_errors"`
+	Dropped       uint64             `json:"dropped"`
+	DecodeDropped uint64             `json:"decode_dropped"`
+	LEDWrites     uint64             `json:"led_writes"`
+	LEDCoalesced  uint64             `json:"led_coalesced"`
+	LEDTime       time.Duration      `json:"led_time"`
+}
+
+// MiHomeSceneAction switches a socket on or off as part of a scene
+type MiHomeSceneAction struct {
+	CID    string // OOK device address, or empty for the default address
+	Socket uint   // Socket 1-4, or zero for all sockets
+	On     bool   // Switch on or off
+}
+
+type MiHomeModeChange struct {
+	Timestamp time.Time `json:"ts"`
+	Mode      string    `json:"mode"`
+	Reason    string    `json:"reason"`
+}
+
+// OTState is the last value reported for a parameter of a sensor. The
+// state is unknown when no value has been reported within the TTL of the
+// parameter, in which case Value is empty and Updated is the time the
+// last value was reported, or zero if none has been
+type OTState struct {
+	SensorID  uint32      `json:"sensor"`
+	ProductID uint8       `json:"product"`
+	Parameter OTParameter `json:"parameter"`
+	Known     bool        `json:"known"`
+	Value     string      `json:"value,omitempty"`
+	Updated   time.Time   `json:"ts"`
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // INTERFACES
 
@@ -40,6 +89,10 @@ type ENER314 interface {
 	// Send off signal - when no sockets specified then
 	// sends to all sockets
 	Off(sockets ...uint) error
+
+	// Send dim signal for a dimmer socket - when socket is
+	// zero then sends to all sockets. Level zero is off
+	Dim(socket uint, level uint) error
 }
 
 type MiHome interface {
@@ -52,8 +105,34 @@ type MiHome interface {
 	// Receive payloads from radio, and emit through pubsub
 	Receive(ctx context.Context, mode MiHomeMode) error
 
+	// Return the modes which can be used with Receive
+	SupportedModes() []MiHomeMode
+
 	// Measure Temperature
 	MeasureTemperature() (float32, error)
+
+	// Get and set the temperature calibration offset
+	TempOffset() float32
+	SetTempOffset(offset float32) error
+
+	// Derive the temperature calibration offset from a
+	// known reference temperature
+	Calibrate(reference float32) error
+
+	// Define a named scene, or remove the scene when there
+	// are no actions
+	SetScene(name string, actions ...MiHomeSceneAction) error
+
+	// Transmit the commands for a named scene
+	TriggerScene(name string) error
+
+	// Return a snapshot of the driver and radio state
+	Diagnostics() MiHomeDiagnostics
+
+	// Subscribe to typed OTEvent values, which are emitted
+	// alongside the gopi.Event values from Subscribe
+	SubscribeOTEvent() <-chan OTEvent
+	UnsubscribeOTEvent(<-chan OTEvent)
 }
 
 type OpenThings interface {
@@ -61,6 +140,28 @@ type OpenThings interface {
 
 	// Decode a message
 	Decode(payload []byte) (OTMessage, error)
+
+	// Encode and encrypt a message with records, for sending to a device
+	Encode(manufacturer OTManufacturer, product_id uint8, sensor_id uint32, records []OTRecord) ([]byte, error)
+
+	// Return a builder for a message to a device
+	NewMessage(manufacturer OTManufacturer, product_id uint8, sensor_id uint32) OTMessageBuilder
+}
+
+type OTMessageBuilder interface {
+	// Append a record with a bool, integer, float, string or OTRecord value.
+	// After the first error further records are ignored
+	Append(name OTParameter, value interface{}) OTMessageBuilder
+
+	// Append a command to set a parameter to a value, or a request for
+	// the device to report the parameter when the value is nil
+	Command(name OTParameter, value interface{}) OTMessageBuilder
+
+	// Return the records appended
+	Records() []OTRecord
+
+	// Encode and encrypt the message, or return the first error
+	Encode() ([]byte, error)
 }
 
 type OTMessage interface {
@@ -71,6 +172,10 @@ type OTMessage interface {
 	CRC() uint16
 	Payload() []byte
 	Records() []OTRecord
+
+	// Return true if the message repeats a recent message from the
+	// sensor, when replay tracking is enabled
+	Replayed() bool
 }
 
 type OTEvent interface {
@@ -79,22 +184,143 @@ type OTEvent interface {
 	Timestamp() time.Time
 	Message() OTMessage
 	Reason() error
+
+	// Name of the decoder which produced the message
+	Decoder() string
+}
+
+type TemperatureEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	Temperature() float32
+}
+
+// MiHomeModeEvent is emitted when the driver mode or the
+// radio mode changes
+type MiHomeModeEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	Mode() MiHomeMode
+	RadioMode() RFMMode
+	Reason() string
+}
+
+// MiHomeControlEvent is emitted when a legacy OOK command from
+// another controller is received in control mode
+type MiHomeControlEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	Address() []byte // 20 bit address, as 3 bytes
+	Command() uint8  // 4 bit command
+}
+
+// MiHomeEvent is a semantic event synthesized from a decoded message
+type MiHomeEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	ProductID() uint8
+	SensorID() uint32
+	Message() OTMessage
+}
+
+type TemperatureReport interface {
+	MiHomeEvent
+
+	// Temperature in Celsius
+	Temperature() float32
+}
+
+type PowerReport interface {
+	MiHomeEvent
+
+	// Power in watts and VAR, voltage and frequency. Values
+	// which were not reported are zero
+	RealPower() float32
+	ReactivePower() float32
+	Voltage() float32
+	Frequency() float32
+}
+
+type SwitchState interface {
+	MiHomeEvent
+
+	// Return true if the switch is on
+	On() bool
+}
+
+type JoinRequest interface {
+	MiHomeEvent
+
+	// Manufacturer of the device requesting to join
+	Manufacturer() OTManufacturer
+}
+
+type BatteryAlarm interface {
+	MiHomeEvent
+
+	// Reported battery voltage
+	BatteryVoltage() float32
 }
 
 type OTRecord interface {
 	Name() OTParameter
 	Type() OTDataType
 	StringValue() (string, error)
+
+	// Return true for a command, or a request to report the parameter
+	IsRequest() bool
+
+	// Return the parameter, type and length, and data bytes of the record,
+	// or nil if the record was not decoded from a message
+	Raw() []byte
+
+	// Typed values, which return ErrWrongType when the record is not
+	// for the parameter or is not a numeric type
+	BoolValue() (bool, error)
+	TemperatureCelsius() (float64, error)
+	PowerWatts() (float64, error)
+	VoltageVolts() (float64, error)
 }
 
+// OTStateCache keeps the last value reported for each parameter of each
+// sensor, so that the current state can be returned without waiting for
+// the next message. Values expire into the unknown state when they are
+// not reported again within the TTL of the parameter
+type OTStateCache interface {
+	gopi.Driver
+
+	// Add the records of a message received at a time
+	Add(ts time.Time, message OTMessage)
+
+	// Return the state of a parameter of a sensor
+	State(sensor uint32, parameter OTParameter) OTState
+
+	// Return the states of all parameters reported by a sensor
+	States(sensor uint32) []OTState
+
+	// Return and set the TTL for a parameter
+	TTL(parameter OTParameter) time.Duration
+	SetTTL(parameter OTParameter, ttl time.Duration) error
+}
+
+// OTProductDecoder interprets the records decoded from a message for a
+// product of a registered manufacturer, and returns the records for the
+// message. It can replace records with its own OTRecord implementations
+type OTProductDecoder func(product_id uint8, records []OTRecord) ([]OTRecord, error)
+
 ////////////////////////////////////////////////////////////////////////////////
 // CONSTANTS
 
 const (
-	MIHOME_MODE_NONE    MiHomeMode = iota
-	MIHOME_MODE_MONITOR            // FSK
-	MIHOME_MODE_CONTROL            // OOK
-	MIHOME_MODE_MAX     = MIHOME_MODE_CONTROL
+	MIHOME_MODE_NONE       MiHomeMode = iota
+	MIHOME_MODE_MONITOR               // FSK
+	MIHOME_MODE_CONTROL               // OOK
+	MIHOME_MODE_CONTROL_RX            // OOK receive
+	MIHOME_MODE_MAX        = MIHOME_MODE_CONTROL_RX
 )
 
 const (
@@ -110,6 +336,10 @@ const (
 	// OTParameter
 	OT_PARAM_NONE              OTParameter = 0x00
 	OT_PARAM_ALARM             OTParameter = 0x21
+	OT_PARAM_EXERCISE_VALVE    OTParameter = 0x23
+	OT_PARAM_LOW_POWER_MODE    OTParameter = 0x24
+	OT_PARAM_VALVE_STATE       OTParameter = 0x25
+	OT_PARAM_DIAGNOSTICS       OTParameter = 0x26
 	OT_PARAM_DEBUG_OUTPUT      OTParameter = 0x2D
 	OT_PARAM_IDENTIFY          OTParameter = 0x3F
 	OT_PARAM_SOURCE_SELECTOR   OTParameter = 0x40
@@ -160,6 +390,70 @@ const (
 	OT_PARAM_3PHASE_POWER      OTParameter = 0x7C
 )
 
+var (
+	// Names and units of the OpenThings parameters, and whether they
+	// can be written with a command record
+	ot_parameters = map[OTParameter]struct {
+		name      string
+		unit      string
+		writeable bool
+	}{
+		OT_PARAM_ALARM:             {"Alarm", "", false},
+		OT_PARAM_EXERCISE_VALVE:    {"Exercise Valve", "", true},
+		OT_PARAM_LOW_POWER_MODE:    {"Low Power Mode", "", true},
+		OT_PARAM_VALVE_STATE:       {"Valve State", "", true},
+		OT_PARAM_DIAGNOSTICS:       {"Diagnostics", "", true},
+		OT_PARAM_DEBUG_OUTPUT:      {"Debug Output", "", false},
+		OT_PARAM_IDENTIFY:          {"Identify", "", true},
+		OT_PARAM_SOURCE_SELECTOR:   {"Source Selector", "", true},
+		OT_PARAM_WATER_DETECTOR:    {"Water Detector", "", false},
+		OT_PARAM_GLASS_BREAKAGE:    {"Glass Breakage", "", false},
+		OT_PARAM_CLOSURES:          {"Closures", "", false},
+		OT_PARAM_DOOR_BELL:         {"Door Bell", "", false},
+		OT_PARAM_ENERGY:            {"Energy", "kWh", false},
+		OT_PARAM_FALL_SENSOR:       {"Fall Sensor", "", false},
+		OT_PARAM_GAS_VOLUME:        {"Gas Volume", "m³", false},
+		OT_PARAM_AIR_PRESSURE:      {"Air Pressure", "mbar", false},
+		OT_PARAM_ILLUMINANCE:       {"Illuminance", "lux", false},
+		OT_PARAM_LEVEL:             {"Level", "", false},
+		OT_PARAM_RAINFALL:          {"Rainfall", "mm", false},
+		OT_PARAM_APPARENT_POWER:    {"Apparent Power", "VA", false},
+		OT_PARAM_POWER_FACTOR:      {"Power Factor", "", false},
+		OT_PARAM_REPORT_PERIOD:     {"Report Period", "s", true},
+		OT_PARAM_SMOKE_DETECTOR:    {"Smoke Detector", "", false},
+		OT_PARAM_TIME_AND_DATE:     {"Time and Date", "s", true},
+		OT_PARAM_VIBRATION:         {"Vibration", "", false},
+		OT_PARAM_WATER_VOLUME:      {"Water Volume", "l", false},
+		OT_PARAM_WIND_SPEED:        {"Wind Speed", "m/s", false},
+		OT_PARAM_GAS_PRESSURE:      {"Gas Pressure", "Pa", false},
+		OT_PARAM_BATTERY_LEVEL:     {"Battery Level", "V", false},
+		OT_PARAM_CO_DETECTOR:       {"CO Detector", "", false},
+		OT_PARAM_DOOR_SENSOR:       {"Door Sensor", "", false},
+		OT_PARAM_EMERGENCY:         {"Emergency", "", false},
+		OT_PARAM_FREQUENCY:         {"Frequency", "Hz", false},
+		OT_PARAM_GAS_FLOW_RATE:     {"Gas Flow Rate", "m³/h", false},
+		OT_PARAM_RELATIVE_HUMIDITY: {"Relative Humidity", "%", false},
+		OT_PARAM_CURRENT:           {"Current", "A", false},
+		OT_PARAM_JOIN:              {"Join", "", true},
+		OT_PARAM_RF_QUALITY:        {"RF Quality", "", false},
+		OT_PARAM_LIGHT_LEVEL:       {"Light Level", "", false},
+		OT_PARAM_MOTION_DETECTOR:   {"Motion Detector", "", false},
+		OT_PARAM_OCCUPANCY:         {"Occupancy", "", false},
+		OT_PARAM_REAL_POWER:        {"Real Power", "W", false},
+		OT_PARAM_REACTIVE_POWER:    {"Reactive Power", "VAR", false},
+		OT_PARAM_ROTATION_SPEED:    {"Rotation Speed", "rpm", false},
+		OT_PARAM_SWITCH_STATE:      {"Switch State", "", true},
+		OT_PARAM_TEMPERATURE:       {"Temperature", "°C", true},
+		OT_PARAM_VOLTAGE:           {"Voltage", "V", false},
+		OT_PARAM_WATER_FLOW_RATE:   {"Water Flow Rate", "l/h", false},
+		OT_PARAM_WATER_PRESSURE:    {"Water Pressure", "Pa", false},
+		OT_PARAM_3PHASE_POWER1:     {"Phase 1 Power", "W", false},
+		OT_PARAM_3PHASE_POWER2:     {"Phase 2 Power", "W", false},
+		OT_PARAM_3PHASE_POWER3:     {"Phase 3 Power", "W", false},
+		OT_PARAM_3PHASE_POWER:      {"3 Phase Power", "W", false},
+	}
+)
+
 const (
 	// OTDataType
 	OT_DATATYPE_UDEC_0  OTDataType = 0x00
@@ -178,29 +472,88 @@ const (
 	OT_DATATYPE_FLOAT   OTDataType = 0x0F
 )
 
+var (
+	// Names and product decoders of the manufacturers, which includes
+	// those registered with RegisterManufacturer
+	ot_manufacturers = map[OTManufacturer]ot_manufacturer{
+		OT_MANUFACTURER_SENTEC:      {"OT_MANUFACTURER_SENTEC", nil},
+		OT_MANUFACTURER_HILDERBRAND: {"OT_MANUFACTURER_HILDERBRAND", nil},
+		OT_MANUFACTURER_ENERGENIE:   {"OT_MANUFACTURER_ENERGENIE", nil},
+	}
+	ot_manufacturers_lock sync.RWMutex
+)
+
+type ot_manufacturer struct {
+	name    string
+	decoder OTProductDecoder
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MANUFACTURERS
+
+// RegisterManufacturer adds a manufacturer which uses OpenThings framing,
+// so that messages from the manufacturer are decoded. The decoder is
+// called with the records of each message, or can be nil. It returns
+// ErrBadParameter if the manufacturer is already registered
+func RegisterManufacturer(id OTManufacturer, name string, decoder OTProductDecoder) error {
+	ot_manufacturers_lock.Lock()
+	defer ot_manufacturers_lock.Unlock()
+
+	if id == OT_MANUFACTURER_NONE || name == "" {
+		return gopi.ErrBadParameter
+	} else if _, exists := ot_manufacturers[id]; exists {
+		return gopi.ErrBadParameter
+	} else {
+		ot_manufacturers[id] = ot_manufacturer{name, decoder}
+		return nil
+	}
+}
+
+// Known returns true if the manufacturer is one of the OT_MANUFACTURER
+// values or has been registered
+func (m OTManufacturer) Known() bool {
+	ot_manufacturers_lock.RLock()
+	defer ot_manufacturers_lock.RUnlock()
+	_, exists := ot_manufacturers[m]
+	return exists
+}
+
+// ProductDecoder returns the product decoder registered for the
+// manufacturer, or nil
+func (m OTManufacturer) ProductDecoder() OTProductDecoder {
+	ot_manufacturers_lock.RLock()
+	defer ot_manufacturers_lock.RUnlock()
+	return ot_manufacturers[m].decoder
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // STRINGIFY
 
 func (m MiHomeMode) String() string {
 	switch m {
+	case MIHOME_MODE_NONE:
+		return "MIHOME_MODE_NONE"
 	case MIHOME_MODE_MONITOR:
 		return "MIHOME_MODE_MONITOR"
 	case MIHOME_MODE_CONTROL:
 		return "MIHOME_MODE_CONTROL"
+	case MIHOME_MODE_CONTROL_RX:
+		return "MIHOME_MODE_CONTROL_RX"
 	default:
 		return "[?? Invalid MiHomeMode value]"
 	}
 }
 
+func (a MiHomeSceneAction) String() string {
+	return fmt.Sprintf("<sensors.MiHomeSceneAction>{ cid=\"%v\" socket=%v on=%v }", a.CID, a.Socket, a.On)
+}
+
 func (m OTManufacturer) String() string {
-	switch m {
-	case OT_MANUFACTURER_SENTEC:
-		return "OT_MANUFACTURER_SENTEC"
-	case OT_MANUFACTURER_HILDERBRAND:
-		return "OT_MANUFACTURER_HILDERBRAND"
-	case OT_MANUFACTURER_ENERGENIE:
-		return "OT_MANUFACTURER_ENERGENIE"
-	default:
+	ot_manufacturers_lock.RLock()
+	defer ot_manufacturers_lock.RUnlock()
+	if manufacturer, exists := ot_manufacturers[m]; exists {
+		return manufacturer.name
+	} else {
 		return "[?? Invalid OTManufacturer value]"
 	}
 }
@@ -209,6 +562,14 @@ func (p OTParameter) String() string {
 	switch p {
 	case OT_PARAM_ALARM:
 		return "OT_PARAM_ALARM"
+	case OT_PARAM_EXERCISE_VALVE:
+		return "OT_PARAM_EXERCISE_VALVE"
+	case OT_PARAM_LOW_POWER_MODE:
+		return "OT_PARAM_LOW_POWER_MODE"
+	case OT_PARAM_VALVE_STATE:
+		return "OT_PARAM_VALVE_STATE"
+	case OT_PARAM_DIAGNOSTICS:
+		return "OT_PARAM_DIAGNOSTICS"
 	case OT_PARAM_DEBUG_OUTPUT:
 		return "OT_PARAM_DEBUG_OUTPUT"
 	case OT_PARAM_IDENTIFY:
@@ -310,6 +671,30 @@ func (p OTParameter) String() string {
 	}
 }
 
+// Known returns true if the parameter is one of the OT_PARAM values
+func (p OTParameter) Known() bool {
+	_, exists := ot_parameters[p]
+	return exists
+}
+
+// Name returns the name of the parameter, or an empty string if the
+// parameter is not known
+func (p OTParameter) Name() string {
+	return ot_parameters[p].name
+}
+
+// Unit returns the unit of the parameter value, or an empty string for
+// states, detectors and unknown parameters
+func (p OTParameter) Unit() string {
+	return ot_parameters[p].unit
+}
+
+// IsWriteable returns true if the parameter can be set on a device
+// with a command record
+func (p OTParameter) IsWriteable() bool {
+	return ot_parameters[p].writeable
+}
+
 func (t OTDataType) String() string {
 	switch t {
 	case OT_DATATYPE_UDEC_0:
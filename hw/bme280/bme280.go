@@ -10,12 +10,15 @@
 package bme280
 
 import (
+	"context"
 	"fmt"
-	"math"
 	"time"
 
 	gopi "github.com/djthorpe/gopi"
 	sensors "github.com/djthorpe/sensors"
+	altimetry "github.com/djthorpe/sensors/util/altimetry"
+	backoff "github.com/djthorpe/sensors/util/backoff"
+	enclosure "github.com/djthorpe/sensors/util/enclosure"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -26,8 +29,19 @@ type BME280_I2C struct {
 	// the I2C driver
 	I2C gopi.I2C
 
-	// The slave address, usually 0x77 or 0x76
+	// The slave address, usually 0x77 or 0x76, or zero to detect it
 	Slave uint8
+
+	// Compensation for a sensor inside the Raspberry Pi enclosure
+	Enclosure enclosure.Model
+
+	// Policy for polling the device status and retrying failed reads
+	// while waiting for a measurement, or zero for the default
+	Backoff backoff.Policy
+
+	// Power policy, for switching between forced and normal mode
+	// automatically from the sample rate
+	Power sensors.BME280PowerPolicy
 }
 
 // SPI Configuration
@@ -37,6 +51,17 @@ type BME280_SPI struct {
 
 	// SPI Device speed in Hertz
 	Speed uint32
+
+	// Compensation for a sensor inside the Raspberry Pi enclosure
+	Enclosure enclosure.Model
+
+	// Policy for polling the device status and retrying failed reads
+	// while waiting for a measurement, or zero for the default
+	Backoff backoff.Policy
+
+	// Power policy, for switching between forced and normal mode
+	// automatically from the sample rate
+	Power sensors.BME280PowerPolicy
 }
 
 // Concrete driver
@@ -54,7 +79,14 @@ type bme280 struct {
 	osrs_p      sensors.BME280Oversample
 	osrs_h      sensors.BME280Oversample
 	spi3w_en    bool
+	enclosure   enclosure.Model
+	backoff     backoff.Policy
 	log         gopi.Logger
+
+	// Power policy and the average interval between samples
+	power_policy   sensors.BME280PowerPolicy
+	power_last     time.Time
+	power_interval time.Duration
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -62,6 +94,7 @@ type bme280 struct {
 
 const (
 	BME280_I2CSLAVE_DEFAULT   uint8  = 0x77
+	BME280_I2CSLAVE_ALT       uint8  = 0x76
 	BME280_SPI_MAXSPEEDHZ     uint32 = 5000
 	BME280_CHIPID_DEFAULT     uint8  = 0x60
 	BME280_SOFTRESET_VALUE    uint8  = 0xB6
@@ -70,6 +103,14 @@ const (
 	BME280_SKIPHUMID_VALUE    int32  = 0x8000
 )
 
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Default status polling policy, which waits for about a second
+	BME280_BACKOFF_DEFAULT = backoff.Policy{Retries: 50, Initial: time.Millisecond, Max: 25 * time.Millisecond, Jitter: 0.2}
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 // INTERFACE - GET
 
@@ -114,6 +155,21 @@ func (this *bme280) Status() (bool, bool, error) {
 	}
 }
 
+// waitStatus polls until the device is not measuring or updating,
+// backing off between polls. Failed status reads are retried, and
+// ErrDeviceTimeout is returned when the retries are used up
+func (this *bme280) waitStatus() error {
+	return this.backoff.Retry(context.Background(), func() error {
+		if measuring, updating, err := this.Status(); err != nil {
+			return err
+		} else if measuring || updating {
+			return sensors.ErrDeviceTimeout
+		} else {
+			return nil
+		}
+	})
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // INTERFACE - SET
 
@@ -125,13 +181,8 @@ func (this *bme280) SoftReset() error {
 	}
 
 	// Wait for no measuring or updating
-	// TODO: TIMEOUT
-	for {
-		if measuring, updating, err := this.Status(); err != nil {
-			return err
-		} else if measuring == false && updating == false {
-			break
-		}
+	if err := this.waitStatus(); err != nil {
+		return err
 	}
 
 	// Read registers and return
@@ -168,13 +219,8 @@ func (this *bme280) SetOversample(osrs_t, osrs_p, osrs_h sensors.BME280Oversampl
 	}
 
 	// Wait for no measuring or updating
-	// TODO: TIMEOUT
-	for {
-		if measuring, updating, err := this.Status(); err != nil {
-			return err
-		} else if measuring == false && updating == false {
-			break
-		}
+	if err := this.waitStatus(); err != nil {
+		return err
 	}
 
 	// Read values back
@@ -237,14 +283,14 @@ func (this *bme280) SetStandby(t_sb sensors.BME280Standby) error {
 func (this *bme280) ReadSample() (float64, float64, float64, error) {
 	this.log.Debug2("<sensors.BME280.ReadSample>{}")
 
+	// Switch mode according to the power policy
+	if err := this.applyPowerPolicy(); err != nil {
+		return 0, 0, 0, err
+	}
+
 	// Wait for no measuring or updating
-	// TODO: Timeout
-	for {
-		if measuring, updating, err := this.Status(); err != nil {
-			return 0, 0, 0, err
-		} else if measuring == false && updating == false {
-			break
-		}
+	if err := this.waitStatus(); err != nil {
+		return 0, 0, 0, err
 	}
 
 	// Set mode of operation if we're in FORCED or SLEEP mode, and wait until we
@@ -288,6 +334,16 @@ func (this *bme280) ReadSample() (float64, float64, float64, error) {
 	}
 	t_humidity := this.toRelativeHumidity(adc_h, t_fine)
 
+	// Compensate for the enclosure
+	if this.enclosure.Zero() == false {
+		if t_room, h_room, err := this.enclosure.Compensate(t_celcius, t_humidity); err != nil {
+			return 0, 0, 0, err
+		} else {
+			this.log.Debug2("Enclosure compensation, temperature=%.2f => %.2f humidity=%.2f => %.2f", t_celcius, t_room, t_humidity, h_room)
+			t_celcius, t_humidity = t_room, h_room
+		}
+	}
+
 	// Return success
 	return t_celcius, t_pressure, t_humidity, nil
 }
@@ -296,7 +352,7 @@ func (this *bme280) ReadSample() (float64, float64, float64, error) {
 // the sealevel pressure in Pascals. You can use a standard value of
 // sensors.BME280_PRESSURE_SEALEVEL for sealevel
 func (this *bme280) AltitudeForPressure(atmospheric, sealevel float64) float64 {
-	return 44330.0 * (1.0 - math.Pow(atmospheric/sealevel, (1.0/5.255)))
+	return altimetry.Altitude(atmospheric, sealevel)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
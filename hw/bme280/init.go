@@ -11,8 +11,11 @@ package bme280
 
 import (
 	"errors"
+	"fmt"
 
 	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+	enclosure "github.com/djthorpe/sensors/util/enclosure"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -22,19 +25,30 @@ func init() {
 	// Register bme280 using I2C
 	gopi.RegisterModule(gopi.Module{
 		Name:     "sensors/bme280:i2c",
-		Requires: []string{"i2c"},
+		Requires: []string{"i2c", "sensors/enclosure"},
 		Type:     gopi.MODULE_TYPE_OTHER,
 		Config: func(config *gopi.AppConfig) {
 			config.AppFlags.FlagUint("i2c.slave", 0, "I2C Slave address")
+			configPower(config)
 		},
 		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
 			slave, _ := app.AppFlags.GetUint("i2c.slave")
 			if slave > 0x7F {
 				return nil, errors.New("Invalid -i2c.slave flag")
 			}
+			power, err := powerPolicy(app)
+			if err != nil {
+				return nil, err
+			}
+			model, err := enclosureModel(app)
+			if err != nil {
+				return nil, err
+			}
 			return gopi.Open(BME280_I2C{
-				Slave: uint8(slave),
-				I2C:   app.ModuleInstance("i2c").(gopi.I2C),
+				Slave:     uint8(slave),
+				I2C:       app.ModuleInstance("i2c").(gopi.I2C),
+				Enclosure: model,
+				Power:     power,
 			}, app.Logger)
 		},
 	})
@@ -42,17 +56,60 @@ func init() {
 	// Register bme280 using SPI
 	gopi.RegisterModule(gopi.Module{
 		Name:     "sensors/bme280:spi",
-		Requires: []string{"spi"},
+		Requires: []string{"spi", "sensors/enclosure"},
 		Type:     gopi.MODULE_TYPE_OTHER,
 		Config: func(config *gopi.AppConfig) {
 			config.AppFlags.FlagUint("spi.speed", 0, "SPI Communication Speed, Hz")
+			configPower(config)
 		},
 		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
 			speed, _ := app.AppFlags.GetUint("spi.speed")
+			power, err := powerPolicy(app)
+			if err != nil {
+				return nil, err
+			}
+			model, err := enclosureModel(app)
+			if err != nil {
+				return nil, err
+			}
 			return gopi.Open(BME280_SPI{
-				Speed: uint32(speed),
-				SPI:   app.ModuleInstance("spi").(gopi.SPI),
+				Speed:     uint32(speed),
+				SPI:       app.ModuleInstance("spi").(gopi.SPI),
+				Enclosure: model,
+				Power:     power,
 			}, app.Logger)
 		},
 	})
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// ENCLOSURE
+
+// Return the model from the sensors/enclosure module, which registers
+// the enclosure flags
+func enclosureModel(app *gopi.AppInstance) (enclosure.Model, error) {
+	if driver, ok := app.ModuleInstance("sensors/enclosure").(enclosure.Enclosure); !ok {
+		return enclosure.Model{}, fmt.Errorf("Missing or invalid enclosure module")
+	} else {
+		return driver.Model(), nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// POWER POLICY
+
+func configPower(config *gopi.AppConfig) {
+	config.AppFlags.FlagString("bme280.power", "manual", "Power policy (manual, auto)")
+}
+
+func powerPolicy(app *gopi.AppInstance) (sensors.BME280PowerPolicy, error) {
+	power, _ := app.AppFlags.GetString("bme280.power")
+	switch power {
+	case "manual", "":
+		return sensors.BME280_POWER_MANUAL, nil
+	case "auto":
+		return sensors.BME280_POWER_AUTO, nil
+	default:
+		return 0, errors.New("Invalid -bme280.power flag")
+	}
+}
@@ -13,7 +13,7 @@ import (
 	"fmt"
 
 	gopi "github.com/djthorpe/gopi"
-	sensors "github.com/djthorpe/sensors"
+	i2caddr "github.com/djthorpe/sensors/util/i2caddr"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -25,30 +25,42 @@ func (config BME280_I2C) Open(log gopi.Logger) (gopi.Driver, error) {
 	this := new(bme280)
 	this.i2c = config.I2C
 	this.log = log
-	this.slave = BME280_I2CSLAVE_DEFAULT
+	this.enclosure = config.Enclosure
+	this.backoff = BME280_BACKOFF_DEFAULT
 
-	if config.Slave != 0 {
-		this.slave = config.Slave
+	if config.Backoff.Zero() == false {
+		this.backoff = config.Backoff
+	}
+	if err := this.SetPowerPolicy(config.Power); err != nil {
+		return nil, err
 	}
 
 	if this.i2c == nil {
 		return nil, gopi.ErrBadParameter
 	}
 
-	// Detect slave
-	if detected, err := this.i2c.DetectSlave(this.slave); err != nil {
+	// Detect slave, or select one of the two addresses when not set
+	candidates := []uint8{BME280_I2CSLAVE_DEFAULT, BME280_I2CSLAVE_ALT}
+	if config.Slave != 0 {
+		candidates = []uint8{config.Slave}
+	}
+	if slave, err := i2caddr.Select(this.i2c, candidates, "BME280"); err != nil {
+		return nil, err
+	} else if err := i2caddr.Claim(this.i2c, slave, "sensors/bme280"); err != nil {
 		return nil, err
-	} else if detected == false {
-		return nil, sensors.ErrNoDevice
+	} else {
+		this.slave = slave
 	}
 
 	// Set slave
 	if err := this.i2c.SetSlave(this.slave); err != nil {
+		i2caddr.Release(this.i2c, this.slave)
 		return nil, err
 	}
 
 	// Now perform additional setup
 	if err := this.setup(); err != nil {
+		i2caddr.Release(this.i2c, this.slave)
 		return nil, err
 	}
 
@@ -61,6 +73,15 @@ func (config BME280_SPI) Open(log gopi.Logger) (gopi.Driver, error) {
 
 	this := new(bme280)
 	this.log = log
+	this.enclosure = config.Enclosure
+	this.backoff = BME280_BACKOFF_DEFAULT
+
+	if config.Backoff.Zero() == false {
+		this.backoff = config.Backoff
+	}
+	if err := this.SetPowerPolicy(config.Power); err != nil {
+		return nil, err
+	}
 
 	if config.SPI != nil {
 		this.spi = config.SPI
@@ -91,6 +112,11 @@ func (config BME280_SPI) Open(log gopi.Logger) (gopi.Driver, error) {
 func (this *bme280) Close() error {
 	this.log.Debug2("<sensors.BME280.Close>{ }")
 
+	// Release the slave address
+	if this.i2c != nil {
+		i2caddr.Release(this.i2c, this.slave)
+	}
+
 	// Zero out fields
 	this.i2c = nil
 	this.spi = nil
@@ -0,0 +1,139 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package bme280
+
+import (
+	"time"
+
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Normal mode is used when samples are read more often than this, and
+	// forced mode when they are read less often than twice this
+	BME280_POWER_NORMAL_INTERVAL = time.Second
+)
+
+var (
+	// Standby times, ordered from shortest to longest
+	bme280_standby = []sensors.BME280Standby{
+		sensors.BME280_STANDBY_0P5MS,
+		sensors.BME280_STANDBY_10MS,
+		sensors.BME280_STANDBY_20MS,
+		sensors.BME280_STANDBY_62P5MS,
+		sensors.BME280_STANDBY_125MS,
+		sensors.BME280_STANDBY_250MS,
+		sensors.BME280_STANDBY_500MS,
+		sensors.BME280_STANDBY_1000MS,
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - POWER POLICY
+
+// Return the power policy
+func (this *bme280) PowerPolicy() sensors.BME280PowerPolicy {
+	return this.power_policy
+}
+
+// Set the power policy. With BME280_POWER_AUTO the interval between calls
+// to ReadSample determines the mode: forced mode, sleeping between samples,
+// at low rates and normal mode, with the longest standby time which keeps
+// up with the rate, at high rates
+func (this *bme280) SetPowerPolicy(policy sensors.BME280PowerPolicy) error {
+	this.log.Debug2("<sensors.BME280.SetPowerPolicy>{ policy=%v }", policy)
+	if policy != sensors.BME280_POWER_MANUAL && policy != sensors.BME280_POWER_AUTO {
+		return gopi.ErrBadParameter
+	}
+	this.power_policy = policy
+	this.power_last = time.Time{}
+	this.power_interval = 0
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// applyPowerPolicy updates the average interval between samples and
+// switches between forced and normal mode when the policy is automatic
+func (this *bme280) applyPowerPolicy() error {
+	if this.power_policy != sensors.BME280_POWER_AUTO {
+		return nil
+	}
+
+	// Update the moving average of the interval between samples
+	now := time.Now()
+	if this.power_last.IsZero() == false {
+		if interval := now.Sub(this.power_last); this.power_interval == 0 {
+			this.power_interval = interval
+		} else {
+			this.power_interval = (this.power_interval*3 + interval) / 4
+		}
+	}
+	this.power_last = now
+	if this.power_interval == 0 {
+		return nil
+	}
+
+	// Switch mode
+	switch {
+	case this.mode != sensors.BME280_MODE_NORMAL && this.power_interval < BME280_POWER_NORMAL_INTERVAL:
+		if err := this.setNormalMode(this.powerStandby()); err != nil {
+			return err
+		}
+		// Wait for the first measurement
+		time.Sleep(toMeasurementTime(this.osrs_t, this.osrs_p, this.osrs_h))
+	case this.mode == sensors.BME280_MODE_NORMAL && this.power_interval > BME280_POWER_NORMAL_INTERVAL*2:
+		// ReadSample uses forced mode from sleep
+		if err := this.SetMode(sensors.BME280_MODE_SLEEP); err != nil {
+			return err
+		}
+	case this.mode == sensors.BME280_MODE_NORMAL:
+		if t_sb := this.powerStandby(); t_sb != this.t_sb {
+			if err := this.setNormalMode(t_sb); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// setNormalMode sets the standby time in sleep mode, as writes to the
+// config register may be ignored in normal mode, then enters normal mode
+func (this *bme280) setNormalMode(t_sb sensors.BME280Standby) error {
+	this.log.Debug("<sensors.BME280>setNormalMode{ interval=%v t_sb=%v }", this.power_interval, t_sb)
+	if err := this.SetMode(sensors.BME280_MODE_SLEEP); err != nil {
+		return err
+	} else if err := this.SetStandby(t_sb); err != nil {
+		return err
+	} else if err := this.SetMode(sensors.BME280_MODE_NORMAL); err != nil {
+		return err
+	}
+	return nil
+}
+
+// powerStandby returns the longest standby time for which a new
+// measurement is available for every sample
+func (this *bme280) powerStandby() sensors.BME280Standby {
+	measure := toMeasurementTime(this.osrs_t, this.osrs_p, this.osrs_h)
+	t_sb := bme280_standby[0]
+	for _, value := range bme280_standby {
+		if measure+toStandbyTime(value) <= this.power_interval {
+			t_sb = value
+		}
+	}
+	return t_sb
+}
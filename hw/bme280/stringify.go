@@ -24,7 +24,7 @@ func (this *bme280) String() string {
 	if this.spi != nil {
 		bus = fmt.Sprintf("%v", this.spi)
 	}
-	return fmt.Sprintf("<sensors.BME280>{ chipid=0x%02X version=0x%02X mode=%v filter=%v t_sb=%v spi3w_en=%v osrs_t=%v osrs_p=%v osrs_h=%v bus=%v calibration=%v }", this.chipid, this.version, this.mode, this.filter, this.t_sb, this.spi3w_en, this.osrs_t, this.osrs_p, this.osrs_h, bus, this.calibration)
+	return fmt.Sprintf("<sensors.BME280>{ chipid=0x%02X version=0x%02X mode=%v filter=%v t_sb=%v spi3w_en=%v osrs_t=%v osrs_p=%v osrs_h=%v power=%v bus=%v calibration=%v }", this.chipid, this.version, this.mode, this.filter, this.t_sb, this.spi3w_en, this.osrs_t, this.osrs_p, this.osrs_h, this.power_policy, bus, this.calibration)
 }
 
 func (this *calibation) String() string {
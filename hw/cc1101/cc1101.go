@@ -0,0 +1,220 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Texas Instruments CC1101 sub-GHz transceiver, in packet mode
+package cc1101
+
+import (
+	"sync"
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Configuration
+type CC1101 struct {
+	// the SPI driver
+	SPI gopi.SPI
+
+	// SPI device speed, or zero for the default
+	Speed uint32
+
+	// GPIO and pin connected to GDO0 for interrupt-driven reception,
+	// or nil to poll the FIFO
+	GPIO    gopi.GPIO
+	PinGDO0 gopi.GPIOPin
+}
+
+// driver
+type cc1101 struct {
+	spi  gopi.SPI
+	log  gopi.Logger
+	lock sync.Mutex
+
+	gdo0        gopi.GPIOPin
+	gdo0_gpio   gopi.GPIO
+	gdo0_events <-chan gopi.Event
+
+	version       uint8
+	mode          sensors.RFMMode
+	modulation    sensors.RFMModulation
+	freq          uint32
+	drate_e       uint8
+	drate_m       uint8
+	deviatn       uint8
+	packet_format sensors.RFMPacketFormat
+	packet_coding sensors.RFMPacketCoding
+	packet_crc    sensors.RFMPacketCRC
+	preamble_size uint16
+	payload_size  uint8
+	sync_word     []byte
+	output_power  int
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	CC1101_SPI_MODE      = gopi.SPI_MODE_0
+	CC1101_SPI_SPEEDHZ   = 4000000  // 4MHz
+	CC1101_PARTNUM_VALUE = 0x00     // Part number
+	CC1101_FXOSC         = 26000000 // Crystal oscillator frequency Hz
+	CC1101_FREQ_MAX      = 0x3FFFFF // Maximum value of FREQ
+	CC1101_FIFO_SIZE     = 64       // Bytes
+	CC1101_STATUS_BYTES  = 2        // RSSI and LQI appended to each received payload
+	CC1101_RSSI_OFFSET   = 74       // dB
+	CC1101_BITRATE_MIN   = 600      // bits per second
+	CC1101_BITRATE_MAX   = 500000   // bits per second
+	CC1101_TX_TIMEOUT    = 1000 * time.Millisecond
+	CC1101_STATE_TIMEOUT = 100 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config CC1101) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug("<sensors.CC1101.Open>{ spi=%v speed=%v }", config.SPI, config.Speed)
+
+	this := new(cc1101)
+	this.spi = config.SPI
+	this.log = log
+	this.gdo0 = gopi.GPIO_PIN_NONE
+
+	if this.spi == nil {
+		return nil, gopi.ErrBadParameter
+	}
+
+	// Set SPI mode and speed
+	speed := config.Speed
+	if speed == 0 {
+		speed = CC1101_SPI_SPEEDHZ
+	}
+	if err := this.spi.SetMode(CC1101_SPI_MODE); err != nil {
+		return nil, err
+	} else if err := this.spi.SetMaxSpeedHz(speed); err != nil {
+		return nil, err
+	}
+
+	// Lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Get part number and version - and check against expected values
+	if partnum, err := this.readstatus(CC1101_REG_PARTNUM); err != nil {
+		return nil, sensors.ErrNoDevice
+	} else if version, err := this.readstatus(CC1101_REG_VERSION); err != nil {
+		return nil, sensors.ErrNoDevice
+	} else if partnum != CC1101_PARTNUM_VALUE || (version != 0x04 && version != 0x14) {
+		return nil, sensors.ErrNoDevice
+	} else {
+		this.version = version
+	}
+
+	// Put into idle, calibrate when leaving idle, return to idle after
+	// receiving a packet and to FSTXON after sending one, and append the
+	// RSSI and CRC status to each received payload
+	if err := this.setMode(sensors.RFM_MODE_STDBY); err != nil {
+		return nil, err
+	} else if err := this.writereg_uint8(CC1101_REG_MCSM0, CC1101_MCSM0_AUTOCAL); err != nil {
+		return nil, err
+	} else if err := this.writereg_uint8(CC1101_REG_MCSM1, CC1101_MCSM1_RXOFF_IDLE_TXOFF_FSTXON); err != nil {
+		return nil, err
+	} else if err := this.setAppendStatus(); err != nil {
+		return nil, err
+	}
+
+	// Modulation, bitrate, carrier frequency and deviation
+	if modulation, err := this.getModulation(); err != nil {
+		return nil, err
+	} else if drate_e, drate_m, err := this.getDataRate(); err != nil {
+		return nil, err
+	} else if freq, err := this.readreg_uint24(CC1101_REG_FREQ2); err != nil {
+		return nil, err
+	} else if deviatn, err := this.readreg_uint8(CC1101_REG_DEVIATN); err != nil {
+		return nil, err
+	} else {
+		this.modulation = modulation
+		this.drate_e = drate_e
+		this.drate_m = drate_m
+		this.freq = freq
+		this.deviatn = deviatn
+	}
+
+	// Packet settings
+	if packet_format, packet_crc, err := this.getPacketConfig(); err != nil {
+		return nil, err
+	} else if packet_coding, err := this.getPacketCoding(); err != nil {
+		return nil, err
+	} else if preamble_size, err := this.getPreambleSize(); err != nil {
+		return nil, err
+	} else if payload_size, err := this.readreg_uint8(CC1101_REG_PKTLEN); err != nil {
+		return nil, err
+	} else if sync_word, err := this.getSyncWord(); err != nil {
+		return nil, err
+	} else {
+		this.packet_format = packet_format
+		this.packet_crc = packet_crc
+		this.packet_coding = packet_coding
+		this.preamble_size = preamble_size
+		this.payload_size = payload_size
+		this.sync_word = sync_word
+	}
+
+	// Output power
+	if output_power, err := this.getOutputPower(); err != nil {
+		return nil, err
+	} else {
+		this.output_power = output_power
+	}
+
+	// Interrupt-driven reception
+	if config.GPIO != nil && config.PinGDO0 != gopi.GPIO_PIN_NONE {
+		if err := this.setInterrupt(config.GPIO, config.PinGDO0); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return success
+	return this, nil
+}
+
+func (this *cc1101) Close() error {
+	this.log.Debug("<sensors.CC1101.Close>{ }")
+
+	// Lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Stop watching for interrupts
+	if err := this.setInterrupt(nil, gopi.GPIO_PIN_NONE); err != nil {
+		return err
+	}
+
+	// Blank out SPI value
+	this.spi = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// VERSION
+
+// Read the version register
+func (this *cc1101) ReadVersion() (uint8, error) {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.readstatus(CC1101_REG_VERSION)
+}
@@ -0,0 +1,183 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ClearFIFO flushes the RX and TX FIFOs, and returns to the current mode
+func (this *cc1101) ClearFIFO() error {
+	this.log.Debug("<sensors.CC1101.ClearFIFO>{ }")
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if err := this.strobe(CC1101_SIDLE); err != nil {
+		return err
+	} else if err := this.waitState(CC1101_MARCSTATE_IDLE, CC1101_STATE_TIMEOUT); err != nil {
+		return err
+	} else if err := this.strobe(CC1101_SFRX); err != nil {
+		return err
+	} else if err := this.strobe(CC1101_SFTX); err != nil {
+		return err
+	}
+
+	return this.setMode(this.mode)
+}
+
+// ReadPayload waits in RX mode for a payload, and returns it with the
+// CRC status. For variable length packets the payload starts with the
+// length byte, as for the RFM69. It returns a nil payload if the context
+// is done before a payload is received
+func (this *cc1101) ReadPayload(ctx context.Context) ([]byte, bool, error) {
+	this.log.Debug("<sensors.CC1101.ReadPayload>{ }")
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Ensure we're in RX mode or else return "OutOfOrder" message
+	if this.mode != sensors.RFM_MODE_RX {
+		return nil, false, gopi.ErrOutOfOrder
+	}
+
+	for {
+		// The radio goes to idle at the end of each packet, after which
+		// the payload is read and reception restarted
+		if rxbytes, err := this.readstatus(CC1101_REG_RXBYTES); err != nil {
+			return nil, false, err
+		} else if rxbytes&CC1101_FIFOBYTES_OVERFLOW != 0 {
+			this.log.Warn("<sensors.CC1101.ReadPayload>{ RX FIFO overflow }")
+			if err := this.setMode(sensors.RFM_MODE_RX); err != nil {
+				return nil, false, err
+			}
+		} else if state, err := this.getState(); err != nil {
+			return nil, false, err
+		} else if state == CC1101_MARCSTATE_IDLE {
+			length := uint(rxbytes & CC1101_FIFOBYTES_MASK)
+			if length <= CC1101_STATUS_BYTES {
+				// Payload was flushed after a failed CRC check
+				if err := this.setMode(sensors.RFM_MODE_RX); err != nil {
+					return nil, false, err
+				}
+			} else if data, err := this.readreg_uint8_array(CC1101_REG_FIFO, length); err != nil {
+				return nil, false, err
+			} else if err := this.setMode(sensors.RFM_MODE_RX); err != nil {
+				return nil, false, err
+			} else {
+				payload, status := data[:length-CC1101_STATUS_BYTES], data[length-CC1101_STATUS_BYTES:]
+				crc_ok := this.packet_crc == sensors.RFM_PACKET_CRC_OFF || status[1]&0x80 != 0
+				return payload, crc_ok, nil
+			}
+		}
+		if this.waitFIFO(ctx) == false {
+			// Context finished without payload
+			return nil, false, nil
+		}
+	}
+}
+
+// WritePayload transmits a payload in TX mode, repeating it the number
+// of times given. For variable length packets the payload starts with
+// the length byte
+func (this *cc1101) WritePayload(data []byte, repeat uint) error {
+	this.log.Debug("<sensors.CC1101.WritePayload>{ data=%v repeat=%v }", strings.ToUpper(hex.EncodeToString(data)), repeat)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Ensure we're in TX mode or else return "OutOfOrder" message
+	if this.mode != sensors.RFM_MODE_TX {
+		return gopi.ErrOutOfOrder
+	}
+
+	// Check repeat and length
+	if repeat < 1 {
+		return gopi.ErrBadParameter
+	} else if length := len(data); length == 0 || length > CC1101_FIFO_SIZE {
+		this.log.Debug2("sensors.CC1101.WritePayload: data length is %v, expected 0 < length <= %v", length, CC1101_FIFO_SIZE)
+		return gopi.ErrBadParameter
+	}
+
+	// Send repeatedly, waiting for each packet to be sent, after which
+	// the radio returns to FSTXON
+	for i := uint(0); i < repeat; i++ {
+		if err := this.writereg_uint8_array(CC1101_REG_FIFO, data); err != nil {
+			return err
+		} else if err := this.strobe(CC1101_STX); err != nil {
+			return err
+		} else if err := this.waitTX(CC1101_TX_TIMEOUT); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// MeasureRSSI returns the current RSSI in dBm, which is only measured
+// in RX mode
+func (this *cc1101) MeasureRSSI() (float32, error) {
+	this.log.Debug("<sensors.CC1101.MeasureRSSI>{ }")
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.mode != sensors.RFM_MODE_RX {
+		return 0, gopi.ErrOutOfOrder
+	} else if value, err := this.readstatus(CC1101_REG_RSSI); err != nil {
+		return 0, err
+	} else {
+		return float32(int8(value))/2 - CC1101_RSSI_OFFSET, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// waitTX waits for the TX FIFO to empty and the radio to return to
+// FSTXON. On TX FIFO underflow the FIFO is flushed
+func (this *cc1101) waitTX(timeout time.Duration) error {
+	timeout_chan := time.After(timeout)
+	for {
+		if txbytes, err := this.readstatus(CC1101_REG_TXBYTES); err != nil {
+			return err
+		} else if txbytes&CC1101_FIFOBYTES_OVERFLOW != 0 {
+			this.strobe(CC1101_SIDLE)
+			this.strobe(CC1101_SFTX)
+			this.setMode(sensors.RFM_MODE_TX)
+			return sensors.ErrUnexpectedResponse
+		} else if state, err := this.getState(); err != nil {
+			return err
+		} else if txbytes&CC1101_FIFOBYTES_MASK == 0 && state == CC1101_MARCSTATE_FSTXON {
+			return nil
+		}
+		select {
+		case <-timeout_chan:
+			return sensors.ErrDeviceTimeout
+		case <-time.After(time.Millisecond):
+			break
+		}
+	}
+}
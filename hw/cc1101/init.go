@@ -0,0 +1,37 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register CC1101 communication through SPI
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/cc1101",
+		Requires: []string{"spi"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagUint("cc1101.spi.speed", 0, "SPI clock speed in Hz, or zero for default")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			speed, _ := app.AppFlags.GetUint("cc1101.spi.speed")
+			return gopi.Open(CC1101{
+				SPI:   app.ModuleInstance("spi").(gopi.SPI),
+				Speed: uint32(speed),
+			}, app.Logger)
+		},
+	})
+}
@@ -0,0 +1,114 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	"context"
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Interval to check the FIFO when waiting for an interrupt, in
+	// case an edge is missed
+	CC1101_INTERRUPT_POLL = 1000 * time.Millisecond
+
+	// Interval to check the FIFO when polling. The radio does not
+	// receive between the end of a packet and the FIFO being read, so
+	// this is shorter than for the RFM69
+	CC1101_FIFO_POLL = 10 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// SetInterrupt configures reception on the rising edge of GDO0, which
+// asserts at the end of a packet. ReadPayload then reads the FIFO only
+// when the interrupt fires. A pin of GPIO_PIN_NONE reverts to polling
+func (this *cc1101) SetInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error {
+	this.log.Debug("<sensors.CC1101.SetInterrupt>{ pin=%v }", pin)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setInterrupt(gpio, pin)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *cc1101) setInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error {
+	// Remove any existing watcher
+	if this.gdo0_gpio != nil {
+		this.gdo0_gpio.Watch(this.gdo0, gopi.GPIO_EDGE_NONE)
+		this.gdo0_gpio.Unsubscribe(this.gdo0_events)
+		this.gdo0_gpio = nil
+		this.gdo0_events = nil
+		this.gdo0 = gopi.GPIO_PIN_NONE
+	}
+
+	// Revert to polling
+	if pin == gopi.GPIO_PIN_NONE {
+		return nil
+	} else if gpio == nil {
+		return gopi.ErrBadParameter
+	}
+
+	// Assert GDO0 at the end of a packet and watch for rising edge
+	if err := this.setGDO0Config(CC1101_IOCFG0_RXFIFO_OR_END); err != nil {
+		return err
+	}
+	gpio.SetPinMode(pin, gopi.GPIO_INPUT)
+	if err := gpio.Watch(pin, gopi.GPIO_EDGE_RISING); err != nil {
+		return err
+	}
+	this.gdo0_gpio = gpio
+	this.gdo0_events = gpio.Subscribe()
+	this.gdo0 = pin
+
+	// Success
+	return nil
+}
+
+// waitFIFO blocks until a payload may be in the FIFO, or the context is
+// done, in which case it returns false. When an interrupt is configured
+// it waits for the GDO0 edge, otherwise it waits for the poll interval
+func (this *cc1101) waitFIFO(ctx context.Context) bool {
+	if this.gdo0_events == nil {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(CC1101_FIFO_POLL):
+			return true
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(CC1101_INTERRUPT_POLL):
+			return true
+		case evt, ok := <-this.gdo0_events:
+			if ok == false {
+				// GPIO has been closed, revert to polling
+				this.gdo0_events = nil
+				return true
+			} else if evt, ok := evt.(gopi.GPIOEvent); ok && evt.Pin() == this.gdo0 {
+				return true
+			}
+		}
+	}
+}
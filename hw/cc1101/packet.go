@@ -0,0 +1,211 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	"encoding/hex"
+
+	// Frameworks
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PACKET FORMAT
+
+func (this *cc1101) PacketFormat() sensors.RFMPacketFormat {
+	return this.packet_format
+}
+
+func (this *cc1101) PacketCoding() sensors.RFMPacketCoding {
+	return this.packet_coding
+}
+
+func (this *cc1101) PacketCRC() sensors.RFMPacketCRC {
+	return this.packet_crc
+}
+
+func (this *cc1101) SetPacketFormat(packet_format sensors.RFMPacketFormat) error {
+	this.log.Debug("<sensors.CC1101.SetPacketFormat>{ packet_format=%v }", packet_format)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setPacketFormat(packet_format); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, _, err := this.getPacketConfig(); err != nil {
+		return err
+	} else if packet_format != value_read {
+		this.log.Debug2("SetPacketFormat expecting packet_format=%v, got=%v", packet_format, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.packet_format = packet_format
+	}
+
+	// Success
+	return nil
+}
+
+func (this *cc1101) SetPacketCoding(packet_coding sensors.RFMPacketCoding) error {
+	this.log.Debug("<sensors.CC1101.SetPacketCoding>{ packet_coding=%v }", packet_coding)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setPacketCoding(packet_coding); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.getPacketCoding(); err != nil {
+		return err
+	} else if packet_coding != value_read {
+		this.log.Debug2("SetPacketCoding expecting packet_coding=%v, got=%v", packet_coding, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.packet_coding = packet_coding
+	}
+
+	// Success
+	return nil
+}
+
+func (this *cc1101) SetPacketCRC(packet_crc sensors.RFMPacketCRC) error {
+	this.log.Debug("<sensors.CC1101.SetPacketCRC>{ packet_crc=%v }", packet_crc)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setPacketCRC(packet_crc); err != nil {
+		return err
+	}
+
+	// Read
+	if _, value_read, err := this.getPacketConfig(); err != nil {
+		return err
+	} else if packet_crc != value_read {
+		this.log.Debug2("SetPacketCRC expecting packet_crc=%v, got=%v", packet_crc, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.packet_crc = packet_crc
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PREAMBLE AND PAYLOAD SIZE
+
+func (this *cc1101) PreambleSize() uint16 {
+	return this.preamble_size
+}
+
+func (this *cc1101) PayloadSize() uint8 {
+	return this.payload_size
+}
+
+// Set the preamble size in bytes, which is 2, 3, 4, 6, 8, 12, 16 or 24
+func (this *cc1101) SetPreambleSize(preamble_size uint16) error {
+	this.log.Debug("<sensors.CC1101.SetPreambleSize>{ preamble_size=%v }", preamble_size)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setPreambleSize(preamble_size); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.getPreambleSize(); err != nil {
+		return err
+	} else if preamble_size != value_read {
+		this.log.Debug2("SetPreambleSize expecting preamble_size=%v, got=%v", preamble_size, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.preamble_size = preamble_size
+	}
+
+	// Success
+	return nil
+}
+
+// Set the payload size, which is the packet length for fixed length
+// packets and the maximum length for variable length packets
+func (this *cc1101) SetPayloadSize(payload_size uint8) error {
+	this.log.Debug("<sensors.CC1101.SetPayloadSize>{ payload_size=%v }", payload_size)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.writereg_uint8(CC1101_REG_PKTLEN, payload_size); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.readreg_uint8(CC1101_REG_PKTLEN); err != nil {
+		return err
+	} else if payload_size != value_read {
+		this.log.Debug2("SetPayloadSize expecting payload_size=%v, got=%v", payload_size, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.payload_size = payload_size
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// SYNC WORD
+
+func (this *cc1101) SyncWord() []byte {
+	return this.sync_word
+}
+
+// Set the sync word, which is nil to switch sync word detection off,
+// two bytes, or four bytes where the second two repeat the first two
+func (this *cc1101) SetSyncWord(word []byte) error {
+	this.log.Debug("<sensors.CC1101.SetSyncWord>{ word=%v }", hex.EncodeToString(word))
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setSyncWord(word); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.getSyncWord(); err != nil {
+		return err
+	} else if string(word) != string(value_read) {
+		this.log.Debug2("SetSyncWord expecting word=%v, got=%v", hex.EncodeToString(word), hex.EncodeToString(value_read))
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.sync_word = value_read
+	}
+
+	// Success
+	return nil
+}
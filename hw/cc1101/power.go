@@ -0,0 +1,149 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// pa_setting is a PA table value and the output power it gives
+type pa_setting struct {
+	dbm   int
+	value uint8
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	CC1101_PATABLE_SIZE = 8
+	CC1101_POWER_MIN    = -30 // dBm
+	CC1101_POWER_MAX    = 10  // dBm
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Recommended PA table settings from the datasheet for each band, in
+	// order of increasing power
+	pa_table_315 = []pa_setting{{-30, 0x12}, {-20, 0x0D}, {-15, 0x1C}, {-10, 0x34}, {0, 0x51}, {5, 0x85}, {7, 0xCB}, {10, 0xC2}}
+	pa_table_433 = []pa_setting{{-30, 0x12}, {-20, 0x0E}, {-15, 0x1D}, {-10, 0x34}, {0, 0x60}, {5, 0x84}, {7, 0xC8}, {10, 0xC0}}
+	pa_table_868 = []pa_setting{{-30, 0x03}, {-20, 0x0F}, {-15, 0x1E}, {-10, 0x27}, {0, 0x50}, {5, 0x81}, {7, 0xCB}, {10, 0xC2}}
+	pa_table_915 = []pa_setting{{-30, 0x03}, {-20, 0x0E}, {-15, 0x1E}, {-10, 0x27}, {0, 0x8E}, {5, 0xCD}, {7, 0xC7}, {10, 0xC0}}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return output power in dBm
+func (this *cc1101) OutputPower() int {
+	return this.output_power
+}
+
+// Set output power in dBm, between -30 and 10, which is rounded down to
+// the closest setting in the PA table for the frequency band
+func (this *cc1101) SetOutputPower(dbm int) error {
+	this.log.Debug("<sensors.CC1101.SetOutputPower>{ dbm=%v }", dbm)
+
+	if dbm < CC1101_POWER_MIN || dbm > CC1101_POWER_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setOutputPower(dbm)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// paTable returns the PA table settings for the carrier frequency
+func (this *cc1101) paTable() []pa_setting {
+	switch hertz := this.FreqCarrier(); {
+	case hertz < 387000000:
+		return pa_table_315
+	case hertz < 779000000:
+		return pa_table_433
+	case hertz < 900000000:
+		return pa_table_868
+	default:
+		return pa_table_915
+	}
+}
+
+// getOutputPower returns the output power of the PA table entry used
+// for a one, which is the second entry for OOK, or the lowest setting
+// if the value is not a recommended setting
+func (this *cc1101) getOutputPower() (int, error) {
+	if frend0, err := this.readreg_uint8(CC1101_REG_FREND0); err != nil {
+		return 0, err
+	} else if pa_table, err := this.readreg_uint8_array(CC1101_REG_PATABLE, CC1101_PATABLE_SIZE); err != nil {
+		return 0, err
+	} else {
+		value := pa_table[frend0&CC1101_FREND0_PA_POWER_MASK]
+		for _, setting := range this.paTable() {
+			if setting.value == value {
+				return setting.dbm, nil
+			}
+		}
+		return CC1101_POWER_MIN, nil
+	}
+}
+
+// setOutputPower writes the PA table for the output power and modulation.
+// OOK uses the first entry for a zero, which switches the PA off, and the
+// second entry for a one
+func (this *cc1101) setOutputPower(dbm int) error {
+	pa_table := this.paTable()
+	setting := pa_table[0]
+	for _, entry := range pa_table {
+		if entry.dbm <= dbm {
+			setting = entry
+		}
+	}
+
+	var table []byte
+	var pa_power uint8
+	if this.modulation == sensors.RFM_MODULATION_OOK {
+		table, pa_power = []byte{0x00, setting.value}, 1
+	} else {
+		table, pa_power = []byte{setting.value}, 0
+	}
+
+	// Write
+	if err := this.writereg_uint8_array(CC1101_REG_PATABLE, table); err != nil {
+		return err
+	} else if frend0, err := this.readreg_uint8(CC1101_REG_FREND0); err != nil {
+		return err
+	} else if err := this.writereg_uint8(CC1101_REG_FREND0, (frend0&^CC1101_FREND0_PA_POWER_MASK)|pa_power); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.getOutputPower(); err != nil {
+		return err
+	} else if setting.dbm != value_read {
+		this.log.Debug2("setOutputPower expecting dbm=%v, got=%v", setting.dbm, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.output_power = setting.dbm
+	}
+
+	// Success
+	return nil
+}
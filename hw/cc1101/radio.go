@@ -0,0 +1,261 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	"math"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// band is a range of carrier frequencies supported by the synthesizer
+type band struct {
+	min, max uint
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Carrier frequency bands, in Hz
+	bands = []band{
+		{300000000, 348000000},
+		{387000000, 464000000},
+		{779000000, 928000000},
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// MODE AND MODULATION
+
+// Return device mode
+func (this *cc1101) Mode() sensors.RFMMode {
+	return this.mode
+}
+
+// Set device mode. In TX mode the synthesizer is kept on, so that each
+// payload written is transmitted straight away
+func (this *cc1101) SetMode(mode sensors.RFMMode) error {
+	this.log.Debug("<sensors.CC1101.SetMode>{ mode=%v }", mode)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setMode(mode)
+}
+
+// Return modulation
+func (this *cc1101) Modulation() sensors.RFMModulation {
+	return this.modulation
+}
+
+// Set modulation, which is FSK, GFSK with BT=0.5 or OOK. The PA table is
+// written again, since OOK transmits a zero from the first entry
+func (this *cc1101) SetModulation(modulation sensors.RFMModulation) error {
+	this.log.Debug("<sensors.CC1101.SetModulation>{ modulation=%v }", modulation)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setModulation(modulation); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.getModulation(); err != nil {
+		return err
+	} else if modulation != value_read {
+		this.log.Debug2("SetModulation expecting modulation=%v, got=%v", modulation, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.modulation = modulation
+	}
+
+	// Write output power for the modulation
+	return this.setOutputPower(this.output_power)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BITRATE AND FREQUENCY
+
+// Return bitrate in bits per second
+func (this *cc1101) Bitrate() uint {
+	return uint(math.Round(bitrateFromRegister(this.drate_e, this.drate_m)))
+}
+
+// Set bitrate to the closest register value
+func (this *cc1101) SetBitrate(bits_per_second uint) error {
+	this.log.Debug("<sensors.CC1101.SetBitrate>{ bits_per_second=%v }", bits_per_second)
+
+	if bits_per_second < CC1101_BITRATE_MIN || bits_per_second > CC1101_BITRATE_MAX {
+		return gopi.ErrBadParameter
+	}
+	drate_e, drate_m := bitrateToRegister(bits_per_second)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setDataRate(drate_e, drate_m); err != nil {
+		return err
+	}
+
+	// Read
+	if drate_e_read, drate_m_read, err := this.getDataRate(); err != nil {
+		return err
+	} else if drate_e != drate_e_read || drate_m != drate_m_read {
+		this.log.Debug2("SetBitrate expecting drate_e=%v drate_m=%v, got drate_e=%v drate_m=%v", drate_e, drate_m, drate_e_read, drate_m_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.drate_e = drate_e
+		this.drate_m = drate_m
+	}
+
+	// Success
+	return nil
+}
+
+// Return carrier frequency in Hz
+func (this *cc1101) FreqCarrier() uint {
+	return uint(uint64(this.freq) * CC1101_FXOSC >> 16)
+}
+
+// Set carrier frequency in Hz, which must be in one of the synthesizer
+// bands of 300-348MHz, 387-464MHz or 779-928MHz
+func (this *cc1101) SetFreqCarrier(hertz uint) error {
+	this.log.Debug("<sensors.CC1101.SetFreqCarrier>{ hertz=%v }", hertz)
+
+	if freqBand(hertz) == false {
+		return gopi.ErrBadParameter
+	}
+	value := uint32(math.Round(float64(hertz) * (1 << 16) / CC1101_FXOSC))
+	if value > CC1101_FREQ_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.writereg_uint24(CC1101_REG_FREQ2, value); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.readreg_uint24(CC1101_REG_FREQ2); err != nil {
+		return err
+	} else if value != value_read {
+		this.log.Debug2("SetFreqCarrier expecting value=0x%06X, got=0x%06X", value, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.freq = value
+	}
+
+	// The PA table depends on the frequency band
+	return this.setOutputPower(this.output_power)
+}
+
+// Return frequency deviation in Hz
+func (this *cc1101) FreqDeviation() uint {
+	return uint(math.Round(deviationFromRegister(this.deviatn)))
+}
+
+// Set frequency deviation in Hz to the closest register value
+func (this *cc1101) SetFreqDeviation(hertz uint) error {
+	this.log.Debug("<sensors.CC1101.SetFreqDeviation>{ hertz=%v }", hertz)
+
+	value := deviationToRegister(hertz)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.writereg_uint8(CC1101_REG_DEVIATN, value); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.readreg_uint8(CC1101_REG_DEVIATN); err != nil {
+		return err
+	} else if value != value_read {
+		this.log.Debug2("SetFreqDeviation expecting value=0x%02X, got=0x%02X", value, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.deviatn = value
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// freqBand returns true if a carrier frequency is in a synthesizer band
+func freqBand(hertz uint) bool {
+	for _, band := range bands {
+		if hertz >= band.min && hertz <= band.max {
+			return true
+		}
+	}
+	return false
+}
+
+// bitrateFromRegister returns the bitrate for the exponent and mantissa,
+// which is (256 + M) * 2^E * FXOSC / 2^28
+func bitrateFromRegister(drate_e, drate_m uint8) float64 {
+	return float64(256+uint(drate_m)) * math.Ldexp(CC1101_FXOSC, int(drate_e)-28)
+}
+
+// bitrateToRegister returns the exponent and mantissa closest to a
+// bitrate, as described in the datasheet
+func bitrateToRegister(bits_per_second uint) (uint8, uint8) {
+	drate_e := int(math.Floor(math.Log2(float64(bits_per_second) * (1 << 20) / CC1101_FXOSC)))
+	drate_m := int(math.Round(float64(bits_per_second)*math.Ldexp(1, 28-drate_e)/CC1101_FXOSC - 256))
+	if drate_m >= 256 {
+		drate_m = 0
+		drate_e++
+	}
+	return uint8(drate_e), uint8(drate_m)
+}
+
+// deviationFromRegister returns the deviation for the DEVIATN register,
+// which is FXOSC / 2^17 * (8 + M) * 2^E
+func deviationFromRegister(value uint8) float64 {
+	deviation_e := int(value>>4) & 0x07
+	deviation_m := float64(value & 0x07)
+	return (8 + deviation_m) * math.Ldexp(CC1101_FXOSC, deviation_e-17)
+}
+
+// deviationToRegister returns the DEVIATN register value closest to a
+// deviation
+func deviationToRegister(hertz uint) uint8 {
+	best, best_error := uint8(0), math.Inf(1)
+	for deviation_e := uint8(0); deviation_e <= 7; deviation_e++ {
+		for deviation_m := uint8(0); deviation_m <= 7; deviation_m++ {
+			value := deviation_e<<4 | deviation_m
+			if diff := math.Abs(deviationFromRegister(value) - float64(hertz)); diff < best_error {
+				best, best_error = value, diff
+			}
+		}
+	}
+	return best
+}
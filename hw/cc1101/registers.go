@@ -0,0 +1,472 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type (
+	register uint8
+	strobe   uint8
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// CC1101 Configuration Registers
+	CC1101_REG_IOCFG2   register = 0x00 // GDO2 output pin configuration
+	CC1101_REG_IOCFG1   register = 0x01 // GDO1 output pin configuration
+	CC1101_REG_IOCFG0   register = 0x02 // GDO0 output pin configuration
+	CC1101_REG_FIFOTHR  register = 0x03 // RX FIFO and TX FIFO thresholds
+	CC1101_REG_SYNC1    register = 0x04 // Sync word, high byte
+	CC1101_REG_SYNC0    register = 0x05 // Sync word, low byte
+	CC1101_REG_PKTLEN   register = 0x06 // Packet length
+	CC1101_REG_PKTCTRL1 register = 0x07 // Packet automation control
+	CC1101_REG_PKTCTRL0 register = 0x08 // Packet automation control
+	CC1101_REG_ADDR     register = 0x09 // Device address
+	CC1101_REG_CHANNR   register = 0x0A // Channel number
+	CC1101_REG_FSCTRL1  register = 0x0B // Frequency synthesizer control
+	CC1101_REG_FSCTRL0  register = 0x0C // Frequency synthesizer control
+	CC1101_REG_FREQ2    register = 0x0D // Frequency control word, high byte
+	CC1101_REG_FREQ1    register = 0x0E // Frequency control word, middle byte
+	CC1101_REG_FREQ0    register = 0x0F // Frequency control word, low byte
+	CC1101_REG_MDMCFG4  register = 0x10 // Modem configuration
+	CC1101_REG_MDMCFG3  register = 0x11 // Modem configuration
+	CC1101_REG_MDMCFG2  register = 0x12 // Modem configuration
+	CC1101_REG_MDMCFG1  register = 0x13 // Modem configuration
+	CC1101_REG_MDMCFG0  register = 0x14 // Modem configuration
+	CC1101_REG_DEVIATN  register = 0x15 // Modem deviation setting
+	CC1101_REG_MCSM2    register = 0x16 // Main Radio Control State Machine configuration
+	CC1101_REG_MCSM1    register = 0x17 // Main Radio Control State Machine configuration
+	CC1101_REG_MCSM0    register = 0x18 // Main Radio Control State Machine configuration
+	CC1101_REG_FOCCFG   register = 0x19 // Frequency Offset Compensation configuration
+	CC1101_REG_BSCFG    register = 0x1A // Bit Synchronization configuration
+	CC1101_REG_AGCCTRL2 register = 0x1B // AGC control
+	CC1101_REG_AGCCTRL1 register = 0x1C // AGC control
+	CC1101_REG_AGCCTRL0 register = 0x1D // AGC control
+	CC1101_REG_FREND1   register = 0x21 // Front end RX configuration
+	CC1101_REG_FREND0   register = 0x22 // Front end TX configuration
+	CC1101_REG_FSCAL3   register = 0x23 // Frequency synthesizer calibration
+	CC1101_REG_FSCAL2   register = 0x24 // Frequency synthesizer calibration
+	CC1101_REG_FSCAL1   register = 0x25 // Frequency synthesizer calibration
+	CC1101_REG_FSCAL0   register = 0x26 // Frequency synthesizer calibration
+	CC1101_REG_TEST2    register = 0x2C // Various test settings
+	CC1101_REG_TEST1    register = 0x2D // Various test settings
+	CC1101_REG_TEST0    register = 0x2E // Various test settings
+
+	// CC1101 Status Registers, read with the burst bit set
+	CC1101_REG_PARTNUM   register = 0x30 // Part number
+	CC1101_REG_VERSION   register = 0x31 // Current version number
+	CC1101_REG_FREQEST   register = 0x32 // Frequency offset estimate
+	CC1101_REG_LQI       register = 0x33 // Demodulator estimate for link quality
+	CC1101_REG_RSSI      register = 0x34 // Received signal strength indication
+	CC1101_REG_MARCSTATE register = 0x35 // Control state machine state
+	CC1101_REG_PKTSTATUS register = 0x38 // Current GDOx status and packet status
+	CC1101_REG_TXBYTES   register = 0x3A // Underflow and number of bytes in the TX FIFO
+	CC1101_REG_RXBYTES   register = 0x3B // Overflow and number of bytes in the RX FIFO
+	CC1101_REG_PATABLE   register = 0x3E // PA power table
+	CC1101_REG_FIFO      register = 0x3F // TX and RX FIFO
+	CC1101_REG_MAX       register = 0x3F // Last possible register value
+	CC1101_REG_BURST     register = 0x40 // Burst bit
+	CC1101_REG_READ      register = 0x80 // Read bit
+)
+
+const (
+	// CC1101 Command Strobes
+	CC1101_SRES    strobe = 0x30 // Reset chip
+	CC1101_SFSTXON strobe = 0x31 // Enable and calibrate frequency synthesizer
+	CC1101_SXOFF   strobe = 0x32 // Turn off crystal oscillator
+	CC1101_SCAL    strobe = 0x33 // Calibrate frequency synthesizer and turn it off
+	CC1101_SRX     strobe = 0x34 // Enable RX
+	CC1101_STX     strobe = 0x35 // Enable TX
+	CC1101_SIDLE   strobe = 0x36 // Exit RX/TX, turn off frequency synthesizer
+	CC1101_SPWD    strobe = 0x39 // Enter power down mode when CSn goes high
+	CC1101_SFRX    strobe = 0x3A // Flush the RX FIFO buffer
+	CC1101_SFTX    strobe = 0x3B // Flush the TX FIFO buffer
+	CC1101_SNOP    strobe = 0x3D // No operation
+)
+
+const (
+	// MARCSTATE values
+	CC1101_MARCSTATE_SLEEP            = 0x00
+	CC1101_MARCSTATE_IDLE             = 0x01
+	CC1101_MARCSTATE_RX               = 0x0D
+	CC1101_MARCSTATE_FSTXON           = 0x12
+	CC1101_MARCSTATE_TX               = 0x13
+	CC1101_MARCSTATE_RXFIFO_OVERFLOW  = 0x11
+	CC1101_MARCSTATE_TXFIFO_UNDERFLOW = 0x16
+	CC1101_MARCSTATE_MASK             = 0x1F
+
+	// RXBYTES and TXBYTES
+	CC1101_FIFOBYTES_OVERFLOW = 0x80
+	CC1101_FIFOBYTES_MASK     = 0x7F
+
+	// MCSM0 and MCSM1
+	CC1101_MCSM0_AUTOCAL                 = 0x18 // Calibrate when going from IDLE to RX or TX
+	CC1101_MCSM1_RXOFF_IDLE_TXOFF_FSTXON = 0x31
+
+	// PKTCTRL1 and PKTCTRL0
+	CC1101_PKTCTRL1_CRC_AUTOFLUSH = 0x08
+	CC1101_PKTCTRL1_APPEND_STATUS = 0x04
+	CC1101_PKTCTRL0_WHITE_DATA    = 0x40
+	CC1101_PKTCTRL0_CRC_EN        = 0x04
+	CC1101_PKTCTRL0_LENGTH_FIXED  = 0x00
+	CC1101_PKTCTRL0_LENGTH_VAR    = 0x01
+
+	// MDMCFG2
+	CC1101_MDMCFG2_MOD_2FSK     = 0x00
+	CC1101_MDMCFG2_MOD_GFSK     = 0x10
+	CC1101_MDMCFG2_MOD_OOK      = 0x30
+	CC1101_MDMCFG2_MOD_MASK     = 0x70
+	CC1101_MDMCFG2_MANCHESTER   = 0x08
+	CC1101_MDMCFG2_SYNC_NONE    = 0x00
+	CC1101_MDMCFG2_SYNC_16      = 0x02
+	CC1101_MDMCFG2_SYNC_32      = 0x03
+	CC1101_MDMCFG2_SYNC_MASK    = 0x07
+	CC1101_IOCFG0_RXFIFO_OR_END = 0x01 // Asserts on RX FIFO threshold or end of packet
+	CC1101_FREND0_PA_POWER_MASK = 0x07
+)
+
+var (
+	// Preamble sizes in bytes for each NUM_PREAMBLE value
+	preamble_sizes = []uint16{2, 3, 4, 6, 8, 12, 16, 24}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// MODE
+
+// setMode strobes the state for a mode and waits for the radio to reach
+// it. TX mode keeps the synthesizer on, and each payload is transmitted
+// by WritePayload
+func (this *cc1101) setMode(mode sensors.RFMMode) error {
+	if err := this.strobe(CC1101_SIDLE); err != nil {
+		return err
+	} else if err := this.waitState(CC1101_MARCSTATE_IDLE, CC1101_STATE_TIMEOUT); err != nil {
+		return err
+	}
+	switch mode {
+	case sensors.RFM_MODE_SLEEP:
+		if err := this.strobe(CC1101_SPWD); err != nil {
+			return err
+		}
+	case sensors.RFM_MODE_STDBY:
+		break
+	case sensors.RFM_MODE_FS, sensors.RFM_MODE_TX:
+		if err := this.strobe(CC1101_SFSTXON); err != nil {
+			return err
+		} else if err := this.waitState(CC1101_MARCSTATE_FSTXON, CC1101_STATE_TIMEOUT); err != nil {
+			return err
+		}
+	case sensors.RFM_MODE_RX:
+		if err := this.strobe(CC1101_SFRX); err != nil {
+			return err
+		} else if err := this.strobe(CC1101_SRX); err != nil {
+			return err
+		} else if err := this.waitState(CC1101_MARCSTATE_RX, CC1101_STATE_TIMEOUT); err != nil {
+			return err
+		}
+	default:
+		return gopi.ErrBadParameter
+	}
+	this.mode = mode
+	return nil
+}
+
+// getState returns the radio control state machine state
+func (this *cc1101) getState() (uint8, error) {
+	if value, err := this.readstatus(CC1101_REG_MARCSTATE); err != nil {
+		return 0, err
+	} else {
+		return value & CC1101_MARCSTATE_MASK, nil
+	}
+}
+
+// waitState waits for the radio control state machine to reach a state
+func (this *cc1101) waitState(state uint8, timeout time.Duration) error {
+	timeout_chan := time.After(timeout)
+	for {
+		if value, err := this.getState(); err != nil {
+			return err
+		} else if value == state {
+			return nil
+		}
+		select {
+		case <-timeout_chan:
+			return sensors.ErrDeviceTimeout
+		case <-time.After(time.Millisecond):
+			break
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CC1101_REG_MDMCFG2
+
+func (this *cc1101) getModulation() (sensors.RFMModulation, error) {
+	if value, err := this.readreg_uint8(CC1101_REG_MDMCFG2); err != nil {
+		return 0, err
+	} else {
+		switch value & CC1101_MDMCFG2_MOD_MASK {
+		case CC1101_MDMCFG2_MOD_GFSK:
+			return sensors.RFM_MODULATION_FSK_BT_0P5, nil
+		case CC1101_MDMCFG2_MOD_OOK:
+			return sensors.RFM_MODULATION_OOK, nil
+		default:
+			return sensors.RFM_MODULATION_FSK, nil
+		}
+	}
+}
+
+func (this *cc1101) setModulation(modulation sensors.RFMModulation) error {
+	var format uint8
+	switch modulation {
+	case sensors.RFM_MODULATION_FSK:
+		format = CC1101_MDMCFG2_MOD_2FSK
+	case sensors.RFM_MODULATION_FSK_BT_0P5:
+		format = CC1101_MDMCFG2_MOD_GFSK
+	case sensors.RFM_MODULATION_OOK:
+		format = CC1101_MDMCFG2_MOD_OOK
+	default:
+		return gopi.ErrBadParameter
+	}
+	if value, err := this.readreg_uint8(CC1101_REG_MDMCFG2); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(CC1101_REG_MDMCFG2, (value&^CC1101_MDMCFG2_MOD_MASK)|format)
+	}
+}
+
+func (this *cc1101) getPacketCoding() (sensors.RFMPacketCoding, error) {
+	if mdmcfg2, err := this.readreg_uint8(CC1101_REG_MDMCFG2); err != nil {
+		return 0, err
+	} else if pktctrl0, err := this.readreg_uint8(CC1101_REG_PKTCTRL0); err != nil {
+		return 0, err
+	} else if mdmcfg2&CC1101_MDMCFG2_MANCHESTER != 0 {
+		return sensors.RFM_PACKET_CODING_MANCHESTER, nil
+	} else if pktctrl0&CC1101_PKTCTRL0_WHITE_DATA != 0 {
+		return sensors.RFM_PACKET_CODING_WHITENING, nil
+	} else {
+		return sensors.RFM_PACKET_CODING_NONE, nil
+	}
+}
+
+func (this *cc1101) setPacketCoding(packet_coding sensors.RFMPacketCoding) error {
+	if mdmcfg2, err := this.readreg_uint8(CC1101_REG_MDMCFG2); err != nil {
+		return err
+	} else if pktctrl0, err := this.readreg_uint8(CC1101_REG_PKTCTRL0); err != nil {
+		return err
+	} else {
+		mdmcfg2 &^= CC1101_MDMCFG2_MANCHESTER
+		pktctrl0 &^= CC1101_PKTCTRL0_WHITE_DATA
+		switch packet_coding {
+		case sensors.RFM_PACKET_CODING_NONE:
+			break
+		case sensors.RFM_PACKET_CODING_MANCHESTER:
+			mdmcfg2 |= CC1101_MDMCFG2_MANCHESTER
+		case sensors.RFM_PACKET_CODING_WHITENING:
+			pktctrl0 |= CC1101_PKTCTRL0_WHITE_DATA
+		default:
+			return gopi.ErrBadParameter
+		}
+		if err := this.writereg_uint8(CC1101_REG_MDMCFG2, mdmcfg2); err != nil {
+			return err
+		} else {
+			return this.writereg_uint8(CC1101_REG_PKTCTRL0, pktctrl0)
+		}
+	}
+}
+
+// getSyncWord returns the sync word, which is nil when sync word
+// detection is off, or the two sync bytes repeated in 30/32 mode
+func (this *cc1101) getSyncWord() ([]byte, error) {
+	if mdmcfg2, err := this.readreg_uint8(CC1101_REG_MDMCFG2); err != nil {
+		return nil, err
+	} else if sync, err := this.readreg_uint8_array(CC1101_REG_SYNC1, 2); err != nil {
+		return nil, err
+	} else {
+		switch mdmcfg2 & 0x03 {
+		case CC1101_MDMCFG2_SYNC_NONE:
+			return nil, nil
+		case CC1101_MDMCFG2_SYNC_32:
+			return append(sync, sync...), nil
+		default:
+			return sync, nil
+		}
+	}
+}
+
+func (this *cc1101) setSyncWord(word []byte) error {
+	var sync_mode uint8
+	switch len(word) {
+	case 0:
+		sync_mode = CC1101_MDMCFG2_SYNC_NONE
+	case 2:
+		sync_mode = CC1101_MDMCFG2_SYNC_16
+	case 4:
+		if word[0] != word[2] || word[1] != word[3] {
+			return gopi.ErrBadParameter
+		}
+		sync_mode = CC1101_MDMCFG2_SYNC_32
+	default:
+		return gopi.ErrBadParameter
+	}
+	if len(word) > 0 {
+		if err := this.writereg_uint8_array(CC1101_REG_SYNC1, word[0:2]); err != nil {
+			return err
+		}
+	}
+	if value, err := this.readreg_uint8(CC1101_REG_MDMCFG2); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(CC1101_REG_MDMCFG2, (value&^CC1101_MDMCFG2_SYNC_MASK)|sync_mode)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CC1101_REG_MDMCFG4 AND CC1101_REG_MDMCFG3
+
+func (this *cc1101) getDataRate() (uint8, uint8, error) {
+	if mdmcfg4, err := this.readreg_uint8(CC1101_REG_MDMCFG4); err != nil {
+		return 0, 0, err
+	} else if mdmcfg3, err := this.readreg_uint8(CC1101_REG_MDMCFG3); err != nil {
+		return 0, 0, err
+	} else {
+		return mdmcfg4 & 0x0F, mdmcfg3, nil
+	}
+}
+
+// setDataRate writes the data rate exponent and mantissa, keeping the
+// channel bandwidth
+func (this *cc1101) setDataRate(drate_e, drate_m uint8) error {
+	if mdmcfg4, err := this.readreg_uint8(CC1101_REG_MDMCFG4); err != nil {
+		return err
+	} else if err := this.writereg_uint8(CC1101_REG_MDMCFG4, (mdmcfg4&0xF0)|(drate_e&0x0F)); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(CC1101_REG_MDMCFG3, drate_m)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CC1101_REG_MDMCFG1
+
+func (this *cc1101) getPreambleSize() (uint16, error) {
+	if value, err := this.readreg_uint8(CC1101_REG_MDMCFG1); err != nil {
+		return 0, err
+	} else {
+		return preamble_sizes[(value>>4)&0x07], nil
+	}
+}
+
+func (this *cc1101) setPreambleSize(preamble_size uint16) error {
+	for i, size := range preamble_sizes {
+		if size != preamble_size {
+			continue
+		} else if value, err := this.readreg_uint8(CC1101_REG_MDMCFG1); err != nil {
+			return err
+		} else {
+			return this.writereg_uint8(CC1101_REG_MDMCFG1, (value&0x8F)|uint8(i)<<4)
+		}
+	}
+	return gopi.ErrBadParameter
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CC1101_REG_PKTCTRL1 AND CC1101_REG_PKTCTRL0
+
+func (this *cc1101) setAppendStatus() error {
+	if value, err := this.readreg_uint8(CC1101_REG_PKTCTRL1); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(CC1101_REG_PKTCTRL1, value|CC1101_PKTCTRL1_APPEND_STATUS)
+	}
+}
+
+func (this *cc1101) getPacketConfig() (sensors.RFMPacketFormat, sensors.RFMPacketCRC, error) {
+	if pktctrl1, err := this.readreg_uint8(CC1101_REG_PKTCTRL1); err != nil {
+		return 0, 0, err
+	} else if pktctrl0, err := this.readreg_uint8(CC1101_REG_PKTCTRL0); err != nil {
+		return 0, 0, err
+	} else {
+		packet_format := sensors.RFM_PACKET_FORMAT_FIXED
+		if pktctrl0&0x03 == CC1101_PKTCTRL0_LENGTH_VAR {
+			packet_format = sensors.RFM_PACKET_FORMAT_VARIABLE
+		}
+		packet_crc := sensors.RFM_PACKET_CRC_OFF
+		if pktctrl0&CC1101_PKTCTRL0_CRC_EN != 0 {
+			if pktctrl1&CC1101_PKTCTRL1_CRC_AUTOFLUSH != 0 {
+				packet_crc = sensors.RFM_PACKET_CRC_AUTOCLEAR_ON
+			} else {
+				packet_crc = sensors.RFM_PACKET_CRC_AUTOCLEAR_OFF
+			}
+		}
+		return packet_format, packet_crc, nil
+	}
+}
+
+func (this *cc1101) setPacketFormat(packet_format sensors.RFMPacketFormat) error {
+	var length_config uint8
+	switch packet_format {
+	case sensors.RFM_PACKET_FORMAT_FIXED:
+		length_config = CC1101_PKTCTRL0_LENGTH_FIXED
+	case sensors.RFM_PACKET_FORMAT_VARIABLE:
+		length_config = CC1101_PKTCTRL0_LENGTH_VAR
+	default:
+		return gopi.ErrBadParameter
+	}
+	if value, err := this.readreg_uint8(CC1101_REG_PKTCTRL0); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(CC1101_REG_PKTCTRL0, (value&0xFC)|length_config)
+	}
+}
+
+func (this *cc1101) setPacketCRC(packet_crc sensors.RFMPacketCRC) error {
+	if pktctrl1, err := this.readreg_uint8(CC1101_REG_PKTCTRL1); err != nil {
+		return err
+	} else if pktctrl0, err := this.readreg_uint8(CC1101_REG_PKTCTRL0); err != nil {
+		return err
+	} else {
+		pktctrl1 &^= CC1101_PKTCTRL1_CRC_AUTOFLUSH
+		pktctrl0 &^= CC1101_PKTCTRL0_CRC_EN
+		switch packet_crc {
+		case sensors.RFM_PACKET_CRC_OFF:
+			break
+		case sensors.RFM_PACKET_CRC_AUTOCLEAR_OFF:
+			pktctrl0 |= CC1101_PKTCTRL0_CRC_EN
+		case sensors.RFM_PACKET_CRC_AUTOCLEAR_ON:
+			pktctrl0 |= CC1101_PKTCTRL0_CRC_EN
+			pktctrl1 |= CC1101_PKTCTRL1_CRC_AUTOFLUSH
+		default:
+			return gopi.ErrBadParameter
+		}
+		if err := this.writereg_uint8(CC1101_REG_PKTCTRL1, pktctrl1); err != nil {
+			return err
+		} else {
+			return this.writereg_uint8(CC1101_REG_PKTCTRL0, pktctrl0)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CC1101_REG_IOCFG0
+
+func (this *cc1101) setGDO0Config(value uint8) error {
+	return this.writereg_uint8(CC1101_REG_IOCFG0, value&0x3F)
+}
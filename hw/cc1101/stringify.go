@@ -0,0 +1,174 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *cc1101) String() string {
+	params := []string{
+		fmt.Sprintf("version=0x%02X", this.version),
+		fmt.Sprintf("mode=%v", this.mode),
+		fmt.Sprintf("modulation=%v", this.modulation),
+		fmt.Sprintf("bitrate=%v", this.Bitrate()),
+		fmt.Sprintf("freq=0x%06X", this.freq),
+		fmt.Sprintf("fdev=%v", this.FreqDeviation()),
+		fmt.Sprintf("packet_format=%v", this.packet_format),
+		fmt.Sprintf("packet_coding=%v", this.packet_coding),
+		fmt.Sprintf("packet_crc=%v", this.packet_crc),
+		fmt.Sprintf("preamble_size=%v", this.preamble_size),
+		fmt.Sprintf("payload_size=%v", this.payload_size),
+		fmt.Sprintf("sync_word=%v", hex.EncodeToString(this.sync_word)),
+		fmt.Sprintf("output_power=%vdBm", this.output_power),
+	}
+	return fmt.Sprintf("sensors.CC1101{ spi=%v %v }", this.spi, strings.Join(params, " "))
+}
+
+func (r register) String() string {
+	switch r {
+	case CC1101_REG_IOCFG2:
+		return "CC1101_REG_IOCFG2"
+	case CC1101_REG_IOCFG1:
+		return "CC1101_REG_IOCFG1"
+	case CC1101_REG_IOCFG0:
+		return "CC1101_REG_IOCFG0"
+	case CC1101_REG_FIFOTHR:
+		return "CC1101_REG_FIFOTHR"
+	case CC1101_REG_SYNC1:
+		return "CC1101_REG_SYNC1"
+	case CC1101_REG_SYNC0:
+		return "CC1101_REG_SYNC0"
+	case CC1101_REG_PKTLEN:
+		return "CC1101_REG_PKTLEN"
+	case CC1101_REG_PKTCTRL1:
+		return "CC1101_REG_PKTCTRL1"
+	case CC1101_REG_PKTCTRL0:
+		return "CC1101_REG_PKTCTRL0"
+	case CC1101_REG_ADDR:
+		return "CC1101_REG_ADDR"
+	case CC1101_REG_CHANNR:
+		return "CC1101_REG_CHANNR"
+	case CC1101_REG_FSCTRL1:
+		return "CC1101_REG_FSCTRL1"
+	case CC1101_REG_FSCTRL0:
+		return "CC1101_REG_FSCTRL0"
+	case CC1101_REG_FREQ2:
+		return "CC1101_REG_FREQ2"
+	case CC1101_REG_FREQ1:
+		return "CC1101_REG_FREQ1"
+	case CC1101_REG_FREQ0:
+		return "CC1101_REG_FREQ0"
+	case CC1101_REG_MDMCFG4:
+		return "CC1101_REG_MDMCFG4"
+	case CC1101_REG_MDMCFG3:
+		return "CC1101_REG_MDMCFG3"
+	case CC1101_REG_MDMCFG2:
+		return "CC1101_REG_MDMCFG2"
+	case CC1101_REG_MDMCFG1:
+		return "CC1101_REG_MDMCFG1"
+	case CC1101_REG_MDMCFG0:
+		return "CC1101_REG_MDMCFG0"
+	case CC1101_REG_DEVIATN:
+		return "CC1101_REG_DEVIATN"
+	case CC1101_REG_MCSM2:
+		return "CC1101_REG_MCSM2"
+	case CC1101_REG_MCSM1:
+		return "CC1101_REG_MCSM1"
+	case CC1101_REG_MCSM0:
+		return "CC1101_REG_MCSM0"
+	case CC1101_REG_FOCCFG:
+		return "CC1101_REG_FOCCFG"
+	case CC1101_REG_BSCFG:
+		return "CC1101_REG_BSCFG"
+	case CC1101_REG_AGCCTRL2:
+		return "CC1101_REG_AGCCTRL2"
+	case CC1101_REG_AGCCTRL1:
+		return "CC1101_REG_AGCCTRL1"
+	case CC1101_REG_AGCCTRL0:
+		return "CC1101_REG_AGCCTRL0"
+	case CC1101_REG_FREND1:
+		return "CC1101_REG_FREND1"
+	case CC1101_REG_FREND0:
+		return "CC1101_REG_FREND0"
+	case CC1101_REG_FSCAL3:
+		return "CC1101_REG_FSCAL3"
+	case CC1101_REG_FSCAL2:
+		return "CC1101_REG_FSCAL2"
+	case CC1101_REG_FSCAL1:
+		return "CC1101_REG_FSCAL1"
+	case CC1101_REG_FSCAL0:
+		return "CC1101_REG_FSCAL0"
+	case CC1101_REG_TEST2:
+		return "CC1101_REG_TEST2"
+	case CC1101_REG_TEST1:
+		return "CC1101_REG_TEST1"
+	case CC1101_REG_TEST0:
+		return "CC1101_REG_TEST0"
+	case CC1101_REG_PARTNUM:
+		return "CC1101_REG_PARTNUM"
+	case CC1101_REG_VERSION:
+		return "CC1101_REG_VERSION"
+	case CC1101_REG_FREQEST:
+		return "CC1101_REG_FREQEST"
+	case CC1101_REG_LQI:
+		return "CC1101_REG_LQI"
+	case CC1101_REG_RSSI:
+		return "CC1101_REG_RSSI"
+	case CC1101_REG_MARCSTATE:
+		return "CC1101_REG_MARCSTATE"
+	case CC1101_REG_PKTSTATUS:
+		return "CC1101_REG_PKTSTATUS"
+	case CC1101_REG_TXBYTES:
+		return "CC1101_REG_TXBYTES"
+	case CC1101_REG_RXBYTES:
+		return "CC1101_REG_RXBYTES"
+	case CC1101_REG_PATABLE:
+		return "CC1101_REG_PATABLE"
+	case CC1101_REG_FIFO:
+		return "CC1101_REG_FIFO"
+	default:
+		return "[?? Invalid register value]"
+	}
+}
+
+func (s strobe) String() string {
+	switch s {
+	case CC1101_SRES:
+		return "CC1101_SRES"
+	case CC1101_SFSTXON:
+		return "CC1101_SFSTXON"
+	case CC1101_SXOFF:
+		return "CC1101_SXOFF"
+	case CC1101_SCAL:
+		return "CC1101_SCAL"
+	case CC1101_SRX:
+		return "CC1101_SRX"
+	case CC1101_STX:
+		return "CC1101_STX"
+	case CC1101_SIDLE:
+		return "CC1101_SIDLE"
+	case CC1101_SPWD:
+		return "CC1101_SPWD"
+	case CC1101_SFRX:
+		return "CC1101_SFRX"
+	case CC1101_SFTX:
+		return "CC1101_SFTX"
+	case CC1101_SNOP:
+		return "CC1101_SNOP"
+	default:
+		return "[?? Invalid strobe value]"
+	}
+}
@@ -0,0 +1,93 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package cc1101
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *cc1101) readreg_uint8(reg register) (uint8, error) {
+	recv, err := this.spi.Transfer([]byte{byte((reg & CC1101_REG_MAX) | CC1101_REG_READ), 0})
+	if err != nil {
+		return 0, err
+	}
+	this.log.Debug2("<sensors.CC1101>readreg_uint8{ reg=%v recv=0x%02X }", reg, recv[1])
+	return recv[1], nil
+}
+
+func (this *cc1101) readreg_uint8_array(reg register, length uint) ([]byte, error) {
+	send := make([]byte, length+1)
+	send[0] = byte((reg & CC1101_REG_MAX) | CC1101_REG_READ | CC1101_REG_BURST)
+	recv, err := this.spi.Transfer(send)
+	if err != nil {
+		return nil, err
+	}
+	this.log.Debug2("<sensors.CC1101>readreg_uint8_array{ reg=%v length=%v recv=0x%v }", reg, length, strings.ToUpper(hex.EncodeToString(recv[1:])))
+	return recv[1:], nil
+}
+
+func (this *cc1101) readreg_uint24(reg register) (uint32, error) {
+	if recv, err := this.readreg_uint8_array(reg, 3); err != nil {
+		return 0, err
+	} else {
+		return uint32(recv[0])<<16 | uint32(recv[1])<<8 | uint32(recv[2]), nil
+	}
+}
+
+// readstatus reads a status register, which shares its address with a
+// command strobe and is read with the burst bit set. The value is read
+// until two reads agree, since the datasheet errata warns that a status
+// register which changes during the read can return a corrupt value
+func (this *cc1101) readstatus(reg register) (uint8, error) {
+	send := []byte{byte((reg & CC1101_REG_MAX) | CC1101_REG_READ | CC1101_REG_BURST), 0}
+	var value uint8
+	for i := 0; ; i++ {
+		recv, err := this.spi.Transfer(send)
+		if err != nil {
+			return 0, err
+		} else if i > 0 && recv[1] == value {
+			break
+		} else {
+			value = recv[1]
+		}
+	}
+	this.log.Debug2("<sensors.CC1101>readstatus{ reg=%v recv=0x%02X }", reg, value)
+	return value, nil
+}
+
+func (this *cc1101) writereg_uint8(reg register, data uint8) error {
+	this.log.Debug2("<sensors.CC1101>writereg_uint8{ reg=%v data=0x%02X }", reg, data)
+	return this.spi.Write([]byte{byte(reg & CC1101_REG_MAX), data})
+}
+
+func (this *cc1101) writereg_uint24(reg register, data uint32) error {
+	this.log.Debug2("<sensors.CC1101>writereg_uint24{ reg=%v data=0x%06X }", reg, data)
+	return this.writereg_uint8_array(reg, []byte{
+		uint8(data & 0xFF0000 >> 16),
+		uint8(data & 0xFF00 >> 8),
+		uint8(data & 0xFF),
+	})
+}
+
+func (this *cc1101) writereg_uint8_array(reg register, data []byte) error {
+	this.log.Debug2("<sensors.CC1101>writereg_uint8_array{ reg=%v data=%v }", reg, strings.ToUpper(hex.EncodeToString(data)))
+	buf := append([]byte(nil), byte((reg&CC1101_REG_MAX)|CC1101_REG_BURST))
+	return this.spi.Write(append(buf, data...))
+}
+
+// strobe sends a command strobe
+func (this *cc1101) strobe(cmd strobe) error {
+	this.log.Debug2("<sensors.CC1101>strobe{ cmd=%v }", cmd)
+	return this.spi.Write([]byte{byte(cmd)})
+}
@@ -0,0 +1,144 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Calibration is the temperature calibration for the radio, which is
+// derived by comparing the radio temperature with a reference sensor
+type Calibration struct {
+	TempOffset float32   `json:"temp_offset"` // Offset added to the radio temperature
+	Samples    uint      `json:"samples"`     // Number of samples used to derive the offset
+	Timestamp  time.Time `json:"timestamp"`   // When the calibration was performed
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ReadCalibration reads the calibration from a file, and returns nil
+// if the file does not exist
+func ReadCalibration(path string) (*Calibration, error) {
+	if data, err := ioutil.ReadFile(path); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	} else {
+		calibration := new(Calibration)
+		if err := json.Unmarshal(data, calibration); err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+		return calibration, nil
+	}
+}
+
+// WriteCalibration writes the calibration to a file
+func WriteCalibration(path string, calibration *Calibration) error {
+	if calibration == nil {
+		return gopi.ErrBadParameter
+	} else if data, err := json.MarshalIndent(calibration, "", "  "); err != nil {
+		return err
+	} else {
+		return ioutil.WriteFile(path, data, 0644)
+	}
+}
+
+// Derive the calibration from pairs of radio and reference temperature
+// samples, the offset is the mean difference between them
+func NewCalibration(radio, reference []float32) (*Calibration, error) {
+	if len(radio) == 0 || len(radio) != len(reference) {
+		return nil, gopi.ErrBadParameter
+	}
+	sum := float64(0)
+	for i := range radio {
+		sum += float64(reference[i] - radio[i])
+	}
+	return &Calibration{
+		TempOffset: float32(sum / float64(len(radio))),
+		Samples:    uint(len(radio)),
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS - MIHOME
+
+// Return the temperature calibration offset
+func (this *mihome) TempOffset() float32 {
+	this.templock.Lock()
+	defer this.templock.Unlock()
+	return this.tempoffset
+}
+
+// Set the temperature calibration offset, and write it to the
+// calibration file if there is one
+func (this *mihome) SetTempOffset(offset float32) error {
+	this.log.Debug("<sensors.energenie.MiHome.SetTempOffset{ offset=%v }", offset)
+
+	this.templock.Lock()
+	defer this.templock.Unlock()
+	return this.setTempOffset(offset, 0)
+}
+
+// Calibrate measures the radio temperature and derives the offset
+// from a known reference temperature. The offset is not changed by other
+// goroutines between the measurement and setting the new offset
+func (this *mihome) Calibrate(reference float32) error {
+	this.log.Debug("<sensors.energenie.MiHome.Calibrate{ reference=%v }", reference)
+
+	// txlock is always acquired before templock
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+	this.endRead()
+	this.templock.Lock()
+	defer this.templock.Unlock()
+
+	if value, err := this.measureTemperature(this.tempoffset); err != nil {
+		return err
+	} else {
+		return this.setTempOffset(this.tempoffset+reference-value, 1)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Set the offset and write the calibration file, called with templock held
+func (this *mihome) setTempOffset(offset float32, samples uint) error {
+	if this.calibration != "" {
+		if err := WriteCalibration(this.calibration, &Calibration{
+			TempOffset: offset,
+			Samples:    samples,
+			Timestamp:  time.Now(),
+		}); err != nil {
+			return err
+		}
+	}
+	this.tempoffset = offset
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *Calibration) String() string {
+	return fmt.Sprintf("<sensors.energenie.Calibration>{ tempoffset=%v samples=%v ts=%v }", this.TempOffset, this.Samples, this.Timestamp.Format(time.Stamp))
+}
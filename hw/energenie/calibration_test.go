@@ -0,0 +1,72 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors/hw/rfm69/mock"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST CALIBRATE
+
+func TestCalibrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	driver, _ := test_mihome(t, MiHome{TempOffset: 1.5, Calibration: path})
+
+	const reference = mock.MOCK_TEMPERATURE - 2
+	if err := driver.Calibrate(reference); err != nil {
+		t.Fatal(err)
+	} else if offset := driver.TempOffset(); offset != -2 {
+		t.Errorf("TempOffset: expected -2, got %v", offset)
+	} else if temperature, err := driver.MeasureTemperature(); err != nil {
+		t.Fatal(err)
+	} else if temperature != reference {
+		t.Errorf("Expected %v, got %v", reference, temperature)
+	}
+
+	if calibration, err := ReadCalibration(path); err != nil {
+		t.Fatal(err)
+	} else if calibration == nil || calibration.TempOffset != -2 || calibration.Samples != 1 {
+		t.Errorf("Unexpected calibration %v", calibration)
+	}
+}
+
+// TestCalibrateConcurrent checks that calibrating while the offset is set
+// and sampled always converges on the reference temperature
+func TestCalibrateConcurrent(t *testing.T) {
+	driver, _ := test_mihome(t, MiHome{TempInterval: time.Millisecond})
+
+	const reference = mock.MOCK_TEMPERATURE + 3
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := driver.Calibrate(reference); err != nil {
+					t.Error(err)
+				} else if _, err := driver.MeasureTemperature(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if offset := driver.TempOffset(); offset != 3 {
+		t.Errorf("TempOffset: expected 3, got %v", offset)
+	}
+}
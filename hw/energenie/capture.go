@@ -0,0 +1,158 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// CaptureRecord is a received raw payload. A capture file contains
+// one record per line, encoded as JSON
+type CaptureRecord struct {
+	Timestamp time.Time `json:"ts"`
+	RSSI      float32   `json:"rssi"`
+	Payload   string    `json:"payload"` // Hexadecimal
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ReadCapture reads all records from a capture file
+func ReadCapture(path string) ([]CaptureRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := make([]CaptureRecord, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if text := strings.TrimSpace(scanner.Text()); text == "" {
+			continue
+		} else {
+			var record CaptureRecord
+			if err := json.Unmarshal([]byte(text), &record); err != nil {
+				return nil, fmt.Errorf("%v:%v: %v", path, line, err)
+			}
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Success
+	return records, nil
+}
+
+// Replay feeds the payloads from a capture file through the OpenThings
+// decoder and emits them as OTEvent values. When realtime is true the
+// original spacing between payloads is kept
+func (this *mihome) Replay(ctx context.Context, path string, realtime bool) error {
+	this.log.Debug("<sensors.energenie.MiHome.Replay{ path=\"%v\" realtime=%v }", path, realtime)
+	return replayCapture(ctx, path, realtime, this.decoders, this.emitMessage)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Payload returns the record payload as bytes
+func (this CaptureRecord) payload() ([]byte, error) {
+	return hex.DecodeString(this.Payload)
+}
+
+// Open the capture file for appending
+func openCapture(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+// Close the capture file, if it is open
+func (this *mihome) closeCapture() {
+	if this.capture_file != nil {
+		if err := this.capture_file.Close(); err != nil {
+			this.log.Error("Capture: %v", err)
+		}
+		this.capture_file = nil
+	}
+}
+
+// Append a received packet to the capture file, with the time and RSSI
+// measured by the radio when it was received. The radio isn't used, since
+// the packet may be captured after a transmission has started
+func (this *mihome) capture(packet *sensors.RFMPacket) {
+	if this.capture_file == nil {
+		return
+	}
+
+	record := CaptureRecord{
+		Timestamp: packet.Timestamp,
+		RSSI:      packet.RSSI,
+		Payload:   strings.ToUpper(hex.EncodeToString(packet.Payload)),
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	if line, err := json.Marshal(record); err != nil {
+		this.log.Error("Capture: %v", err)
+	} else if _, err := this.capture_file.Write(append(line, '\n')); err != nil {
+		this.log.Error("Capture: %v", err)
+	}
+}
+
+func replayCapture(ctx context.Context, path string, realtime bool, decoders []Decoder, emit func(sensors.OTMessage, string, error)) error {
+	if len(decoders) == 0 {
+		return gopi.ErrBadParameter
+	}
+	records, err := ReadCapture(path)
+	if err != nil {
+		return err
+	}
+	for i, record := range records {
+		// Wait for the original spacing between payloads
+		if realtime && i > 0 {
+			if delta := record.Timestamp.Sub(records[i-1].Timestamp); delta > 0 {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(delta):
+					break
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			if data, err := record.payload(); err != nil {
+				return fmt.Errorf("%v: %v", path, err)
+			} else if message, decoder, reason := decodePayload(decoders, data); message != nil {
+				emit(message, decoder, reason)
+			}
+		}
+	}
+
+	// Success
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST CAPTURE
+
+// TestCapture checks that captured payloads record the time and RSSI of
+// the packet, rather than measurements made after it was read
+func TestCapture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.json")
+	driver, radio := test_mihome(t, MiHome{Capture: path})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- driver.Receive(ctx, sensors.MIHOME_MODE_MONITOR)
+	}()
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+
+	ts := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	radio.InjectPacket(&sensors.RFMPacket{
+		Timestamp: ts,
+		RSSI:      -42,
+		Payload:   []byte{0x01, 0x02, 0x03},
+		CRCOk:     true,
+	})
+
+	// Wait for the record to be written, which may be read part-written
+	timeout := time.Now().Add(time.Second)
+	for {
+		if records, err := ReadCapture(path); err != nil && time.Now().After(timeout) {
+			t.Fatal(err)
+		} else if len(records) == 1 {
+			if records[0].Timestamp.Equal(ts) == false {
+				t.Errorf("Timestamp: expected %v, got %v", ts, records[0].Timestamp)
+			}
+			if records[0].RSSI != -42 {
+				t.Errorf("RSSI: expected -42, got %v", records[0].RSSI)
+			}
+			if records[0].Payload != "010203" {
+				t.Errorf("Payload: expected 010203, got %v", records[0].Payload)
+			}
+			break
+		} else if err == nil && time.Now().After(timeout) {
+			t.Fatalf("Timeout waiting for capture, got %v records", len(records))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+type control_rx_event struct {
+	driver  gopi.Driver
+	ts      time.Time
+	cid     []byte
+	command Command
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Received control payload, which is the three zero bytes after
+	// the sync word, then the encoded address and command
+	OOK_RX_PAYLOAD_SIZE = 15
+	OOK_RX_ZERO_BYTES   = 3
+
+	// The demodulator threshold follows the peak of the signal, so
+	// that distant transmitters are decoded, but not below the floor
+	OOK_RX_THRESHOLD_TYPE  = sensors.RFM_OOK_THRESHOLD_PEAK
+	OOK_RX_THRESHOLD_STEP  = sensors.RFM_OOK_THRESHOLD_STEP_0P5
+	OOK_RX_THRESHOLD_DEC   = sensors.RFM_OOK_THRESHOLD_DEC_1
+	OOK_RX_THRESHOLD_FLOOR = 6 // dB
+)
+
+var (
+	// The first byte of the preamble is used as the sync word, since
+	// the data never contains more than three zero bits in a row
+	OOK_RX_SYNCWORD = OOK_PREAMBLE[0:1]
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return the modes which can be used with Receive
+func (this *mihome) SupportedModes() []sensors.MiHomeMode {
+	return []sensors.MiHomeMode{sensors.MIHOME_MODE_MONITOR, sensors.MIHOME_MODE_CONTROL}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return true if a mode is in a list of supported modes
+func supportsMode(modes []sensors.MiHomeMode, mode sensors.MiHomeMode) bool {
+	for _, supported := range modes {
+		if supported == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// receiveControl receives OOK commands sent by other controllers until
+// the context is done. The radio is left in MIHOME_MODE_CONTROL_RX, so the
+// next transmission sets the control profile again
+func (this *mihome) receiveControl(ctx context.Context) error {
+	// Repeatedly read until context is done. Each read can be interrupted
+	// by a transmission, after which the radio is returned to OOK receive
+FOR_LOOP:
+	for {
+		select {
+		case <-ctx.Done():
+			break FOR_LOOP
+		default:
+			read_ctx, err := this.beginRead(ctx, sensors.MIHOME_MODE_CONTROL_RX)
+			if err != nil {
+				return err
+			}
+			packet, err := this.radio.ReadPacket(read_ctx)
+			this.endRead()
+			if err != nil {
+				return err
+			} else if packet != nil {
+				// RX light on
+				this.SetLED(LED_RX, gopi.GPIO_HIGH)
+
+				// Record the raw payload
+				data := packet.Payload
+				this.capture(packet)
+
+				// Decode & Emit command
+				cid, cmd, reason := decodeCommandPayload(data)
+				this.countRX(reason)
+				if reason == nil {
+					this.inferState(cid, cmd)
+					this.pubsub.Emit(&control_rx_event{this, this.packetTime(packet), cid, cmd})
+				} else {
+					this.log.Debug2("<sensors.energenie.MiHome.receiveControl>{ data=%v reason=%v }", strings.ToUpper(hex.EncodeToString(data)), reason)
+				}
+
+				// RX Light off
+				this.SetLED(LED_RX, gopi.GPIO_LOW)
+			}
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// setOOKReceive sets the control profile, with fixed length
+// packets which follow the preamble. It should be called with txlock held
+func (this *mihome) setOOKReceive() error {
+	if err := this.setOOKMode(); err != nil {
+		return err
+	} else if err := this.radio.SetPacketFormat(sensors.RFM_PACKET_FORMAT_FIXED); err != nil {
+		return err
+	} else if err := this.radio.SetPayloadSize(OOK_RX_PAYLOAD_SIZE); err != nil {
+		return err
+	} else if err := this.radio.SetSyncWord(OOK_RX_SYNCWORD); err != nil {
+		return err
+	} else if err := this.radio.SetSyncTolerance(0); err != nil {
+		return err
+	} else if err := this.radio.SetOOKThreshold(OOK_RX_THRESHOLD_TYPE, OOK_RX_THRESHOLD_STEP, OOK_RX_THRESHOLD_DEC); err != nil {
+		return err
+	} else if err := this.radio.SetOOKFixedThreshold(OOK_RX_THRESHOLD_FLOOR); err != nil {
+		return err
+	}
+
+	// Success
+	return nil
+}
+
+// decodeCommandPayload returns the address and command from a received
+// control payload, or ErrUnexpectedResponse if the payload is not valid
+func decodeCommandPayload(data []byte) ([]byte, Command, error) {
+	if len(data) != OOK_RX_PAYLOAD_SIZE {
+		return nil, OOK_NONE, sensors.ErrUnexpectedResponse
+	}
+	for _, value := range data[0:OOK_RX_ZERO_BYTES] {
+		if value != 0x00 {
+			return nil, OOK_NONE, sensors.ErrUnexpectedResponse
+		}
+	}
+
+	// Each nibble encodes a single bit, which results in 20 bits of
+	// address followed by 4 bits of command
+	bits := uint32(0)
+	for _, value := range data[OOK_RX_ZERO_BYTES:] {
+		for _, nibble := range []byte{value >> 4, value & 0x0F} {
+			bits <<= 1
+			switch nibble {
+			case OOK_ZERO:
+				break
+			case OOK_ONE:
+				bits |= 1
+			default:
+				return nil, OOK_NONE, sensors.ErrUnexpectedResponse
+			}
+		}
+	}
+	cid := []byte{byte(bits >> 20), byte(bits >> 12), byte(bits >> 4)}
+	return cid, Command(bits & 0x0F), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - control_rx_event
+
+func (this *control_rx_event) Name() string {
+	return "MiHomeControlEvent"
+}
+
+func (this *control_rx_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *control_rx_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *control_rx_event) Address() []byte {
+	return this.cid
+}
+
+func (this *control_rx_event) Command() uint8 {
+	return uint8(this.command)
+}
+
+func (this *control_rx_event) String() string {
+	return fmt.Sprintf("<sensors.MiHomeControlEvent>{ ts=%v cid=%v command=%v }", this.ts.Format(time.Stamp), strings.ToUpper(hex.EncodeToString(this.cid)), this.command)
+}
@@ -0,0 +1,177 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/hw/rfm69/mock"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST SEND AFTER RECEIVE
+
+// TestSendAfterReceive checks that a command sent after receiving in
+// control mode is transmitted with the control profile, not the profile
+// used to receive commands
+func TestSendAfterReceive(t *testing.T) {
+	driver, radio := test_mihome(t, MiHome{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		errs <- driver.Receive(ctx, sensors.MIHOME_MODE_CONTROL)
+	}()
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+	if radio.PayloadSize() != OOK_RX_PAYLOAD_SIZE {
+		t.Errorf("PayloadSize: expected %v, got %v", OOK_RX_PAYLOAD_SIZE, radio.PayloadSize())
+	}
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := driver.SendControl(driver.cid, OOK_ON_1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if tx := radio.Transmitted(); len(tx) != 1 {
+		t.Errorf("Expected one transmission, got %v", len(tx))
+	} else {
+		checkControlProfile(t, driver, tx[0])
+	}
+}
+
+// TestSendDuringReceive checks that a transmission interrupts a receive
+// in control mode, which resumes afterwards
+func TestSendDuringReceive(t *testing.T) {
+	driver, radio := test_mihome(t, MiHome{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- driver.Receive(ctx, sensors.MIHOME_MODE_CONTROL)
+	}()
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+
+	// The send interrupts the read, rather than waiting for a packet
+	sent := make(chan error)
+	go func() {
+		sent <- driver.SendControl(driver.cid, OOK_OFF_2, 1)
+	}()
+	select {
+	case err := <-sent:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendControl blocked by Receive")
+	}
+	if tx := radio.Transmitted(); len(tx) != 1 {
+		t.Errorf("Expected one transmission, got %v", len(tx))
+	} else {
+		checkControlProfile(t, driver, tx[0])
+	}
+
+	// Receive returns the radio to OOK receive
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if driver.mode != sensors.MIHOME_MODE_CONTROL_RX {
+		t.Errorf("Mode: expected %v, got %v", sensors.MIHOME_MODE_CONTROL_RX, driver.mode)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST DECODE
+
+func TestDecodeCommandPayload(t *testing.T) {
+	cid := []byte{0x06, 0xC6, 0xC6}
+	payload, err := encodeCommandPayload(cid, OOK_ON_3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The received payload follows the sync word, which is the first
+	// byte of the preamble
+	received := payload[len(OOK_RX_SYNCWORD):]
+	corrupt := append([]byte{}, received...)
+	corrupt[OOK_RX_ZERO_BYTES+1] = OOK_ZERO<<4 | 0x0F
+
+	tests := []struct {
+		name    string
+		payload []byte
+		cid     []byte
+		cmd     Command
+		ok      bool
+	}{
+		{"Valid", received, cid, OOK_ON_3, true},
+		{"Nil", nil, nil, OOK_NONE, false},
+		{"Short", received[:OOK_RX_PAYLOAD_SIZE-1], nil, OOK_NONE, false},
+		{"Long", append(append([]byte{}, received...), OOK_ZERO<<4|OOK_ZERO), nil, OOK_NONE, false},
+		{"Preamble", payload[:OOK_RX_PAYLOAD_SIZE], nil, OOK_NONE, false},
+		{"CorruptNibble", corrupt, nil, OOK_NONE, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cid, cmd, err := decodeCommandPayload(test.payload)
+			if test.ok == false {
+				if err != sensors.ErrUnexpectedResponse {
+					t.Errorf("Expected ErrUnexpectedResponse, got %v", err)
+				}
+			} else if err != nil {
+				t.Error(err)
+			} else if bytes.Equal(cid, test.cid) == false || cmd != test.cmd {
+				t.Errorf("Expected %X %v, got %X %v", test.cid, test.cmd, cid, cmd)
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+// waitRadioMode waits for the radio to enter a mode
+func waitRadioMode(t *testing.T, radio mock.RFM69, mode sensors.RFMMode) {
+	t.Helper()
+	timeout := time.Now().Add(time.Second)
+	for radio.Mode() != mode {
+		if time.Now().After(timeout) {
+			t.Fatalf("Timeout waiting for radio mode %v", mode)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// checkControlProfile checks a command was transmitted with the control
+// profile
+func checkControlProfile(t *testing.T, driver *mihome, tx mock.Transmission) {
+	t.Helper()
+	profile := driver.profile_control
+	if tx.Modulation != sensors.RFM_MODULATION_OOK {
+		t.Errorf("Modulation: expected OOK, got %v", tx.Modulation)
+	}
+	if tx.PacketFormat != profile.PacketFormat {
+		t.Errorf("PacketFormat: expected %v, got %v", profile.PacketFormat, tx.PacketFormat)
+	}
+	if tx.PayloadSize != profile.PayloadSize {
+		t.Errorf("PayloadSize: expected %v, got %v", profile.PayloadSize, tx.PayloadSize)
+	}
+	if bytes.Equal(tx.SyncWord, profile.SyncWord) == false {
+		t.Errorf("SyncWord: expected %X, got %X", profile.SyncWord, tx.SyncWord)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"sync/atomic"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// counters are updated with atomic operations, so that counting a packet
+// never waits for a diagnostics read. The struct must be allocated on its
+// own so that the fields are 64-bit aligned on 32-bit platforms
+type counters struct {
+	packets_rx    uint64
+	packets_tx    uint64
+	packet_errors uint64
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func newCounters() *counters {
+	return new(counters)
+}
+
+// Count a received packet, and whether it could be decoded. The packet
+// is counted before the error, so a snapshot never has more errors than
+// packets
+func (this *counters) CountRX(reason error) {
+	atomic.AddUint64(&this.packets_rx, 1)
+	if reason != nil {
+		atomic.AddUint64(&this.packet_errors, 1)
+	}
+}
+
+// Count a transmitted packet
+func (this *counters) CountTX() {
+	atomic.AddUint64(&this.packets_tx, 1)
+}
+
+// Snapshot returns the number of received packets, transmitted packets
+// and packet errors. The counters are read in the reverse order to which
+// they are updated, so the values are consistent with each other
+func (this *counters) Snapshot() (uint64, uint64, uint64) {
+	packet_errors := atomic.LoadUint64(&this.packet_errors)
+	packets_tx := atomic.LoadUint64(&this.packets_tx)
+	packets_rx := atomic.LoadUint64(&this.packets_rx)
+	return packets_rx, packets_tx, packet_errors
+}
@@ -0,0 +1,182 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// decode_pool decodes received payloads on a bounded pool of worker
+// goroutines, so that the goroutine reading the radio only queues the
+// payload and returns to the FIFO. Payloads are decoded in parallel, and
+// then handed in order of reception to a lane chosen by sensor ID, which
+// emits the message. Messages from one sensor are always emitted in the
+// order they were received. When the queue is full, payloads are dropped
+type decode_pool struct {
+	dropped  uint64 // Atomic, first for 64-bit alignment
+	decoders []Decoder
+	emit     func(*decode_job)
+	jobs     chan *decode_job
+	ordered  chan *decode_job
+	lanes    []chan *decode_job
+	wait     sync.WaitGroup
+	lock     sync.Mutex
+	closed   bool
+}
+
+// decode_job is a payload waiting to be decoded and emitted. The done
+// channel is closed when the payload has been decoded
+type decode_job struct {
+	ts      time.Time
+	data    []byte
+	message sensors.OTMessage
+	decoder string
+	reason  error
+	done    chan struct{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Payloads waiting to be decoded before payloads are dropped
+	DECODE_QUEUE_DEFAULT = 64
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// newDecodePool starts a pool of workers, or one worker per CPU when
+// workers is zero, with a queue of the given size, or the default when
+// zero. The emit function is called from the lane goroutines
+func newDecodePool(decoders []Decoder, workers, queue uint, emit func(*decode_job)) *decode_pool {
+	if workers == 0 {
+		workers = uint(runtime.NumCPU())
+	}
+	if queue == 0 {
+		queue = DECODE_QUEUE_DEFAULT
+	}
+
+	this := new(decode_pool)
+	this.decoders = decoders
+	this.emit = emit
+	// The sequencer can hold one job taken from the ordered queue which
+	// is still waiting in the jobs queue, so the jobs queue is one larger
+	this.jobs = make(chan *decode_job, queue+1)
+	this.ordered = make(chan *decode_job, queue)
+	this.lanes = make([]chan *decode_job, workers)
+	for i := range this.lanes {
+		this.lanes[i] = make(chan *decode_job, queue)
+		this.wait.Add(1)
+		go this.runLane(this.lanes[i])
+	}
+	for i := uint(0); i < workers; i++ {
+		this.wait.Add(1)
+		go this.runWorker()
+	}
+	this.wait.Add(1)
+	go this.runSequencer()
+	return this
+}
+
+// Submit queues a payload for decoding, and returns false if the queue
+// is full or the pool is closed, and the payload was dropped
+func (this *decode_pool) Submit(ts time.Time, data []byte) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.closed {
+		return false
+	}
+
+	job := &decode_job{
+		ts:   ts,
+		data: data,
+		done: make(chan struct{}),
+	}
+	// A job leaves the ordered queue only after it has been decoded, so
+	// when there is space in the ordered queue there is space in the jobs
+	// queue and the second send does not block
+	select {
+	case this.ordered <- job:
+		this.jobs <- job
+		return true
+	default:
+		atomic.AddUint64(&this.dropped, 1)
+		return false
+	}
+}
+
+// Return number of payloads dropped because the queue was full
+func (this *decode_pool) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+// Close stops accepting payloads, waits for queued payloads to be decoded
+// and emitted, and then stops the goroutines. Payloads submitted after
+// Close are dropped
+func (this *decode_pool) Close() {
+	this.lock.Lock()
+	if this.closed == false {
+		this.closed = true
+		close(this.jobs)
+		close(this.ordered)
+	}
+	this.lock.Unlock()
+	this.wait.Wait()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *decode_pool) runWorker() {
+	defer this.wait.Done()
+	for job := range this.jobs {
+		job.message, job.decoder, job.reason = decodePayload(this.decoders, job.data)
+		close(job.done)
+	}
+}
+
+// runSequencer hands decoded jobs to the lanes in order of reception,
+// waiting for each job to be decoded in turn
+func (this *decode_pool) runSequencer() {
+	defer this.wait.Done()
+	for job := range this.ordered {
+		<-job.done
+		this.lanes[this.lane(job.message)] <- job
+	}
+	for _, lane := range this.lanes {
+		close(lane)
+	}
+}
+
+func (this *decode_pool) runLane(lane <-chan *decode_job) {
+	defer this.wait.Done()
+	for job := range lane {
+		this.emit(job)
+	}
+}
+
+// lane returns the lane for a message, which is the same for all messages
+// from a sensor. Payloads which failed to decode use the first lane
+func (this *decode_pool) lane(message sensors.OTMessage) int {
+	if message == nil {
+		return 0
+	}
+	return int(message.SensorID() % uint32(len(this.lanes)))
+}
@@ -0,0 +1,113 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// test_protocol decodes payloads of the form [sensor seq delay], sleeping
+// for delay milliseconds so that payloads finish decoding out of order
+type test_protocol struct {
+	sensors.OpenThings
+}
+
+// test_message is a message decoded by test_protocol
+type test_message struct {
+	sensors.OTMessage
+
+	sensor uint32
+	seq    uint8
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST ORDER
+
+// TestDecodeOrder checks that with several workers, subscribers receive
+// the messages from each sensor in the order they were received
+func TestDecodeOrder(t *testing.T) {
+	const sensor_count, message_count = 3, 40
+	driver, radio := test_mihome(t, MiHome{
+		Decoders:      []Decoder{{"test", test_protocol{}}},
+		DecodeWorkers: 4,
+		DecodeQueue:   sensor_count * message_count,
+		EventBuffer:   sensor_count * message_count,
+	})
+	events := driver.SubscribeOTEvent()
+	defer driver.UnsubscribeOTEvent(events)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- driver.Receive(ctx, sensors.MIHOME_MODE_MONITOR)
+	}()
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+
+	// Later payloads decode faster than earlier ones
+	for seq := 0; seq < message_count; seq++ {
+		for sensor := 0; sensor < sensor_count; sensor++ {
+			radio.InjectPacket(&sensors.RFMPacket{
+				Timestamp: time.Now(),
+				Payload:   []byte{byte(sensor), byte(seq), byte(3 - seq%4)},
+				CRCOk:     true,
+			})
+		}
+	}
+
+	next := make(map[uint32]uint8, sensor_count)
+	timeout := time.After(5 * time.Second)
+	for received := 0; received < sensor_count*message_count; received++ {
+		select {
+		case evt := <-events:
+			if message, ok := evt.Message().(*test_message); ok == false {
+				t.Fatalf("Unexpected event %v", evt)
+			} else if message.seq != next[message.sensor] {
+				t.Fatalf("Sensor %v: expected message %v, got %v", message.sensor, next[message.sensor], message.seq)
+			} else {
+				next[message.sensor]++
+			}
+		case <-timeout:
+			t.Fatalf("Timeout after %v messages", received)
+		}
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - test_protocol
+
+func (test_protocol) Decode(payload []byte) (sensors.OTMessage, error) {
+	if len(payload) != 3 {
+		return nil, sensors.ErrMessageCorruption
+	}
+	time.Sleep(time.Duration(payload[2]) * time.Millisecond)
+	return &test_message{sensor: uint32(payload[0]), seq: payload[1]}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - test_message
+
+func (this *test_message) SensorID() uint32            { return this.sensor }
+func (this *test_message) ProductID() uint8            { return 0 }
+func (this *test_message) Records() []sensors.OTRecord { return nil }
+func (this *test_message) Replayed() bool              { return false }
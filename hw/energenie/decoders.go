@@ -0,0 +1,82 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"fmt"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Decoder is a named payload protocol. When a payload fails to decode
+// with the OpenThings protocol, alternative decoders are tried in order
+// and the name of the one which succeeded is recorded in the OTEvent
+type Decoder struct {
+	Name     string
+	Protocol sensors.OpenThings
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	DECODER_OPENTHINGS = "openthings"
+	DECODER_DEMO       = "demo"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// newDecoders returns the OpenThings protocol followed by any
+// alternative decoders, or nil if there is no OpenThings protocol
+func newDecoders(protocol sensors.OpenThings, alternatives []Decoder) ([]Decoder, error) {
+	if protocol == nil {
+		return nil, nil
+	}
+	decoders := []Decoder{Decoder{DECODER_OPENTHINGS, protocol}}
+	for _, decoder := range alternatives {
+		if decoder.Name == "" || decoder.Protocol == nil {
+			return nil, fmt.Errorf("Invalid decoder: %v", decoder)
+		}
+		decoders = append(decoders, decoder)
+	}
+	return decoders, nil
+}
+
+// decodePayload tries each decoder in turn and returns the first message
+// which decodes without error. If none succeed, the result from the first
+// decoder is returned
+func decodePayload(decoders []Decoder, data []byte) (sensors.OTMessage, string, error) {
+	var first_message sensors.OTMessage
+	var first_reason error
+	for i, decoder := range decoders {
+		message, reason := decoder.Protocol.Decode(data)
+		if reason == nil && message != nil {
+			return message, decoder.Name, nil
+		} else if i == 0 {
+			first_message, first_reason = message, reason
+		}
+	}
+	if len(decoders) == 0 {
+		return nil, "", nil
+	}
+	return first_message, decoders[0].Name, first_reason
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (d Decoder) String() string {
+	return fmt.Sprintf("<sensors.energenie.Decoder>{ name=\"%v\" protocol=%v }", d.Name, d.Protocol)
+}
@@ -0,0 +1,548 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Demo Configuration, which fabricates a set of virtual devices so that
+// applications can be evaluated without any radio hardware
+type Demo struct {
+	Interval   time.Duration      // Interval between messages from virtual devices
+	Replay     string             // Capture file to replay instead, or empty
+	OpenThings sensors.OpenThings // Payload protocol, required for replay
+}
+
+// demo driver
+type demo struct {
+	log        gopi.Logger
+	interval   time.Duration
+	replay     string
+	protocol   sensors.OpenThings
+	decoders   []Decoder
+	sockets    [ENER314_SOCKET_MAX]bool
+	loads      [ENER314_SOCKET_MAX]float64
+	door       bool
+	tempoffset float32
+	opened     time.Time
+	next       uint
+	counters   *counters
+	random     *rand.Rand
+	pubsub     *pubsub
+	otevents   *ot_pubsub
+	lock       sync.Mutex
+}
+
+// demo_message is a fabricated OpenThings message
+type demo_message struct {
+	product uint8
+	sensor  uint32
+	records []sensors.OTRecord
+}
+
+// demo_record is a fabricated OpenThings record
+type demo_record struct {
+	name     sensors.OTParameter
+	datatype sensors.OTDataType
+	value    string
+}
+
+type demo_event struct {
+	driver  *demo
+	ts      time.Time
+	message sensors.OTMessage
+	decoder string
+	reason  error
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	DEMO_INTERVAL_DEFAULT = 5 * time.Second
+)
+
+const (
+	// Energenie product identifiers for the virtual devices
+	DEMO_PRODUCT_ADAPTER_PLUS = 0x02 // MIHO005
+	DEMO_PRODUCT_ETRV         = 0x03 // MIHO013
+	DEMO_PRODUCT_OPEN_CLOSE   = 0x0D // MIHO033
+)
+
+const (
+	// Sensor identifiers for the virtual devices
+	DEMO_SENSOR_ADAPTER_PLUS = 0x00D000 // Plus socket number
+	DEMO_SENSOR_ETRV         = 0x00D010
+	DEMO_SENSOR_OPEN_CLOSE   = 0x00D020
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Demo) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug2("<sensors.energenie.Demo>Open{ interval=%v }", config.Interval)
+
+	this := new(demo)
+	this.log = log
+	this.interval = config.Interval
+	this.replay = config.Replay
+	this.protocol = config.OpenThings
+	if this.replay != "" && this.protocol == nil {
+		return nil, gopi.ErrBadParameter
+	} else if decoders, err := newDecoders(config.OpenThings, nil); err != nil {
+		return nil, err
+	} else {
+		this.decoders = decoders
+	}
+	if this.interval == 0 {
+		this.interval = DEMO_INTERVAL_DEFAULT
+	}
+	this.opened = time.Now()
+	this.counters = newCounters()
+	this.random = rand.New(rand.NewSource(this.opened.UnixNano()))
+
+	// Each socket has an appliance with a different load
+	for i := range this.loads {
+		this.loads[i] = 20 + this.random.Float64()*2000
+	}
+
+	// Event interface
+	this.pubsub = newPubSub(EVENT_BUFFER_DEFAULT)
+	this.otevents = newOTPubSub(EVENT_BUFFER_DEFAULT)
+
+	// Return success
+	return this, nil
+}
+
+func (this *demo) Close() error {
+	this.log.Debug2("<sensors.energenie.Demo>Close{ }")
+
+	// Close subscriber channels
+	this.pubsub.Close()
+	this.otevents.Close()
+
+	// Free resources
+	this.pubsub = nil
+	this.otevents = nil
+	this.protocol = nil
+	this.decoders = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *demo) String() string {
+	return fmt.Sprintf("<sensors.energenie.Demo>{ interval=%v sockets=%v }", this.interval, this.sockets)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS - ENER314
+
+func (this *demo) On(sockets ...uint) error {
+	return this.setSockets(true, sockets...)
+}
+
+func (this *demo) Off(sockets ...uint) error {
+	return this.setSockets(false, sockets...)
+}
+
+func (this *demo) Dim(socket uint, level uint) error {
+	if level > DIM_LEVEL_MAX {
+		return gopi.ErrBadParameter
+	} else if socket == 0 {
+		return this.setSockets(level != DIM_LEVEL_OFF)
+	} else {
+		return this.setSockets(level != DIM_LEVEL_OFF, socket)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS - MIHOME
+
+func (this *demo) ResetRadio() error {
+	return nil
+}
+
+// The demo driver only fabricates monitor mode messages
+func (this *demo) SupportedModes() []sensors.MiHomeMode {
+	return []sensors.MiHomeMode{sensors.MIHOME_MODE_MONITOR}
+}
+
+// Receive fabricates messages from the virtual devices until the
+// context is cancelled, or replays messages from a capture file
+func (this *demo) Receive(ctx context.Context, mode sensors.MiHomeMode) error {
+	if supportsMode(this.SupportedModes(), mode) == false {
+		return gopi.ErrNotImplemented
+	} else if this.replay != "" {
+		return replayCapture(ctx, this.replay, true, this.decoders, this.emitMessage)
+	}
+
+	ticker := time.NewTicker(this.interval)
+	defer ticker.Stop()
+
+FOR_LOOP:
+	for {
+		select {
+		case <-ctx.Done():
+			break FOR_LOOP
+		case <-ticker.C:
+			this.emit(this.nextMessage())
+		}
+	}
+
+	// Success
+	return nil
+}
+
+func (this *demo) MeasureTemperature() (float32, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.temperature() + this.tempoffset, nil
+}
+
+func (this *demo) TempOffset() float32 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.tempoffset
+}
+
+func (this *demo) SetTempOffset(offset float32) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.tempoffset = offset
+	return nil
+}
+
+func (this *demo) Calibrate(reference float32) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.tempoffset = reference - this.temperature()
+	return nil
+}
+
+func (this *demo) SetScene(name string, actions ...sensors.MiHomeSceneAction) error {
+	// Scenes can't be defined for the virtual devices
+	return gopi.ErrNotImplemented
+}
+
+func (this *demo) TriggerScene(name string) error {
+	// There are no scenes defined for the virtual devices
+	return gopi.ErrBadParameter
+}
+
+func (this *demo) Diagnostics() sensors.MiHomeDiagnostics {
+	packets_rx, packets_tx, packet_errors := this.counters.Snapshot()
+
+	return sensors.MiHomeDiagnostics{
+		Mode:         sensors.MIHOME_MODE_MONITOR.String(),
+		RadioMode:    "DEMO",
+		Uptime:       time.Since(this.opened),
+		PacketsRX:    packets_rx,
+		PacketsTX:    packets_tx,
+		PacketErrors: packet_errors,
+		Dropped:      this.pubsub.Dropped() + this.otevents.Dropped(),
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the uncalibrated gateway temperature, which varies slowly around 35C
+func (this *demo) temperature() float32 {
+	minutes := time.Since(this.opened).Minutes()
+	return float32(35 + 2*math.Sin(minutes/30))
+}
+
+func (this *demo) setSockets(state bool, sockets ...uint) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if len(sockets) == 0 {
+		for i := range this.sockets {
+			sockets = append(sockets, uint(i+1))
+		}
+	}
+	for _, socket := range sockets {
+		if socket < ENER314_SOCKET_MIN || socket > ENER314_SOCKET_MAX {
+			return gopi.ErrBadParameter
+		}
+	}
+	for _, socket := range sockets {
+		this.sockets[socket-1] = state
+	}
+	this.counters.CountTX()
+
+	// Report the new state of the sockets
+	go func() {
+		for _, socket := range sockets {
+			this.emit(this.adapterPlusMessage(socket))
+		}
+	}()
+
+	return nil
+}
+
+// Return the next message, visiting each virtual device in turn
+func (this *demo) nextMessage() *demo_message {
+	this.lock.Lock()
+	device := this.next
+	this.next = (this.next + 1) % (ENER314_SOCKET_MAX + 2)
+	this.lock.Unlock()
+
+	switch device {
+	case ENER314_SOCKET_MAX:
+		return this.etrvMessage()
+	case ENER314_SOCKET_MAX + 1:
+		return this.openCloseMessage()
+	default:
+		return this.adapterPlusMessage(device + 1)
+	}
+}
+
+func (this *demo) adapterPlusMessage(socket uint) *demo_message {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	state := this.sockets[socket-1]
+	power := float64(0)
+	if state {
+		power = this.loads[socket-1] * (0.95 + this.random.Float64()*0.1)
+	}
+	voltage := 238 + this.random.Float64()*6
+	return &demo_message{
+		product: DEMO_PRODUCT_ADAPTER_PLUS,
+		sensor:  DEMO_SENSOR_ADAPTER_PLUS + uint32(socket),
+		records: []sensors.OTRecord{
+			&demo_record{sensors.OT_PARAM_SWITCH_STATE, sensors.OT_DATATYPE_UDEC_0, fmt.Sprint(to_uint(state))},
+			&demo_record{sensors.OT_PARAM_REAL_POWER, sensors.OT_DATATYPE_DEC_0, fmt.Sprintf("%.0f", power)},
+			&demo_record{sensors.OT_PARAM_REACTIVE_POWER, sensors.OT_DATATYPE_DEC_0, "0"},
+			&demo_record{sensors.OT_PARAM_VOLTAGE, sensors.OT_DATATYPE_UDEC_0, fmt.Sprintf("%.0f", voltage)},
+			&demo_record{sensors.OT_PARAM_FREQUENCY, sensors.OT_DATATYPE_UDEC_8, fmt.Sprintf("%.2f", 49.9+this.random.Float64()*0.2)},
+		},
+	}
+}
+
+func (this *demo) etrvMessage() *demo_message {
+	// Room temperature follows a daily cycle between 17C and 21C
+	hours := float64(time.Now().Hour()) + float64(time.Now().Minute())/60
+	temperature := 19 + 2*math.Sin((hours-9)*math.Pi/12)
+	return &demo_message{
+		product: DEMO_PRODUCT_ETRV,
+		sensor:  DEMO_SENSOR_ETRV,
+		records: []sensors.OTRecord{
+			&demo_record{sensors.OT_PARAM_TEMPERATURE, sensors.OT_DATATYPE_DEC_8, fmt.Sprintf("%.1f", temperature)},
+		},
+	}
+}
+
+func (this *demo) openCloseMessage() *demo_message {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// The door is opened and closed occasionally
+	if this.random.Intn(4) == 0 {
+		this.door = !this.door
+	}
+	return &demo_message{
+		product: DEMO_PRODUCT_OPEN_CLOSE,
+		sensor:  DEMO_SENSOR_OPEN_CLOSE,
+		records: []sensors.OTRecord{
+			&demo_record{sensors.OT_PARAM_DOOR_SENSOR, sensors.OT_DATATYPE_UDEC_0, fmt.Sprint(to_uint(this.door))},
+		},
+	}
+}
+
+func (this *demo) emit(message *demo_message) {
+	this.emitMessage(message, DECODER_DEMO, nil)
+}
+
+func (this *demo) emitMessage(message sensors.OTMessage, decoder string, reason error) {
+	this.counters.CountRX(reason)
+
+	event := &demo_event{
+		driver:  this,
+		ts:      time.Now(),
+		message: message,
+		decoder: decoder,
+		reason:  reason,
+	}
+	this.pubsub.Emit(event)
+	this.otevents.Emit(event)
+	if reason == nil {
+		for _, report := range reports(this, event.ts, message) {
+			this.pubsub.Emit(report)
+		}
+	}
+}
+
+func to_uint(value bool) uint {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBSUB
+
+func (this *demo) Subscribe() <-chan gopi.Event {
+	return this.pubsub.Subscribe()
+}
+
+func (this *demo) Unsubscribe(subscriber <-chan gopi.Event) {
+	this.pubsub.Unsubscribe(subscriber)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - demo_message
+
+func (this *demo_message) Size() uint8 {
+	return 0
+}
+
+func (this *demo_message) Manufacturer() sensors.OTManufacturer {
+	return sensors.OT_MANUFACTURER_ENERGENIE
+}
+
+func (this *demo_message) ProductID() uint8 {
+	return this.product
+}
+
+func (this *demo_message) SensorID() uint32 {
+	return this.sensor
+}
+
+func (this *demo_message) CRC() uint16 {
+	return 0
+}
+
+func (this *demo_message) Payload() []byte {
+	return []byte{}
+}
+
+func (this *demo_message) Records() []sensors.OTRecord {
+	return this.records
+}
+
+func (this *demo_message) Replayed() bool {
+	return false
+}
+
+func (this *demo_message) String() string {
+	return fmt.Sprintf("<sensors.energenie.DemoMessage>{ product_id=0x%02X sensor_id=0x%06X records=%v }", this.product, this.sensor, this.records)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - demo_record
+
+func (this *demo_record) Name() sensors.OTParameter {
+	return this.name
+}
+
+func (this *demo_record) Type() sensors.OTDataType {
+	return this.datatype
+}
+
+func (this *demo_record) StringValue() (string, error) {
+	return this.value, nil
+}
+
+// IsRequest returns false, since fabricated records are all reports
+func (this *demo_record) IsRequest() bool {
+	return false
+}
+
+// Raw returns nil, since fabricated records are not encoded
+func (this *demo_record) Raw() []byte {
+	return nil
+}
+
+// BoolValue returns the value of the switch and door sensor records
+func (this *demo_record) BoolValue() (bool, error) {
+	if this.name != sensors.OT_PARAM_SWITCH_STATE && this.name != sensors.OT_PARAM_DOOR_SENSOR {
+		return false, sensors.ErrWrongType
+	} else if value, err := this.floatValue(this.name); err != nil {
+		return false, err
+	} else {
+		return value != 0, nil
+	}
+}
+
+func (this *demo_record) TemperatureCelsius() (float64, error) {
+	return this.floatValue(sensors.OT_PARAM_TEMPERATURE)
+}
+
+func (this *demo_record) PowerWatts() (float64, error) {
+	return this.floatValue(sensors.OT_PARAM_REAL_POWER)
+}
+
+func (this *demo_record) VoltageVolts() (float64, error) {
+	return this.floatValue(sensors.OT_PARAM_VOLTAGE)
+}
+
+// floatValue parses the value, and returns ErrWrongType if the record
+// is not for the parameter
+func (this *demo_record) floatValue(name sensors.OTParameter) (float64, error) {
+	if this.name != name {
+		return 0, sensors.ErrWrongType
+	}
+	return strconv.ParseFloat(this.value, 64)
+}
+
+func (this *demo_record) String() string {
+	return fmt.Sprintf("%v<value=%v>", this.name, this.value)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - demo_event
+
+func (this *demo_event) Name() string {
+	return "OTEvent"
+}
+
+func (this *demo_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *demo_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *demo_event) Message() sensors.OTMessage {
+	return this.message
+}
+
+func (this *demo_event) Reason() error {
+	return this.reason
+}
+
+func (this *demo_event) Decoder() string {
+	return this.decoder
+}
+
+func (this *demo_event) String() string {
+	return fmt.Sprintf("<sensors.MonitorRXEvent>{ ts=%v message=%v decoder=\"%v\" reason=%v demo=true }", this.ts.Format(time.Stamp), this.message, this.decoder, this.reason)
+}
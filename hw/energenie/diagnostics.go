@@ -0,0 +1,166 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+type mode_event struct {
+	driver     *mihome
+	ts         time.Time
+	mode       sensors.MiHomeMode
+	radio_mode sensors.RFMMode
+	reason     string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Number of mode changes retained for diagnostics
+	MODE_HISTORY_SIZE = 10
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Diagnostics returns a snapshot of the driver and radio state. The
+// counters are read without locks, so reading diagnostics never holds up
+// the goroutines receiving and emitting packets
+func (this *mihome) Diagnostics() sensors.MiHomeDiagnostics {
+	// Copy the mode and history
+	this.statslock.Lock()
+	mode := this.mode
+	history := make([]sensors.MiHomeModeChange, len(this.history))
+	copy(history, this.history)
+	this.statslock.Unlock()
+
+	packets_rx, packets_tx, packet_errors := this.counters.Snapshot()
+	led_writes, led_coalesced, led_time := this.leds.Stats()
+
+	return sensors.MiHomeDiagnostics{
+		Mode:          mode.String(),
+		ModeHistory:   history,
+		RadioMode:     this.radio.Mode().String(),
+		Modulation:    this.radio.Modulation().String(),
+		FreqCarrier:   this.radio.FreqCarrier(),
+		FIFOThreshold: this.radio.FIFOThreshold(),
+		CID:           strings.ToUpper(hex.EncodeToString(this.cid)),
+		Repeat:        this.repeat,
+		Uptime:        time.Since(this.opened),
+		PacketsRX:     packets_rx,
+		PacketsTX:     packets_tx,
+		PacketErrors:  packet_errors,
+		Dropped:       this.pubsub.Dropped() + this.otevents.Dropped(),
+		DecodeDropped: this.decode.Dropped(),
+		LEDWrites:     led_writes,
+		LEDCoalesced:  led_coalesced,
+		LEDTime:       led_time,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Set the mode, record the change in the mode history and emit
+// a mode event
+func (this *mihome) setMode(mode sensors.MiHomeMode, reason string) {
+	this.statslock.Lock()
+	ts := time.Now()
+	this.mode = mode
+	this.history = append(this.history, sensors.MiHomeModeChange{
+		Timestamp: ts,
+		Mode:      mode.String(),
+		Reason:    reason,
+	})
+	if len(this.history) > MODE_HISTORY_SIZE {
+		this.history = this.history[len(this.history)-MODE_HISTORY_SIZE:]
+	}
+	this.statslock.Unlock()
+
+	this.emitMode(ts, reason)
+}
+
+// Set the radio mode and emit a mode event when the mode changes
+func (this *mihome) setRadioMode(mode sensors.RFMMode, reason string) error {
+	old_mode := this.radio.Mode()
+	if err := this.radio.SetMode(mode); err != nil {
+		return err
+	} else if old_mode != mode {
+		this.emitMode(time.Now(), reason)
+	}
+	return nil
+}
+
+func (this *mihome) emitMode(ts time.Time, reason string) {
+	// The pubsub does not exist until the driver is opened
+	if this.pubsub == nil {
+		return
+	}
+	this.pubsub.Emit(&mode_event{
+		driver:     this,
+		ts:         ts,
+		mode:       this.mode,
+		radio_mode: this.radio.Mode(),
+		reason:     reason,
+	})
+}
+
+// Count a received packet, and whether it could be decoded
+func (this *mihome) countRX(reason error) {
+	this.counters.CountRX(reason)
+}
+
+// Count a transmitted packet
+func (this *mihome) countTX() {
+	this.counters.CountTX()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - mode_event
+
+func (this *mode_event) Name() string {
+	return "MiHomeModeEvent"
+}
+
+func (this *mode_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *mode_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *mode_event) Mode() sensors.MiHomeMode {
+	return this.mode
+}
+
+func (this *mode_event) RadioMode() sensors.RFMMode {
+	return this.radio_mode
+}
+
+func (this *mode_event) Reason() string {
+	return this.reason
+}
+
+func (this *mode_event) String() string {
+	return fmt.Sprintf("<sensors.energenie.MiHomeModeEvent>{ mode=%v radio_mode=%v reason=\"%v\" }", this.mode, this.radio_mode, this.reason)
+}
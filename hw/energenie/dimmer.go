@@ -0,0 +1,84 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	gopi "github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Dimmer levels, where zero is off and DIM_LEVEL_MAX is full brightness
+	DIM_LEVEL_OFF = 0
+	DIM_LEVEL_MAX = 5
+)
+
+const (
+	// Dim level commands (MIHO009, MIHO010) which are sent after the
+	// socket command in order to set the brightness. These are not in
+	// the Energenie ENER314 documentation, which lists only the socket
+	// on and off codes, and are the K0-K3 codes which remain once those
+	// are assigned. Confirm them against a capture of the dimmer's hand
+	// controller, made with Receive in MIHOME_MODE_CONTROL and the
+	// -mihome.capture flag, before relying on them
+	OOK_DIM_1 Command = 0x01 // 20%
+	OOK_DIM_2 Command = 0x09 // 40%
+	OOK_DIM_3 Command = 0x05 // 60%
+	OOK_DIM_4 Command = 0x08 // 80%
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the sequence of commands which sets a dimmer to a level. A socket
+// of zero addresses all sockets. Level zero switches the dimmer off and
+// DIM_LEVEL_MAX switches it on at full brightness, otherwise the socket is
+// switched on and followed by the dim level command
+func dimCommandsForSocket(socket, level uint) ([]Command, error) {
+	var on, off Command
+	if socket == 0 {
+		on, off = OOK_ON_ALL, OOK_OFF_ALL
+	} else if cmd, err := onCommandForSocket(socket); err != nil {
+		return nil, err
+	} else {
+		on = cmd
+		off, _ = offCommandForSocket(socket)
+	}
+	switch level {
+	case DIM_LEVEL_OFF:
+		return []Command{off}, nil
+	case 1:
+		return []Command{on, OOK_DIM_1}, nil
+	case 2:
+		return []Command{on, OOK_DIM_2}, nil
+	case 3:
+		return []Command{on, OOK_DIM_3}, nil
+	case 4:
+		return []Command{on, OOK_DIM_4}, nil
+	case DIM_LEVEL_MAX:
+		return []Command{on}, nil
+	default:
+		return nil, gopi.ErrBadParameter
+	}
+}
+
+// Reverse the bits of a command, which is the order in which
+// the ENER314 encoder reads the K0-K3 lines
+func reverseCommand(cmd Command) byte {
+	value := byte(0)
+	for i := uint(0); i < 4; i++ {
+		if cmd&(1<<i) != 0 {
+			value |= 0x08 >> i
+		}
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST DIM
+
+func TestDimCommandsForSocket(t *testing.T) {
+	tests := []struct {
+		socket, level uint
+		cmds          []Command
+	}{
+		{1, DIM_LEVEL_OFF, []Command{OOK_OFF_1}},
+		{1, 1, []Command{OOK_ON_1, OOK_DIM_1}},
+		{2, 2, []Command{OOK_ON_2, OOK_DIM_2}},
+		{3, 3, []Command{OOK_ON_3, OOK_DIM_3}},
+		{4, 4, []Command{OOK_ON_4, OOK_DIM_4}},
+		{4, DIM_LEVEL_MAX, []Command{OOK_ON_4}},
+		{0, 2, []Command{OOK_ON_ALL, OOK_DIM_2}},
+		{0, DIM_LEVEL_OFF, []Command{OOK_OFF_ALL}},
+		{5, 1, nil},
+		{1, DIM_LEVEL_MAX + 1, nil},
+	}
+	for _, test := range tests {
+		if cmds, err := dimCommandsForSocket(test.socket, test.level); test.cmds == nil {
+			if err == nil {
+				t.Errorf("socket=%v level=%v: expected an error, got %v", test.socket, test.level, cmds)
+			}
+		} else if err != nil {
+			t.Errorf("socket=%v level=%v: %v", test.socket, test.level, err)
+		} else if reflect.DeepEqual(cmds, test.cmds) == false {
+			t.Errorf("socket=%v level=%v: expected %v, got %v", test.socket, test.level, test.cmds, cmds)
+		}
+	}
+}
+
+// TestCommandString checks that every command has a name
+func TestCommandString(t *testing.T) {
+	cmds := []Command{
+		OOK_ON_ALL, OOK_OFF_ALL,
+		OOK_ON_1, OOK_OFF_1, OOK_ON_2, OOK_OFF_2, OOK_ON_3, OOK_OFF_3, OOK_ON_4, OOK_OFF_4,
+		OOK_DIM_1, OOK_DIM_2, OOK_DIM_3, OOK_DIM_4,
+	}
+	for _, cmd := range cmds {
+		if name := cmd.String(); strings.HasPrefix(name, "OOK_") == false {
+			t.Errorf("Command 0x%02X: unexpected name %v", uint8(cmd), name)
+		}
+	}
+}
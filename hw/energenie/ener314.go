@@ -116,6 +116,19 @@ func (this *ener314) Off(sockets ...uint) error {
 	return nil
 }
 
+func (this *ener314) Dim(socket uint, level uint) error {
+	this.log.Debug2("<sensors.energenie.ENER314>Dim{ socket=%v level=%v }", socket, level)
+	if cmds, err := dimCommandsForSocket(socket, level); err != nil {
+		return err
+	} else {
+		for _, cmd := range cmds {
+			reg := reverseCommand(cmd)
+			this.write(reg&0x07, reg&0x08 != 0)
+		}
+	}
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // SEND
 
@@ -0,0 +1,218 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"sync"
+	"sync/atomic"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// pubsub delivers gopi.Event values to subscribers on buffered channels.
+// When a subscriber channel is full the oldest event is dropped, so that
+// emitting never blocks on slow consumers
+type pubsub struct {
+	dropped     uint64 // Atomic, first for 64-bit alignment
+	subscribers []chan gopi.Event
+	capacity    uint
+	lock        sync.Mutex
+}
+
+// ot_pubsub delivers OTEvent values on typed channels, alongside
+// the gopi.Event values delivered through the driver pubsub, with
+// the same drop-oldest policy
+type ot_pubsub struct {
+	dropped     uint64 // Atomic, first for 64-bit alignment
+	subscribers []chan sensors.OTEvent
+	capacity    uint
+	lock        sync.Mutex
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	EVENT_BUFFER_DEFAULT = 32
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS - pubsub
+
+func newPubSub(capacity uint) *pubsub {
+	return &pubsub{
+		subscribers: make([]chan gopi.Event, 0),
+		capacity:    capacity,
+	}
+}
+
+func (this *pubsub) Subscribe() <-chan gopi.Event {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	c := make(chan gopi.Event, this.capacity)
+	this.subscribers = append(this.subscribers, c)
+	return c
+}
+
+func (this *pubsub) Unsubscribe(subscriber <-chan gopi.Event) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for i, c := range this.subscribers {
+		if c == subscriber {
+			close(c)
+			this.subscribers = append(this.subscribers[:i], this.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (this *pubsub) Emit(event gopi.Event) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for _, c := range this.subscribers {
+		select {
+		case c <- event:
+			continue
+		default:
+			// Drop the oldest event to make room
+			select {
+			case <-c:
+				atomic.AddUint64(&this.dropped, 1)
+			default:
+				break
+			}
+			select {
+			case c <- event:
+				break
+			default:
+				atomic.AddUint64(&this.dropped, 1)
+			}
+		}
+	}
+}
+
+// Return the number of events dropped
+func (this *pubsub) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *pubsub) Close() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for _, c := range this.subscribers {
+		close(c)
+	}
+	this.subscribers = nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS - ot_pubsub
+
+func newOTPubSub(capacity uint) *ot_pubsub {
+	return &ot_pubsub{
+		subscribers: make([]chan sensors.OTEvent, 0),
+		capacity:    capacity,
+	}
+}
+
+func (this *ot_pubsub) Subscribe() <-chan sensors.OTEvent {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	c := make(chan sensors.OTEvent, this.capacity)
+	this.subscribers = append(this.subscribers, c)
+	return c
+}
+
+func (this *ot_pubsub) Unsubscribe(subscriber <-chan sensors.OTEvent) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for i, c := range this.subscribers {
+		if c == subscriber {
+			close(c)
+			this.subscribers = append(this.subscribers[:i], this.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (this *ot_pubsub) Emit(event sensors.OTEvent) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for _, c := range this.subscribers {
+		select {
+		case c <- event:
+			continue
+		default:
+			// Drop the oldest event to make room
+			select {
+			case <-c:
+				atomic.AddUint64(&this.dropped, 1)
+			default:
+				break
+			}
+			select {
+			case c <- event:
+				break
+			default:
+				atomic.AddUint64(&this.dropped, 1)
+			}
+		}
+	}
+}
+
+// Return the number of events dropped
+func (this *ot_pubsub) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *ot_pubsub) Close() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for _, c := range this.subscribers {
+		close(c)
+	}
+	this.subscribers = nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBSUB - MIHOME
+
+// Subscribe to typed OTEvent values
+func (this *mihome) SubscribeOTEvent() <-chan sensors.OTEvent {
+	return this.otevents.Subscribe()
+}
+
+func (this *mihome) UnsubscribeOTEvent(subscriber <-chan sensors.OTEvent) {
+	this.otevents.Unsubscribe(subscriber)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBSUB - DEMO
+
+func (this *demo) SubscribeOTEvent() <-chan sensors.OTEvent {
+	return this.otevents.Subscribe()
+}
+
+func (this *demo) UnsubscribeOTEvent(subscriber <-chan sensors.OTEvent) {
+	this.otevents.Unsubscribe(subscriber)
+}
@@ -10,6 +10,7 @@
 package energenie
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 
@@ -34,6 +35,30 @@ func init() {
 		},
 	})
 
+	// Register mihome demo, which fabricates virtual devices
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/mihome:demo",
+		Requires: []string{"protocol/openthings"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagDuration("demo.interval", DEMO_INTERVAL_DEFAULT, "Interval between virtual device messages")
+			config.AppFlags.FlagString("demo.replay", "", "Capture file to replay")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			if openthings, ok := app.ModuleInstance("protocol/openthings").(sensors.OpenThings); !ok {
+				return nil, fmt.Errorf("Missing or invalid OpenThings module")
+			} else {
+				interval, _ := app.AppFlags.GetDuration("demo.interval")
+				replay, _ := app.AppFlags.GetString("demo.replay")
+				return gopi.Open(Demo{
+					Interval:   interval,
+					Replay:     replay,
+					OpenThings: openthings,
+				}, app.Logger)
+			}
+		},
+	})
+
 	// Register mihome using SPI & RFM69
 	gopi.RegisterModule(gopi.Module{
 		Name:     "sensors/mihome",
@@ -44,11 +69,40 @@ func init() {
 			config.AppFlags.FlagUint("gpio.reset", 25, "Reset Pin (Logical)")
 			config.AppFlags.FlagUint("gpio.led1", 27, "Green LED Pin (Logical)")
 			config.AppFlags.FlagUint("gpio.led2", 22, "Red LED Pin (Logical)")
+			config.AppFlags.FlagUint("gpio.dio0", 0, "Radio DIO0 Pin (Logical), or zero to poll")
 
 			// MiHome flags
 			config.AppFlags.FlagString("mihome.cid", "", "20-bit Command Device ID (hexadecimal)")
 			config.AppFlags.FlagUint("mihome.repeat", 0, "Command TX Repeat")
 			config.AppFlags.FlagFloat64("mihome.tempoffset", 0, "Temperature Calibration Value")
+			config.AppFlags.FlagString("mihome.calibration", "", "Temperature Calibration File")
+			config.AppFlags.FlagDuration("mihome.tempinterval", 0, "Temperature Sample Interval")
+			config.AppFlags.FlagString("mihome.capture", "", "File to record received payloads")
+			config.AppFlags.FlagUint("mihome.eventbuffer", EVENT_BUFFER_DEFAULT, "Events buffered per subscriber")
+			config.AppFlags.FlagBool("mihome.asyncled", false, "Write LED states in the background")
+			config.AppFlags.FlagString("mihome.timestamp", "rx", "Event timestamp source (rx, decode)")
+			config.AppFlags.FlagUint("mihome.decodeworkers", 0, "Goroutines decoding received payloads, or 0 for one per CPU")
+			config.AppFlags.FlagUint("mihome.decodequeue", DECODE_QUEUE_DEFAULT, "Payloads queued for decoding before they are dropped")
+
+			// Radio profile flags, zero values use the default profile
+			config.AppFlags.FlagUint("mihome.monitor.freq", 0, "Monitor mode carrier frequency (Hz)")
+			config.AppFlags.FlagUint("mihome.monitor.bitrate", 0, "Monitor mode bitrate")
+			config.AppFlags.FlagUint("mihome.monitor.deviation", 0, "Monitor mode frequency deviation (Hz)")
+			config.AppFlags.FlagUint("mihome.monitor.payload", 0, "Monitor mode fixed packet length (bytes), or 0 for variable length")
+			config.AppFlags.FlagBool("mihome.monitor.crc", false, "Monitor mode hardware CRC check")
+			config.AppFlags.FlagString("mihome.monitor.aeskey", "", "Monitor mode AES-128 key (32 hex digits)")
+			config.AppFlags.FlagBool("mihome.monitor.afc", false, "Monitor mode automatic frequency correction, using the low-beta routine")
+			config.AppFlags.FlagInt("mihome.monitor.afcoffset", 0, "Monitor mode low-beta AFC offset (Hz)")
+			config.AppFlags.FlagBool("mihome.monitor.boost", false, "Monitor mode LNA sensitivity boost")
+			config.AppFlags.FlagBool("mihome.monitor.dagc", false, "Monitor mode continuous DAGC")
+			config.AppFlags.FlagUint("mihome.control.freq", 0, "Control mode carrier frequency (Hz)")
+			config.AppFlags.FlagUint("mihome.control.bitrate", 0, "Control mode bitrate")
+
+			// Reset timing flags, zero values use the named profile
+			config.AppFlags.FlagString("mihome.reset", "default", "Reset timing profile (default, slow)")
+			config.AppFlags.FlagDuration("mihome.reset.pulse", 0, "Reset pulse duration")
+			config.AppFlags.FlagDuration("mihome.reset.settle", 0, "Delay after reset before polling the radio")
+			config.AppFlags.FlagDuration("mihome.reset.timeout", 0, "Timeout waiting for the radio after reset")
 
 			// Default spi.slave to 1
 			if err := config.AppFlags.SetUint("spi.slave", 1); err != nil {
@@ -70,6 +124,7 @@ func init() {
 					PinReset:   gopi.GPIO_PIN_NONE,
 					PinLED1:    gopi.GPIO_PIN_NONE,
 					PinLED2:    gopi.GPIO_PIN_NONE,
+					PinDIO0:    gopi.GPIO_PIN_NONE,
 				}
 				if reset, _ := app.AppFlags.GetUint("gpio.reset"); reset > 0 && reset <= 0xFF {
 					config.PinReset = gopi.GPIOPin(reset)
@@ -80,6 +135,9 @@ func init() {
 				if led2, _ := app.AppFlags.GetUint("gpio.led2"); led2 > 0 && led2 <= 0xFF {
 					config.PinLED2 = gopi.GPIOPin(led2)
 				}
+				if dio0, _ := app.AppFlags.GetUint("gpio.dio0"); dio0 > 0 && dio0 <= 0xFF {
+					config.PinDIO0 = gopi.GPIOPin(dio0)
+				}
 				if cid, exists := app.AppFlags.GetString("mihome.cid"); exists {
 					config.CID = cid
 				}
@@ -89,9 +147,138 @@ func init() {
 				if tempoffset, exists := app.AppFlags.GetFloat64("mihome.tempoffset"); exists {
 					config.TempOffset = float32(tempoffset)
 				}
+				if calibration, exists := app.AppFlags.GetString("mihome.calibration"); exists {
+					config.Calibration = calibration
+				}
+				if tempinterval, exists := app.AppFlags.GetDuration("mihome.tempinterval"); exists {
+					config.TempInterval = tempinterval
+				}
+				if capture, exists := app.AppFlags.GetString("mihome.capture"); exists {
+					config.Capture = capture
+				}
+				if eventbuffer, exists := app.AppFlags.GetUint("mihome.eventbuffer"); exists {
+					config.EventBuffer = eventbuffer
+				}
+				if asyncled, exists := app.AppFlags.GetBool("mihome.asyncled"); exists {
+					config.AsyncLED = asyncled
+				}
+				if workers, exists := app.AppFlags.GetUint("mihome.decodeworkers"); exists {
+					config.DecodeWorkers = workers
+				}
+				if queue, exists := app.AppFlags.GetUint("mihome.decodequeue"); exists {
+					config.DecodeQueue = queue
+				}
+				if timestamp, err := timestampSource(app); err != nil {
+					return nil, err
+				} else {
+					config.Timestamp = timestamp
+				}
+				config.MonitorProfile = monitorProfile(app)
+				config.ControlProfile = controlProfile(app)
+				if profile, err := resetProfile(app); err != nil {
+					return nil, err
+				} else {
+					config.ResetProfile = profile
+				}
 				return gopi.Open(config, app.Logger)
 			}
 		},
 	})
 
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// RADIO PROFILES
+
+func monitorProfile(app *gopi.AppInstance) *RadioProfile {
+	profile := PROFILE_MONITOR_DEFAULT
+	if freq, _ := app.AppFlags.GetUint("mihome.monitor.freq"); freq > 0 {
+		profile.FreqCarrier = freq
+	}
+	if bitrate, _ := app.AppFlags.GetUint("mihome.monitor.bitrate"); bitrate > 0 {
+		profile.Bitrate = bitrate
+	}
+	if deviation, _ := app.AppFlags.GetUint("mihome.monitor.deviation"); deviation > 0 {
+		profile.FreqDeviation = deviation
+	}
+	if payload, _ := app.AppFlags.GetUint("mihome.monitor.payload"); payload > 0 {
+		profile.PacketFormat = sensors.RFM_PACKET_FORMAT_FIXED
+		if payload <= PROFILE_PAYLOAD_MAX {
+			profile.PayloadSize = uint8(payload)
+		} else {
+			// Rejected when the profile is validated
+			profile.PayloadSize = 0
+		}
+	}
+	if crc, _ := app.AppFlags.GetBool("mihome.monitor.crc"); crc {
+		profile.PacketCRC = sensors.RFM_PACKET_CRC_AUTOCLEAR_ON
+	}
+	if key, _ := app.AppFlags.GetString("mihome.monitor.aeskey"); key != "" {
+		if aes_key, err := hex.DecodeString(key); err == nil {
+			profile.AESKey = aes_key
+		} else {
+			// Rejected when the profile is validated
+			profile.AESKey = []byte{}
+		}
+	}
+	if afc, _ := app.AppFlags.GetBool("mihome.monitor.afc"); afc {
+		profile.AFCMode = sensors.RFM_AFCMODE_AUTOCLEAR
+		profile.AFCRoutine = sensors.RFM_AFCROUTINE_IMPROVED
+	}
+	if offset, _ := app.AppFlags.GetInt("mihome.monitor.afcoffset"); offset != 0 {
+		profile.AFCOffset = offset
+	}
+	if boost, _ := app.AppFlags.GetBool("mihome.monitor.boost"); boost {
+		profile.SensitivityBoost = true
+	}
+	if dagc, _ := app.AppFlags.GetBool("mihome.monitor.dagc"); dagc {
+		profile.DAGC = true
+	}
+	return &profile
+}
+
+func controlProfile(app *gopi.AppInstance) *RadioProfile {
+	profile := PROFILE_CONTROL_DEFAULT
+	if freq, _ := app.AppFlags.GetUint("mihome.control.freq"); freq > 0 {
+		profile.FreqCarrier = freq
+	}
+	if bitrate, _ := app.AppFlags.GetUint("mihome.control.bitrate"); bitrate > 0 {
+		profile.Bitrate = bitrate
+	}
+	return &profile
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// TIMESTAMP SOURCE
+
+func timestampSource(app *gopi.AppInstance) (TimestampSource, error) {
+	switch value, _ := app.AppFlags.GetString("mihome.timestamp"); value {
+	case "", "rx":
+		return TIMESTAMP_RX, nil
+	case "decode":
+		return TIMESTAMP_DECODE, nil
+	default:
+		return TIMESTAMP_RX, fmt.Errorf("Invalid -mihome.timestamp flag: %v", value)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RESET PROFILE
+
+func resetProfile(app *gopi.AppInstance) (*ResetProfile, error) {
+	name, _ := app.AppFlags.GetString("mihome.reset")
+	profile, exists := RESET_PROFILES[name]
+	if exists == false {
+		return nil, fmt.Errorf("Invalid reset profile: \"%v\"", name)
+	}
+	if pulse, _ := app.AppFlags.GetDuration("mihome.reset.pulse"); pulse > 0 {
+		profile.Pulse = pulse
+	}
+	if settle, _ := app.AppFlags.GetDuration("mihome.reset.settle"); settle > 0 {
+		profile.Settle = settle
+	}
+	if timeout, _ := app.AppFlags.GetDuration("mihome.reset.timeout"); timeout > 0 {
+		profile.Timeout = timeout
+	}
+	return &profile, nil
+}
@@ -0,0 +1,150 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// led_driver writes LED states to GPIO pins. When asynchronous, writes are
+// queued and performed in order in a background goroutine, so that a pulse
+// is never lost. A write which repeats the last queued state of a pin is
+// coalesced with it
+type led_driver struct {
+	gpio      gopi.GPIO
+	async     bool
+	pending   []led_write
+	signal    chan struct{}
+	done      chan struct{}
+	wait      sync.WaitGroup
+	lock      sync.Mutex
+	writes    uint64
+	coalesced uint64
+	elapsed   time.Duration
+}
+
+type led_write struct {
+	pin   gopi.GPIOPin
+	state gopi.GPIOState
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func newLEDDriver(gpio gopi.GPIO, async bool) *led_driver {
+	this := new(led_driver)
+	this.gpio = gpio
+	this.async = async
+	if async {
+		this.signal = make(chan struct{}, 1)
+		this.done = make(chan struct{})
+		this.wait.Add(1)
+		go this.run()
+	}
+	return this
+}
+
+// Set the state of an LED pin
+func (this *led_driver) Set(pin gopi.GPIOPin, state gopi.GPIOState) {
+	if this.async == false {
+		this.write(pin, state)
+		return
+	}
+
+	this.lock.Lock()
+	if this.queued(pin, state) {
+		this.coalesced++
+	} else {
+		this.pending = append(this.pending, led_write{pin, state})
+	}
+	this.lock.Unlock()
+
+	// Wake the background goroutine
+	select {
+	case this.signal <- struct{}{}:
+		break
+	default:
+		break
+	}
+}
+
+// Return number of writes, number of coalesced writes and the
+// total time spent writing to GPIO pins
+func (this *led_driver) Stats() (uint64, uint64, time.Duration) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.writes, this.coalesced, this.elapsed
+}
+
+// Close stops the background goroutine, after writing any pending states
+func (this *led_driver) Close() {
+	if this.async {
+		close(this.done)
+		this.wait.Wait()
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *led_driver) run() {
+	defer this.wait.Done()
+	for {
+		select {
+		case <-this.done:
+			this.flush()
+			return
+		case <-this.signal:
+			this.flush()
+		}
+	}
+}
+
+// Write all pending states in the order they were queued
+func (this *led_driver) flush() {
+	this.lock.Lock()
+	pending := this.pending
+	this.pending = nil
+	this.lock.Unlock()
+
+	for _, pending := range pending {
+		this.write(pending.pin, pending.state)
+	}
+}
+
+// Return true if the last pending state for a pin is the same state,
+// called with lock held
+func (this *led_driver) queued(pin gopi.GPIOPin, state gopi.GPIOState) bool {
+	for i := len(this.pending) - 1; i >= 0; i-- {
+		if this.pending[i].pin == pin {
+			return this.pending[i].state == state
+		}
+	}
+	return false
+}
+
+func (this *led_driver) write(pin gopi.GPIOPin, state gopi.GPIOState) {
+	start := time.Now()
+	this.gpio.SetPinMode(pin, gopi.GPIO_OUTPUT)
+	this.gpio.WritePin(pin, state)
+	elapsed := time.Since(start)
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.writes++
+	this.elapsed += elapsed
+}
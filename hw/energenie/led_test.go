@@ -0,0 +1,62 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"reflect"
+	"testing"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST ASYNC LED
+
+// TestLEDPulse checks that asynchronous writes keep a pulse, rather than
+// coalescing it into the final state
+func TestLEDPulse(t *testing.T) {
+	gpio := new(test_gpio)
+	leds := newLEDDriver(gpio, true)
+	for i := 0; i < 3; i++ {
+		leds.Set(gopi.GPIOPin(1), gopi.GPIO_HIGH)
+		leds.Set(gopi.GPIOPin(1), gopi.GPIO_LOW)
+	}
+	leds.Close()
+
+	expected := []gopi.GPIOState{gopi.GPIO_HIGH, gopi.GPIO_LOW, gopi.GPIO_HIGH, gopi.GPIO_LOW, gopi.GPIO_HIGH, gopi.GPIO_LOW}
+	if writes := gpio.Writes(gopi.GPIOPin(1)); reflect.DeepEqual(writes, expected) == false {
+		t.Errorf("Expected %v, got %v", expected, writes)
+	}
+}
+
+// TestLEDCoalesce checks that a write which repeats the last queued state
+// of a pin is coalesced, without affecting other pins
+func TestLEDCoalesce(t *testing.T) {
+	gpio := new(test_gpio)
+
+	// The background goroutine is not started, so writes are flushed here
+	leds := &led_driver{gpio: gpio, async: true, signal: make(chan struct{}, 1)}
+	leds.Set(gopi.GPIOPin(1), gopi.GPIO_HIGH)
+	leds.Set(gopi.GPIOPin(2), gopi.GPIO_HIGH)
+	leds.Set(gopi.GPIOPin(1), gopi.GPIO_HIGH)
+	leds.Set(gopi.GPIOPin(2), gopi.GPIO_LOW)
+	leds.flush()
+
+	if writes, coalesced, _ := leds.Stats(); writes != 3 || coalesced != 1 {
+		t.Errorf("Expected 3 writes and 1 coalesced, got %v and %v", writes, coalesced)
+	}
+	if writes := gpio.Writes(gopi.GPIOPin(1)); reflect.DeepEqual(writes, []gopi.GPIOState{gopi.GPIO_HIGH}) == false {
+		t.Errorf("Pin 1: expected [HIGH], got %v", writes)
+	}
+	if writes := gpio.Writes(gopi.GPIOPin(2)); reflect.DeepEqual(writes, []gopi.GPIOState{gopi.GPIO_HIGH, gopi.GPIO_LOW}) == false {
+		t.Errorf("Pin 2: expected [HIGH LOW], got %v", writes)
+	}
+}
@@ -13,12 +13,13 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	// Frameworks
 	"github.com/djthorpe/gopi"
-	evt "github.com/djthorpe/gopi/util/event"
 	"github.com/djthorpe/sensors"
 )
 
@@ -27,45 +28,95 @@ import (
 
 // Configuration
 type MiHome struct {
-	GPIO       gopi.GPIO          // GPIO interface
-	Radio      sensors.RFM69      // Radio interface
-	OpenThings sensors.OpenThings // Payload Protocol
-	PinReset   gopi.GPIOPin       // Reset pin
-	PinLED1    gopi.GPIOPin       // LED1 (Green, Rx) pin
-	PinLED2    gopi.GPIOPin       // LED2 (Red, Tx) pin
-	CID        string             // OOK device address
-	Repeat     uint               // Number of times to repeat messages by default
-	TempOffset float32            // Temperature Offset
+	GPIO           gopi.GPIO                // GPIO interface
+	Radio          sensors.RFM69            // Radio interface
+	OpenThings     sensors.OpenThings       // Payload Protocol
+	Decoders       []Decoder                // Alternative FSK decoders, tried when OpenThings fails
+	PinReset       gopi.GPIOPin             // Reset pin
+	PinLED1        gopi.GPIOPin             // LED1 (Green, Rx) pin
+	PinLED2        gopi.GPIOPin             // LED2 (Red, Tx) pin
+	PinDIO0        gopi.GPIOPin             // Radio DIO0 pin for interrupt-driven reception
+	CID            string                   // OOK device address
+	Repeat         uint                     // Number of times to repeat messages by default
+	TempOffset     float32                  // Temperature Offset
+	Calibration    string                   // Temperature calibration file, overrides TempOffset
+	Scenes         map[string][]SceneAction // Named scenes
+	SceneGap       time.Duration            // Gap between transmissions in a scene
+	TempInterval   time.Duration            // Interval between temperature samples, or zero
+	MonitorProfile *RadioProfile            // Radio profile for monitor mode, or nil for default
+	ControlProfile *RadioProfile            // Radio profile for control mode, or nil for default
+	Capture        string                   // File to record received payloads, or empty
+	EventBuffer    uint                     // Events buffered per subscriber, or zero for default
+	ResetProfile   *ResetProfile            // Reset timing, or nil for default
+	AsyncLED       bool                     // Write LED states in the background
+	Timestamp      TimestampSource          // Event timestamp from payload reception or decode
+	StateHalfLife  time.Duration            // Time for confidence in inferred socket states to halve, or zero for default
+	DecodeWorkers  uint                     // Goroutines decoding received payloads, or zero for one per CPU
+	DecodeQueue    uint                     // Payloads queued for decoding before they are dropped, or zero for default
 }
 
 // mihome driver
 type mihome struct {
-	log        gopi.Logger
-	gpio       gopi.GPIO
-	radio      sensors.RFM69
-	protocol   sensors.OpenThings
-	reset      gopi.GPIOPin
-	cid        []byte // 10 bytes for the OOK address
-	repeat     uint
-	tempoffset float32
-	led1       gopi.GPIOPin
-	led2       gopi.GPIOPin
-	ledrx      gopi.GPIOPin
-	ledtx      gopi.GPIOPin
-	mode       sensors.MiHomeMode
-	pubsub     *evt.PubSub
+	log             gopi.Logger
+	gpio            gopi.GPIO
+	radio           sensors.RFM69
+	protocol        sensors.OpenThings
+	decoders        []Decoder
+	reset           gopi.GPIOPin
+	dio0            gopi.GPIOPin
+	reset_profile   ResetProfile
+	cid             []byte // 10 bytes for the OOK address
+	repeat          uint
+	tempoffset      float32
+	calibration     string
+	templock        sync.Mutex
+	profile_monitor RadioProfile
+	profile_control RadioProfile
+	capture_file    *os.File
+	otevents        *ot_pubsub
+	decode          *decode_pool
+	led1            gopi.GPIOPin
+	led2            gopi.GPIOPin
+	ledrx           gopi.GPIOPin
+	ledtx           gopi.GPIOPin
+	leds            *led_driver
+	timestamp       TimestampSource
+	states          *socket_states
+	mode            sensors.MiHomeMode
+	pubsub          *pubsub
+	scenes          map[string][]scene_command
+	scenelock       sync.Mutex
+	scenegap        time.Duration
+	txlock          sync.Mutex
+	rxlock          sync.Mutex
+	rxcancel        context.CancelFunc
+	done            chan struct{}
+	wait            sync.WaitGroup
+	opened          time.Time
+	history         []sensors.MiHomeModeChange
+	statslock       sync.Mutex
+	counters        *counters
+}
+
+// Options for transmitting control commands
+type ControlOptions struct {
+	Repeat  uint          // Number of times to repeat the command
+	Gap     time.Duration // Gap between repeated bursts, or zero for none
+	Bitrate uint          // Symbol rate, or zero for the control profile bitrate
 }
 
 type monitor_rx_event struct {
 	driver  *mihome
 	ts      time.Time
 	message sensors.OTMessage
+	decoder string
 	reason  error
 	rssi    float32
 }
 
 type LED uint
 type Command byte
+type TimestampSource uint
 
 ////////////////////////////////////////////////////////////////////////////////
 // CONSTANTS, GLOBAL VARIABLES
@@ -101,6 +152,13 @@ const (
 	OOK_OFF_4   Command = 0x02
 )
 
+const (
+	// Events are stamped with the time the payload was received
+	// by the radio, or the time it was decoded
+	TIMESTAMP_RX TimestampSource = iota
+	TIMESTAMP_DECODE
+)
+
 const (
 	LED_ALL LED = iota
 	LED_1
@@ -120,11 +178,13 @@ func (config MiHome) Open(log gopi.Logger) (gopi.Driver, error) {
 	if config.Repeat == 0 {
 		config.Repeat = REPEAT_DEFAULT
 	}
-	log.Debug2("<sensors.energenie.MiHome>Open{ reset=%v led1=%v led2=%v cid=\"%v\" repeat=%v tempoffset=%v }", config.PinReset, config.PinLED1, config.PinLED2, config.CID, config.Repeat, config.TempOffset)
+	log.Debug2("<sensors.energenie.MiHome>Open{ reset=%v led1=%v led2=%v cid=\"%v\" repeat=%v tempoffset=%v calibration=\"%v\" }", config.PinReset, config.PinLED1, config.PinLED2, config.CID, config.Repeat, config.TempOffset, config.Calibration)
 
 	if config.GPIO == nil || config.Radio == nil || config.OpenThings == nil {
 		// Fail when either GPIO, Radio or OpenThings is nil
 		return nil, gopi.ErrBadParameter
+	} else if config.Timestamp != TIMESTAMP_RX && config.Timestamp != TIMESTAMP_DECODE {
+		return nil, gopi.ErrBadParameter
 	}
 
 	this := new(mihome)
@@ -132,7 +192,19 @@ func (config MiHome) Open(log gopi.Logger) (gopi.Driver, error) {
 	this.gpio = config.GPIO
 	this.radio = config.Radio
 	this.protocol = config.OpenThings
+	if decoders, err := newDecoders(config.OpenThings, config.Decoders); err != nil {
+		return nil, err
+	} else {
+		this.decoders = decoders
+	}
 	this.reset = config.PinReset
+	this.dio0 = gopi.GPIO_PIN_NONE
+	this.timestamp = config.Timestamp
+	if config.ResetProfile != nil {
+		this.reset_profile = *config.ResetProfile
+	} else {
+		this.reset_profile = RESET_PROFILE_DEFAULT
+	}
 
 	// Set LED's
 	this.led1 = config.PinLED1
@@ -154,17 +226,97 @@ func (config MiHome) Open(log gopi.Logger) (gopi.Driver, error) {
 		this.cid = cid
 	}
 
+	// Set radio profiles
+	this.profile_monitor = PROFILE_MONITOR_DEFAULT.copy()
+	this.profile_control = PROFILE_CONTROL_DEFAULT.copy()
+	if config.MonitorProfile != nil {
+		this.profile_monitor = config.MonitorProfile.copy()
+	}
+	if config.ControlProfile != nil {
+		this.profile_control = config.ControlProfile.copy()
+	}
+	if err := this.profile_monitor.validate(sensors.MIHOME_MODE_MONITOR); err != nil {
+		return nil, err
+	} else if err := this.profile_control.validate(sensors.MIHOME_MODE_CONTROL); err != nil {
+		return nil, err
+	}
+
 	// Set number of times to repeat TX by default
 	this.repeat = config.Repeat
 
-	// Set the temperature calibration offset
+	// Set the temperature calibration offset, which is read from the
+	// calibration file if it exists
 	this.tempoffset = config.TempOffset
+	this.calibration = config.Calibration
+	if config.Calibration != "" {
+		if calibration, err := ReadCalibration(config.Calibration); err != nil {
+			return nil, err
+		} else if calibration != nil {
+			log.Debug("<sensors.energenie.MiHome>Open: %v", calibration)
+			this.tempoffset = calibration.TempOffset
+		}
+	}
 
 	// Set mode to undefined
-	this.mode = sensors.MIHOME_MODE_NONE
+	this.opened = time.Now()
+	this.counters = newCounters()
+	this.setMode(sensors.MIHOME_MODE_NONE, "open")
+
+	// Set scenes
+	this.scenes = make(map[string][]scene_command, len(config.Scenes))
+	this.scenegap = config.SceneGap
+	if this.scenegap == 0 {
+		this.scenegap = SCENE_GAP_DEFAULT
+	}
+	for name, actions := range config.Scenes {
+		if err := this.SetScene(name, actions...); err != nil {
+			return nil, err
+		}
+	}
+
+	// The configuration has been validated, so resources are acquired
+	// from here, and released if a later step fails
+
+	// Open the capture file
+	if config.Capture != "" {
+		if file, err := openCapture(config.Capture); err != nil {
+			return nil, err
+		} else {
+			this.capture_file = file
+		}
+	}
 
 	// Event interface
-	this.pubsub = evt.NewPubSub(0)
+	if config.EventBuffer == 0 {
+		config.EventBuffer = EVENT_BUFFER_DEFAULT
+	}
+	this.pubsub = newPubSub(config.EventBuffer)
+	this.otevents = newOTPubSub(config.EventBuffer)
+	// Interrupt-driven reception, which is the last step which can fail
+	if config.PinDIO0 != gopi.GPIO_PIN_NONE {
+		if err := this.radio.SetInterrupt(this.gpio, config.PinDIO0); err != nil {
+			this.closeCapture()
+			return nil, err
+		} else {
+			this.dio0 = config.PinDIO0
+		}
+	}
+
+	// LED writes, which are performed in the background when AsyncLED is set
+	this.leds = newLEDDriver(this.gpio, config.AsyncLED)
+
+	// Inferred socket states
+	this.states = newSocketStates(config.StateHalfLife)
+
+	// Decode received payloads away from the goroutine reading the radio
+	this.decode = newDecodePool(this.decoders, config.DecodeWorkers, config.DecodeQueue, this.emitDecoded)
+
+	// Sample temperature in the background
+	if config.TempInterval > 0 {
+		this.done = make(chan struct{})
+		this.wait.Add(1)
+		go this.sampleTemperature(config.TempInterval, this.done)
+	}
 
 	// Return success
 	return this, nil
@@ -173,15 +325,43 @@ func (config MiHome) Open(log gopi.Logger) (gopi.Driver, error) {
 func (this *mihome) Close() error {
 	this.log.Debug2("<sensors.energenie.MiHome>Close{ cid=0x%v }", strings.ToUpper(hex.EncodeToString(this.cid)))
 
+	// Stop background tasks
+	if this.done != nil {
+		close(this.done)
+		this.wait.Wait()
+	}
+
+	// Close the capture file
+	this.closeCapture()
+
+	// Revert the radio to polling
+	if this.dio0 != gopi.GPIO_PIN_NONE {
+		if err := this.radio.SetInterrupt(nil, gopi.GPIO_PIN_NONE); err != nil {
+			this.log.Error("SetInterrupt: %v", err)
+		}
+	}
+
+	// Emit messages still waiting to be decoded
+	this.decode.Close()
+
+	// Stop LED writes
+	this.leds.Close()
+
 	// Close subscriber channels
 	this.pubsub.Close()
+	this.otevents.Close()
 
 	// Free resources
 	this.gpio = nil
+	this.leds = nil
 	this.radio = nil
 	this.protocol = nil
+	this.decoders = nil
 	this.cid = nil
 	this.pubsub = nil
+	this.otevents = nil
+	this.decode = nil
+	this.scenes = nil
 
 	return nil
 }
@@ -210,19 +390,18 @@ func (this *mihome) ResetRadio() error {
 		return err
 	}
 
-	// Pull reset high for 100ms and then low for 5ms
-	this.gpio.WritePin(this.reset, gopi.GPIO_HIGH)
-	time.Sleep(time.Millisecond * 100)
-	this.gpio.WritePin(this.reset, gopi.GPIO_LOW)
-	time.Sleep(time.Millisecond * 5)
+	// Pulse reset and wait for the radio to be ready
+	reset_err := this.pulseReset()
 
 	// Turn all LED's off
 	if err := this.SetLED(LED_ALL, gopi.GPIO_LOW); err != nil {
 		return err
+	} else if reset_err != nil {
+		return reset_err
 	}
 
 	// Set undefined mode
-	this.mode = sensors.MIHOME_MODE_NONE
+	this.setMode(sensors.MIHOME_MODE_NONE, "reset")
 
 	return nil
 }
@@ -244,31 +423,27 @@ func (this *mihome) SetLED(led LED, state gopi.GPIOState) error {
 		if this.led1 == gopi.GPIO_PIN_NONE {
 			return gopi.ErrNotImplemented
 		} else {
-			this.gpio.SetPinMode(this.led1, gopi.GPIO_OUTPUT)
-			this.gpio.WritePin(this.led1, state)
+			this.leds.Set(this.led1, state)
 		}
 	case LED_2:
 		if this.led2 == gopi.GPIO_PIN_NONE {
 			return gopi.ErrNotImplemented
 		} else {
-			this.gpio.SetPinMode(this.led2, gopi.GPIO_OUTPUT)
-			this.gpio.WritePin(this.led2, state)
+			this.leds.Set(this.led2, state)
 		}
 	case LED_RX:
 		if this.ledrx == gopi.GPIO_PIN_NONE {
 			// Allow to silently do nothing where device does have RX indicator
 			return nil
 		} else {
-			this.gpio.SetPinMode(this.ledrx, gopi.GPIO_OUTPUT)
-			this.gpio.WritePin(this.ledrx, state)
+			this.leds.Set(this.ledrx, state)
 		}
 	case LED_TX:
 		if this.ledtx == gopi.GPIO_PIN_NONE {
 			// Allow to silently do nothing where device does have RX indicator
 			return nil
 		} else {
-			this.gpio.SetPinMode(this.ledtx, gopi.GPIO_OUTPUT)
-			this.gpio.WritePin(this.ledtx, state)
+			this.leds.Set(this.ledtx, state)
 		}
 	default:
 		return gopi.ErrBadParameter
@@ -278,51 +453,59 @@ func (this *mihome) SetLED(led LED, state gopi.GPIOState) error {
 
 // Receive OOK and FSK payloads until context is cancelled or timeout
 func (this *mihome) Receive(ctx context.Context, mode sensors.MiHomeMode) error {
-	// We only support the MONITOR mode (FSK) for the moment
-	if mode != sensors.MIHOME_MODE_MONITOR {
+	if supportsMode(this.SupportedModes(), mode) == false {
 		return gopi.ErrNotImplemented
 	}
 
-	// Switch into FSK mode
-	if this.radio.Modulation() != sensors.RFM_MODULATION_FSK || this.mode != sensors.MIHOME_MODE_MONITOR {
-		if err := this.setFSKMode(); err != nil {
-			return err
-		} else {
-			this.mode = sensors.MIHOME_MODE_MONITOR
-		}
+	// Receive OOK commands in CONTROL mode
+	if mode == sensors.MIHOME_MODE_CONTROL {
+		return this.receiveControl(ctx)
 	}
 
-	// Switch into RX mode
-	if this.radio.Mode() != sensors.RFM_MODE_RX {
-		if err := this.radio.SetMode(sensors.RFM_MODE_RX); err != nil {
-			return err
-		}
-	} else if err := this.radio.ClearFIFO(); err != nil {
+	// Clear the FIFO if already receiving
+	if err := this.clearReceive(); err != nil {
 		return err
 	}
 
-	// Repeatedly read until context is done
+	// Repeatedly read until context is done. Each read can be interrupted
+	// by a transmission, after which the radio is returned to FSK receive
 FOR_LOOP:
 	for {
 		select {
 		case <-ctx.Done():
 			break FOR_LOOP
 		default:
-			if data, _, err := this.radio.ReadPayload(ctx); err != nil {
+			read_ctx, err := this.beginRead(ctx, sensors.MIHOME_MODE_MONITOR)
+			if err != nil {
 				return err
-			} else if data != nil {
+			}
+			packet, err := this.radio.ReadPacket(read_ctx)
+			this.endRead()
+			if err != nil {
+				return err
+			} else if packet != nil {
 				// RX light on
 				this.SetLED(LED_RX, gopi.GPIO_HIGH)
 
-				// Decode & Emit package
-				if message, reason := this.protocol.Decode(data); message != nil {
-					this.emitMessage(message, reason)
-					// If there was an error receiving messages, clear the FIFO
-					if reason != nil {
+				// Record the raw payload
+				data := packet.Payload
+				this.capture(packet)
+
+				// If there was an error receiving the packet, clear the FIFO
+				// unless a transmission has taken over the radio
+				if packet.CRCOk == false && this.radio.PacketCRC() != sensors.RFM_PACKET_CRC_OFF {
+					this.txlock.Lock()
+					if this.radio.Mode() == sensors.RFM_MODE_RX {
 						if err := this.radio.ClearFIFO(); err != nil {
 							this.log.Error("ClearFIFO: %v", err)
 						}
 					}
+					this.txlock.Unlock()
+				}
+
+				// Queue the payload to be decoded and emitted
+				if this.decode.Submit(this.packetTime(packet), data) == false {
+					this.log.Warn("Receive: decode queue full, payload dropped")
 				}
 
 				// RX Light off
@@ -335,54 +518,174 @@ FOR_LOOP:
 	return nil
 }
 
+// beginRead waits for any transmission to complete, returns the radio to
+// FSK receive in MIHOME_MODE_MONITOR or OOK receive in MIHOME_MODE_CONTROL_RX
+// and returns a context which is cancelled by endRead
+func (this *mihome) beginRead(ctx context.Context, mode sensors.MiHomeMode) (context.Context, error) {
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+
+	switch mode {
+	case sensors.MIHOME_MODE_MONITOR:
+		// Switch into FSK mode
+		if this.radio.Modulation() != sensors.RFM_MODULATION_FSK || this.mode != mode {
+			if err := this.setFSKMode(); err != nil {
+				return nil, err
+			} else {
+				this.setMode(mode, "receive")
+			}
+		}
+	case sensors.MIHOME_MODE_CONTROL_RX:
+		// Switch into OOK receive mode
+		if this.radio.Modulation() != sensors.RFM_MODULATION_OOK || this.mode != mode {
+			if err := this.setOOKReceive(); err != nil {
+				return nil, err
+			} else {
+				this.setMode(mode, "receive")
+			}
+		}
+	default:
+		return nil, gopi.ErrBadParameter
+	}
+
+	// Switch into RX mode
+	if this.radio.Mode() != sensors.RFM_MODE_RX {
+		if err := this.setRadioMode(sensors.RFM_MODE_RX, "receive"); err != nil {
+			return nil, err
+		}
+	}
+
+	// The read is cancelled when the parent context is done or a
+	// transmission interrupts it
+	read_ctx, cancel := context.WithCancel(ctx)
+	this.rxlock.Lock()
+	this.rxcancel = cancel
+	this.rxlock.Unlock()
+	return read_ctx, nil
+}
+
+// reading returns true while a read is in progress. It should be called
+// with txlock held, so that a read can't begin
+func (this *mihome) reading() bool {
+	this.rxlock.Lock()
+	defer this.rxlock.Unlock()
+	return this.rxcancel != nil
+}
+
+// clearReceive clears the FIFO when the radio is already receiving in
+// monitor mode
+func (this *mihome) clearReceive() error {
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+	if this.radio.Modulation() == sensors.RFM_MODULATION_FSK && this.mode == sensors.MIHOME_MODE_MONITOR && this.radio.Mode() == sensors.RFM_MODE_RX {
+		return this.radio.ClearFIFO()
+	}
+	return nil
+}
+
+// endRead cancels the context returned by beginRead. When called with txlock
+// held by a transmission, this interrupts any read in progress
+func (this *mihome) endRead() {
+	this.rxlock.Lock()
+	defer this.rxlock.Unlock()
+	if this.rxcancel != nil {
+		this.rxcancel()
+		this.rxcancel = nil
+	}
+}
+
 // Send Command TX in Control Mode (aka Legacy mode, or using OOK
 func (this *mihome) SendControl(cid []byte, cmd Command, repeat uint) error {
-	this.log.Debug("<sensors.energenie.MiHome.SendControl{ cid=%v cmd=%v repeat=%v }", strings.ToUpper(hex.EncodeToString(cid)), cmd, repeat)
+	return this.SendControlWithOptions(cid, cmd, ControlOptions{Repeat: repeat})
+}
 
-	if repeat == 0 || cid == nil {
+// Send Command TX in Control Mode with options for the repeat count, the
+// gap between repeated bursts and the symbol rate. Transmissions are
+// serialised, and interrupt a Receive which resumes once the transmission
+// is complete
+func (this *mihome) SendControlWithOptions(cid []byte, cmd Command, opts ControlOptions) error {
+	this.log.Debug("<sensors.energenie.MiHome.SendControl{ cid=%v cmd=%v opts=%v }", strings.ToUpper(hex.EncodeToString(cid)), cmd, opts)
+
+	// Wait for other transmissions, and interrupt any read in progress
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+	this.endRead()
+
+	if opts.Repeat == 0 || cid == nil {
 		return gopi.ErrBadParameter
-	} else if payload, err := encodeCommandPayload(cid, cmd); err != nil {
+	}
+	payload, err := encodeCommandPayload(cid, cmd)
+	if err != nil {
 		return err
-	} else if this.radio.Modulation() != sensors.RFM_MODULATION_OOK || this.mode != sensors.MIHOME_MODE_CONTROL {
+	}
+
+	// Switch into OOK mode
+	if this.radio.Modulation() != sensors.RFM_MODULATION_OOK || this.mode != sensors.MIHOME_MODE_CONTROL {
 		if err := this.setOOKMode(); err != nil {
 			return err
 		} else {
-			this.mode = sensors.MIHOME_MODE_CONTROL
+			this.setMode(sensors.MIHOME_MODE_CONTROL, "transmit")
 		}
-	} else if err := this.radio.SetMode(sensors.RFM_MODE_TX); err != nil {
+	}
+
+	// Transmit
+	if err := this.setRadioMode(sensors.RFM_MODE_TX, "transmit"); err != nil {
 		return err
 	} else if err := this.radio.SetSequencer(true); err != nil {
 		return err
 	} else {
+		// Set symbol rate for this call, and restore afterwards
+		if opts.Bitrate != 0 && opts.Bitrate != this.radio.Bitrate() {
+			if err := this.radio.SetBitrate(opts.Bitrate); err != nil {
+				return err
+			}
+			defer this.radio.SetBitrate(this.profile_control.Bitrate)
+		}
 		// TX light on
 		this.SetLED(LED_TX, gopi.GPIO_HIGH)
 		defer this.SetLED(LED_TX, gopi.GPIO_LOW)
 		// Write payload
-		if err := this.radio.WritePayload(payload, repeat); err != nil {
+		if err := this.writeBursts(payload, opts); err != nil {
 			return err
 		}
+		this.countTX()
+		this.inferState(cid, cmd)
 	}
 	// Success
 	return nil
 }
 
+// MeasureTemperature returns the radio temperature with the calibration
+// offset applied. Any Receive is interrupted until the measurement is complete
 func (this *mihome) MeasureTemperature() (float32, error) {
 	this.log.Debug("<sensors.energenie.MiHome.MeasureTemperature{ }")
 
+	// Wait for transmissions, and interrupt any read in progress, for the
+	// whole of the measurement
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+	this.endRead()
+
+	return this.measureTemperature(this.TempOffset())
+}
+
+// measureTemperature switches the radio to standby, measures the temperature
+// with an offset and restores the mode. It should be called with txlock held
+func (this *mihome) measureTemperature(offset float32) (float32, error) {
 	// Need to put into standby mode to measure the temperature
 	old_mode := this.radio.Mode()
 	if old_mode != sensors.RFM_MODE_STDBY {
-		if err := this.radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		if err := this.setRadioMode(sensors.RFM_MODE_STDBY, "measure temperature"); err != nil {
 			return 0, err
 		}
 	}
 
 	// Perform the measurement
-	value, err := this.radio.MeasureTemperature(this.tempoffset)
+	value, err := this.radio.MeasureTemperature(offset)
 
 	// Return to previous mode of operation
 	if old_mode != sensors.RFM_MODE_STDBY {
-		if err := this.radio.SetMode(old_mode); err != nil {
+		if err := this.setRadioMode(old_mode, "measure temperature"); err != nil {
 			return 0, err
 		}
 	}
@@ -396,14 +699,21 @@ func (this *mihome) MeasureTemperature() (float32, error) {
 
 // Satisfies the ENER314 interface to switch sockets on
 func (this *mihome) On(sockets ...uint) error {
-	if len(sockets) == 0 {
+	// Validate the sockets before sending anything
+	cmds := make([]Command, len(sockets))
+	for i, socket := range sockets {
+		if cmd, err := onCommandForSocket(socket); err != nil {
+			return err
+		} else {
+			cmds[i] = cmd
+		}
+	}
+	if len(sockets) == 0 || coversAllSockets(sockets) {
 		// all on
 		return this.SendControl(this.cid, OOK_ON_ALL, this.repeat)
 	} else {
-		for _, socket := range sockets {
-			if cmd, err := onCommandForSocket(socket); err != nil {
-				return err
-			} else if err := this.SendControl(this.cid, cmd, this.repeat); err != nil {
+		for _, cmd := range cmds {
+			if err := this.SendControl(this.cid, cmd, this.repeat); err != nil {
 				return err
 			}
 		}
@@ -415,14 +725,37 @@ func (this *mihome) On(sockets ...uint) error {
 
 // Satisfies the ENER314 interface to switch sockets off
 func (this *mihome) Off(sockets ...uint) error {
-	if len(sockets) == 0 {
+	// Validate the sockets before sending anything
+	cmds := make([]Command, len(sockets))
+	for i, socket := range sockets {
+		if cmd, err := offCommandForSocket(socket); err != nil {
+			return err
+		} else {
+			cmds[i] = cmd
+		}
+	}
+	if len(sockets) == 0 || coversAllSockets(sockets) {
 		// all off
 		return this.SendControl(this.cid, OOK_OFF_ALL, this.repeat)
 	} else {
-		for _, socket := range sockets {
-			if cmd, err := offCommandForSocket(socket); err != nil {
+		for _, cmd := range cmds {
+			if err := this.SendControl(this.cid, cmd, this.repeat); err != nil {
 				return err
-			} else if err := this.SendControl(this.cid, cmd, this.repeat); err != nil {
+			}
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// Satisfies the ENER314 interface to set the level of a dimmer
+func (this *mihome) Dim(socket uint, level uint) error {
+	if cmds, err := dimCommandsForSocket(socket, level); err != nil {
+		return err
+	} else {
+		for _, cmd := range cmds {
+			if err := this.SendControl(this.cid, cmd, this.repeat); err != nil {
 				return err
 			}
 		}
@@ -436,49 +769,55 @@ func (this *mihome) Off(sockets ...uint) error {
 // PRIVATE METHODS
 
 func (this *mihome) setFSKMode() error {
-	if err := this.radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+	if err := this.setRadioMode(sensors.RFM_MODE_STDBY, "monitor mode"); err != nil {
 		return err
 	} else if err := this.radio.SetModulation(sensors.RFM_MODULATION_FSK); err != nil {
 		return err
 	} else if err := this.radio.SetSequencer(true); err != nil {
 		return err
-	} else if err := this.radio.SetBitrate(4800); err != nil {
+	} else if err := this.radio.SetBitrate(this.profile_monitor.Bitrate); err != nil {
+		return err
+	} else if err := this.radio.SetFreqCarrier(this.profile_monitor.FreqCarrier); err != nil {
+		return err
+	} else if err := this.radio.SetFreqDeviation(this.profile_monitor.FreqDeviation); err != nil {
 		return err
-	} else if err := this.radio.SetFreqCarrier(434300000); err != nil {
+	} else if err := this.radio.SetAFCMode(this.profile_monitor.AFCMode); err != nil {
 		return err
-	} else if err := this.radio.SetFreqDeviation(30000); err != nil {
+	} else if err := this.radio.SetAFCLowBetaOffset(this.profile_monitor.AFCOffset); err != nil {
 		return err
-	} else if err := this.radio.SetAFCMode(sensors.RFM_AFCMODE_OFF); err != nil {
+	} else if err := this.radio.SetAFCRoutine(this.profile_monitor.AFCRoutine); err != nil {
 		return err
-	} else if err := this.radio.SetAFCRoutine(sensors.RFM_AFCROUTINE_STANDARD); err != nil {
+	} else if err := this.radio.SetLNA(this.profile_monitor.LNAImpedance, this.profile_monitor.LNAGain); err != nil {
 		return err
-	} else if err := this.radio.SetLNA(sensors.RFM_LNA_IMPEDANCE_50, sensors.RFM_LNA_GAIN_AUTO); err != nil {
+	} else if err := this.radio.SetSensitivityBoost(this.profile_monitor.SensitivityBoost); err != nil {
 		return err
-	} else if err := this.radio.SetRXFilter(sensors.RFM_RXBW_FREQUENCY_FSK_62P5, sensors.RFM_RXBW_CUTOFF_4); err != nil {
+	} else if err := this.radio.SetDAGC(this.profile_monitor.DAGC); err != nil {
+		return err
+	} else if err := this.radio.SetRXFilter(this.profile_monitor.RXFilterFrequency, this.profile_monitor.RXFilterCutoff); err != nil {
 		return err
 	} else if err := this.radio.SetDataMode(sensors.RFM_DATAMODE_PACKET); err != nil {
 		return err
-	} else if err := this.radio.SetPacketFormat(sensors.RFM_PACKET_FORMAT_VARIABLE); err != nil {
+	} else if err := this.radio.SetPacketFormat(this.profile_monitor.PacketFormat); err != nil {
 		return err
-	} else if err := this.radio.SetPacketCoding(sensors.RFM_PACKET_CODING_MANCHESTER); err != nil {
+	} else if err := this.radio.SetPacketCoding(this.profile_monitor.PacketCoding); err != nil {
 		return err
-	} else if err := this.radio.SetPacketFilter(sensors.RFM_PACKET_FILTER_NONE); err != nil {
+	} else if err := this.radio.SetPacketFilter(this.profile_monitor.PacketFilter); err != nil {
 		return err
-	} else if err := this.radio.SetPacketCRC(sensors.RFM_PACKET_CRC_OFF); err != nil {
+	} else if err := this.radio.SetPacketCRC(this.profile_monitor.PacketCRC); err != nil {
 		return err
-	} else if err := this.radio.SetPreambleSize(3); err != nil {
+	} else if err := this.radio.SetPreambleSize(this.profile_monitor.PreambleSize); err != nil {
 		return err
-	} else if err := this.radio.SetPayloadSize(0x40); err != nil {
+	} else if err := this.radio.SetAESKey(this.profile_monitor.AESKey); err != nil {
 		return err
-	} else if err := this.radio.SetSyncWord([]byte{0x2D, 0xD4}); err != nil {
+	} else if err := this.radio.SetPayloadSize(this.profile_monitor.PayloadSize); err != nil {
 		return err
-	} else if err := this.radio.SetSyncTolerance(0); err != nil {
+	} else if err := this.radio.SetSyncWord(this.profile_monitor.SyncWord); err != nil {
 		return err
-	} else if err := this.radio.SetNodeAddress(0x04); err != nil {
+	} else if err := this.radio.SetSyncTolerance(this.profile_monitor.SyncTolerance); err != nil {
 		return err
-	} else if err := this.radio.SetBroadcastAddress(0xFF); err != nil {
+	} else if err := this.radio.SetNodeAddress(this.profile_monitor.NodeAddress); err != nil {
 		return err
-	} else if err := this.radio.SetAESKey(nil); err != nil {
+	} else if err := this.radio.SetBroadcastAddress(this.profile_monitor.BroadcastAddress); err != nil {
 		return err
 	} else if err := this.radio.SetFIFOThreshold(1); err != nil {
 		return err
@@ -489,37 +828,41 @@ func (this *mihome) setFSKMode() error {
 }
 
 func (this *mihome) setOOKMode() error {
-	if err := this.radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+	if err := this.setRadioMode(sensors.RFM_MODE_STDBY, "control mode"); err != nil {
 		return err
 	} else if err := this.radio.SetModulation(sensors.RFM_MODULATION_OOK); err != nil {
 		return err
 	} else if err := this.radio.SetSequencer(true); err != nil {
 		return err
-	} else if err := this.radio.SetBitrate(4800); err != nil {
+	} else if err := this.radio.SetBitrate(this.profile_control.Bitrate); err != nil {
+		return err
+	} else if err := this.radio.SetFreqCarrier(this.profile_control.FreqCarrier); err != nil {
+		return err
+	} else if err := this.radio.SetFreqDeviation(this.profile_control.FreqDeviation); err != nil {
 		return err
-	} else if err := this.radio.SetFreqCarrier(433920000); err != nil {
+	} else if err := this.radio.SetAFCMode(this.profile_control.AFCMode); err != nil {
 		return err
-	} else if err := this.radio.SetFreqDeviation(0); err != nil {
+	} else if err := this.radio.SetSensitivityBoost(this.profile_control.SensitivityBoost); err != nil {
 		return err
-	} else if err := this.radio.SetAFCMode(sensors.RFM_AFCMODE_OFF); err != nil {
+	} else if err := this.radio.SetDAGC(false); err != nil {
 		return err
 	} else if err := this.radio.SetDataMode(sensors.RFM_DATAMODE_PACKET); err != nil {
 		return err
-	} else if err := this.radio.SetPacketFormat(sensors.RFM_PACKET_FORMAT_VARIABLE); err != nil {
+	} else if err := this.radio.SetPacketFormat(this.profile_control.PacketFormat); err != nil {
 		return err
-	} else if err := this.radio.SetPacketCoding(sensors.RFM_PACKET_CODING_NONE); err != nil {
+	} else if err := this.radio.SetPacketCoding(this.profile_control.PacketCoding); err != nil {
 		return err
-	} else if err := this.radio.SetPacketFilter(sensors.RFM_PACKET_FILTER_NONE); err != nil {
+	} else if err := this.radio.SetPacketFilter(this.profile_control.PacketFilter); err != nil {
 		return err
-	} else if err := this.radio.SetPacketCRC(sensors.RFM_PACKET_CRC_OFF); err != nil {
+	} else if err := this.radio.SetPacketCRC(this.profile_control.PacketCRC); err != nil {
 		return err
-	} else if err := this.radio.SetPreambleSize(0); err != nil {
+	} else if err := this.radio.SetPreambleSize(this.profile_control.PreambleSize); err != nil {
 		return err
-	} else if err := this.radio.SetPayloadSize(0); err != nil {
+	} else if err := this.radio.SetAESKey(this.profile_control.AESKey); err != nil {
 		return err
-	} else if err := this.radio.SetSyncWord(nil); err != nil {
+	} else if err := this.radio.SetPayloadSize(this.profile_control.PayloadSize); err != nil {
 		return err
-	} else if err := this.radio.SetAESKey(nil); err != nil {
+	} else if err := this.radio.SetSyncWord(this.profile_control.SyncWord); err != nil {
 		return err
 	} else if err := this.radio.SetFIFOThreshold(1); err != nil {
 		return err
@@ -529,6 +872,22 @@ func (this *mihome) setOOKMode() error {
 	return nil
 }
 
+// Write payload repeatedly, with a gap between each burst if set
+func (this *mihome) writeBursts(payload []byte, opts ControlOptions) error {
+	if opts.Gap == 0 {
+		return this.radio.WritePayload(payload, opts.Repeat)
+	}
+	for i := uint(0); i < opts.Repeat; i++ {
+		if i > 0 {
+			time.Sleep(opts.Gap)
+		}
+		if err := this.radio.WritePayload(payload, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Convert hex string into bytes
 func decodeHexString(value string) ([]byte, error) {
 	// Pad with zeros
@@ -569,6 +928,23 @@ func offCommandForSocket(socket uint) (Command, error) {
 	}
 }
 
+// coversAllSockets returns true if every socket is in the list
+func coversAllSockets(sockets []uint) bool {
+	for socket := uint(1); socket <= SOCKET_MAX; socket++ {
+		found := false
+		for _, s := range sockets {
+			if s == socket {
+				found = true
+				break
+			}
+		}
+		if found == false {
+			return false
+		}
+	}
+	return true
+}
+
 func encodeByte(value byte) []byte {
 	// A byte is encoded as 4 bytes (each bit is converted to an 8 or an E - or 4 bits)
 	encoded := make([]byte, 4)
@@ -603,7 +979,10 @@ func encodeByteArray(array []byte) []byte {
 }
 
 func encodeCommandPayload(cid []byte, cmd Command) ([]byte, error) {
-	if encoded_cmd := encodeByte(byte(cmd)); len(encoded_cmd) != 4 {
+	// Commands are four bits
+	if cmd > 0x0F {
+		return nil, gopi.ErrBadParameter
+	} else if encoded_cmd := encodeByte(byte(cmd)); len(encoded_cmd) != 4 {
 		return nil, gopi.ErrAppError
 	} else if encoded_cid := encodeByteArray(cid); len(encoded_cid) != 12 {
 		return nil, gopi.ErrAppError
@@ -621,6 +1000,17 @@ func encodeCommandPayload(cid []byte, cmd Command) ([]byte, error) {
 ////////////////////////////////////////////////////////////////////////////////
 // STRINGIFY
 
+func (t TimestampSource) String() string {
+	switch t {
+	case TIMESTAMP_RX:
+		return "TIMESTAMP_RX"
+	case TIMESTAMP_DECODE:
+		return "TIMESTAMP_DECODE"
+	default:
+		return "[?? Invalid TimestampSource value]"
+	}
+}
+
 func (c Command) String() string {
 	switch c {
 	case OOK_ON_ALL:
@@ -629,6 +1019,8 @@ func (c Command) String() string {
 		return "OOK_OFF_ALL"
 	case OOK_ON_1:
 		return "OOK_ON_1"
+	case OOK_OFF_1:
+		return "OOK_OFF_1"
 	case OOK_ON_2:
 		return "OOK_ON_2"
 	case OOK_OFF_2:
@@ -641,11 +1033,23 @@ func (c Command) String() string {
 		return "OOK_ON_4"
 	case OOK_OFF_4:
 		return "OOK_OFF_4"
+	case OOK_DIM_1:
+		return "OOK_DIM_1"
+	case OOK_DIM_2:
+		return "OOK_DIM_2"
+	case OOK_DIM_3:
+		return "OOK_DIM_3"
+	case OOK_DIM_4:
+		return "OOK_DIM_4"
 	default:
 		return "[?? Invalid Command value]"
 	}
 }
 
+func (o ControlOptions) String() string {
+	return fmt.Sprintf("<sensors.energenie.ControlOptions>{ repeat=%v gap=%v bitrate=%v }", o.Repeat, o.Gap, o.Bitrate)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // PUBSUB
 
@@ -657,14 +1061,51 @@ func (this *mihome) Unsubscribe(subscriber <-chan gopi.Event) {
 	this.pubsub.Unsubscribe(subscriber)
 }
 
+// Return the event timestamp for a received packet
+func (this *mihome) packetTime(packet *sensors.RFMPacket) time.Time {
+	if this.timestamp == TIMESTAMP_RX && packet.Timestamp.IsZero() == false {
+		return packet.Timestamp
+	} else {
+		return time.Now()
+	}
+}
+
+// Emit a decoded payload from the decode pool
+func (this *mihome) emitDecoded(job *decode_job) {
+	this.countRX(job.reason)
+	if job.message != nil {
+		ts := job.ts
+		if this.timestamp == TIMESTAMP_DECODE {
+			ts = time.Now()
+		}
+		this.emitMessageAt(ts, job.message, job.decoder, job.reason)
+	}
+}
+
 // Emit OpenThings Message
-func (this *mihome) emitMessage(message sensors.OTMessage, reason error) {
-	this.pubsub.Emit(&monitor_rx_event{
+func (this *mihome) emitMessage(message sensors.OTMessage, decoder string, reason error) {
+	this.emitMessageAt(time.Now(), message, decoder, reason)
+}
+
+// Emit OpenThings Message with a timestamp
+func (this *mihome) emitMessageAt(ts time.Time, message sensors.OTMessage, decoder string, reason error) {
+	event := &monitor_rx_event{
 		driver:  this,
-		ts:      time.Now(),
+		ts:      ts,
 		message: message,
+		decoder: decoder,
 		reason:  reason,
-	})
+	}
+	// Emit as both gopi.Event and typed OTEvent
+	this.pubsub.Emit(event)
+	this.otevents.Emit(event)
+
+	// Emit semantic events for messages which decoded without error
+	if reason == nil {
+		for _, report := range reports(this, event.ts, message) {
+			this.pubsub.Emit(report)
+		}
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -690,6 +1131,10 @@ func (this *monitor_rx_event) Reason() error {
 	return this.reason
 }
 
+func (this *monitor_rx_event) Decoder() string {
+	return this.decoder
+}
+
 func (this *monitor_rx_event) String() string {
-	return fmt.Sprintf("<sensors.MonitorRXEvent>{ ts=%v message=%v reason=%v source=%v }", this.ts.Format(time.Stamp), this.message, this.reason, this.driver)
+	return fmt.Sprintf("<sensors.MonitorRXEvent>{ ts=%v message=%v decoder=\"%v\" reason=%v source=%v }", this.ts.Format(time.Stamp), this.message, this.decoder, this.reason, this.driver)
 }
@@ -0,0 +1,156 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors/hw/rfm69/mock"
+	"github.com/djthorpe/sensors/protocol/openthings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// test_logger discards log messages
+type test_logger struct {
+	gopi.Logger
+}
+
+// test_gpio records pin writes in order
+type test_gpio struct {
+	gopi.GPIO
+
+	writes []test_write
+	lock   sync.Mutex
+}
+
+type test_write struct {
+	pin   gopi.GPIOPin
+	state gopi.GPIOState
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST OPEN
+
+// TestOpenBadParameter checks that an invalid configuration fails before
+// the capture file is created
+func TestOpenBadParameter(t *testing.T) {
+	radio, err := (mock.Mock{}).Open(test_logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer radio.Close()
+	protocol, err := (openthings.Config{}).Open(test_logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer protocol.Close()
+
+	gpio, rfm, ot := new(test_gpio), radio.(mock.RFM69), protocol.(*openthings.OpenThings)
+	tests := []struct {
+		name   string
+		config MiHome
+	}{
+		{"NoGPIO", MiHome{Radio: rfm, OpenThings: ot}},
+		{"NoRadio", MiHome{GPIO: gpio, OpenThings: ot}},
+		{"NoOpenThings", MiHome{GPIO: gpio, Radio: rfm}},
+		{"CID", MiHome{GPIO: gpio, Radio: rfm, OpenThings: ot, CID: "XYZ"}},
+		{"Timestamp", MiHome{GPIO: gpio, Radio: rfm, OpenThings: ot, Timestamp: TIMESTAMP_DECODE + 1}},
+		{"ControlProfile", MiHome{GPIO: gpio, Radio: rfm, OpenThings: ot, ControlProfile: &RadioProfile{}}},
+		{"Scene", MiHome{GPIO: gpio, Radio: rfm, OpenThings: ot, Scenes: map[string][]SceneAction{"scene": {{Socket: SOCKET_MAX + 1}}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.config.Capture = filepath.Join(t.TempDir(), "capture.json")
+			if driver, err := test.config.Open(test_logger{}); err == nil {
+				driver.Close()
+				t.Error("Expected an error")
+			} else if _, err := os.Stat(test.config.Capture); os.IsNotExist(err) == false {
+				t.Errorf("Expected no capture file, got %v", err)
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+// test_mihome returns a driver with an in-memory radio. The GPIO, radio
+// and protocol are set when they are nil in the configuration
+func test_mihome(t *testing.T, config MiHome) (*mihome, mock.RFM69) {
+	t.Helper()
+	if config.GPIO == nil {
+		config.GPIO = new(test_gpio)
+	}
+	if config.Radio == nil {
+		if radio, err := (mock.Mock{}).Open(test_logger{}); err != nil {
+			t.Fatal(err)
+		} else {
+			config.Radio = radio.(mock.RFM69)
+		}
+	}
+	if config.OpenThings == nil {
+		if protocol, err := (openthings.Config{}).Open(test_logger{}); err != nil {
+			t.Fatal(err)
+		} else {
+			config.OpenThings = protocol.(*openthings.OpenThings)
+		}
+	}
+	driver, err := config.Open(test_logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := driver.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return driver.(*mihome), config.Radio.(mock.RFM69)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - test_logger
+
+func (test_logger) Error(format string, v ...interface{}) error { return nil }
+func (test_logger) Warn(format string, v ...interface{})        {}
+func (test_logger) Info(format string, v ...interface{})        {}
+func (test_logger) Debug(format string, v ...interface{})       {}
+func (test_logger) Debug2(format string, v ...interface{})      {}
+func (test_logger) IsDebug() bool                               { return false }
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - test_gpio
+
+func (this *test_gpio) SetPinMode(pin gopi.GPIOPin, mode gopi.GPIOMode) {}
+
+func (this *test_gpio) WritePin(pin gopi.GPIOPin, state gopi.GPIOState) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.writes = append(this.writes, test_write{pin, state})
+}
+
+// Writes returns the states written to a pin, in order
+func (this *test_gpio) Writes(pin gopi.GPIOPin) []gopi.GPIOState {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	states := make([]gopi.GPIOState, 0, len(this.writes))
+	for _, write := range this.writes {
+		if write.pin == pin {
+			states = append(states, write.state)
+		}
+	}
+	return states
+}
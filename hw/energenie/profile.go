@@ -0,0 +1,224 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// RadioProfile is the set of radio parameters used for a mode. The
+// modulation is FSK for monitor mode and OOK for control mode
+type RadioProfile struct {
+	FreqCarrier       uint                     // Carrier frequency, Hz
+	Bitrate           uint                     // Bits per second
+	FreqDeviation     uint                     // Frequency deviation, Hz (FSK only)
+	AFCMode           sensors.RFMAFCMode       // Automatic frequency correction
+	AFCRoutine        sensors.RFMAFCRoutine    // AFC routine (FSK only)
+	AFCOffset         int                      // Low-beta AFC offset, Hz, for the improved routine (FSK only)
+	LNAImpedance      sensors.RFMLNAImpedance  // LNA impedance (FSK only)
+	LNAGain           sensors.RFMLNAGain       // LNA gain (FSK only)
+	SensitivityBoost  bool                     // LNA high sensitivity mode, for weak signals
+	DAGC              bool                     // Continuous DAGC, for weak signals (FSK only)
+	RXFilterFrequency sensors.RFMRXBWFrequency // RX filter bandwidth (FSK only)
+	RXFilterCutoff    sensors.RFMRXBWCutoff    // RX filter DC cutoff (FSK only)
+	PacketFormat      sensors.RFMPacketFormat  // Fixed or variable length packets
+	PacketCoding      sensors.RFMPacketCoding  // Packet coding
+	PacketFilter      sensors.RFMPacketFilter  // Address filtering (FSK only)
+	PacketCRC         sensors.RFMPacketCRC     // Hardware CRC (FSK only)
+	PreambleSize      uint16                   // Preamble size, bytes
+	PayloadSize       uint8                    // Payload size for fixed packets, or maximum for variable packets
+	SyncWord          []byte                   // Sync word, or nil
+	SyncTolerance     uint8                    // Sync word tolerance, bits (FSK only)
+	NodeAddress       uint8                    // Node address (FSK only)
+	BroadcastAddress  uint8                    // Broadcast address (FSK only)
+	AESKey            []byte                   // AES-128 key, or nil (FSK only)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Largest fixed length packet which fits in the radio FIFO
+	PROFILE_PAYLOAD_MAX = 66
+
+	// Largest packet when AES is enabled, and the key size
+	PROFILE_AES_PAYLOAD_MAX = 64
+	PROFILE_AES_KEY_BYTES   = 16
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Default profile for monitor mode (FSK, 434.3MHz)
+	PROFILE_MONITOR_DEFAULT = RadioProfile{
+		FreqCarrier:       434300000,
+		Bitrate:           4800,
+		FreqDeviation:     30000,
+		AFCMode:           sensors.RFM_AFCMODE_OFF,
+		AFCRoutine:        sensors.RFM_AFCROUTINE_STANDARD,
+		LNAImpedance:      sensors.RFM_LNA_IMPEDANCE_50,
+		LNAGain:           sensors.RFM_LNA_GAIN_AUTO,
+		RXFilterFrequency: sensors.RFM_RXBW_FREQUENCY_FSK_62P5,
+		RXFilterCutoff:    sensors.RFM_RXBW_CUTOFF_4,
+		PacketFormat:      sensors.RFM_PACKET_FORMAT_VARIABLE,
+		PacketCoding:      sensors.RFM_PACKET_CODING_MANCHESTER,
+		PacketFilter:      sensors.RFM_PACKET_FILTER_NONE,
+		PacketCRC:         sensors.RFM_PACKET_CRC_OFF,
+		PreambleSize:      3,
+		PayloadSize:       0x40,
+		SyncWord:          []byte{0x2D, 0xD4},
+		SyncTolerance:     0,
+		NodeAddress:       0x04,
+		BroadcastAddress:  0xFF,
+	}
+
+	// Default profile for control mode (OOK, 433.92MHz)
+	PROFILE_CONTROL_DEFAULT = RadioProfile{
+		FreqCarrier:   433920000,
+		Bitrate:       4800,
+		FreqDeviation: 0,
+		AFCMode:       sensors.RFM_AFCMODE_OFF,
+		PacketFormat:  sensors.RFM_PACKET_FORMAT_VARIABLE,
+		PacketCoding:  sensors.RFM_PACKET_CODING_NONE,
+		PacketFilter:  sensors.RFM_PACKET_FILTER_NONE,
+		PacketCRC:     sensors.RFM_PACKET_CRC_OFF,
+		PreambleSize:  0,
+		PayloadSize:   0,
+		SyncWord:      nil,
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return a copy of the radio profile for a mode
+func (this *mihome) RadioProfile(mode sensors.MiHomeMode) (RadioProfile, error) {
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+
+	switch mode {
+	case sensors.MIHOME_MODE_MONITOR:
+		return this.profile_monitor.copy(), nil
+	case sensors.MIHOME_MODE_CONTROL:
+		return this.profile_control.copy(), nil
+	default:
+		return RadioProfile{}, gopi.ErrBadParameter
+	}
+}
+
+// Set the radio profile for a mode, which takes effect the next time the
+// mode is entered. A read in progress in the mode is interrupted, and
+// resumes with the new profile
+func (this *mihome) SetRadioProfile(mode sensors.MiHomeMode, profile RadioProfile) error {
+	this.log.Debug("<sensors.energenie.MiHome.SetRadioProfile{ mode=%v profile=%v }", mode, profile)
+
+	if err := profile.validate(mode); err != nil {
+		return err
+	}
+
+	// Wait for transmissions, so the profile isn't changed while in use
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+
+	switch mode {
+	case sensors.MIHOME_MODE_MONITOR:
+		this.profile_monitor = profile.copy()
+	case sensors.MIHOME_MODE_CONTROL:
+		this.profile_control = profile.copy()
+	default:
+		return gopi.ErrBadParameter
+	}
+
+	// Force the mode to be set again on next use
+	if this.mode == mode {
+		this.endRead()
+		this.setMode(sensors.MIHOME_MODE_NONE, "profile changed")
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return a copy of the profile which shares no slices with it
+func (p RadioProfile) copy() RadioProfile {
+	if p.SyncWord != nil {
+		p.SyncWord = append([]byte{}, p.SyncWord...)
+	}
+	if p.AESKey != nil {
+		p.AESKey = append([]byte{}, p.AESKey...)
+	}
+	return p
+}
+
+// Check a profile can be used for a mode
+func (p RadioProfile) validate(mode sensors.MiHomeMode) error {
+	if p.FreqCarrier == 0 || p.Bitrate == 0 {
+		return gopi.ErrBadParameter
+	}
+	switch p.PacketFormat {
+	case sensors.RFM_PACKET_FORMAT_FIXED:
+		// Fixed length packets must fit in the FIFO
+		if p.PayloadSize == 0 || p.PayloadSize > PROFILE_PAYLOAD_MAX {
+			return gopi.ErrBadParameter
+		}
+	case sensors.RFM_PACKET_FORMAT_VARIABLE:
+		break
+	default:
+		return gopi.ErrBadParameter
+	}
+	if p.PacketFilter > sensors.RFM_PACKET_FILTER_BROADCAST {
+		return gopi.ErrBadParameter
+	}
+	if p.PacketCRC > sensors.RFM_PACKET_CRC_AUTOCLEAR_ON {
+		return gopi.ErrBadParameter
+	}
+	if p.AESKey != nil {
+		if len(p.AESKey) != PROFILE_AES_KEY_BYTES || p.PayloadSize > PROFILE_AES_PAYLOAD_MAX {
+			return gopi.ErrBadParameter
+		}
+	}
+	switch mode {
+	case sensors.MIHOME_MODE_MONITOR:
+		// Address filtering needs an address byte after the sync word
+		if p.PacketFilter != sensors.RFM_PACKET_FILTER_NONE && len(p.SyncWord) == 0 {
+			return gopi.ErrBadParameter
+		}
+	case sensors.MIHOME_MODE_CONTROL:
+		// Legacy OOK devices send neither addresses, a CRC nor encrypted packets
+		if p.PacketFilter != sensors.RFM_PACKET_FILTER_NONE || p.PacketCRC != sensors.RFM_PACKET_CRC_OFF || p.AESKey != nil {
+			return gopi.ErrBadParameter
+		}
+	default:
+		return gopi.ErrBadParameter
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (p RadioProfile) String() string {
+	return fmt.Sprintf("<sensors.energenie.RadioProfile>{ freq_carrier=%v bitrate=%v freq_deviation=%v afc_mode=%v packet_format=%v packet_coding=%v packet_filter=%v packet_crc=%v preamble_size=%v payload_size=%v sync_word=%v aes=%v }", p.FreqCarrier, p.Bitrate, p.FreqDeviation, p.AFCMode, p.PacketFormat, p.PacketCoding, p.PacketFilter, p.PacketCRC, p.PreambleSize, p.PayloadSize, strings.ToUpper(hex.EncodeToString(p.SyncWord)), p.AESKey != nil)
+}
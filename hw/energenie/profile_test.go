@@ -0,0 +1,90 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST PROFILE
+
+// TestRadioProfileCopy checks that profiles share no slices with the caller
+func TestRadioProfileCopy(t *testing.T) {
+	driver, _ := test_mihome(t, MiHome{})
+
+	profile := PROFILE_MONITOR_DEFAULT
+	profile.SyncWord = []byte{0x2D, 0xD4}
+	profile.AESKey = bytes.Repeat([]byte{0x01}, PROFILE_AES_KEY_BYTES)
+	if err := driver.SetRadioProfile(sensors.MIHOME_MODE_MONITOR, profile); err != nil {
+		t.Fatal(err)
+	}
+	profile.SyncWord[0] = 0xFF
+	profile.AESKey[0] = 0xFF
+
+	if other, err := driver.RadioProfile(sensors.MIHOME_MODE_MONITOR); err != nil {
+		t.Fatal(err)
+	} else if other.SyncWord[0] != 0x2D || other.AESKey[0] != 0x01 {
+		t.Errorf("Profile changed by the caller: %X %X", other.SyncWord, other.AESKey)
+	} else {
+		other.SyncWord[0] = 0xFF
+		if driver.profile_monitor.SyncWord[0] != 0x2D {
+			t.Error("Profile changed through RadioProfile")
+		}
+	}
+	if PROFILE_MONITOR_DEFAULT.SyncWord[0] != 0x2D {
+		t.Error("Default profile changed")
+	}
+}
+
+// TestSetRadioProfileWhileReceiving checks that a read in monitor mode
+// resumes with the new profile
+func TestSetRadioProfileWhileReceiving(t *testing.T) {
+	driver, radio := test_mihome(t, MiHome{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- driver.Receive(ctx, sensors.MIHOME_MODE_MONITOR)
+	}()
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+
+	profile := PROFILE_MONITOR_DEFAULT
+	profile.FreqCarrier = 434000000
+	if err := driver.SetRadioProfile(sensors.MIHOME_MODE_MONITOR, profile); err != nil {
+		t.Fatal(err)
+	}
+
+	// The radio is configured under txlock, which is held while it's checked
+	timeout := time.Now().Add(time.Second)
+	for {
+		driver.txlock.Lock()
+		done := driver.mode == sensors.MIHOME_MODE_MONITOR && driver.reading() && radio.FreqCarrier() == profile.FreqCarrier
+		driver.txlock.Unlock()
+		if done {
+			break
+		} else if time.Now().After(timeout) {
+			t.Fatal("Timeout waiting for the new profile")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,234 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+type report_event struct {
+	driver  gopi.Driver
+	ts      time.Time
+	message sensors.OTMessage
+}
+
+type temperature_report struct {
+	report_event
+	temperature float32
+}
+
+type power_report struct {
+	report_event
+	real_power     float32
+	reactive_power float32
+	voltage        float32
+	frequency      float32
+}
+
+type switch_state struct {
+	report_event
+	on bool
+}
+
+type join_request struct {
+	report_event
+}
+
+type battery_alarm struct {
+	report_event
+	voltage float32
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Battery voltage at or below which a BatteryAlarm is emitted
+	BATTERY_ALARM_VOLTAGE = 2.4
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// reports synthesizes semantic events from the records of a decoded message
+func reports(driver gopi.Driver, ts time.Time, message sensors.OTMessage) []gopi.Event {
+	if message == nil {
+		return nil
+	}
+
+	events := make([]gopi.Event, 0, 1)
+	report := report_event{driver, ts, message}
+	power := &power_report{report_event: report}
+	has_power := false
+	for _, record := range message.Records() {
+		if record.IsRequest() {
+			// Commands echoed from another controller are not reports
+			continue
+		}
+		switch record.Name() {
+		case sensors.OT_PARAM_TEMPERATURE:
+			if value, err := record_float(record); err == nil {
+				events = append(events, &temperature_report{report, value})
+			}
+		case sensors.OT_PARAM_REAL_POWER:
+			if value, err := record_float(record); err == nil {
+				power.real_power, has_power = value, true
+			}
+		case sensors.OT_PARAM_REACTIVE_POWER:
+			if value, err := record_float(record); err == nil {
+				power.reactive_power, has_power = value, true
+			}
+		case sensors.OT_PARAM_VOLTAGE:
+			if value, err := record_float(record); err == nil {
+				power.voltage, has_power = value, true
+			}
+		case sensors.OT_PARAM_FREQUENCY:
+			if value, err := record_float(record); err == nil {
+				power.frequency, has_power = value, true
+			}
+		case sensors.OT_PARAM_SWITCH_STATE:
+			if value, err := record_float(record); err == nil {
+				events = append(events, &switch_state{report, value != 0})
+			}
+		case sensors.OT_PARAM_JOIN:
+			events = append(events, &join_request{report})
+		case sensors.OT_PARAM_BATTERY_LEVEL:
+			if value, err := record_float(record); err == nil && value <= BATTERY_ALARM_VOLTAGE {
+				events = append(events, &battery_alarm{report, value})
+			}
+		}
+	}
+	if has_power {
+		events = append(events, power)
+	}
+
+	return events
+}
+
+// record_float returns the value of a numeric record
+func record_float(record sensors.OTRecord) (float32, error) {
+	if value, err := record.StringValue(); err != nil {
+		return 0, err
+	} else if number, err := strconv.ParseFloat(value, 32); err != nil {
+		return 0, err
+	} else {
+		return float32(number), nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - report_event
+
+func (this *report_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *report_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *report_event) ProductID() uint8 {
+	return this.message.ProductID()
+}
+
+func (this *report_event) SensorID() uint32 {
+	return this.message.SensorID()
+}
+
+func (this *report_event) Message() sensors.OTMessage {
+	return this.message
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - reports
+
+func (this *temperature_report) Name() string {
+	return "TemperatureReport"
+}
+
+func (this *temperature_report) Temperature() float32 {
+	return this.temperature
+}
+
+func (this *power_report) Name() string {
+	return "PowerReport"
+}
+
+func (this *power_report) RealPower() float32 {
+	return this.real_power
+}
+
+func (this *power_report) ReactivePower() float32 {
+	return this.reactive_power
+}
+
+func (this *power_report) Voltage() float32 {
+	return this.voltage
+}
+
+func (this *power_report) Frequency() float32 {
+	return this.frequency
+}
+
+func (this *switch_state) Name() string {
+	return "SwitchState"
+}
+
+func (this *switch_state) On() bool {
+	return this.on
+}
+
+func (this *join_request) Name() string {
+	return "JoinRequest"
+}
+
+func (this *join_request) Manufacturer() sensors.OTManufacturer {
+	return this.message.Manufacturer()
+}
+
+func (this *battery_alarm) Name() string {
+	return "BatteryAlarm"
+}
+
+func (this *battery_alarm) BatteryVoltage() float32 {
+	return this.voltage
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *temperature_report) String() string {
+	return fmt.Sprintf("<sensors.energenie.TemperatureReport>{ sensor_id=0x%06X temperature=%v }", this.SensorID(), this.temperature)
+}
+
+func (this *power_report) String() string {
+	return fmt.Sprintf("<sensors.energenie.PowerReport>{ sensor_id=0x%06X real_power=%v reactive_power=%v voltage=%v frequency=%v }", this.SensorID(), this.real_power, this.reactive_power, this.voltage, this.frequency)
+}
+
+func (this *switch_state) String() string {
+	return fmt.Sprintf("<sensors.energenie.SwitchState>{ sensor_id=0x%06X on=%v }", this.SensorID(), this.on)
+}
+
+func (this *join_request) String() string {
+	return fmt.Sprintf("<sensors.energenie.JoinRequest>{ sensor_id=0x%06X manufacturer=%v product_id=0x%02X }", this.SensorID(), this.Manufacturer(), this.ProductID())
+}
+
+func (this *battery_alarm) String() string {
+	return fmt.Sprintf("<sensors.energenie.BatteryAlarm>{ sensor_id=0x%06X battery_voltage=%v }", this.SensorID(), this.voltage)
+}
@@ -0,0 +1,93 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"fmt"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// ResetProfile is the timing used to reset the radio through the
+// reset pin. After the pulse, the radio version register is polled
+// until it reads back the expected value
+type ResetProfile struct {
+	Pulse   time.Duration // Duration reset is held high
+	Hold    time.Duration // Duration reset is held low after the pulse
+	Settle  time.Duration // Delay before polling the radio
+	Timeout time.Duration // Maximum time to wait for the radio to be ready
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	RESET_POLL_INTERVAL = 5 * time.Millisecond
+	RADIO_VERSION_VALUE = 0x24
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Reset profiles by board name
+	RESET_PROFILES = map[string]ResetProfile{
+		"default": ResetProfile{100 * time.Millisecond, 5 * time.Millisecond, 0, 100 * time.Millisecond},
+		"slow":    ResetProfile{250 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond, 1000 * time.Millisecond},
+	}
+	RESET_PROFILE_DEFAULT = RESET_PROFILES["default"]
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// pulseReset pulses the reset pin and waits for the radio to be ready
+func (this *mihome) pulseReset() error {
+	// Pull reset high and then low
+	this.gpio.WritePin(this.reset, gopi.GPIO_HIGH)
+	time.Sleep(this.reset_profile.Pulse)
+	this.gpio.WritePin(this.reset, gopi.GPIO_LOW)
+	time.Sleep(this.reset_profile.Hold)
+
+	// Settle before polling the radio
+	if this.reset_profile.Settle > 0 {
+		time.Sleep(this.reset_profile.Settle)
+	}
+
+	return this.waitReady(this.reset_profile.Timeout)
+}
+
+// waitReady polls the radio version register until it reads
+// the expected value, or returns ErrNoDevice on timeout
+func (this *mihome) waitReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if version, err := this.radio.ReadVersion(); err == nil && version == RADIO_VERSION_VALUE {
+			return nil
+		} else if time.Now().After(deadline) {
+			this.log.Debug2("<sensors.energenie.MiHome.waitReady>{ version=0x%02X err=%v }", version, err)
+			return sensors.ErrNoDevice
+		}
+		time.Sleep(RESET_POLL_INTERVAL)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (p ResetProfile) String() string {
+	return fmt.Sprintf("<sensors.energenie.ResetProfile>{ pulse=%v hold=%v settle=%v timeout=%v }", p.Pulse, p.Hold, p.Settle, p.Timeout)
+}
@@ -0,0 +1,212 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// SceneAction switches a socket on or off as part of a scene
+type SceneAction = sensors.MiHomeSceneAction
+
+// scene_command is an action encoded for transmission
+type scene_command struct {
+	cid []byte
+	cmd Command
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Default spacing between transmissions in a scene
+	SCENE_GAP_DEFAULT = 250 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// SetScene defines a named scene, or removes the scene when there
+// are no actions
+func (this *mihome) SetScene(name string, actions ...SceneAction) error {
+	this.log.Debug("<sensors.energenie.MiHome.SetScene{ name=\"%v\" actions=%v }", name, actions)
+
+	if name = strings.TrimSpace(name); name == "" {
+		return gopi.ErrBadParameter
+	} else if len(actions) == 0 {
+		this.scenelock.Lock()
+		defer this.scenelock.Unlock()
+		delete(this.scenes, name)
+		return nil
+	}
+
+	// Encode the actions so errors are reported when the scene is defined
+	commands := make([]scene_command, 0, len(actions))
+	for _, action := range actions {
+		if command, err := this.sceneCommand(action); err != nil {
+			return err
+		} else {
+			commands = append(commands, command)
+		}
+	}
+	this.scenelock.Lock()
+	defer this.scenelock.Unlock()
+	this.scenes[name] = groupCommands(commands)
+
+	// Success
+	return nil
+}
+
+// TriggerScene transmits the commands for a scene in order, with
+// a gap between each transmission. Scenes which switch every socket
+// on an address the same way are sent as a single command
+func (this *mihome) TriggerScene(name string) error {
+	this.log.Debug("<sensors.energenie.MiHome.TriggerScene{ name=\"%v\" }", name)
+
+	// The commands for a scene are replaced rather than modified, so the lock
+	// is released before sending
+	this.scenelock.Lock()
+	commands, exists := this.scenes[name]
+	this.scenelock.Unlock()
+
+	if exists == false {
+		return gopi.ErrBadParameter
+	} else {
+		for i, command := range commands {
+			if i > 0 {
+				time.Sleep(this.scenegap)
+			}
+			if err := this.SendControl(command.cid, command.cmd, this.repeat); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *mihome) sceneCommand(action SceneAction) (scene_command, error) {
+	command := scene_command{cid: this.cid}
+	if action.CID != "" {
+		if cid, err := decodeHexString(action.CID); err != nil {
+			return command, err
+		} else {
+			command.cid = cid
+		}
+	}
+	switch {
+	case action.Socket == 0 && action.On:
+		command.cmd = OOK_ON_ALL
+	case action.Socket == 0:
+		command.cmd = OOK_OFF_ALL
+	case action.On:
+		if cmd, err := onCommandForSocket(action.Socket); err != nil {
+			return command, err
+		} else {
+			command.cmd = cmd
+		}
+	default:
+		if cmd, err := offCommandForSocket(action.Socket); err != nil {
+			return command, err
+		} else {
+			command.cmd = cmd
+		}
+	}
+	// Check the address can be encoded, as SendControl would
+	if _, err := encodeCommandPayload(command.cid, command.cmd); err != nil {
+		return command, err
+	}
+	return command, nil
+}
+
+// groupCommands replaces the commands for an address with a single
+// ON_ALL or OFF_ALL command, when they switch every socket on that
+// address the same way and there are no other commands for the address.
+// The grouped command is sent in place of the first command it replaces,
+// and commands which are not grouped keep their original order
+func groupCommands(commands []scene_command) []scene_command {
+	grouped := make([]scene_command, 0, len(commands))
+	for i, command := range commands {
+		// Collect the commands for this address, noting whether this is
+		// the first of them
+		first := true
+		cmds := make([]Command, 0, SOCKET_MAX)
+		for j, other := range commands {
+			if bytes.Equal(other.cid, command.cid) {
+				if j < i {
+					first = false
+				}
+				cmds = append(cmds, other.cmd)
+			}
+		}
+		if all, ok := groupCommand(cmds); ok == false {
+			grouped = append(grouped, command)
+		} else if first {
+			grouped = append(grouped, scene_command{cid: command.cid, cmd: all})
+		}
+	}
+	return grouped
+}
+
+// groupCommand returns ON_ALL or OFF_ALL when the commands switch each
+// socket exactly once and all in the same way
+func groupCommand(cmds []Command) (Command, bool) {
+	if len(cmds) != SOCKET_MAX {
+		return OOK_NONE, false
+	}
+	on, off := 0, 0
+	seen := make(map[uint]bool, SOCKET_MAX)
+	for _, cmd := range cmds {
+		socket, state, ok := socketForCommand(cmd)
+		if ok == false || seen[socket] {
+			return OOK_NONE, false
+		}
+		seen[socket] = true
+		if state {
+			on++
+		} else {
+			off++
+		}
+	}
+	switch {
+	case on == SOCKET_MAX:
+		return OOK_ON_ALL, true
+	case off == SOCKET_MAX:
+		return OOK_OFF_ALL, true
+	default:
+		return OOK_NONE, false
+	}
+}
+
+// socketForCommand returns the socket and state for a command which
+// switches a single socket
+func socketForCommand(cmd Command) (uint, bool, bool) {
+	for socket := uint(1); socket <= SOCKET_MAX; socket++ {
+		if on, _ := onCommandForSocket(socket); on == cmd {
+			return socket, true, true
+		} else if off, _ := offCommandForSocket(socket); off == cmd {
+			return socket, false, true
+		}
+	}
+	return 0, false, false
+}
@@ -0,0 +1,173 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+// Scenes are defined through the public interface
+var _ sensors.MiHome = (*mihome)(nil)
+var _ sensors.MiHome = (*demo)(nil)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST TRIGGER SCENE
+
+// TestTriggerScene checks that a scene is transmitted in order with the
+// control profile, and that the sockets on an address are grouped
+func TestTriggerScene(t *testing.T) {
+	driver, radio := test_mihome(t, MiHome{
+		SceneGap: time.Millisecond,
+		Scenes: map[string][]SceneAction{
+			"evening": {
+				{Socket: 1, On: true},
+				{CID: "12345", Socket: 2, On: false},
+				{Socket: 2, On: true},
+				{Socket: 3, On: true},
+				{Socket: 4, On: true},
+			},
+		},
+	})
+
+	if err := driver.TriggerScene("evening"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []scene_command{
+		{driver.cid, OOK_ON_ALL},
+		{[]byte{0x01, 0x23, 0x45}, OOK_OFF_2},
+	}
+	if tx := radio.Transmitted(); len(tx) != len(expected) {
+		t.Fatalf("Expected %v transmissions, got %v", len(expected), len(tx))
+	} else {
+		for i, command := range expected {
+			checkControlProfile(t, driver, tx[i])
+			if payload, err := encodeCommandPayload(command.cid, command.cmd); err != nil {
+				t.Fatal(err)
+			} else if bytes.Equal(tx[i].Payload, payload) == false {
+				t.Errorf("Transmission %v: expected %X %v", i, command.cid, command.cmd)
+			}
+		}
+	}
+
+	// Removed and undefined scenes aren't transmitted
+	if err := driver.SetScene("evening"); err != nil {
+		t.Fatal(err)
+	} else if err := driver.TriggerScene("evening"); err != gopi.ErrBadParameter {
+		t.Errorf("Expected ErrBadParameter, got %v", err)
+	} else if tx := radio.Transmitted(); len(tx) != 0 {
+		t.Errorf("Expected no transmissions, got %v", len(tx))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST SET SCENE
+
+// TestSetSceneCID checks that addresses which can't be transmitted are
+// rejected when the scene is defined
+func TestSetSceneCID(t *testing.T) {
+	driver, _ := test_mihome(t, MiHome{})
+	tests := []struct {
+		cid string
+		ok  bool
+	}{
+		{"", true},
+		{"6C6C6", true},
+		{"06C6C6", true},
+		{"00001", true},
+		{"1", false},
+		{"XYZ", false},
+		{"1234567", false},
+		{"00000000", false},
+	}
+	for _, test := range tests {
+		t.Run(test.cid, func(t *testing.T) {
+			err := driver.SetScene("scene", SceneAction{CID: test.cid, Socket: 1, On: true})
+			if test.ok && err != nil {
+				t.Error(err)
+			} else if test.ok == false && err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST GROUP COMMANDS
+
+func TestGroupCommands(t *testing.T) {
+	a, b := []byte{0x0A}, []byte{0x0B}
+	tests := []struct {
+		name     string
+		commands []scene_command
+		expected []scene_command
+	}{
+		{"Empty", nil, []scene_command{}},
+		{"Single", []scene_command{{a, OOK_ON_1}}, []scene_command{{a, OOK_ON_1}}},
+		{
+			"AllOn",
+			[]scene_command{{a, OOK_ON_3}, {a, OOK_ON_1}, {a, OOK_ON_4}, {a, OOK_ON_2}},
+			[]scene_command{{a, OOK_ON_ALL}},
+		},
+		{
+			"AllOff",
+			[]scene_command{{a, OOK_OFF_1}, {a, OOK_OFF_2}, {a, OOK_OFF_3}, {a, OOK_OFF_4}},
+			[]scene_command{{a, OOK_OFF_ALL}},
+		},
+		{
+			// The grouped command is sent in place of the first one
+			"GroupedInPlace",
+			[]scene_command{{b, OOK_ON_1}, {a, OOK_OFF_1}, {b, OOK_OFF_2}, {a, OOK_OFF_2}, {a, OOK_OFF_3}, {a, OOK_OFF_4}},
+			[]scene_command{{b, OOK_ON_1}, {a, OOK_OFF_ALL}, {b, OOK_OFF_2}},
+		},
+		{
+			"TwoAddresses",
+			[]scene_command{{a, OOK_ON_1}, {b, OOK_OFF_1}, {a, OOK_ON_2}, {b, OOK_OFF_2}, {a, OOK_ON_3}, {b, OOK_OFF_3}, {a, OOK_ON_4}, {b, OOK_OFF_4}},
+			[]scene_command{{a, OOK_ON_ALL}, {b, OOK_OFF_ALL}},
+		},
+		{
+			// Mixed states are sent in their original order
+			"Mixed",
+			[]scene_command{{a, OOK_ON_1}, {a, OOK_OFF_2}, {a, OOK_ON_3}, {a, OOK_ON_4}},
+			[]scene_command{{a, OOK_ON_1}, {a, OOK_OFF_2}, {a, OOK_ON_3}, {a, OOK_ON_4}},
+		},
+		{
+			// A socket switched twice isn't grouped, so the order is kept
+			"Repeated",
+			[]scene_command{{a, OOK_ON_1}, {a, OOK_ON_2}, {a, OOK_ON_3}, {a, OOK_ON_1}},
+			[]scene_command{{a, OOK_ON_1}, {a, OOK_ON_2}, {a, OOK_ON_3}, {a, OOK_ON_1}},
+		},
+		{
+			// Other commands for the address prevent grouping
+			"Extra",
+			[]scene_command{{a, OOK_ON_1}, {a, OOK_ON_2}, {a, OOK_ON_3}, {a, OOK_ON_4}, {a, OOK_OFF_ALL}},
+			[]scene_command{{a, OOK_ON_1}, {a, OOK_ON_2}, {a, OOK_ON_3}, {a, OOK_ON_4}, {a, OOK_OFF_ALL}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grouped := groupCommands(test.commands)
+			if len(grouped) != len(test.expected) {
+				t.Fatalf("Expected %v, got %v", test.expected, grouped)
+			}
+			for i := range grouped {
+				if bytes.Equal(grouped[i].cid, test.expected[i].cid) == false || grouped[i].cmd != test.expected[i].cmd {
+					t.Errorf("Expected %v, got %v", test.expected, grouped)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// SocketState is the inferred state of a legacy OOK socket, which never
+// reports its state. It is derived from the last command sent to or
+// received for the socket. Confidence is one when the command was sent
+// and halves every half-life, since the socket may have been switched by
+// hand or by another controller. A state marked manually does not decay
+type SocketState struct {
+	Socket     uint      // Socket number, from 1
+	Known      bool      // False if no command has been seen for the socket
+	On         bool      // Inferred state
+	Override   bool      // True if the state was marked manually
+	Updated    time.Time // Time of the last command or mark
+	Confidence float64   // Confidence in the inferred state, between zero and one
+}
+
+type socket_states struct {
+	halflife time.Duration
+	states   map[uint]SocketState
+	lock     sync.Mutex
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Number of sockets which can be addressed
+	SOCKET_MAX = 4
+
+	// Default time for confidence in the inferred state to halve
+	STATE_HALFLIFE_DEFAULT = time.Hour
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return the inferred state of a socket from 1 to SOCKET_MAX
+func (this *mihome) SocketState(socket uint) (SocketState, error) {
+	return this.states.Get(socket, time.Now())
+}
+
+// Mark the state of a socket, for example when it has been switched by
+// hand. The state is kept with full confidence until the next command
+func (this *mihome) MarkSocketState(socket uint, on bool) error {
+	this.log.Debug("<sensors.energenie.MiHome.MarkSocketState{ socket=%v on=%v }", socket, on)
+	return this.states.Set(socket, on, true, time.Now())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func newSocketStates(halflife time.Duration) *socket_states {
+	this := new(socket_states)
+	this.halflife = halflife
+	if this.halflife == 0 {
+		this.halflife = STATE_HALFLIFE_DEFAULT
+	}
+	this.states = make(map[uint]SocketState, SOCKET_MAX)
+	return this
+}
+
+func (this *socket_states) Get(socket uint, now time.Time) (SocketState, error) {
+	if socket == 0 || socket > SOCKET_MAX {
+		return SocketState{}, gopi.ErrBadParameter
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	state, exists := this.states[socket]
+	if exists == false {
+		return SocketState{Socket: socket}, nil
+	} else if state.Override == false {
+		age := now.Sub(state.Updated)
+		state.Confidence = math.Pow(0.5, age.Seconds()/this.halflife.Seconds())
+	}
+	return state, nil
+}
+
+func (this *socket_states) Set(socket uint, on, override bool, now time.Time) error {
+	if socket == 0 || socket > SOCKET_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.states[socket] = SocketState{
+		Socket:     socket,
+		Known:      true,
+		On:         on,
+		Override:   override,
+		Updated:    now,
+		Confidence: 1,
+	}
+	return nil
+}
+
+// Update the inferred state from a command sent or received for an
+// address, ignoring commands for other addresses
+func (this *mihome) inferState(cid []byte, cmd Command) {
+	if bytes.Equal(cid, this.cid) == false {
+		return
+	}
+	now := time.Now()
+	switch cmd {
+	case OOK_ON_ALL, OOK_OFF_ALL:
+		for socket := uint(1); socket <= SOCKET_MAX; socket++ {
+			this.states.Set(socket, cmd == OOK_ON_ALL, false, now)
+		}
+	case OOK_DIM_1, OOK_DIM_2, OOK_DIM_3, OOK_DIM_4:
+		// Dim commands follow an on command, so the state is already known
+		break
+	default:
+		for socket := uint(1); socket <= SOCKET_MAX; socket++ {
+			if on, _ := onCommandForSocket(socket); on == cmd {
+				this.states.Set(socket, true, false, now)
+			} else if off, _ := offCommandForSocket(socket); off == cmd {
+				this.states.Set(socket, false, false, now)
+			}
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (s SocketState) String() string {
+	if s.Known == false {
+		return fmt.Sprintf("<sensors.energenie.SocketState>{ socket=%v known=false }", s.Socket)
+	}
+	return fmt.Sprintf("<sensors.energenie.SocketState>{ socket=%v on=%v override=%v updated=%v confidence=%.2f }", s.Socket, s.On, s.Override, s.Updated.Format(time.Stamp), s.Confidence)
+}
@@ -0,0 +1,102 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"fmt"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+type temperature_event struct {
+	driver      *mihome
+	ts          time.Time
+	temperature float32
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BACKGROUND TASK
+
+// Sample the radio temperature at an interval and emit TemperatureEvent
+// values, skipping samples while the radio is receiving or transmitting
+func (this *mihome) sampleTemperature(interval time.Duration, done <-chan struct{}) {
+	defer this.wait.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+FOR_LOOP:
+	for {
+		select {
+		case <-done:
+			break FOR_LOOP
+		case <-ticker.C:
+			if temperature, sampled, err := this.trySampleTemperature(); err != nil {
+				this.log.Warn("MeasureTemperature: %v", err)
+			} else if sampled {
+				this.pubsub.Emit(&temperature_event{
+					driver:      this,
+					ts:          time.Now(),
+					temperature: temperature,
+				})
+			}
+		}
+	}
+}
+
+// trySampleTemperature measures the temperature unless the radio is in use,
+// and returns false when the sample is skipped. The radio is in use while
+// another goroutine holds txlock, a read is in progress, or it is not in
+// standby or sleep mode
+func (this *mihome) trySampleTemperature() (float32, bool, error) {
+	if this.txlock.TryLock() == false {
+		return 0, false, nil
+	}
+	defer this.txlock.Unlock()
+
+	if this.reading() {
+		return 0, false, nil
+	} else if mode := this.radio.Mode(); mode != sensors.RFM_MODE_STDBY && mode != sensors.RFM_MODE_SLEEP {
+		return 0, false, nil
+	} else if temperature, err := this.measureTemperature(this.TempOffset()); err != nil {
+		return 0, false, err
+	} else {
+		return temperature, true, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - temperature_event
+
+func (this *temperature_event) Name() string {
+	return "TemperatureEvent"
+}
+
+func (this *temperature_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *temperature_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *temperature_event) Temperature() float32 {
+	return this.temperature
+}
+
+func (this *temperature_event) String() string {
+	return fmt.Sprintf("<sensors.TemperatureEvent>{ ts=%v temperature=%.1fC }", this.ts.Format(time.Stamp), this.temperature)
+}
@@ -0,0 +1,114 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package energenie
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/hw/rfm69/mock"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST SAMPLE
+
+func TestSampleTemperature(t *testing.T) {
+	driver, _ := test_mihome(t, MiHome{TempOffset: 1.5})
+	if temperature, sampled, err := driver.trySampleTemperature(); err != nil {
+		t.Fatal(err)
+	} else if sampled == false {
+		t.Fatal("Expected a sample in standby mode")
+	} else if temperature != mock.MOCK_TEMPERATURE+1.5 {
+		t.Errorf("Expected %v, got %v", mock.MOCK_TEMPERATURE+1.5, temperature)
+	}
+}
+
+// TestSampleWhileReceiving checks that samples are skipped while reading,
+// but that MeasureTemperature interrupts the read, which then resumes
+func TestSampleWhileReceiving(t *testing.T) {
+	driver, radio := test_mihome(t, MiHome{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- driver.Receive(ctx, sensors.MIHOME_MODE_MONITOR)
+	}()
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+
+	if _, sampled, err := driver.trySampleTemperature(); err != nil {
+		t.Fatal(err)
+	} else if sampled {
+		t.Error("Expected the sample to be skipped while receiving")
+	}
+	if temperature, err := driver.MeasureTemperature(); err != nil {
+		t.Fatal(err)
+	} else if temperature != mock.MOCK_TEMPERATURE {
+		t.Errorf("Expected %v, got %v", mock.MOCK_TEMPERATURE, temperature)
+	}
+
+	waitRadioMode(t, radio, sensors.RFM_MODE_RX)
+	cancel()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSampleDuringTransmit checks that background samples and
+// transmissions don't interfere with each other
+func TestSampleDuringTransmit(t *testing.T) {
+	driver, radio := test_mihome(t, MiHome{TempInterval: time.Millisecond})
+	events := driver.Subscribe()
+	defer driver.Unsubscribe(events)
+
+	const count = 20
+	for i := 0; i < count; i++ {
+		if err := driver.SendControl(driver.cid, OOK_ON_1, 1); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if tx := radio.Transmitted(); len(tx) != count {
+		t.Errorf("Expected %v transmissions, got %v", count, len(tx))
+	} else {
+		for _, tx := range tx {
+			checkControlProfile(t, driver, tx)
+		}
+	}
+
+	// Samples continue once the radio is back in standby
+	if err := driver.setRadioModeLocked(sensors.RFM_MODE_STDBY); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case evt := <-events:
+			if _, ok := evt.(sensors.TemperatureEvent); ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for TemperatureEvent")
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+// setRadioModeLocked sets the radio mode with txlock held
+func (this *mihome) setRadioModeLocked(mode sensors.RFMMode) error {
+	this.txlock.Lock()
+	defer this.txlock.Unlock()
+	return this.setRadioMode(mode, "test")
+}
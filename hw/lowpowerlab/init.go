@@ -0,0 +1,75 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package lowpowerlab
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register LowPowerLab network node using RFM69
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/lowpowerlab",
+		Requires: []string{"sensors/rfm69"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagUint("lowpowerlab.network", 100, "Network identifier")
+			config.AppFlags.FlagUint("lowpowerlab.node", 1, "Node identifier of this gateway")
+			config.AppFlags.FlagUint("lowpowerlab.freq", 0, "Carrier frequency (Hz), or 0 to keep the radio frequency")
+			config.AppFlags.FlagString("lowpowerlab.aeskey", "", "AES-128 key (32 hex digits)")
+			config.AppFlags.FlagUint("lowpowerlab.retries", RETRIES_DEFAULT, "Number of times a send is retried")
+			config.AppFlags.FlagDuration("lowpowerlab.retrywait", RETRY_WAIT_DEFAULT, "Time to wait for an acknowledgement")
+			config.AppFlags.FlagBool("lowpowerlab.promiscuous", false, "Receive packets sent to any node")
+			config.AppFlags.FlagString("lowpowerlab.radio", "sensors/rfm69", "Radio module, such as sensors/rfm69/1 for a second radio")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			module, _ := app.AppFlags.GetString("lowpowerlab.radio")
+			if radio, ok := app.ModuleInstance(module).(sensors.RFM69); !ok {
+				return nil, fmt.Errorf("Missing or invalid Radio module")
+			} else {
+				network_id, _ := app.AppFlags.GetUint("lowpowerlab.network")
+				node_id, _ := app.AppFlags.GetUint("lowpowerlab.node")
+				freq, _ := app.AppFlags.GetUint("lowpowerlab.freq")
+				key, _ := app.AppFlags.GetString("lowpowerlab.aeskey")
+				retries, _ := app.AppFlags.GetUint("lowpowerlab.retries")
+				retry_wait, _ := app.AppFlags.GetDuration("lowpowerlab.retrywait")
+				promiscuous, _ := app.AppFlags.GetBool("lowpowerlab.promiscuous")
+				if network_id > 0xFF || node_id > 0xFF {
+					return nil, gopi.ErrBadParameter
+				}
+				config := Network{
+					Radio:       radio,
+					NetworkID:   uint8(network_id),
+					NodeID:      uint8(node_id),
+					FreqCarrier: freq,
+					Retries:     retries,
+					RetryWait:   retry_wait,
+					Promiscuous: promiscuous,
+				}
+				if key != "" {
+					if aes_key, err := hex.DecodeString(key); err != nil {
+						return nil, err
+					} else {
+						config.AESKey = aes_key
+					}
+				}
+				return gopi.Open(config, app.Logger)
+			}
+		},
+	})
+}
@@ -0,0 +1,401 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package lowpowerlab implements sensors.RFM69Network, which exchanges
+// packets with nodes running the LowPowerLab RFM69 Arduino library such
+// as Moteino sensors. Packets are variable length with a CRC, and the
+// header after the length byte is the target node, the sender node and
+// a control byte which requests or acknowledges an ACK
+package lowpowerlab
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Network struct {
+	Radio       sensors.RFM69 // Radio interface
+	NetworkID   uint8         // Network identifier, which is the second byte of the sync word
+	NodeID      uint8         // Node identifier of this gateway
+	FreqCarrier uint          // Carrier frequency (Hz), or zero to keep the radio frequency
+	AESKey      []byte        // AES-128 key, or nil for no encryption
+	Retries     uint          // Number of times a send is retried when not acknowledged
+	RetryWait   time.Duration // Time to wait for an acknowledgement, or zero for default
+	Promiscuous bool          // Receive packets sent to any node
+}
+
+// network driver
+type network struct {
+	log         gopi.Logger
+	radio       sensors.RFM69
+	network_id  uint8
+	node_id     uint8
+	retries     uint
+	retry_wait  time.Duration
+	promiscuous bool
+
+	// Messages received while waiting for an acknowledgement
+	pending []*sensors.RFMNetworkMessage
+
+	lock sync.Mutex
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Modem settings of the LowPowerLab library
+	NETWORK_BITRATE        = 55555
+	NETWORK_FREQ_DEVIATION = 50000
+	NETWORK_PREAMBLE_SIZE  = 3
+	NETWORK_SYNCWORD       = 0x2D
+	NETWORK_PAYLOAD_LENGTH = 66 // Maximum length byte accepted in RX
+
+	// Header after the length byte, and the control byte flags
+	NETWORK_HEADER_SIZE = 3
+	NETWORK_CTL_SENDACK = 0x80
+	NETWORK_CTL_REQACK  = 0x40
+
+	// Acknowledgement and channel access
+	RETRIES_DEFAULT    = 2
+	RETRY_WAIT_DEFAULT = 40 * time.Millisecond
+	CSMA_LIMIT         = -90.0 // Channel is free below this RSSI, dBm
+	CSMA_TIMEOUT       = 1000 * time.Millisecond
+
+	// Receive reads in slices, so that sends are not held off
+	RECEIVE_SLICE = 100 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Network) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug("<sensors.RFM69Network>Open{ network_id=%v node_id=%v freq_carrier=%v retries=%v retry_wait=%v promiscuous=%v }", config.NetworkID, config.NodeID, config.FreqCarrier, config.Retries, config.RetryWait, config.Promiscuous)
+
+	if config.Radio == nil {
+		return nil, gopi.ErrBadParameter
+	} else if config.NodeID == sensors.RFM_NETWORK_BROADCAST {
+		return nil, gopi.ErrBadParameter
+	} else if config.AESKey != nil && len(config.AESKey) != 16 {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(network)
+	this.log = log
+	this.radio = config.Radio
+	this.network_id = config.NetworkID
+	this.node_id = config.NodeID
+	this.retries = config.Retries
+	this.retry_wait = config.RetryWait
+	this.promiscuous = config.Promiscuous
+	if this.retry_wait == 0 {
+		this.retry_wait = RETRY_WAIT_DEFAULT
+	}
+	this.pending = make([]*sensors.RFMNetworkMessage, 0)
+
+	// Set up the radio
+	if err := this.setRadio(config.FreqCarrier, config.AESKey); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return this, nil
+}
+
+func (this *network) Close() error {
+	this.log.Debug("<sensors.RFM69Network>Close{ }")
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Put the radio into standby
+	if err := this.radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return err
+	}
+
+	// Free resources
+	this.radio = nil
+	this.pending = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *network) String() string {
+	return fmt.Sprintf("<sensors.RFM69Network>{ network_id=%v node_id=%v retries=%v retry_wait=%v promiscuous=%v }", this.network_id, this.node_id, this.retries, this.retry_wait, this.promiscuous)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *network) NetworkID() uint8 {
+	return this.network_id
+}
+
+func (this *network) NodeID() uint8 {
+	return this.node_id
+}
+
+func (this *network) Send(to uint8, data []byte, ack bool) error {
+	this.log.Debug("<sensors.RFM69Network>Send{ to=%v data=%v ack=%v }", to, strings.ToUpper(hex.EncodeToString(data)), ack)
+
+	if len(data) > sensors.RFM_NETWORK_PAYLOAD_MAX {
+		return gopi.ErrBadParameter
+	} else if ack && to == sensors.RFM_NETWORK_BROADCAST {
+		// Broadcasts cannot be acknowledged
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if ack == false {
+		return this.send(to, data, 0)
+	}
+
+	// Send and wait for the acknowledgement, retrying on timeout
+	for attempt := uint(0); attempt <= this.retries; attempt++ {
+		if err := this.send(to, data, NETWORK_CTL_REQACK); err != nil {
+			return err
+		} else if acked, err := this.waitACK(to); err != nil {
+			return err
+		} else if acked {
+			return nil
+		}
+		this.log.Debug2("sensors.RFM69Network.Send: no acknowledgement from node %v, attempt %v", to, attempt+1)
+	}
+
+	return sensors.ErrDeviceTimeout
+}
+
+func (this *network) Receive(ctx context.Context) (*sensors.RFMNetworkMessage, error) {
+	this.log.Debug2("<sensors.RFM69Network>Receive{ }")
+
+	for {
+		this.lock.Lock()
+		message, err := this.receive(ctx)
+		this.lock.Unlock()
+
+		if err != nil {
+			return nil, err
+		} else if message != nil {
+			return message, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+			break
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *network) setRadio(freq_carrier uint, aes_key []byte) error {
+	if err := this.radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return err
+	} else if err := this.radio.SetModulation(sensors.RFM_MODULATION_FSK); err != nil {
+		return err
+	} else if err := this.radio.SetSequencer(true); err != nil {
+		return err
+	} else if err := this.radio.SetBitrate(NETWORK_BITRATE); err != nil {
+		return err
+	} else if err := this.radio.SetFreqDeviation(NETWORK_FREQ_DEVIATION); err != nil {
+		return err
+	} else if err := this.radio.SetRXFilter(sensors.RFM_RXBW_FREQUENCY_FSK_125P0, sensors.RFM_RXBW_CUTOFF_4); err != nil {
+		return err
+	} else if err := this.radio.SetDataMode(sensors.RFM_DATAMODE_PACKET); err != nil {
+		return err
+	} else if err := this.radio.SetPacketFormat(sensors.RFM_PACKET_FORMAT_VARIABLE); err != nil {
+		return err
+	} else if err := this.radio.SetPacketCoding(sensors.RFM_PACKET_CODING_NONE); err != nil {
+		return err
+	} else if err := this.radio.SetPacketFilter(sensors.RFM_PACKET_FILTER_NONE); err != nil {
+		return err
+	} else if err := this.radio.SetPacketCRC(sensors.RFM_PACKET_CRC_AUTOCLEAR_ON); err != nil {
+		return err
+	} else if err := this.radio.SetPreambleSize(NETWORK_PREAMBLE_SIZE); err != nil {
+		return err
+	} else if err := this.radio.SetPayloadSize(NETWORK_PAYLOAD_LENGTH); err != nil {
+		return err
+	} else if err := this.radio.SetAESKey(aes_key); err != nil {
+		return err
+	} else if err := this.radio.SetSyncWord([]byte{NETWORK_SYNCWORD, this.network_id}); err != nil {
+		return err
+	} else if err := this.radio.SetSyncTolerance(0); err != nil {
+		return err
+	}
+
+	// Set the carrier frequency
+	if freq_carrier != 0 {
+		if err := this.radio.SetFreqCarrier(freq_carrier); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// send transmits a packet with the header and control byte, waiting for
+// the channel to be free first, and then returns to RX. It is called
+// with the lock held
+func (this *network) send(to uint8, data []byte, ctl uint8) error {
+	packet := make([]byte, 0, len(data)+NETWORK_HEADER_SIZE+1)
+	packet = append(packet, uint8(len(data)+NETWORK_HEADER_SIZE), to, this.node_id, ctl)
+	packet = append(packet, data...)
+
+	if err := this.waitChannel(); err != nil {
+		return err
+	} else if err := this.radio.SetMode(sensors.RFM_MODE_TX); err != nil {
+		return err
+	} else if err := this.radio.WritePayload(packet, 1); err != nil {
+		return err
+	} else if err := this.radio.SetMode(sensors.RFM_MODE_RX); err != nil {
+		return err
+	}
+
+	// Success
+	return nil
+}
+
+// waitChannel waits for the RSSI to drop below the CSMA limit, and
+// transmits anyway when the channel does not become free
+func (this *network) waitChannel() error {
+	if err := this.radio.SetMode(sensors.RFM_MODE_RX); err != nil {
+		return err
+	}
+	timeout := time.Now().Add(CSMA_TIMEOUT)
+	for time.Now().Before(timeout) {
+		if rssi, err := this.radio.MeasureRSSI(); err != nil {
+			return err
+		} else if rssi < CSMA_LIMIT {
+			return nil
+		}
+	}
+	this.log.Debug2("sensors.RFM69Network.waitChannel: channel busy after %v", CSMA_TIMEOUT)
+	return nil
+}
+
+// waitACK returns true when an acknowledgement is received from a node
+// within the retry wait. Other messages received are acknowledged and
+// kept for Receive
+func (this *network) waitACK(from uint8) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), this.retry_wait)
+	defer cancel()
+
+	for {
+		if packet, err := this.radio.ReadPacket(ctx); err != nil {
+			return false, err
+		} else if packet == nil {
+			// Timeout
+			return false, nil
+		} else if message, ctl := this.decode(packet); message == nil {
+			continue
+		} else if ctl&NETWORK_CTL_SENDACK != 0 {
+			if message.From == from && message.To == this.node_id {
+				return true, nil
+			}
+		} else if err := this.accept(message); err != nil {
+			return false, err
+		} else {
+			this.pending = append(this.pending, message)
+		}
+	}
+}
+
+// receive returns a message which was received while waiting for an
+// acknowledgement, or reads for up to the receive slice. It is called
+// with the lock held, and returns nil if nothing was received
+func (this *network) receive(ctx context.Context) (*sensors.RFMNetworkMessage, error) {
+	if len(this.pending) > 0 {
+		message := this.pending[0]
+		this.pending = this.pending[1:]
+		return message, nil
+	}
+
+	// Switch into RX mode
+	if this.radio.Mode() != sensors.RFM_MODE_RX {
+		if err := this.radio.SetMode(sensors.RFM_MODE_RX); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, RECEIVE_SLICE)
+	defer cancel()
+	if packet, err := this.radio.ReadPacket(ctx); err != nil {
+		return nil, err
+	} else if packet == nil {
+		return nil, nil
+	} else if message, ctl := this.decode(packet); message == nil {
+		return nil, nil
+	} else if ctl&NETWORK_CTL_SENDACK != 0 {
+		// Ignore late acknowledgements
+		return nil, nil
+	} else if err := this.accept(message); err != nil {
+		return nil, err
+	} else {
+		return message, nil
+	}
+}
+
+// accept sends an acknowledgement to the sender of a message when it
+// was requested and the message was sent to this node
+func (this *network) accept(message *sensors.RFMNetworkMessage) error {
+	if message.ACKRequested == false || message.To != this.node_id {
+		return nil
+	}
+	return this.send(message.From, nil, NETWORK_CTL_SENDACK)
+}
+
+// decode returns the message and control byte from a received packet,
+// or nil if the packet is invalid or not addressed to this node
+func (this *network) decode(packet *sensors.RFMPacket) (*sensors.RFMNetworkMessage, uint8) {
+	data := packet.Payload
+	if packet.CRCOk == false {
+		return nil, 0
+	} else if len(data) < NETWORK_HEADER_SIZE+1 || int(data[0]) < NETWORK_HEADER_SIZE || int(data[0]) >= len(data) {
+		this.log.Debug2("sensors.RFM69Network.decode: invalid packet %v", strings.ToUpper(hex.EncodeToString(data)))
+		return nil, 0
+	}
+
+	// Remove any trailing bytes after the length
+	data = data[:data[0]+1]
+	to, from, ctl := data[1], data[2], data[3]
+	if this.promiscuous == false && to != this.node_id && to != sensors.RFM_NETWORK_BROADCAST {
+		return nil, 0
+	}
+
+	return &sensors.RFMNetworkMessage{
+		Timestamp:    packet.Timestamp,
+		To:           to,
+		From:         from,
+		ACKRequested: ctl&NETWORK_CTL_REQACK != 0 && to != sensors.RFM_NETWORK_BROADCAST,
+		Payload:      append([]byte(nil), data[NETWORK_HEADER_SIZE+1:]...),
+		RSSI:         packet.RSSI,
+	}, ctl
+}
@@ -12,6 +12,7 @@ package rfm69
 import (
 	"time"
 
+	"github.com/djthorpe/gopi"
 	"github.com/djthorpe/sensors"
 )
 
@@ -33,7 +34,53 @@ func (this *rfm69) AFCRoutine() sensors.RFMAFCRoutine {
 	return this.afc_routine
 }
 
-// Set AFC Routine
+// Return the offset applied by the improved (low-beta) AFC routine, in Hz
+func (this *rfm69) AFCLowBetaOffset() int {
+	return int(this.afc_lowbeta_offset) * RFM_AFCOFFSET_STEP
+}
+
+// Set the offset applied by the improved (low-beta) AFC routine, in Hz.
+// The offset is rounded to the nearest 488Hz step, and is only used when
+// the AFC routine is RFM_AFCROUTINE_IMPROVED
+func (this *rfm69) SetAFCLowBetaOffset(hz int) error {
+	this.log.Debug("<sensors.RFM69.SetAFCLowBetaOffset>{ hz=%v }", hz)
+
+	// Round to the nearest step
+	var value int
+	if hz < 0 {
+		value = (hz - RFM_AFCOFFSET_STEP/2) / RFM_AFCOFFSET_STEP
+	} else {
+		value = (hz + RFM_AFCOFFSET_STEP/2) / RFM_AFCOFFSET_STEP
+	}
+	if value < -128 || value > 127 {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setAFCLowBetaOffset(int8(value)); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.getAFCLowBetaOffset(); err != nil {
+		return err
+	} else if int8(value) != value_read {
+		this.log.Debug2("SetAFCLowBetaOffset expecting value=%v, got=%v", value, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.afc_lowbeta_offset = value_read
+	}
+
+	// Success
+	return nil
+}
+
+// Set AFC Routine. The improved routine is for low modulation index
+// (low-beta) systems, and applies the low-beta offset
 func (this *rfm69) SetAFCRoutine(afc_routine sensors.RFMAFCRoutine) error {
 	this.log.Debug("<sensors.RFM69.SetAFCRoutine>{ afc_routine=%v }", afc_routine)
 
@@ -56,11 +103,28 @@ func (this *rfm69) SetAFCRoutine(afc_routine sensors.RFMAFCRoutine) error {
 		this.afc_routine = afc_routine
 	}
 
+	// The offset register is rewritten when the improved routine is
+	// selected, as it may have been reset with the device
+	if afc_routine == sensors.RFM_AFCROUTINE_IMPROVED {
+		if err := this.setAFCLowBetaOffset(this.afc_lowbeta_offset); err != nil {
+			return err
+		}
+	}
+
+	// The DAGC setting depends on the routine
+	if this.dagc {
+		if err := this.setDAGC(true, afc_routine); err != nil {
+			return err
+		}
+	}
+
 	// Success
 	return nil
 }
 
-// Set AFC Mode
+// Set AFC Mode. When AFC is on, the correction is made automatically
+// each time the receiver starts, so the receiver is restarted if it is
+// already running
 func (this *rfm69) SetAFCMode(afc_mode sensors.RFMAFCMode) error {
 	this.log.Debug("<sensors.RFM69.SetAFCMode>{ afc_mode=%v }", afc_mode)
 
@@ -92,6 +156,10 @@ func (this *rfm69) SetAFCMode(afc_mode sensors.RFMAFCMode) error {
 		} else {
 			this.afc = afc
 		}
+	} else if this.mode == sensors.RFM_MODE_RX {
+		if err := this.restartRX(); err != nil {
+			return err
+		}
 	}
 
 	// Success
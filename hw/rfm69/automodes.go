@@ -0,0 +1,185 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Time to wait for each packet to be sent, and the polling interval
+	RFM_AUTOMODE_TIMEOUT = time.Second
+	RFM_AUTOMODE_POLL    = time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return the AutoModes enter condition, exit condition and intermediate mode
+func (this *rfm69) AutoModes() (sensors.RFMAutoModeEnter, sensors.RFMAutoModeExit, sensors.RFMAutoModeIntermediate) {
+	return this.automode_enter, this.automode_exit, this.automode_intermediate
+}
+
+// SetAutoModes sets the conditions on which the radio enters and exits
+// the intermediate mode. The radio returns to the mode set with SetMode
+// on the exit condition. Use RFM_AUTOMODE_ENTER_OFF and
+// RFM_AUTOMODE_EXIT_OFF to switch AutoModes off
+func (this *rfm69) SetAutoModes(enter sensors.RFMAutoModeEnter, exit sensors.RFMAutoModeExit, intermediate sensors.RFMAutoModeIntermediate) error {
+	this.log.Debug("<sensors.RFM69.SetAutoModes>{ enter=%v exit=%v intermediate=%v }", enter, exit, intermediate)
+
+	if enter > sensors.RFM_AUTOMODE_ENTER_MAX || exit > sensors.RFM_AUTOMODE_EXIT_MAX || intermediate > sensors.RFM_AUTOMODE_INTERMEDIATE_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setAutoModes(enter, exit, intermediate); err != nil {
+		return err
+	}
+
+	// Read
+	if enter_read, exit_read, intermediate_read, err := this.getAutoModes(); err != nil {
+		return err
+	} else if enter_read != enter {
+		this.log.Debug2("SetAutoModes expecting enter=%v, got=%v", enter, enter_read)
+		return sensors.ErrUnexpectedResponse
+	} else if exit_read != exit {
+		this.log.Debug2("SetAutoModes expecting exit=%v, got=%v", exit, exit_read)
+		return sensors.ErrUnexpectedResponse
+	} else if intermediate_read != intermediate {
+		this.log.Debug2("SetAutoModes expecting intermediate=%v, got=%v", intermediate, intermediate_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.automode_enter = enter_read
+		this.automode_exit = exit_read
+		this.automode_intermediate = intermediate_read
+	}
+
+	// Success
+	return nil
+}
+
+// WriteAutoPayload sends the payload a number of times from standby. The
+// radio enters TX when the whole payload is in the FIFO and returns to
+// standby when the packet is sent, so that each burst starts and stops
+// without waiting for the host. The AutoModes and FIFO threshold are
+// restored afterwards
+func (this *rfm69) WriteAutoPayload(data []byte, repeat uint) error {
+	this.log.Debug("<sensors.RFM69.WriteAutoPayload>{ data=%v repeat=%v }", strings.ToUpper(hex.EncodeToString(data)), repeat)
+
+	// Ensure we're in standby mode or else return "OutOfOrder" message
+	if this.mode != sensors.RFM_MODE_STDBY {
+		return gopi.ErrOutOfOrder
+	}
+
+	// Check repeat and length
+	if repeat < 1 {
+		return gopi.ErrBadParameter
+	} else if length := len(data); length == 0 || length > RFM_FIFO_SIZE {
+		this.log.Debug2("sensors.RFM69.WriteAutoPayload: data length is %v, expected 0 < length <= %v", length, RFM_FIFO_SIZE)
+		return gopi.ErrBadParameter
+	} else if this.aes_on && length > RFM_AES_PAYLOAD_MAX {
+		this.log.Debug2("sensors.RFM69.WriteAutoPayload: data length is %v, expected <= %v when AES is enabled", length, RFM_AES_PAYLOAD_MAX)
+		return gopi.ErrBadParameter
+	}
+
+	// FifoLevel rises when the FIFO holds more than the threshold, which
+	// is when the last byte of the payload is written
+	enter, exit, intermediate := this.AutoModes()
+	fifo_threshold := this.fifo_threshold
+	if err := this.SetFIFOThreshold(uint8(len(data) - 1)); err != nil {
+		return err
+	} else if err := this.SetAutoModes(sensors.RFM_AUTOMODE_ENTER_FIFOLEVEL, sensors.RFM_AUTOMODE_EXIT_PACKETSENT, sensors.RFM_AUTOMODE_INTERMEDIATE_TX); err != nil {
+		this.SetFIFOThreshold(fifo_threshold)
+		return err
+	}
+
+	// Send, then restore the settings and return the first error
+	errs := []error{
+		this.writeAutoPayload(data, repeat),
+		this.SetAutoModes(enter, exit, intermediate),
+		this.SetFIFOThreshold(fifo_threshold),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *rfm69) writeAutoPayload(data []byte, repeat uint) error {
+	// The high power boost registers are set for the intermediate TX mode,
+	// and cleared again before returning
+	this.lock.Lock()
+	err := this.setHighPower(this.pa_boost)
+	this.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		this.lock.Lock()
+		defer this.lock.Unlock()
+		this.setHighPower(false)
+	}()
+
+	// Write each repeat once the previous packet has been sent
+	for i := uint(0); i < repeat; i++ {
+		this.lock.Lock()
+		err := this.writeFIFO(data)
+		this.lock.Unlock()
+		if err != nil {
+			return err
+		} else if err := this.waitAutoModeExit(); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// waitAutoModeExit polls until the radio has left the intermediate mode
+// and the FIFO is empty, or returns ErrDeviceTimeout
+func (this *rfm69) waitAutoModeExit() error {
+	timeout := time.Now().Add(RFM_AUTOMODE_TIMEOUT)
+	for {
+		this.lock.Lock()
+		automode, err := this.getIRQFlags1(RFM_IRQFLAGS1_AUTOMODE)
+		empty := false
+		if err == nil {
+			empty, err = this.recvFIFOEmpty()
+		}
+		this.lock.Unlock()
+		if err != nil {
+			return err
+		} else if automode == 0 && empty {
+			return nil
+		} else if time.Now().After(timeout) {
+			return sensors.ErrDeviceTimeout
+		}
+		time.Sleep(RFM_AUTOMODE_POLL)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"math"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Exact frequency synthesizer step, FXOSC / 2^19
+	RFM_FSTEP = RFM_FXOSC_MHZ * 1e6 / (1 << 19)
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return the bitrate achieved by the register value, and the error from
+// the bitrate requested with SetBitrate in ppm, or zero when the register
+// value was set directly
+func (this *rfm69) BitrateActual() (float64, float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	actual := BitrateFromRegister(this.bitrate)
+	return actual, ErrorPPM(actual, this.bitrate_request)
+}
+
+// Return the frequency deviation achieved by the register value, and the
+// error from the deviation requested with SetFreqDeviation in ppm, or zero
+// when the register value was set directly
+func (this *rfm69) FreqDeviationActual() (float64, float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	actual := FreqDeviationFromRegister(this.fdev)
+	return actual, ErrorPPM(actual, this.fdev_request)
+}
+
+// BitrateToRegister returns the register value closest to a bitrate. The
+// bitrate must be between RFM_BITRATE_MIN and RFM_BITRATE_MAX
+func BitrateToRegister(bits_per_second uint) uint16 {
+	return uint16(math.Round(RFM_FXOSC_MHZ * 1e6 / float64(bits_per_second)))
+}
+
+// BitrateFromRegister returns the bitrate in bits per second for a
+// register value
+func BitrateFromRegister(value uint16) float64 {
+	if value == 0 {
+		return 0
+	}
+	return RFM_FXOSC_MHZ * 1e6 / float64(value)
+}
+
+// FreqDeviationToRegister returns the register value closest to a
+// frequency deviation in Hz, which may be larger than RFM_FDEV_MAX
+func FreqDeviationToRegister(hertz uint) uint32 {
+	return uint32(math.Round(float64(hertz) / RFM_FSTEP))
+}
+
+// FreqDeviationFromRegister returns the frequency deviation in Hz for a
+// register value
+func FreqDeviationFromRegister(value uint16) float64 {
+	return float64(value) * RFM_FSTEP
+}
+
+// ErrorPPM returns the difference between an achieved and a requested
+// value in parts per million, or zero when nothing was requested
+func ErrorPPM(actual float64, requested uint) float64 {
+	if requested == 0 {
+		return 0
+	}
+	return (actual - float64(requested)) * 1e6 / float64(requested)
+}
@@ -0,0 +1,194 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// config_step writes one setting when it differs from the radio
+type config_step struct {
+	changed func() bool
+	set     func() error
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Config returns a snapshot of the radio configuration
+func (this *rfm69) Config() sensors.RFM69Config {
+	return ReadConfig(this)
+}
+
+// SetConfig restores a configuration snapshot. The radio is put into
+// standby while the settings are written, and then returned to the mode
+// it was in, except that listen mode is switched off. Only the settings
+// which differ from the radio are written. On error, the radio is left
+// in standby with the settings written so far
+func (this *rfm69) SetConfig(config sensors.RFM69Config) error {
+	this.log.Debug("<sensors.RFM69.SetConfig>{ config=%+v }", config)
+	return WriteConfig(this, config)
+}
+
+// ReadConfig returns a snapshot of the configuration of any radio
+func ReadConfig(radio sensors.RFM69) sensors.RFM69Config {
+	rx_start, rssi_thresh := radio.RXTimeout()
+	ook_type, ook_step, ook_dec := radio.OOKThreshold()
+	enter, exit, intermediate := radio.AutoModes()
+	lowbat_on, lowbat_trim := radio.LowBattery()
+	return sensors.RFM69Config{
+		DataMode:             radio.DataMode(),
+		Modulation:           radio.Modulation(),
+		Sequencer:            radio.SequencerEnabled(),
+		Bitrate:              radio.Bitrate(),
+		FreqCarrier:          radio.FreqCarrier(),
+		FreqDeviation:        radio.FreqDeviation(),
+		RadioHead:            radio.RadioHead(),
+		PacketFormat:         radio.PacketFormat(),
+		PacketCoding:         radio.PacketCoding(),
+		PacketFilter:         radio.PacketFilter(),
+		PacketCRC:            radio.PacketCRC(),
+		NodeAddress:          radio.NodeAddress(),
+		BroadcastAddress:     radio.BroadcastAddress(),
+		PreambleSize:         radio.PreambleSize(),
+		PayloadSize:          radio.PayloadSize(),
+		AESKey:               copy_bytes(radio.AESKey()),
+		SyncWord:             copy_bytes(radio.SyncWord()),
+		SyncTolerance:        radio.SyncTolerance(),
+		AFCMode:              radio.AFCMode(),
+		AFCRoutine:           radio.AFCRoutine(),
+		AFCLowBetaOffset:     radio.AFCLowBetaOffset(),
+		OutputPower:          radio.OutputPower(),
+		PARamp:               radio.PARamp(),
+		LNAImpedance:         radio.LNAImpedance(),
+		LNAGain:              radio.LNAGain(),
+		SensitivityBoost:     radio.SensitivityBoost(),
+		DAGC:                 radio.DAGC(),
+		RSSIThreshold:        radio.RSSIThreshold(),
+		RXTimeoutStart:       rx_start,
+		RXTimeoutRSSI:        rssi_thresh,
+		RXFilterFrequency:    radio.RXFilterFrequency(),
+		RXFilterCutoff:       radio.RXFilterCutoff(),
+		OOKThresholdType:     ook_type,
+		OOKThresholdStep:     ook_step,
+		OOKThresholdDec:      ook_dec,
+		OOKFixedThreshold:    radio.OOKFixedThreshold(),
+		OOKAverageFilter:     radio.OOKAverageFilter(),
+		FIFOThreshold:        radio.FIFOThreshold(),
+		AutoModeEnter:        enter,
+		AutoModeExit:         exit,
+		AutoModeIntermediate: intermediate,
+		ClockOut:             radio.ClockOutput(),
+		LowBattery:           lowbat_on,
+		LowBatteryTrim:       lowbat_trim,
+	}
+}
+
+// WriteConfig restores a configuration snapshot to any radio, as
+// described for SetConfig. Each setting is compared with the radio just
+// before it is written, since RadioHead mode and the AES key change
+// other settings. RadioHead mode is written first so that the packet
+// settings in the snapshot take precedence
+func WriteConfig(radio sensors.RFM69, config sensors.RFM69Config) error {
+	mode := radio.Mode()
+	if err := radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return err
+	}
+
+	steps := []config_step{
+		{func() bool { return radio.RadioHead() != config.RadioHead }, func() error { return radio.SetRadioHead(config.RadioHead) }},
+		{func() bool { return radio.DataMode() != config.DataMode }, func() error { return radio.SetDataMode(config.DataMode) }},
+		{func() bool { return radio.Modulation() != config.Modulation }, func() error { return radio.SetModulation(config.Modulation) }},
+		{func() bool { return radio.SequencerEnabled() != config.Sequencer }, func() error { return radio.SetSequencer(config.Sequencer) }},
+		{func() bool { return radio.Bitrate() != config.Bitrate }, func() error { return radio.SetBitrate(config.Bitrate) }},
+		{func() bool { return radio.FreqCarrier() != config.FreqCarrier }, func() error { return radio.SetFreqCarrier(config.FreqCarrier) }},
+		{func() bool { return radio.FreqDeviation() != config.FreqDeviation }, func() error { return radio.SetFreqDeviation(config.FreqDeviation) }},
+		{func() bool { return radio.PacketFormat() != config.PacketFormat }, func() error { return radio.SetPacketFormat(config.PacketFormat) }},
+		{func() bool { return radio.PacketCoding() != config.PacketCoding }, func() error { return radio.SetPacketCoding(config.PacketCoding) }},
+		{func() bool { return radio.PacketFilter() != config.PacketFilter }, func() error { return radio.SetPacketFilter(config.PacketFilter) }},
+		{func() bool { return radio.PacketCRC() != config.PacketCRC }, func() error { return radio.SetPacketCRC(config.PacketCRC) }},
+		{func() bool { return radio.NodeAddress() != config.NodeAddress }, func() error { return radio.SetNodeAddress(config.NodeAddress) }},
+		{func() bool { return radio.BroadcastAddress() != config.BroadcastAddress }, func() error { return radio.SetBroadcastAddress(config.BroadcastAddress) }},
+		{func() bool { return radio.PreambleSize() != config.PreambleSize }, func() error { return radio.SetPreambleSize(config.PreambleSize) }},
+		// The AES key reduces the payload size, so it is written first
+		{func() bool { return matches_byte_array(radio.AESKey(), config.AESKey) == false }, func() error { return radio.SetAESKey(config.AESKey) }},
+		{func() bool { return radio.PayloadSize() != config.PayloadSize }, func() error { return radio.SetPayloadSize(config.PayloadSize) }},
+		{func() bool { return matches_byte_array(radio.SyncWord(), config.SyncWord) == false }, func() error { return radio.SetSyncWord(config.SyncWord) }},
+		{func() bool { return radio.SyncTolerance() != config.SyncTolerance }, func() error { return radio.SetSyncTolerance(config.SyncTolerance) }},
+		{func() bool { return radio.AFCMode() != config.AFCMode }, func() error { return radio.SetAFCMode(config.AFCMode) }},
+		{func() bool { return radio.AFCRoutine() != config.AFCRoutine }, func() error { return radio.SetAFCRoutine(config.AFCRoutine) }},
+		{func() bool { return radio.AFCLowBetaOffset() != config.AFCLowBetaOffset }, func() error { return radio.SetAFCLowBetaOffset(config.AFCLowBetaOffset) }},
+		{func() bool { return radio.OutputPower() != config.OutputPower }, func() error { return radio.SetOutputPower(config.OutputPower) }},
+		{func() bool { return radio.PARamp() != config.PARamp }, func() error { return radio.SetPARamp(config.PARamp) }},
+		{func() bool {
+			return radio.LNAImpedance() != config.LNAImpedance || radio.LNAGain() != config.LNAGain
+		}, func() error { return radio.SetLNA(config.LNAImpedance, config.LNAGain) }},
+		{func() bool { return radio.SensitivityBoost() != config.SensitivityBoost }, func() error { return radio.SetSensitivityBoost(config.SensitivityBoost) }},
+		{func() bool { return radio.DAGC() != config.DAGC }, func() error { return radio.SetDAGC(config.DAGC) }},
+		{func() bool { return radio.RSSIThreshold() != config.RSSIThreshold }, func() error { return radio.SetRSSIThreshold(config.RSSIThreshold) }},
+		{func() bool {
+			rx_start, rssi_thresh := radio.RXTimeout()
+			return rx_start != config.RXTimeoutStart || rssi_thresh != config.RXTimeoutRSSI
+		}, func() error { return radio.SetRXTimeout(config.RXTimeoutStart, config.RXTimeoutRSSI) }},
+		{func() bool {
+			return radio.RXFilterFrequency() != config.RXFilterFrequency || radio.RXFilterCutoff() != config.RXFilterCutoff
+		}, func() error { return radio.SetRXFilter(config.RXFilterFrequency, config.RXFilterCutoff) }},
+		{func() bool {
+			ook_type, ook_step, ook_dec := radio.OOKThreshold()
+			return ook_type != config.OOKThresholdType || ook_step != config.OOKThresholdStep || ook_dec != config.OOKThresholdDec
+		}, func() error {
+			return radio.SetOOKThreshold(config.OOKThresholdType, config.OOKThresholdStep, config.OOKThresholdDec)
+		}},
+		{func() bool { return radio.OOKFixedThreshold() != config.OOKFixedThreshold }, func() error { return radio.SetOOKFixedThreshold(config.OOKFixedThreshold) }},
+		{func() bool { return radio.OOKAverageFilter() != config.OOKAverageFilter }, func() error { return radio.SetOOKAverageFilter(config.OOKAverageFilter) }},
+		{func() bool { return radio.FIFOThreshold() != config.FIFOThreshold }, func() error { return radio.SetFIFOThreshold(config.FIFOThreshold) }},
+		{func() bool {
+			enter, exit, intermediate := radio.AutoModes()
+			return enter != config.AutoModeEnter || exit != config.AutoModeExit || intermediate != config.AutoModeIntermediate
+		}, func() error {
+			return radio.SetAutoModes(config.AutoModeEnter, config.AutoModeExit, config.AutoModeIntermediate)
+		}},
+		{func() bool { return radio.ClockOutput() != config.ClockOut }, func() error { return radio.SetClockOutput(config.ClockOut) }},
+		{func() bool {
+			lowbat_on, lowbat_trim := radio.LowBattery()
+			return lowbat_on != config.LowBattery || lowbat_trim != config.LowBatteryTrim
+		}, func() error { return radio.SetLowBattery(config.LowBattery, config.LowBatteryTrim) }},
+	}
+	for _, step := range steps {
+		if step.changed() == false {
+			continue
+		} else if err := step.set(); err != nil {
+			return err
+		}
+	}
+
+	// Return to the previous mode
+	if mode != sensors.RFM_MODE_STDBY {
+		return radio.SetMode(mode)
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// copy_bytes returns a copy of a byte slice, or nil
+func copy_bytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append([]byte(nil), data...)
+}
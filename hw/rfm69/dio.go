@@ -0,0 +1,128 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Mapping register values for the signals on each DIO pin in packet
+	// mode. The same value outputs a different signal in other modes
+	dio_mappings = map[sensors.RFMDIOPin]map[sensors.RFMDIOFunction]uint8{
+		sensors.RFM_DIO0: {
+			sensors.RFM_DIO_FUNC_CRCOK:        0x00,
+			sensors.RFM_DIO_FUNC_PACKETSENT:   0x00,
+			sensors.RFM_DIO_FUNC_PAYLOADREADY: 0x01,
+			sensors.RFM_DIO_FUNC_TXREADY:      0x01,
+			sensors.RFM_DIO_FUNC_SYNCADDRESS:  0x02,
+			sensors.RFM_DIO_FUNC_RSSI:         0x03,
+			sensors.RFM_DIO_FUNC_PLLLOCK:      0x03,
+		},
+		sensors.RFM_DIO1: {
+			sensors.RFM_DIO_FUNC_FIFOLEVEL:    0x00,
+			sensors.RFM_DIO_FUNC_FIFOFULL:     0x01,
+			sensors.RFM_DIO_FUNC_FIFONOTEMPTY: 0x02,
+			sensors.RFM_DIO_FUNC_PLLLOCK:      0x03,
+			sensors.RFM_DIO_FUNC_TIMEOUT:      0x03,
+		},
+		sensors.RFM_DIO2: {
+			sensors.RFM_DIO_FUNC_FIFONOTEMPTY: 0x00,
+			sensors.RFM_DIO_FUNC_DATA:         0x01,
+			sensors.RFM_DIO_FUNC_LOWBAT:       0x02,
+			sensors.RFM_DIO_FUNC_AUTOMODE:     0x03,
+		},
+		sensors.RFM_DIO3: {
+			sensors.RFM_DIO_FUNC_FIFOFULL:    0x00,
+			sensors.RFM_DIO_FUNC_RSSI:        0x01,
+			sensors.RFM_DIO_FUNC_TXREADY:     0x01,
+			sensors.RFM_DIO_FUNC_SYNCADDRESS: 0x02,
+			sensors.RFM_DIO_FUNC_PLLLOCK:     0x03,
+		},
+		sensors.RFM_DIO4: {
+			sensors.RFM_DIO_FUNC_TIMEOUT:   0x00,
+			sensors.RFM_DIO_FUNC_MODEREADY: 0x00,
+			sensors.RFM_DIO_FUNC_RSSI:      0x01,
+			sensors.RFM_DIO_FUNC_TXREADY:   0x01,
+			sensors.RFM_DIO_FUNC_RXREADY:   0x02,
+			sensors.RFM_DIO_FUNC_PLLLOCK:   0x03,
+		},
+		sensors.RFM_DIO5: {
+			sensors.RFM_DIO_FUNC_CLKOUT:    0x00,
+			sensors.RFM_DIO_FUNC_DATA:      0x01,
+			sensors.RFM_DIO_FUNC_LOWBAT:    0x02,
+			sensors.RFM_DIO_FUNC_MODEREADY: 0x03,
+		},
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return the signal routed onto a DIO pin, or RFM_DIO_FUNC_NONE if the
+// mapping has not been set
+func (this *rfm69) DIOMapping(pin sensors.RFMDIOPin) sensors.RFMDIOFunction {
+	if pin > sensors.RFM_DIO_MAX {
+		return sensors.RFM_DIO_FUNC_NONE
+	}
+	return this.dio_mapping[pin]
+}
+
+// SetDIOMapping routes a signal onto a DIO pin. It returns
+// ErrBadParameter if the signal is not available on the pin
+func (this *rfm69) SetDIOMapping(pin sensors.RFMDIOPin, function sensors.RFMDIOFunction) error {
+	this.log.Debug("<sensors.RFM69.SetDIOMapping>{ pin=%v function=%v }", pin, function)
+
+	value, exists := DIOMappingValue(pin, function)
+	if exists == false {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// DIO0 is mapped to PayloadReady for the interrupt
+	if pin == sensors.RFM_DIO0 && this.dio0_events != nil {
+		return gopi.ErrOutOfOrder
+	}
+
+	if err := this.setDIOMapping(pin, value); err != nil {
+		return err
+	} else if value_read, err := this.getDIOMapping(pin); err != nil {
+		return err
+	} else if value_read != value {
+		this.log.Debug2("SetDIOMapping: expected 0x%02X got 0x%02X", value, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.dio_mapping[pin] = function
+	}
+
+	// Success
+	return nil
+}
+
+// DIOMappingValue returns the mapping register value which routes a
+// signal onto a DIO pin, and false if the signal is not available on
+// the pin
+func DIOMappingValue(pin sensors.RFMDIOPin, function sensors.RFMDIOFunction) (uint8, bool) {
+	if mappings, exists := dio_mappings[pin]; exists == false {
+		return 0, false
+	} else if value, exists := mappings[function]; exists == false {
+		return 0, false
+	} else {
+		return value, true
+	}
+}
@@ -0,0 +1,83 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// DumpRegisters reads every named register except the FIFO, so that
+// reading does not consume received data
+func (this *rfm69) DumpRegisters() ([]sensors.RFMRegisterValue, error) {
+	this.log.Debug("<sensors.RFM69.DumpRegisters>{ }")
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	values := make([]sensors.RFMRegisterValue, 0, RFM_REG_MAX)
+	for reg := RFM_REG_OPMODE; reg <= RFM_REG_MAX; reg++ {
+		name := reg.String()
+		if strings.HasPrefix(name, "RFM_REG_") == false {
+			continue
+		} else if value, err := this.readreg_uint8(reg); err != nil {
+			return nil, err
+		} else {
+			values = append(values, sensors.RFMRegisterValue{Register: uint8(reg), Name: name, Value: value})
+		}
+	}
+	return values, nil
+}
+
+// ReadRegister reads any register. It is only available when the
+// driver is opened with Debug set
+func (this *rfm69) ReadRegister(reg uint8) (uint8, error) {
+	this.log.Debug("<sensors.RFM69.ReadRegister>{ reg=0x%02X }", reg)
+
+	if this.debug_registers == false {
+		return 0, gopi.ErrNotImplemented
+	} else if register(reg) > RFM_REG_MAX {
+		return 0, gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.readreg_uint8(register(reg))
+}
+
+// WriteRegister writes any register. It is only available when the
+// driver is opened with Debug set. The driver does not update its own
+// copy of the configuration, so values returned by other methods may
+// no longer reflect the device
+func (this *rfm69) WriteRegister(reg, value uint8) error {
+	this.log.Debug("<sensors.RFM69.WriteRegister>{ reg=0x%02X value=0x%02X }", reg, value)
+
+	if this.debug_registers == false {
+		return gopi.ErrNotImplemented
+	} else if register(reg) > RFM_REG_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.log.Warn("<sensors.RFM69>WriteRegister{ reg=%v value=0x%02X }", register(reg), value)
+	return this.writereg_uint8(register(reg), value)
+}
@@ -0,0 +1,187 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// debug_pubsub delivers radio events to subscribers. Events are
+// dropped when a subscriber is not keeping up, so that the radio
+// is never stalled by debugging
+type debug_pubsub struct {
+	subscribers []chan sensors.RFMEvent
+	lock        sync.Mutex
+}
+
+type rfm_event struct {
+	driver     *rfm69
+	ts         time.Time
+	event_type sensors.RFMEventType
+	mode       sensors.RFMMode
+	irqflags1  uint8
+	irqflags2  uint8
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	RFM_DEBUG_BUFFER = 64
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *rfm69) SubscribeDebug() <-chan sensors.RFMEvent {
+	this.debug.lock.Lock()
+	defer this.debug.lock.Unlock()
+
+	c := make(chan sensors.RFMEvent, RFM_DEBUG_BUFFER)
+	this.debug.subscribers = append(this.debug.subscribers, c)
+	return c
+}
+
+func (this *rfm69) UnsubscribeDebug(subscriber <-chan sensors.RFMEvent) {
+	this.debug.lock.Lock()
+	defer this.debug.lock.Unlock()
+
+	for i, c := range this.debug.subscribers {
+		if c == subscriber {
+			close(c)
+			this.debug.subscribers = append(this.debug.subscribers[:i], this.debug.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// emitDebug sends an event to debug subscribers
+func (this *rfm69) emitDebug(event_type sensors.RFMEventType) {
+	this.debug.lock.Lock()
+	defer this.debug.lock.Unlock()
+	if len(this.debug.subscribers) == 0 {
+		return
+	}
+
+	event := &rfm_event{
+		driver:     this,
+		ts:         time.Now(),
+		event_type: event_type,
+		mode:       this.mode,
+		irqflags1:  this.irqflags1,
+		irqflags2:  this.irqflags2,
+	}
+	for _, c := range this.debug.subscribers {
+		select {
+		case c <- event:
+			break
+		default:
+			break
+		}
+	}
+}
+
+// observeIRQFlags1 records the IRQFlags1 register value and emits events
+// for changes and newly set sync address match
+func (this *rfm69) observeIRQFlags1(value uint8) {
+	if value == this.irqflags1 {
+		return
+	}
+	sync_match := value&^this.irqflags1&RFM_IRQFLAGS1_SYNCADDRESSMATCH != 0
+	this.irqflags1 = value
+	this.emitDebug(sensors.RFM_EVENT_IRQ)
+	if sync_match {
+		this.emitDebug(sensors.RFM_EVENT_SYNC)
+	}
+}
+
+// observeIRQFlags2 records the IRQFlags2 register value and emits events
+// for changes, newly set FIFO overrun and low battery
+func (this *rfm69) observeIRQFlags2(value uint8) {
+	if value == this.irqflags2 {
+		return
+	}
+	overrun := value&^this.irqflags2&RFM_IRQFLAGS2_FIFOOVERRUN != 0
+	lowbat := value&^this.irqflags2&RFM_IRQFLAGS2_LOWBAT != 0
+	this.irqflags2 = value
+	this.emitDebug(sensors.RFM_EVENT_IRQ)
+	if overrun {
+		this.emitDebug(sensors.RFM_EVENT_FIFO_OVERRUN)
+	}
+	if lowbat {
+		this.observeLowBattery(true)
+	}
+}
+
+// observeLowBattery records the low battery state and emits an event
+// when the supply voltage drops below the threshold
+func (this *rfm69) observeLowBattery(value bool) {
+	if value && this.low_battery == false {
+		this.emitDebug(sensors.RFM_EVENT_LOW_BATTERY)
+	}
+	this.low_battery = value
+}
+
+func (this *rfm69) closeDebug() {
+	this.debug.lock.Lock()
+	defer this.debug.lock.Unlock()
+
+	for _, c := range this.debug.subscribers {
+		close(c)
+	}
+	this.debug.subscribers = nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - rfm_event
+
+func (this *rfm_event) Name() string {
+	return "RFMEvent"
+}
+
+func (this *rfm_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *rfm_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *rfm_event) Type() sensors.RFMEventType {
+	return this.event_type
+}
+
+func (this *rfm_event) Mode() sensors.RFMMode {
+	return this.mode
+}
+
+func (this *rfm_event) IRQFlags1() uint8 {
+	return this.irqflags1
+}
+
+func (this *rfm_event) IRQFlags2() uint8 {
+	return this.irqflags2
+}
+
+func (this *rfm_event) String() string {
+	return fmt.Sprintf("<sensors.RFMEvent>{ type=%v mode=%v irqflags1=0x%02X irqflags2=0x%02X }", this.event_type, this.mode, this.irqflags1, this.irqflags2)
+}
@@ -79,34 +79,45 @@ func (this *rfm69) ReadPayload(ctx context.Context) ([]byte, bool, error) {
 	this.lock.Lock()
 	defer this.lock.Unlock()
 
-	// Ensure we're in RX mode or else return "OutOfOrder" message
-	if this.mode != sensors.RFM_MODE_RX {
-		this.log.Debug("Expected mode=%v, got %v", sensors.RFM_MODE_RX, this.mode)
-		return nil, false, gopi.ErrOutOfOrder
+	// Read the packet
+	if packet, err := this.readPacket(ctx); err != nil {
+		return nil, false, err
+	} else if packet == nil {
+		return nil, false, nil
+	} else {
+		return packet.Payload, packet.CRCOk, nil
 	}
+}
 
-	// Check FIFO every 100 milliseconds
-	interval := time.NewTicker(100 * time.Millisecond)
-	defer interval.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			// Context finished without FIFO
-			return nil, false, nil
-		case <-interval.C:
-			// Check Payload
-			if payload_ready, err := this.recvPayloadReady(); err != nil {
-				return nil, false, err
-			} else if payload_ready == false {
-				continue
-			} else if data, err := this.recvFIFO(); err != nil {
-				return nil, false, err
-			} else if crc_ok, err := this.recvCRCOk(); err != nil {
-				return nil, false, err
-			} else {
-				return data, crc_ok, nil
-			}
-		}
+// ReadPayloadTimeout waits up to the timeout for a payload, and returns
+// a nil payload if none was received
+func (this *rfm69) ReadPayloadTimeout(timeout time.Duration) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return this.ReadPayload(ctx)
+}
+
+// TryReadPayload returns a payload if one is pending, or a nil payload
+// without waiting, so that reception can be polled from an event loop.
+// A nil payload is also returned when another call is using the radio
+func (this *rfm69) TryReadPayload() ([]byte, bool, error) {
+	this.log.Debug("<sensors.RFM69.TryReadPayload>{ }")
+
+	// Mutex lock, or return if the radio is busy
+	if this.lock.TryLock() == false {
+		return nil, false, nil
+	}
+	defer this.lock.Unlock()
+
+	// Read the packet without waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if packet, err := this.readPacket(ctx); err != nil {
+		return nil, false, err
+	} else if packet == nil {
+		return nil, false, nil
+	} else {
+		return packet.Payload, packet.CRCOk, nil
 	}
 }
 
@@ -143,13 +154,19 @@ func (this *rfm69) WritePayload(data []byte, repeat uint) error {
 	if length := len(data); length == 0 || length > RFM_FIFO_SIZE {
 		this.log.Debug2("sensors.RFM69.WritePayload: data length is %v, expected 0 < length <= %v", length, RFM_FIFO_SIZE)
 		return gopi.ErrBadParameter
+	} else if this.aes_on && length > RFM_AES_PAYLOAD_MAX {
+		this.log.Debug2("sensors.RFM69.WritePayload: data length is %v, expected <= %v when AES is enabled", length, RFM_AES_PAYLOAD_MAX)
+		return gopi.ErrBadParameter
 	} else if err := this.SetFIFOThreshold(uint8(length)); err != nil {
 		return err
 	}
 
-	// Send repeatedly
+	// Send repeatedly, writing the payload in a single burst transfer
 	for i := uint(0); i < repeat; i++ {
-		if err := this.WriteFIFO(data); err != nil {
+		this.lock.Lock()
+		err := this.writeFIFO(data)
+		this.lock.Unlock()
+		if err != nil {
 			return err
 		}
 
@@ -10,7 +10,11 @@
 package rfm69
 
 import (
+	"fmt"
+
+	// Frameworks
 	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -22,10 +26,95 @@ func init() {
 		Name:     "sensors/rfm69",
 		Requires: []string{"spi"},
 		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			radioFlags(config, "rfm69")
+		},
 		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
-			return gopi.Open(RFM69{
-				SPI: app.ModuleInstance("spi").(gopi.SPI),
-			}, app.Logger)
+			return newRadio(app, "rfm69", app.ModuleInstance("spi").(gopi.SPI))
 		},
 	})
 }
+
+// radioFlags registers the flags for a radio, with names which start
+// with the prefix so that each radio in a process has its own flags
+func radioFlags(config *gopi.AppConfig, prefix string) {
+	config.AppFlags.FlagUint(prefix+".spi.mode", uint(RFM_SPI_MODE), "SPI mode (0-3)")
+	config.AppFlags.FlagUint(prefix+".spi.speed", 0, "SPI clock speed in Hz, or zero for board default")
+	config.AppFlags.FlagDuration(prefix+".spi.delay", 0, "Settle delay after each SPI transfer")
+	config.AppFlags.FlagBool(prefix+".verify", false, "Read back configuration registers after writing")
+	config.AppFlags.FlagUint(prefix+".retries", RFM_VERIFY_RETRIES_DEFAULT, "Retries for failed SPI transfers and writes which fail verification")
+	config.AppFlags.FlagBool(prefix+".highpower", false, "High power module (RFM69HW or RFM69HCW)")
+	config.AppFlags.FlagBool(prefix+".debug", false, "Allow raw register reads and writes")
+	config.AppFlags.FlagBool(prefix+".radiohead", false, "RadioHead RF69 compatible packets")
+	config.AppFlags.FlagString(prefix+".clkout", "", "Clock output on DIO5 (off, rc, 1, 2, 4, 8, 16, 32), or empty to leave unchanged")
+}
+
+// newRadio opens a radio on an SPI device, with the flags registered
+// by radioFlags for the prefix
+func newRadio(app *gopi.AppInstance, prefix string, spi gopi.SPI) (gopi.Driver, error) {
+	mode, _ := app.AppFlags.GetUint(prefix + ".spi.mode")
+	speed, _ := app.AppFlags.GetUint(prefix + ".spi.speed")
+	delay, _ := app.AppFlags.GetDuration(prefix + ".spi.delay")
+	verify, _ := app.AppFlags.GetBool(prefix + ".verify")
+	retries, _ := app.AppFlags.GetUint(prefix + ".retries")
+	high_power, _ := app.AppFlags.GetBool(prefix + ".highpower")
+	debug, _ := app.AppFlags.GetBool(prefix + ".debug")
+	radiohead, _ := app.AppFlags.GetBool(prefix + ".radiohead")
+	clkout, err := clockOut(app, prefix)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := gopi.Open(RFM69{
+		SPI:       spi,
+		Mode:      gopi.SPIMode(mode),
+		Speed:     uint32(speed),
+		Delay:     delay,
+		Verify:    verify,
+		Retries:   retries,
+		HighPower: high_power,
+		Debug:     debug,
+		ClockOut:  clkout,
+	}, app.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Switch on RadioHead packet mode
+	if radiohead {
+		if err := driver.(*rfm69).SetRadioHead(true); err != nil {
+			driver.Close()
+			return nil, err
+		}
+	}
+	return driver, nil
+}
+
+// clockOut returns the clock output from the clkout flag, which is
+// off, rc or the crystal oscillator divider, or nil when not set
+func clockOut(app *gopi.AppInstance, prefix string) (*sensors.RFMClockOut, error) {
+	value, _ := app.AppFlags.GetString(prefix + ".clkout")
+	var clkout sensors.RFMClockOut
+	switch value {
+	case "":
+		return nil, nil
+	case "off":
+		clkout = sensors.RFM_CLKOUT_OFF
+	case "rc":
+		clkout = sensors.RFM_CLKOUT_RC
+	case "1":
+		clkout = sensors.RFM_CLKOUT_FXOSC
+	case "2":
+		clkout = sensors.RFM_CLKOUT_FXOSC_2
+	case "4":
+		clkout = sensors.RFM_CLKOUT_FXOSC_4
+	case "8":
+		clkout = sensors.RFM_CLKOUT_FXOSC_8
+	case "16":
+		clkout = sensors.RFM_CLKOUT_FXOSC_16
+	case "32":
+		clkout = sensors.RFM_CLKOUT_FXOSC_32
+	default:
+		return nil, fmt.Errorf("Invalid -%v.clkout flag", prefix)
+	}
+	return &clkout, nil
+}
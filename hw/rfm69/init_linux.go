@@ -0,0 +1,68 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	linux "github.com/djthorpe/gopi/sys/hw/linux"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// instance is a radio which owns its SPI device, and closes it
+type instance struct {
+	*rfm69
+	spi gopi.Driver
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register a second RFM69 on its own SPI device, so that a process
+	// can use two radios such as a 433MHz and an 868MHz module. The
+	// "spi" module can only open one device, so this module opens the
+	// device from its own flags, which like the radio flags start with
+	// rfm69.1
+	gopi.RegisterModule(gopi.Module{
+		Name: "sensors/rfm69/1",
+		Type: gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagUint("rfm69.1.spi.bus", 0, "SPI bus")
+			config.AppFlags.FlagUint("rfm69.1.spi.slave", 1, "SPI chip select")
+			radioFlags(config, "rfm69.1")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			bus, _ := app.AppFlags.GetUint("rfm69.1.spi.bus")
+			slave, _ := app.AppFlags.GetUint("rfm69.1.spi.slave")
+			if spi, err := gopi.Open(linux.SPI{Bus: bus, Slave: slave}, app.Logger); err != nil {
+				return nil, err
+			} else if driver, err := newRadio(app, "rfm69.1", spi.(gopi.SPI)); err != nil {
+				spi.Close()
+				return nil, err
+			} else {
+				return &instance{driver.(*rfm69), spi}, nil
+			}
+		},
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (this *instance) Close() error {
+	err := this.rfm69.Close()
+	if err_spi := this.spi.Close(); err == nil {
+		err = err_spi
+	}
+	return err
+}
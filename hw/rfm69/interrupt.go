@@ -0,0 +1,120 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"context"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// DIO0 mapping in RX packet mode
+	RFM_DIO0_RX_PAYLOADREADY = 0x01
+
+	// Interval to check the payload when waiting for an interrupt, in
+	// case an edge is missed
+	RFM_INTERRUPT_POLL = 1000 * time.Millisecond
+
+	// Interval to check the payload when polling
+	RFM_PAYLOAD_POLL = 100 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// SetInterrupt configures reception on the rising edge of DIO0, which is
+// mapped to PayloadReady. ReadPayload then reads the FIFO only when the
+// interrupt fires. A pin of GPIO_PIN_NONE reverts to polling
+func (this *rfm69) SetInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error {
+	this.log.Debug("<sensors.RFM69.SetInterrupt>{ pin=%v }", pin)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setInterrupt(gpio, pin)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *rfm69) setInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error {
+	// Remove any existing watcher
+	if this.dio0_gpio != nil {
+		this.dio0_gpio.Watch(this.dio0, gopi.GPIO_EDGE_NONE)
+		this.dio0_gpio.Unsubscribe(this.dio0_events)
+		this.dio0_gpio = nil
+		this.dio0_events = nil
+		this.dio0 = gopi.GPIO_PIN_NONE
+	}
+
+	// Revert to polling
+	if pin == gopi.GPIO_PIN_NONE {
+		return nil
+	} else if gpio == nil {
+		return gopi.ErrBadParameter
+	}
+
+	// Map DIO0 to PayloadReady and watch for rising edge
+	if err := this.setDIO0Mapping(RFM_DIO0_RX_PAYLOADREADY); err != nil {
+		return err
+	} else {
+		this.dio_mapping[sensors.RFM_DIO0] = sensors.RFM_DIO_FUNC_PAYLOADREADY
+	}
+	gpio.SetPinMode(pin, gopi.GPIO_INPUT)
+	if err := gpio.Watch(pin, gopi.GPIO_EDGE_RISING); err != nil {
+		return err
+	}
+	this.dio0_gpio = gpio
+	this.dio0_events = gpio.Subscribe()
+	this.dio0 = pin
+
+	// Success
+	return nil
+}
+
+// waitPayload blocks until the payload may be ready, or the context is
+// done, in which case it returns false. When an interrupt is configured
+// it waits for the DIO0 edge and records the time it arrived, otherwise
+// it waits for the poll interval
+func (this *rfm69) waitPayload(ctx context.Context) bool {
+	if this.dio0_events == nil {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(RFM_PAYLOAD_POLL):
+			return true
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(RFM_INTERRUPT_POLL):
+			return true
+		case evt, ok := <-this.dio0_events:
+			if ok == false {
+				// GPIO has been closed, revert to polling
+				this.dio0_events = nil
+				return true
+			} else if evt, ok := evt.(gopi.GPIOEvent); ok && evt.Pin() == this.dio0 {
+				this.dio0_ts = time.Now()
+				return true
+			}
+		}
+	}
+}
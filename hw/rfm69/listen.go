@@ -0,0 +1,158 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// listen_resolution is a ListenResolIdle or ListenResolRx value and the
+// duration of each step
+type listen_resolution struct {
+	value uint8
+	step  time.Duration
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// RegListen1 criteria: RSSI threshold, or RSSI threshold and sync address
+	RFM_LISTEN_CRITERIA_RSSI = 0x00
+	RFM_LISTEN_CRITERIA_SYNC = 0x01
+
+	// RegListen1 end: stay in RX after PayloadReady, then resume listen mode
+	RFM_LISTEN_END_RESUME = 0x02
+
+	// Maximum ListenCoefIdle or ListenCoefRx value
+	RFM_LISTEN_COEF_MAX = 0xFF
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Resolutions, from finest to coarsest
+	listen_resolutions = []listen_resolution{
+		{0x01, 64 * time.Microsecond},
+		{0x02, 4100 * time.Microsecond},
+		{0x03, 262 * time.Millisecond},
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// EnterListenMode duty cycles the receiver, sleeping in idle for the idle
+// duration and then receiving for the rx duration. When a sync word is set,
+// the radio only stays in RX when the sync word matches. After each payload
+// is received the radio resumes listen mode, and ReadPayload emits a
+// RFM_EVENT_LISTEN_WAKE debug event. When entered from standby, the RC
+// oscillator which times the durations is calibrated first. Call
+// SetListenOn(false) to stop
+func (this *rfm69) EnterListenMode(idle, rx time.Duration) error {
+	this.log.Debug("<sensors.RFM69.EnterListenMode>{ idle=%v rx=%v }", idle, rx)
+
+	// Set listen registers
+	if err := this.setListenDurations(idle, rx); err != nil {
+		return err
+	}
+
+	// Switch on listen mode
+	return this.SetListenOn(true)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *rfm69) setListenDurations(idle, rx time.Duration) error {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	criteria := uint8(RFM_LISTEN_CRITERIA_RSSI)
+	if this.sync_on {
+		criteria = RFM_LISTEN_CRITERIA_SYNC
+	}
+
+	// Calibrate the RC oscillator, which can only be done in standby
+	if this.mode == sensors.RFM_MODE_STDBY {
+		if err := this.calibrateRC(); err != nil {
+			return err
+		}
+	}
+
+	if resol_idle, coef_idle, err := listenCoef(idle); err != nil {
+		this.log.Debug2("EnterListenMode: invalid idle duration %v", idle)
+		return err
+	} else if resol_rx, coef_rx, err := listenCoef(rx); err != nil {
+		this.log.Debug2("EnterListenMode: invalid rx duration %v", rx)
+		return err
+	} else if err := this.setListen(resol_idle, resol_rx, criteria, RFM_LISTEN_END_RESUME, coef_idle, coef_rx); err != nil {
+		return err
+	}
+
+	// Map DIO0 to PayloadReady, which wakes the host
+	if this.dio0_events != nil {
+		if err := this.setDIO0Mapping(RFM_DIO0_RX_PAYLOADREADY); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// listenCoef returns the finest resolution and coefficient which
+// represents a duration, or an error if the duration is out of range
+func listenCoef(duration time.Duration) (uint8, uint8, error) {
+	if duration <= 0 {
+		return 0, 0, gopi.ErrBadParameter
+	}
+	for _, resolution := range listen_resolutions {
+		coef := (duration + resolution.step/2) / resolution.step
+		if coef == 0 {
+			coef = 1
+		}
+		if coef <= RFM_LISTEN_COEF_MAX {
+			return resolution.value, uint8(coef), nil
+		}
+	}
+	return 0, 0, gopi.ErrBadParameter
+}
+
+// setListen writes the RegListen1, RegListen2 and RegListen3 registers
+func (this *rfm69) setListen(resol_idle, resol_rx, criteria, end, coef_idle, coef_rx uint8) error {
+	value := (resol_idle&0x03)<<6 | (resol_rx&0x03)<<4 | (criteria&0x01)<<3 | (end&0x03)<<1
+	if err := this.writereg_uint8(RFM_REG_LISTEN1, value); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM_REG_LISTEN2, coef_idle); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM_REG_LISTEN3, coef_rx); err != nil {
+		return err
+	}
+
+	// Success
+	return nil
+}
+
+// listenWake is called when a payload is received in listen mode
+func (this *rfm69) listenWake() {
+	if this.listen_on {
+		this.emitDebug(sensors.RFM_EVENT_LISTEN_WAKE)
+	}
+}
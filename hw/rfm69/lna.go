@@ -13,6 +13,18 @@ package rfm69
 
 import "github.com/djthorpe/sensors"
 
+const (
+	// RegTestLna values for normal and high sensitivity
+	RFM_TESTLNA_NORMAL = 0x1B
+	RFM_TESTLNA_BOOST  = 0x2D
+
+	// RegTestDagc values for continuous DAGC off, and on with the
+	// improved (low-beta) AFC routine or the standard routine
+	RFM_TESTDAGC_OFF     = 0x00
+	RFM_TESTDAGC_LOWBETA = 0x20
+	RFM_TESTDAGC_ON      = 0x30
+)
+
 func (this *rfm69) LNAImpedance() sensors.RFMLNAImpedance {
 	return this.lna_impedance
 }
@@ -56,3 +68,64 @@ func (this *rfm69) SetLNA(impedance sensors.RFMLNAImpedance, gain sensors.RFMLNA
 	}
 	return nil
 }
+
+func (this *rfm69) SensitivityBoost() bool {
+	return this.sensitivity_boost
+}
+
+// SetSensitivityBoost switches the LNA into high sensitivity mode, which
+// improves reception of weak signals by about 3dB
+func (this *rfm69) SetSensitivityBoost(enabled bool) error {
+	this.log.Debug("<sensors.RFM69.SetSensitivityBoost{ enabled=%v }", enabled)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setSensitivityBoost(enabled); err != nil {
+		return err
+	}
+
+	// Read
+	if enabled_read, err := this.getSensitivityBoost(); err != nil {
+		return err
+	} else if enabled_read != enabled {
+		this.log.Debug2("SetSensitivityBoost expecting enabled=%v, got=%v", enabled, enabled_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.sensitivity_boost = enabled
+	}
+	return nil
+}
+
+func (this *rfm69) DAGC() bool {
+	return this.dagc
+}
+
+// SetDAGC switches continuous digital automatic gain control on or off,
+// which improves the fading margin when receiving. The register value
+// follows the AFC routine, and is rewritten when the routine is changed
+func (this *rfm69) SetDAGC(enabled bool) error {
+	this.log.Debug("<sensors.RFM69.SetDAGC{ enabled=%v }", enabled)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setDAGC(enabled, this.afc_routine); err != nil {
+		return err
+	}
+
+	// Read
+	if enabled_read, err := this.getDAGC(); err != nil {
+		return err
+	} else if enabled_read != enabled {
+		this.log.Debug2("SetDAGC expecting enabled=%v, got=%v", enabled, enabled_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.dagc = enabled
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return whether the low battery detector is on, and the threshold
+func (this *rfm69) LowBattery() (bool, sensors.RFMLowBatTrim) {
+	return this.lowbat_on, this.lowbat_trim
+}
+
+// SetLowBattery switches the low battery detector on or off and sets the
+// threshold, for receivers which run from a battery
+func (this *rfm69) SetLowBattery(enabled bool, trim sensors.RFMLowBatTrim) error {
+	this.log.Debug("<sensors.RFM69.SetLowBattery>{ enabled=%v trim=%v }", enabled, trim)
+
+	if trim > sensors.RFM_LOWBAT_TRIM_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if err := this.setLowBattery(enabled, trim); err != nil {
+		return err
+	} else if lowbat_on, lowbat_trim, err := this.getLowBattery(); err != nil {
+		return err
+	} else if lowbat_on != enabled || lowbat_trim != trim {
+		this.log.Debug2("SetLowBattery: expected enabled=%v trim=%v got enabled=%v trim=%v", enabled, trim, lowbat_on, lowbat_trim)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.lowbat_on = lowbat_on
+		this.lowbat_trim = lowbat_trim
+	}
+
+	// Success
+	return nil
+}
+
+// MeasureLowBattery returns true if the supply voltage is below the
+// threshold, and emits RFM_EVENT_LOW_BATTERY when it first drops below.
+// The detector needs to be switched on
+func (this *rfm69) MeasureLowBattery() (bool, error) {
+	this.log.Debug("<sensors.RFM69.MeasureLowBattery>{ }")
+
+	if this.lowbat_on == false {
+		return false, gopi.ErrOutOfOrder
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if value, err := this.getLowBatteryMonitor(); err != nil {
+		return false, err
+	} else {
+		this.observeLowBattery(value)
+		return value, nil
+	}
+}
+
+// PLLLocked returns true if the frequency synthesizer is locked, which
+// is only the case in FS, TX and RX modes
+func (this *rfm69) PLLLocked() (bool, error) {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if value, err := this.getIRQFlags1(RFM_IRQFLAGS1_PLLLOCK); err != nil {
+		return false, err
+	} else {
+		return to_uint8_bool(value), nil
+	}
+}
@@ -0,0 +1,44 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package mock
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register in-memory RFM69, which needs no hardware
+	gopi.RegisterModule(gopi.Module{
+		Name: "sensors/rfm69:mock",
+		Type: gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagBool("rfm69.highpower", false, "High power module (RFM69HW or RFM69HCW)")
+			config.AppFlags.FlagBool("rfm69.debug", false, "Allow raw register writes")
+			config.AppFlags.FlagFloat64("rfm69.mock.temperature", MOCK_TEMPERATURE, "Temperature returned by the mock radio, Celcius")
+			config.AppFlags.FlagFloat64("rfm69.mock.rssi", MOCK_RSSI, "RSSI returned by the mock radio, dBm")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			high_power, _ := app.AppFlags.GetBool("rfm69.highpower")
+			debug, _ := app.AppFlags.GetBool("rfm69.debug")
+			temperature, _ := app.AppFlags.GetFloat64("rfm69.mock.temperature")
+			rssi, _ := app.AppFlags.GetFloat64("rfm69.mock.rssi")
+			return gopi.Open(Mock{
+				HighPower:   high_power,
+				Debug:       debug,
+				Temperature: float32(temperature),
+				RSSI:        float32(rssi),
+			}, app.Logger)
+		},
+	})
+}
@@ -0,0 +1,399 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package mock implements sensors.RFM69 entirely in memory, so that
+// drivers, protocols and tools which use the radio can be tested without
+// hardware. Received packets are injected with Inject or InjectPacket, and
+// transmitted payloads are captured and returned by Transmitted
+package mock
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/hw/rfm69"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Mock struct {
+	HighPower   bool    // Output power limits of the high power variants
+	Temperature float32 // Temperature returned by MeasureTemperature
+	RSSI        float32 // RSSI returned by MeasureRSSI and attached to packets
+	Debug       bool    // Allow register writes
+	LowBattery  bool    // Supply voltage below the low battery threshold
+}
+
+// Transmission is a payload captured by WritePayload or WriteAutoPayload,
+// with the carrier frequency and packet configuration it would have been
+// sent with
+type Transmission struct {
+	Timestamp    time.Time
+	FreqCarrier  uint
+	Modulation   sensors.RFMModulation
+	PacketFormat sensors.RFMPacketFormat
+	PayloadSize  uint8
+	SyncWord     []byte
+	Payload      []byte
+	Repeat       uint
+}
+
+// RFM69 is the mock driver interface, which adds methods for injecting
+// received packets and returning captured transmissions
+type RFM69 interface {
+	sensors.RFM69
+
+	// Inject a received payload, with the CRC marked as correct
+	Inject(payload []byte)
+
+	// Inject a received packet
+	InjectPacket(packet *sensors.RFMPacket)
+
+	// Return transmitted payloads, and clear them
+	Transmitted() []Transmission
+}
+
+// mock driver
+type mock struct {
+	log         gopi.Logger
+	high_power  bool
+	temperature float32
+	rssi        float32
+	debug_on    bool
+
+	// Radio state
+	mode                sensors.RFMMode
+	data_mode           sensors.RFMDataMode
+	modulation          sensors.RFMModulation
+	bitrate             uint
+	freq_carrier        uint
+	freq_dev            uint
+	sequencer_on        bool
+	listen_on           bool
+	packet_format       sensors.RFMPacketFormat
+	packet_coding       sensors.RFMPacketCoding
+	packet_filter       sensors.RFMPacketFilter
+	packet_crc          sensors.RFMPacketCRC
+	radiohead           bool
+	node_addr           uint8
+	broadcast_addr      uint8
+	preamble_size       uint16
+	payload_size        uint8
+	aes_key             []byte
+	sync_word           []byte
+	sync_tol            uint8
+	afc                 uint
+	afc_mode            sensors.RFMAFCMode
+	afc_routine         sensors.RFMAFCRoutine
+	afc_lowbeta_offset  int
+	output_power        int
+	pa_ramp             sensors.RFMPARamp
+	lna_impedance       sensors.RFMLNAImpedance
+	lna_gain            sensors.RFMLNAGain
+	sensitivity_boost   bool
+	dagc                bool
+	rssi_threshold      float32
+	rx_timeout_start    time.Duration
+	rx_timeout_rssi     time.Duration
+	rxbw_frequency      sensors.RFMRXBWFrequency
+	rxbw_cutoff         sensors.RFMRXBWCutoff
+	ook_threshold_type  sensors.RFMOOKThresholdType
+	ook_threshold_step  sensors.RFMOOKThresholdStep
+	ook_threshold_dec   sensors.RFMOOKThresholdDecrement
+	ook_fixed_threshold uint8
+	ook_average_filter  sensors.RFMOOKAverageFilter
+	fifo_threshold      uint8
+	automode_enter      sensors.RFMAutoModeEnter
+	automode_exit       sensors.RFMAutoModeExit
+	automode_inter      sensors.RFMAutoModeIntermediate
+	clkout              sensors.RFMClockOut
+	lowbat_on           bool
+	lowbat_trim         sensors.RFMLowBatTrim
+	low_battery         bool
+	low_battery_seen    bool
+	dio_mapping         [sensors.RFM_DIO_MAX + 1]sensors.RFMDIOFunction
+	registers           [MOCK_REGISTER_COUNT]uint8
+
+	// Injected packets, data written to the FIFO and captured transmissions
+	rx     []*sensors.RFMPacket
+	fifo   []byte
+	tx     []Transmission
+	signal chan struct{}
+
+	// Debug subscribers
+	subscribers []chan sensors.RFMEvent
+
+	lock sync.Mutex
+}
+
+type mock_event struct {
+	driver     *mock
+	ts         time.Time
+	event_type sensors.RFMEventType
+	mode       sensors.RFMMode
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	MOCK_VERSION_VALUE   = rfm69.RFM_VERSION_VALUE
+	MOCK_REGISTER_COUNT  = 0x72
+	MOCK_DEBUG_BUFFER    = 64
+	MOCK_TEMPERATURE     = 20.0
+	MOCK_RSSI            = -60.0
+	MOCK_BITRATE         = 4800
+	MOCK_FREQ_CARRIER    = 915000000
+	MOCK_FREQ_DEVIATION  = 5000
+	MOCK_PREAMBLE_SIZE   = 3
+	MOCK_PAYLOAD_SIZE    = 0x40
+	MOCK_FIFO_THRESHOLD  = 0x0F
+	MOCK_OUTPUT_POWER    = 13
+	MOCK_RSSI_THRESHOLD  = -114.0
+	MOCK_OOK_FIXED_DB    = 6
+	MOCK_RX_TIMEOUT_UNIT = 16 // Bit periods per RX timeout step
+)
+
+// Check the driver implements the interface
+var _ RFM69 = (*mock)(nil)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Mock) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug("<sensors.RFM69.Mock>Open{ high_power=%v temperature=%v rssi=%v }", config.HighPower, config.Temperature, config.RSSI)
+
+	this := new(mock)
+	this.log = log
+	this.high_power = config.HighPower
+	this.temperature = config.Temperature
+	this.rssi = config.RSSI
+	this.debug_on = config.Debug
+	this.low_battery = config.LowBattery
+	if this.temperature == 0 {
+		this.temperature = MOCK_TEMPERATURE
+	}
+	if this.rssi == 0 {
+		this.rssi = MOCK_RSSI
+	}
+
+	// Power-on defaults
+	this.mode = sensors.RFM_MODE_STDBY
+	this.clkout = sensors.RFM_CLKOUT_OFF
+	this.lowbat_trim = sensors.RFM_LOWBAT_TRIM_1835
+	this.data_mode = sensors.RFM_DATAMODE_PACKET
+	this.modulation = sensors.RFM_MODULATION_FSK
+	this.bitrate = MOCK_BITRATE
+	this.freq_carrier = MOCK_FREQ_CARRIER
+	this.freq_dev = MOCK_FREQ_DEVIATION
+	this.sequencer_on = true
+	this.packet_format = sensors.RFM_PACKET_FORMAT_FIXED
+	this.packet_crc = sensors.RFM_PACKET_CRC_AUTOCLEAR_ON
+	this.preamble_size = MOCK_PREAMBLE_SIZE
+	this.payload_size = MOCK_PAYLOAD_SIZE
+	this.sync_word = []byte{0x01, 0x01, 0x01, 0x01}
+	this.output_power = MOCK_OUTPUT_POWER
+	this.pa_ramp = sensors.RFM_PARAMP_40US
+	this.lna_impedance = sensors.RFM_LNA_IMPEDANCE_100
+	this.lna_gain = sensors.RFM_LNA_GAIN_AUTO
+	this.rssi_threshold = MOCK_RSSI_THRESHOLD
+	this.rxbw_frequency = sensors.RFM_RXBW_FREQUENCY_FSK_10P4
+	this.rxbw_cutoff = sensors.RFM_RXBW_CUTOFF_4
+	this.ook_threshold_type = sensors.RFM_OOK_THRESHOLD_PEAK
+	this.ook_fixed_threshold = MOCK_OOK_FIXED_DB
+	this.fifo_threshold = MOCK_FIFO_THRESHOLD
+	this.signal = make(chan struct{}, 1)
+
+	// Return success
+	return this, nil
+}
+
+func (this *mock) Close() error {
+	this.log.Debug("<sensors.RFM69.Mock>Close{ }")
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Close debug subscribers
+	for _, c := range this.subscribers {
+		close(c)
+	}
+
+	// Free resources
+	this.subscribers = nil
+	this.rx = nil
+	this.fifo = nil
+	this.tx = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *mock) String() string {
+	return fmt.Sprintf("<sensors.RFM69.Mock>{ mode=%v modulation=%v freq_carrier=%v bitrate=%v rx=%v tx=%v }", this.mode, this.modulation, this.freq_carrier, this.bitrate, len(this.rx), len(this.tx))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INJECTION AND CAPTURE
+
+func (this *mock) Inject(payload []byte) {
+	this.InjectPacket(&sensors.RFMPacket{
+		Payload: payload,
+		CRCOk:   true,
+	})
+}
+
+func (this *mock) InjectPacket(packet *sensors.RFMPacket) {
+	this.log.Debug2("<sensors.RFM69.Mock>InjectPacket{ payload=%v }", strings.ToUpper(hex.EncodeToString(packet.Payload)))
+
+	this.lock.Lock()
+	this.rx = append(this.rx, packet)
+	this.lock.Unlock()
+
+	// Wake any reader
+	select {
+	case this.signal <- struct{}{}:
+		break
+	default:
+		break
+	}
+}
+
+func (this *mock) Transmitted() []Transmission {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	tx := this.tx
+	this.tx = nil
+	return tx
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// nextPacket returns the next injected packet, or nil if there is none
+// or the radio is not receiving
+func (this *mock) nextPacket() *sensors.RFMPacket {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.mode != sensors.RFM_MODE_RX && this.listen_on == false {
+		return nil
+	} else if len(this.rx) == 0 {
+		return nil
+	}
+
+	// Copy the packet, filling in the measurements the radio would make
+	packet := *this.rx[0]
+	this.rx = this.rx[1:]
+	if packet.Timestamp.IsZero() {
+		packet.Timestamp = time.Now()
+	}
+	if packet.FreqCarrier == 0 {
+		packet.FreqCarrier = this.freq_carrier
+	}
+	if packet.RSSI == 0 {
+		packet.RSSI = this.rssi
+	}
+	if packet.CRCOk == false && this.packet_crc == sensors.RFM_PACKET_CRC_AUTOCLEAR_ON {
+		// The radio discards packets with a bad CRC when autoclear is on
+		return nil
+	}
+	if this.radiohead && packet.Header == nil && len(packet.Payload) > rfm69.RFM_RADIOHEAD_HEADER_SIZE {
+		packet.Header = &sensors.RFMRadioHeadHeader{
+			To:    packet.Payload[1],
+			From:  packet.Payload[2],
+			ID:    packet.Payload[3],
+			Flags: packet.Payload[4],
+		}
+		packet.Payload = packet.Payload[rfm69.RFM_RADIOHEAD_HEADER_SIZE+1:]
+	}
+	return &packet
+}
+
+// waitPacket blocks until a packet is injected, or the context is done,
+// in which case it returns nil
+func (this *mock) waitPacket(ctx context.Context) *sensors.RFMPacket {
+	for {
+		if packet := this.nextPacket(); packet != nil {
+			return packet
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-this.signal:
+			break
+		}
+	}
+}
+
+// emitDebug sends an event to debug subscribers, and is called with
+// the lock held
+func (this *mock) emitDebug(event_type sensors.RFMEventType) {
+	event := &mock_event{
+		driver:     this,
+		ts:         time.Now(),
+		event_type: event_type,
+		mode:       this.mode,
+	}
+	for _, c := range this.subscribers {
+		select {
+		case c <- event:
+			break
+		default:
+			break
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - mock_event
+
+func (this *mock_event) Name() string {
+	return "RFMEvent"
+}
+
+func (this *mock_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *mock_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *mock_event) Type() sensors.RFMEventType {
+	return this.event_type
+}
+
+func (this *mock_event) Mode() sensors.RFMMode {
+	return this.mode
+}
+
+func (this *mock_event) IRQFlags1() uint8 {
+	return 0
+}
+
+func (this *mock_event) IRQFlags2() uint8 {
+	return 0
+}
+
+func (this *mock_event) String() string {
+	return fmt.Sprintf("<sensors.RFMEvent>{ type=%v mode=%v }", this.event_type, this.mode)
+}
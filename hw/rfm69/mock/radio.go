@@ -0,0 +1,1096 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package mock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/hw/rfm69"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// MODE, DATA MODE AND MODULATION
+
+// Mode is locked, so that tests can wait for a mode whilst another
+// goroutine uses the radio
+func (this *mock) Mode() sensors.RFMMode {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.mode
+}
+
+func (this *mock) DataMode() sensors.RFMDataMode {
+	return this.data_mode
+}
+
+func (this *mock) Modulation() sensors.RFMModulation {
+	return this.modulation
+}
+
+func (this *mock) SetMode(mode sensors.RFMMode) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetMode>{ mode=%v }", mode)
+	if mode > sensors.RFM_MODE_RX {
+		return gopi.ErrBadParameter
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if mode != sensors.RFM_MODE_SLEEP {
+		this.listen_on = false
+	}
+	if mode != this.mode {
+		this.mode = mode
+		this.emitDebug(sensors.RFM_EVENT_MODE)
+	}
+	return nil
+}
+
+func (this *mock) SetDataMode(data_mode sensors.RFMDataMode) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetDataMode>{ data_mode=%v }", data_mode)
+	if data_mode > sensors.RFM_DATAMODE_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.data_mode = data_mode
+	return nil
+}
+
+func (this *mock) SetModulation(modulation sensors.RFMModulation) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetModulation>{ modulation=%v }", modulation)
+	if modulation > sensors.RFM_MODULATION_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.modulation = modulation
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BITRATE AND FREQUENCY
+
+// Bitrate returns the bitrate achieved by the register value, as the
+// radio does
+func (this *mock) Bitrate() uint {
+	bitrate, _ := this.BitrateActual()
+	return uint(math.Round(bitrate))
+}
+
+func (this *mock) FreqCarrier() uint {
+	return this.freq_carrier
+}
+
+func (this *mock) FreqDeviation() uint {
+	deviation, _ := this.FreqDeviationActual()
+	return uint(math.Round(deviation))
+}
+
+func (this *mock) BitrateActual() (float64, float64) {
+	actual := rfm69.BitrateFromRegister(rfm69.BitrateToRegister(this.bitrate))
+	return actual, rfm69.ErrorPPM(actual, this.bitrate)
+}
+
+func (this *mock) FreqDeviationActual() (float64, float64) {
+	actual := rfm69.FreqDeviationFromRegister(uint16(rfm69.FreqDeviationToRegister(this.freq_dev)))
+	return actual, rfm69.ErrorPPM(actual, this.freq_dev)
+}
+
+func (this *mock) SetBitrate(bits_per_second uint) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetBitrate>{ bitrate=%v }", bits_per_second)
+	if bits_per_second < rfm69.RFM_BITRATE_MIN || bits_per_second > rfm69.RFM_BITRATE_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.bitrate = bits_per_second
+	return nil
+}
+
+func (this *mock) SetFreqCarrier(hertz uint) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetFreqCarrier>{ hertz=%v }", hertz)
+	if hertz/rfm69.RFM_FSTEP_HZ > rfm69.RFM_FRF_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.freq_carrier = hertz
+	return nil
+}
+
+func (this *mock) SetFreqDeviation(hertz uint) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetFreqDeviation>{ hertz=%v }", hertz)
+	if rfm69.FreqDeviationToRegister(hertz) > rfm69.RFM_FDEV_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.freq_dev = hertz
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// LISTEN MODE AND SEQUENCER
+
+func (this *mock) SetSequencer(enabled bool) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.sequencer_on = enabled
+	return nil
+}
+
+func (this *mock) SequencerEnabled() bool {
+	return this.sequencer_on
+}
+
+func (this *mock) SetListenOn(value bool) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if value && this.mode != sensors.RFM_MODE_SLEEP {
+		this.mode = sensors.RFM_MODE_SLEEP
+		this.emitDebug(sensors.RFM_EVENT_MODE)
+	}
+	this.listen_on = value
+	return nil
+}
+
+func (this *mock) ListenOn() bool {
+	return this.listen_on
+}
+
+func (this *mock) EnterListenMode(idle, rx time.Duration) error {
+	this.log.Debug("<sensors.RFM69.Mock.EnterListenMode>{ idle=%v rx=%v }", idle, rx)
+	if idle <= 0 || rx <= 0 {
+		return gopi.ErrBadParameter
+	}
+	return this.SetListenOn(true)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PACKETS
+
+func (this *mock) PacketFormat() sensors.RFMPacketFormat {
+	return this.packet_format
+}
+
+func (this *mock) PacketCoding() sensors.RFMPacketCoding {
+	return this.packet_coding
+}
+
+func (this *mock) PacketFilter() sensors.RFMPacketFilter {
+	return this.packet_filter
+}
+
+func (this *mock) PacketCRC() sensors.RFMPacketCRC {
+	return this.packet_crc
+}
+
+func (this *mock) SetPacketFormat(packet_format sensors.RFMPacketFormat) error {
+	if packet_format > sensors.RFM_PACKET_FORMAT_VARIABLE {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.packet_format = packet_format
+	return nil
+}
+
+func (this *mock) SetPacketCoding(packet_coding sensors.RFMPacketCoding) error {
+	if packet_coding > sensors.RFM_PACKET_CODING_WHITENING {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.packet_coding = packet_coding
+	return nil
+}
+
+func (this *mock) SetPacketFilter(packet_filter sensors.RFMPacketFilter) error {
+	if packet_filter > sensors.RFM_PACKET_FILTER_BROADCAST {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.packet_filter = packet_filter
+	return nil
+}
+
+func (this *mock) SetPacketCRC(packet_crc sensors.RFMPacketCRC) error {
+	if packet_crc > sensors.RFM_PACKET_CRC_AUTOCLEAR_ON {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.packet_crc = packet_crc
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RADIOHEAD
+
+func (this *mock) RadioHead() bool {
+	return this.radiohead
+}
+
+func (this *mock) SetRadioHead(enabled bool) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if enabled {
+		this.packet_format = sensors.RFM_PACKET_FORMAT_VARIABLE
+		this.packet_coding = sensors.RFM_PACKET_CODING_WHITENING
+		this.packet_filter = sensors.RFM_PACKET_FILTER_NONE
+		this.packet_crc = sensors.RFM_PACKET_CRC_AUTOCLEAR_ON
+		this.preamble_size = rfm69.RFM_RADIOHEAD_PREAMBLE_SIZE
+		this.sync_word = append([]byte{}, rfm69.RFM_RADIOHEAD_SYNCWORD...)
+		this.payload_size = rfm69.RFM_AES_PAYLOAD_MAX
+	}
+	this.radiohead = enabled
+	return nil
+}
+
+// WriteRadioHead captures the payload with the length byte and header
+func (this *mock) WriteRadioHead(header sensors.RFMRadioHeadHeader, data []byte, repeat uint) error {
+	if this.radiohead == false {
+		return gopi.ErrOutOfOrder
+	} else if len(data) > rfm69.RFM_RADIOHEAD_PAYLOAD_MAX {
+		return gopi.ErrBadParameter
+	}
+	packet := append([]byte{uint8(len(data) + rfm69.RFM_RADIOHEAD_HEADER_SIZE), header.To, header.From, header.ID, header.Flags}, data...)
+	return this.WritePayload(packet, repeat)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// ADDRESSES
+
+func (this *mock) NodeAddress() uint8 {
+	return this.node_addr
+}
+
+func (this *mock) BroadcastAddress() uint8 {
+	return this.broadcast_addr
+}
+
+func (this *mock) SetNodeAddress(value uint8) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.node_addr = value
+	return nil
+}
+
+func (this *mock) SetBroadcastAddress(value uint8) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.broadcast_addr = value
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PAYLOAD AND PREAMBLE
+
+func (this *mock) PreambleSize() uint16 {
+	return this.preamble_size
+}
+
+func (this *mock) PayloadSize() uint8 {
+	return this.payload_size
+}
+
+func (this *mock) SetPreambleSize(preamble_size uint16) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.preamble_size = preamble_size
+	return nil
+}
+
+func (this *mock) SetPayloadSize(payload_size uint8) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.aes_key != nil && payload_size > rfm69.RFM_AES_PAYLOAD_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.payload_size = payload_size
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// AES KEY AND SYNC WORD
+
+func (this *mock) AESKey() []byte {
+	return this.aes_key
+}
+
+func (this *mock) SetAESKey(key []byte) error {
+	if key != nil && len(key) != rfm69.RFM_AESKEY_BYTES {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if key == nil {
+		this.aes_key = nil
+	} else {
+		this.aes_key = append([]byte{}, key...)
+		if this.payload_size > rfm69.RFM_AES_PAYLOAD_MAX {
+			this.payload_size = rfm69.RFM_AES_PAYLOAD_MAX
+		}
+	}
+	return nil
+}
+
+func (this *mock) SyncWord() []byte {
+	return this.sync_word
+}
+
+func (this *mock) SetSyncWord(word []byte) error {
+	if len(word) > rfm69.RFM_SYNCWORD_BYTES {
+		return gopi.ErrBadParameter
+	}
+	for _, value := range word {
+		if value == 0x00 {
+			return gopi.ErrBadParameter
+		}
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if len(word) == 0 {
+		this.sync_word = nil
+	} else {
+		this.sync_word = append([]byte{}, word...)
+	}
+	return nil
+}
+
+func (this *mock) SyncTolerance() uint8 {
+	return this.sync_tol
+}
+
+func (this *mock) SetSyncTolerance(bits uint8) error {
+	if bits > 7 {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.sync_tol = bits
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// AFC
+
+func (this *mock) AFC() uint {
+	return this.afc
+}
+
+func (this *mock) AFCMode() sensors.RFMAFCMode {
+	return this.afc_mode
+}
+
+func (this *mock) AFCRoutine() sensors.RFMAFCRoutine {
+	return this.afc_routine
+}
+
+func (this *mock) SetAFCRoutine(afc_routine sensors.RFMAFCRoutine) error {
+	if afc_routine > sensors.RFM_AFCROUTINE_MASK {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.afc_routine = afc_routine
+	return nil
+}
+
+func (this *mock) SetAFCMode(afc_mode sensors.RFMAFCMode) error {
+	if afc_mode > sensors.RFM_AFCMODE_MASK {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.afc_mode = afc_mode
+	return nil
+}
+
+func (this *mock) AFCLowBetaOffset() int {
+	return this.afc_lowbeta_offset
+}
+
+func (this *mock) SetAFCLowBetaOffset(hz int) error {
+	if value := hz / rfm69.RFM_AFCOFFSET_STEP; value < -128 || value > 127 {
+		return gopi.ErrBadParameter
+	} else {
+		this.lock.Lock()
+		defer this.lock.Unlock()
+		this.afc_lowbeta_offset = value * rfm69.RFM_AFCOFFSET_STEP
+		return nil
+	}
+}
+
+func (this *mock) TriggerAFC() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.mode != sensors.RFM_MODE_RX {
+		return gopi.ErrOutOfOrder
+	}
+	this.afc = 0
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// OUTPUT POWER
+
+func (this *mock) OutputPower() int {
+	return this.output_power
+}
+
+func (this *mock) SetOutputPower(dbm int) error {
+	if this.high_power && (dbm < rfm69.RFM_POWER_HIGH_MIN || dbm > rfm69.RFM_POWER_HIGH_MAX) {
+		return gopi.ErrBadParameter
+	} else if this.high_power == false && (dbm < rfm69.RFM_POWER_MIN || dbm > rfm69.RFM_POWER_MAX) {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.output_power = dbm
+	return nil
+}
+
+func (this *mock) PARamp() sensors.RFMPARamp {
+	return this.pa_ramp
+}
+
+func (this *mock) SetPARamp(ramp sensors.RFMPARamp) error {
+	if ramp > sensors.RFM_PARAMP_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.pa_ramp = ramp
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// LOW NOISE AMPLIFIER
+
+func (this *mock) LNAImpedance() sensors.RFMLNAImpedance {
+	return this.lna_impedance
+}
+
+func (this *mock) LNAGain() sensors.RFMLNAGain {
+	return this.lna_gain
+}
+
+func (this *mock) LNACurrentGain() (sensors.RFMLNAGain, error) {
+	if this.lna_gain == sensors.RFM_LNA_GAIN_AUTO {
+		return sensors.RFM_LNA_GAIN_G1, nil
+	} else {
+		return this.lna_gain, nil
+	}
+}
+
+func (this *mock) SetLNA(impedance sensors.RFMLNAImpedance, gain sensors.RFMLNAGain) error {
+	if impedance > sensors.RFM_LNA_IMPEDANCE_MAX || gain > sensors.RFM_LNA_GAIN_G6 {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.lna_impedance = impedance
+	this.lna_gain = gain
+	return nil
+}
+
+func (this *mock) SensitivityBoost() bool {
+	return this.sensitivity_boost
+}
+
+func (this *mock) SetSensitivityBoost(enabled bool) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.sensitivity_boost = enabled
+	return nil
+}
+
+func (this *mock) DAGC() bool {
+	return this.dagc
+}
+
+func (this *mock) SetDAGC(enabled bool) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.dagc = enabled
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RSSI THRESHOLD AND RX TIMEOUTS
+
+func (this *mock) RSSIThreshold() float32 {
+	return this.rssi_threshold
+}
+
+func (this *mock) SetRSSIThreshold(dbm float32) error {
+	if dbm > 0 || dbm < rfm69.RFM_RSSI_MIN {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.rssi_threshold = float32(int(-dbm*2.0)) / -2.0
+	return nil
+}
+
+func (this *mock) RXTimeout() (time.Duration, time.Duration) {
+	return this.rx_timeout_start, this.rx_timeout_rssi
+}
+
+func (this *mock) SetRXTimeout(rx_start, rssi_thresh time.Duration) error {
+	unit := time.Second * MOCK_RX_TIMEOUT_UNIT / time.Duration(this.bitrate)
+	if rx_start < 0 || rssi_thresh < 0 || rx_start > unit*0xFF || rssi_thresh > unit*0xFF {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.rx_timeout_start = (rx_start + unit - 1) / unit * unit
+	this.rx_timeout_rssi = (rssi_thresh + unit - 1) / unit * unit
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CHANNEL FILTER
+
+func (this *mock) RXFilterFrequency() sensors.RFMRXBWFrequency {
+	return this.rxbw_frequency
+}
+
+func (this *mock) RXFilterCutoff() sensors.RFMRXBWCutoff {
+	return this.rxbw_cutoff
+}
+
+func (this *mock) SetRXFilter(frequency sensors.RFMRXBWFrequency, cutoff sensors.RFMRXBWCutoff) error {
+	if frequency > sensors.RFM_RXBW_FREQUENCY_MAX || cutoff > sensors.RFM_RXBW_CUTOFF_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.rxbw_frequency = frequency
+	this.rxbw_cutoff = cutoff
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// OOK DEMODULATOR
+
+func (this *mock) OOKThreshold() (sensors.RFMOOKThresholdType, sensors.RFMOOKThresholdStep, sensors.RFMOOKThresholdDecrement) {
+	return this.ook_threshold_type, this.ook_threshold_step, this.ook_threshold_dec
+}
+
+func (this *mock) SetOOKThreshold(threshold_type sensors.RFMOOKThresholdType, step sensors.RFMOOKThresholdStep, dec sensors.RFMOOKThresholdDecrement) error {
+	if threshold_type > sensors.RFM_OOK_THRESHOLD_AVERAGE || step > sensors.RFM_OOK_THRESHOLD_STEP_MAX || dec > sensors.RFM_OOK_THRESHOLD_DEC_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.ook_threshold_type = threshold_type
+	this.ook_threshold_step = step
+	this.ook_threshold_dec = dec
+	return nil
+}
+
+func (this *mock) OOKFixedThreshold() uint8 {
+	return this.ook_fixed_threshold
+}
+
+func (this *mock) SetOOKFixedThreshold(db uint8) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.ook_fixed_threshold = db
+	return nil
+}
+
+func (this *mock) OOKAverageFilter() sensors.RFMOOKAverageFilter {
+	return this.ook_average_filter
+}
+
+func (this *mock) SetOOKAverageFilter(filter sensors.RFMOOKAverageFilter) error {
+	if filter > sensors.RFM_OOK_AVERAGE_FILTER_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.ook_average_filter = filter
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// FIFO
+
+func (this *mock) FIFOThreshold() uint8 {
+	return this.fifo_threshold
+}
+
+func (this *mock) SetFIFOThreshold(fifo_threshold uint8) error {
+	if fifo_threshold > 0x7F {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.fifo_threshold = fifo_threshold
+	return nil
+}
+
+// ReadFIFO returns data written with WriteFIFO, or else the payload of
+// the next injected packet. It returns nil if the context is done first
+func (this *mock) ReadFIFO(ctx context.Context) ([]byte, error) {
+	this.lock.Lock()
+	if len(this.fifo) > 0 {
+		data := this.fifo
+		this.fifo = nil
+		this.lock.Unlock()
+		return data, nil
+	}
+	this.lock.Unlock()
+
+	if packet := this.waitPacket(ctx); packet == nil {
+		return nil, nil
+	} else {
+		return packet.Payload, nil
+	}
+}
+
+func (this *mock) WriteFIFO(data []byte) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if len(this.fifo)+len(data) > rfm69.RFM_FIFO_SIZE {
+		this.emitDebug(sensors.RFM_EVENT_FIFO_OVERRUN)
+		this.fifo = nil
+		return nil
+	}
+	this.fifo = append(this.fifo, data...)
+	return nil
+}
+
+func (this *mock) ClearFIFO() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.fifo = nil
+	return nil
+}
+
+// IRQFlags returns flags derived from the mode, the FIFO and injected
+// packets waiting to be read
+func (this *mock) IRQFlags() (sensors.RFMIRQFlags, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	fifo := this.fifoStatus()
+	receiving := this.mode == sensors.RFM_MODE_RX
+	return sensors.RFMIRQFlags{
+		ModeReady:    true,
+		RxReady:      receiving,
+		TxReady:      this.mode == sensors.RFM_MODE_TX,
+		PLLLock:      receiving || this.mode == sensors.RFM_MODE_TX || this.mode == sensors.RFM_MODE_FS,
+		FIFOFull:     fifo.Full,
+		FIFONotEmpty: fifo.NotEmpty,
+		FIFOLevel:    fifo.Level,
+		PayloadReady: receiving && len(this.rx) > 0,
+		CRCOk:        receiving && len(this.rx) > 0 && this.rx[0].CRCOk,
+	}, nil
+}
+
+func (this *mock) FIFOStatus() (sensors.RFMFIFOStatus, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.fifoStatus(), nil
+}
+
+// fifoStatus returns the status of the FIFO, which is never overrun as it
+// is cleared when it would overflow
+func (this *mock) fifoStatus() sensors.RFMFIFOStatus {
+	return sensors.RFMFIFOStatus{
+		Full:      len(this.fifo) >= rfm69.RFM_FIFO_SIZE,
+		NotEmpty:  len(this.fifo) > 0,
+		Level:     len(this.fifo) > int(this.fifo_threshold),
+		Threshold: this.fifo_threshold,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PAYLOAD
+
+// SetInterrupt is accepted but has no effect, as injected packets are
+// delivered to readers immediately
+func (this *mock) SetInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error {
+	if pin != gopi.GPIO_PIN_NONE && gpio == nil {
+		return gopi.ErrBadParameter
+	}
+	return nil
+}
+
+func (this *mock) ReadPayload(ctx context.Context) ([]byte, bool, error) {
+	if packet, err := this.ReadPacket(ctx); err != nil {
+		return nil, false, err
+	} else if packet == nil {
+		return nil, false, nil
+	} else {
+		return packet.Payload, packet.CRCOk, nil
+	}
+}
+
+func (this *mock) ReadPayloadTimeout(timeout time.Duration) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return this.ReadPayload(ctx)
+}
+
+func (this *mock) TryReadPayload() ([]byte, bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return this.ReadPayload(ctx)
+}
+
+func (this *mock) ReadPacket(ctx context.Context) (*sensors.RFMPacket, error) {
+	this.lock.Lock()
+	if this.mode != sensors.RFM_MODE_RX && this.listen_on == false {
+		this.lock.Unlock()
+		return nil, gopi.ErrOutOfOrder
+	}
+	this.lock.Unlock()
+
+	return this.waitPacket(ctx), nil
+}
+
+func (this *mock) ScanChannels(ctx context.Context, channels []uint, dwell time.Duration) (*sensors.RFMPacket, error) {
+	if len(channels) == 0 || dwell <= 0 {
+		return nil, gopi.ErrBadParameter
+	}
+	for i := 0; ; i++ {
+		if err := this.SetFreqCarrier(channels[i%len(channels)]); err != nil {
+			return nil, err
+		} else if err := this.SetMode(sensors.RFM_MODE_RX); err != nil {
+			return nil, err
+		}
+		dwell_ctx, cancel := context.WithTimeout(ctx, dwell)
+		packet := this.waitPacket(dwell_ctx)
+		cancel()
+		if packet != nil {
+			return packet, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+			break
+		}
+	}
+}
+
+// TransmitCarrier stays in TX until the context is done
+func (this *mock) TransmitCarrier(ctx context.Context) error {
+	return this.transmitTest(ctx)
+}
+
+// TransmitPN9 stays in TX until the context is done
+func (this *mock) TransmitPN9(ctx context.Context) error {
+	return this.transmitTest(ctx)
+}
+
+func (this *mock) transmitTest(ctx context.Context) error {
+	if err := this.SetMode(sensors.RFM_MODE_TX); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return this.SetMode(sensors.RFM_MODE_STDBY)
+}
+
+// ScanRSSI returns the mock RSSI at each step
+func (this *mock) ScanRSSI(from, to, step uint) ([]sensors.RFMRSSIReading, error) {
+	if step == 0 || from > to {
+		return nil, gopi.ErrBadParameter
+	} else if (to-from)/step >= rfm69.RFM_SCAN_RSSI_STEPS_MAX {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	readings := make([]sensors.RFMRSSIReading, 0, (to-from)/step+1)
+	for hertz := from; hertz <= to; hertz += step {
+		readings = append(readings, sensors.RFMRSSIReading{
+			FreqCarrier: hertz,
+			RSSI:        this.rssi,
+		})
+	}
+	return readings, nil
+}
+
+// WritePayload captures the payload, which must be sent in TX mode
+func (this *mock) WritePayload(data []byte, repeat uint) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.mode != sensors.RFM_MODE_TX {
+		return gopi.ErrOutOfOrder
+	} else if repeat < 1 {
+		return gopi.ErrBadParameter
+	} else if length := len(data); length == 0 || length > rfm69.RFM_FIFO_SIZE {
+		return gopi.ErrBadParameter
+	} else if this.aes_key != nil && length > rfm69.RFM_AES_PAYLOAD_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	this.tx = append(this.tx, Transmission{
+		Timestamp:    time.Now(),
+		FreqCarrier:  this.freq_carrier,
+		Modulation:   this.modulation,
+		PacketFormat: this.packet_format,
+		PayloadSize:  this.payload_size,
+		SyncWord:     append([]byte{}, this.sync_word...),
+		Payload:      append([]byte{}, data...),
+		Repeat:       repeat,
+	})
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// AUTOMODES
+
+func (this *mock) AutoModes() (sensors.RFMAutoModeEnter, sensors.RFMAutoModeExit, sensors.RFMAutoModeIntermediate) {
+	return this.automode_enter, this.automode_exit, this.automode_inter
+}
+
+func (this *mock) SetAutoModes(enter sensors.RFMAutoModeEnter, exit sensors.RFMAutoModeExit, intermediate sensors.RFMAutoModeIntermediate) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetAutoModes>{ enter=%v exit=%v intermediate=%v }", enter, exit, intermediate)
+	if enter > sensors.RFM_AUTOMODE_ENTER_MAX || exit > sensors.RFM_AUTOMODE_EXIT_MAX || intermediate > sensors.RFM_AUTOMODE_INTERMEDIATE_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.automode_enter = enter
+	this.automode_exit = exit
+	this.automode_inter = intermediate
+	return nil
+}
+
+// WriteAutoPayload captures the payload, which must be sent from standby
+func (this *mock) WriteAutoPayload(data []byte, repeat uint) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.mode != sensors.RFM_MODE_STDBY {
+		return gopi.ErrOutOfOrder
+	} else if repeat < 1 {
+		return gopi.ErrBadParameter
+	} else if length := len(data); length == 0 || length > rfm69.RFM_FIFO_SIZE {
+		return gopi.ErrBadParameter
+	} else if this.aes_key != nil && length > rfm69.RFM_AES_PAYLOAD_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	this.tx = append(this.tx, Transmission{
+		Timestamp:    time.Now(),
+		FreqCarrier:  this.freq_carrier,
+		Modulation:   this.modulation,
+		PacketFormat: this.packet_format,
+		PayloadSize:  this.payload_size,
+		SyncWord:     append([]byte{}, this.sync_word...),
+		Payload:      append([]byte{}, data...),
+		Repeat:       repeat,
+	})
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// OSCILLATORS
+
+// CalibrateRC does nothing, and must be called in standby
+func (this *mock) CalibrateRC() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.mode != sensors.RFM_MODE_STDBY {
+		return gopi.ErrOutOfOrder
+	}
+	return nil
+}
+
+func (this *mock) ClockOutput() sensors.RFMClockOut {
+	return this.clkout
+}
+
+func (this *mock) SetClockOutput(divider sensors.RFMClockOut) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetClockOutput>{ divider=%v }", divider)
+	if divider > sensors.RFM_CLKOUT_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.clkout = divider
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// LOW BATTERY AND PLL
+
+func (this *mock) LowBattery() (bool, sensors.RFMLowBatTrim) {
+	return this.lowbat_on, this.lowbat_trim
+}
+
+func (this *mock) SetLowBattery(enabled bool, trim sensors.RFMLowBatTrim) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetLowBattery>{ enabled=%v trim=%v }", enabled, trim)
+	if trim > sensors.RFM_LOWBAT_TRIM_MAX {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.lowbat_on = enabled
+	this.lowbat_trim = trim
+	return nil
+}
+
+// MeasureLowBattery returns the low battery state from the configuration,
+// and emits RFM_EVENT_LOW_BATTERY the first time it is measured
+func (this *mock) MeasureLowBattery() (bool, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.lowbat_on == false {
+		return false, gopi.ErrOutOfOrder
+	}
+	if this.low_battery && this.low_battery_seen == false {
+		this.emitDebug(sensors.RFM_EVENT_LOW_BATTERY)
+	}
+	this.low_battery_seen = this.low_battery
+	return this.low_battery, nil
+}
+
+// PLLLocked returns true in FS, TX and RX modes
+func (this *mock) PLLLocked() (bool, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.mode == sensors.RFM_MODE_RX || this.mode == sensors.RFM_MODE_TX || this.mode == sensors.RFM_MODE_FS, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// DIO MAPPING
+
+func (this *mock) DIOMapping(pin sensors.RFMDIOPin) sensors.RFMDIOFunction {
+	if pin > sensors.RFM_DIO_MAX {
+		return sensors.RFM_DIO_FUNC_NONE
+	}
+	return this.dio_mapping[pin]
+}
+
+// SetDIOMapping records the signal, if it is available on the pin
+func (this *mock) SetDIOMapping(pin sensors.RFMDIOPin, function sensors.RFMDIOFunction) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetDIOMapping>{ pin=%v function=%v }", pin, function)
+	if _, exists := rfm69.DIOMappingValue(pin, function); exists == false {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.dio_mapping[pin] = function
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONFIGURATION
+
+func (this *mock) Config() sensors.RFM69Config {
+	return rfm69.ReadConfig(this)
+}
+
+func (this *mock) SetConfig(config sensors.RFM69Config) error {
+	this.log.Debug("<sensors.RFM69.Mock.SetConfig>{ config=%+v }", config)
+	return rfm69.WriteConfig(this, config)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MEASUREMENTS
+
+func (this *mock) MeasureTemperature(calibration float32) (float32, error) {
+	if this.mode == sensors.RFM_MODE_RX {
+		return 0, gopi.ErrOutOfOrder
+	}
+	return this.temperature + calibration, nil
+}
+
+func (this *mock) MeasureRSSI() (float32, error) {
+	return this.rssi, nil
+}
+
+func (this *mock) ReadVersion() (uint8, error) {
+	return MOCK_VERSION_VALUE, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// REGISTERS
+
+// DumpRegisters returns the values written with WriteRegister, as the
+// mock does not keep register state for the other methods
+func (this *mock) DumpRegisters() ([]sensors.RFMRegisterValue, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	values := make([]sensors.RFMRegisterValue, 0, MOCK_REGISTER_COUNT-1)
+	for reg := uint8(1); reg < MOCK_REGISTER_COUNT; reg++ {
+		values = append(values, sensors.RFMRegisterValue{
+			Register: reg,
+			Name:     fmt.Sprintf("RFM_REG_%02X", reg),
+			Value:    this.registers[reg],
+		})
+	}
+	return values, nil
+}
+
+func (this *mock) ReadRegister(reg uint8) (uint8, error) {
+	if reg >= MOCK_REGISTER_COUNT {
+		return 0, gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.registers[reg], nil
+}
+
+func (this *mock) WriteRegister(reg, value uint8) error {
+	if this.debug_on == false {
+		return gopi.ErrNotImplemented
+	} else if reg >= MOCK_REGISTER_COUNT {
+		return gopi.ErrBadParameter
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.registers[reg] = value
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// DEBUG EVENTS
+
+func (this *mock) SubscribeDebug() <-chan sensors.RFMEvent {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	c := make(chan sensors.RFMEvent, MOCK_DEBUG_BUFFER)
+	this.subscribers = append(this.subscribers, c)
+	return c
+}
+
+func (this *mock) UnsubscribeDebug(subscriber <-chan sensors.RFMEvent) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for i, c := range this.subscribers {
+		if c == subscriber {
+			close(c)
+			this.subscribers = append(this.subscribers[:i], this.subscribers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OOK Demodulator Settings
+
+// Return the OOK threshold type, and the step and period of decrement
+// of the threshold in peak mode
+func (this *rfm69) OOKThreshold() (sensors.RFMOOKThresholdType, sensors.RFMOOKThresholdStep, sensors.RFMOOKThresholdDecrement) {
+	return this.ook_threshold_type, this.ook_threshold_step, this.ook_threshold_dec
+}
+
+// Return the OOK fixed threshold in dB
+func (this *rfm69) OOKFixedThreshold() uint8 {
+	return this.ook_fixed_threshold
+}
+
+// Return the OOK filter coefficient in average mode
+func (this *rfm69) OOKAverageFilter() sensors.RFMOOKAverageFilter {
+	return this.ook_average_filter
+}
+
+// Set the OOK threshold type. In peak mode the threshold follows the
+// peak of the signal, and decreases by the step at the decrement period
+// down to the fixed threshold, which suits weak or fading signals
+func (this *rfm69) SetOOKThreshold(threshold_type sensors.RFMOOKThresholdType, step sensors.RFMOOKThresholdStep, dec sensors.RFMOOKThresholdDecrement) error {
+	this.log.Debug("<sensors.RFM69.SetOOKThreshold{ type=%v step=%v dec=%v }", threshold_type, step, dec)
+
+	if threshold_type > sensors.RFM_OOK_THRESHOLD_AVERAGE || step > sensors.RFM_OOK_THRESHOLD_STEP_MAX || dec > sensors.RFM_OOK_THRESHOLD_DEC_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setRegOOKPeak(threshold_type, step, dec); err != nil {
+		return err
+	}
+
+	// Read
+	if threshold_type_read, step_read, dec_read, err := this.getRegOOKPeak(); err != nil {
+		return err
+	} else if threshold_type_read != threshold_type {
+		this.log.Debug2("SetOOKThreshold expecting type=%v, got=%v", threshold_type, threshold_type_read)
+		return sensors.ErrUnexpectedResponse
+	} else if step_read != step {
+		this.log.Debug2("SetOOKThreshold expecting step=%v, got=%v", step, step_read)
+		return sensors.ErrUnexpectedResponse
+	} else if dec_read != dec {
+		this.log.Debug2("SetOOKThreshold expecting dec=%v, got=%v", dec, dec_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.ook_threshold_type = threshold_type
+		this.ook_threshold_step = step
+		this.ook_threshold_dec = dec
+	}
+	return nil
+}
+
+// Set the OOK fixed threshold in dB, which is also the floor of the
+// threshold in peak mode
+func (this *rfm69) SetOOKFixedThreshold(db uint8) error {
+	this.log.Debug("<sensors.RFM69.SetOOKFixedThreshold{ db=%v }", db)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setRegOOKFix(db); err != nil {
+		return err
+	}
+
+	// Read
+	if db_read, err := this.getRegOOKFix(); err != nil {
+		return err
+	} else if db_read != db {
+		this.log.Debug2("SetOOKFixedThreshold expecting db=%v, got=%v", db, db_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.ook_fixed_threshold = db
+	}
+	return nil
+}
+
+// Set the OOK filter coefficient in average mode
+func (this *rfm69) SetOOKAverageFilter(filter sensors.RFMOOKAverageFilter) error {
+	this.log.Debug("<sensors.RFM69.SetOOKAverageFilter{ filter=%v }", filter)
+
+	if filter > sensors.RFM_OOK_AVERAGE_FILTER_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setRegOOKAvg(filter); err != nil {
+		return err
+	}
+
+	// Read
+	if filter_read, err := this.getRegOOKAvg(); err != nil {
+		return err
+	} else if filter_read != filter {
+		this.log.Debug2("SetOOKAverageFilter expecting filter=%v, got=%v", filter, filter_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.ook_average_filter = filter
+	}
+	return nil
+}
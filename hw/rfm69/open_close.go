@@ -10,8 +10,12 @@
 package rfm69
 
 import (
+	"time"
+
+	// Frameworks
 	gopi "github.com/djthorpe/gopi"
 	sensors "github.com/djthorpe/sensors"
+	backoff "github.com/djthorpe/sensors/util/backoff"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -22,37 +26,85 @@ type RFM69 struct {
 	// the SPI driver
 	SPI gopi.SPI
 
-	// Device speed
+	// SPI mode, device speed and settle delay after each transfer.
+	// When speed is zero, the speed and delay are set from the
+	// defaults for the board model
+	Mode  gopi.SPIMode
 	Speed uint32
+	Delay time.Duration
+
+	// Read back configuration registers after writing, and the
+	// number of times to retry a failed SPI transfer or a write
+	// which fails verification
+	Verify  bool
+	Retries uint
+
+	// Delay between retries, or zero for the default. The number of
+	// retries is set by Retries
+	Backoff backoff.Policy
+
+	// GPIO and pin connected to DIO0 for interrupt-driven reception,
+	// or nil to poll for received payloads
+	GPIO    gopi.GPIO
+	PinDIO0 gopi.GPIOPin
+
+	// High power module (RFM69HW or RFM69HCW), which can only transmit
+	// through PA1 and PA2. The variant cannot be read from the device
+	HighPower bool
+
+	// Allow any register to be read and written, for debugging
+	Debug bool
+
+	// Clock output on DIO5, or nil to leave it unchanged
+	ClockOut *sensors.RFMClockOut
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // OPEN AND CLOSE
 
 func (config RFM69) Open(log gopi.Logger) (gopi.Driver, error) {
-	log.Debug("<sensors.RFM69.Open>{ spi=%v speed=%v }", config.SPI, config.Speed)
+	log.Debug("<sensors.RFM69.Open>{ spi=%v mode=%v speed=%v delay=%v verify=%v retries=%v high_power=%v }", config.SPI, config.Mode, config.Speed, config.Delay, config.Verify, config.Retries, config.HighPower)
 
 	this := new(rfm69)
 	this.spi = config.SPI
 	this.log = log
+	this.verify = config.Verify
+	this.retries = config.Retries
+	this.backoff = RFM_VERIFY_BACKOFF_DEFAULT
+	if config.Backoff.Zero() == false {
+		this.backoff = config.Backoff
+	}
+	this.dio0 = gopi.GPIO_PIN_NONE
+	this.high_power = config.HighPower
+	this.debug_registers = config.Debug
 
 	if this.spi == nil {
 		return nil, gopi.ErrBadParameter
 	}
 
 	// Set SPI mode
-	if err := this.spi.SetMode(RFM_SPI_MODE); err != nil {
+	if spiModeValid(config.Mode) == false {
+		return nil, gopi.ErrBadParameter
+	} else if err := this.spi.SetMode(config.Mode); err != nil {
 		return nil, err
 	}
 
-	// Set SPI speed
+	// Set SPI speed and delay
 	if config.Speed > 0 {
 		if err := this.spi.SetMaxSpeedHz(config.Speed); err != nil {
 			return nil, err
+		} else {
+			this.delay = config.Delay
 		}
 	} else {
-		if err := this.spi.SetMaxSpeedHz(RFM_SPI_SPEEDHZ); err != nil {
+		model, speed, delay := spiBoardDefaults()
+		log.Debug2("<sensors.RFM69.Open>{ model=\"%v\" speed=%v delay=%v }", model, speed, delay)
+		if err := this.spi.SetMaxSpeedHz(speed); err != nil {
 			return nil, err
+		} else if config.Delay > 0 {
+			this.delay = config.Delay
+		} else {
+			this.delay = delay
 		}
 	}
 
@@ -110,10 +162,13 @@ func (config RFM69) Open(log gopi.Logger) (gopi.Driver, error) {
 		return nil, err
 	} else if afc_mode, _, _, err := this.getAFCControl(); err != nil {
 		return nil, err
+	} else if afc_lowbeta_offset, err := this.getAFCLowBetaOffset(); err != nil {
+		return nil, err
 	} else {
 		this.afc = afc
 		this.afc_routine = afc_routine
 		this.afc_mode = afc_mode
+		this.afc_lowbeta_offset = afc_lowbeta_offset
 	}
 
 	// Low Noise Amplifer values (last value ignored is the current gain setting)
@@ -124,6 +179,16 @@ func (config RFM69) Open(log gopi.Logger) (gopi.Driver, error) {
 		this.lna_gain = gain
 	}
 
+	// Sensitivity boost and continuous DAGC
+	if sensitivity_boost, err := this.getSensitivityBoost(); err != nil {
+		return nil, err
+	} else if dagc, err := this.getDAGC(); err != nil {
+		return nil, err
+	} else {
+		this.sensitivity_boost = sensitivity_boost
+		this.dagc = dagc
+	}
+
 	// Channel filter settings
 	if frequency, cutoff, err := this.getRegRXBW(); err != nil {
 		return nil, err
@@ -132,6 +197,32 @@ func (config RFM69) Open(log gopi.Logger) (gopi.Driver, error) {
 		this.rxbw_cutoff = cutoff
 	}
 
+	// OOK demodulator settings
+	if threshold_type, step, dec, err := this.getRegOOKPeak(); err != nil {
+		return nil, err
+	} else if filter, err := this.getRegOOKAvg(); err != nil {
+		return nil, err
+	} else if fixed_threshold, err := this.getRegOOKFix(); err != nil {
+		return nil, err
+	} else {
+		this.ook_threshold_type = threshold_type
+		this.ook_threshold_step = step
+		this.ook_threshold_dec = dec
+		this.ook_average_filter = filter
+		this.ook_fixed_threshold = fixed_threshold
+	}
+
+	// RSSI threshold and RX timeouts
+	if rssi_threshold, err := this.getRSSIThreshold(); err != nil {
+		return nil, err
+	} else if rx_timeout_start, rx_timeout_rssi, err := this.getRXTimeout(); err != nil {
+		return nil, err
+	} else {
+		this.rssi_threshold = rssi_threshold
+		this.rx_timeout_start = rx_timeout_start
+		this.rx_timeout_rssi = rx_timeout_rssi
+	}
+
 	// Get Node address and Broadcast address
 	if node_address, err := this.getNodeAddress(); err != nil {
 		return nil, err
@@ -200,6 +291,64 @@ func (config RFM69) Open(log gopi.Logger) (gopi.Driver, error) {
 		this.fifo_threshold = fifo_threshold
 	}
 
+	// AutoModes
+	if enter, exit, intermediate, err := this.getAutoModes(); err != nil {
+		return nil, err
+	} else {
+		this.automode_enter = enter
+		this.automode_exit = exit
+		this.automode_intermediate = intermediate
+	}
+
+	// Clock output
+	if config.ClockOut != nil {
+		if err := this.setClockOut(*config.ClockOut); err != nil {
+			return nil, err
+		}
+	}
+	if clkout, err := this.getClockOut(); err != nil {
+		return nil, err
+	} else {
+		this.clkout = clkout
+	}
+
+	// Low battery detector
+	if lowbat_on, lowbat_trim, err := this.getLowBattery(); err != nil {
+		return nil, err
+	} else {
+		this.lowbat_on = lowbat_on
+		this.lowbat_trim = lowbat_trim
+	}
+
+	// Output power and high power boost registers, which are cleared
+	// unless transmitting
+	if pa_level, err := this.readreg_uint8(RFM_REG_PALEVEL); err != nil {
+		return nil, err
+	} else if testpa1, err := this.readreg_uint8(RFM_REG_TESTPA1); err != nil {
+		return nil, err
+	} else {
+		this.pa_boosted = (testpa1 == RFM_TESTPA1_BOOST)
+		this.pa_boost = this.pa_boosted
+		this.output_power = outputPower(pa_level, this.pa_boost)
+		if err := this.setHighPower(this.mode == sensors.RFM_MODE_TX && this.pa_boost); err != nil {
+			return nil, err
+		}
+	}
+
+	// PA ramp time
+	if pa_ramp, err := this.getPARamp(); err != nil {
+		return nil, err
+	} else {
+		this.pa_ramp = pa_ramp
+	}
+
+	// Interrupt-driven reception
+	if config.GPIO != nil && config.PinDIO0 != gopi.GPIO_PIN_NONE {
+		if err := this.setInterrupt(config.GPIO, config.PinDIO0); err != nil {
+			return nil, err
+		}
+	}
+
 	// Return success
 	return this, nil
 }
@@ -211,6 +360,14 @@ func (this *rfm69) Close() error {
 	this.lock.Lock()
 	defer this.lock.Unlock()
 
+	// Stop watching for interrupts
+	if err := this.setInterrupt(nil, gopi.GPIO_PIN_NONE); err != nil {
+		return err
+	}
+
+	// Close debug subscribers
+	this.closeDebug()
+
 	// Blank out SPI value
 	this.spi = nil
 
@@ -0,0 +1,98 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Time to wait for RC oscillator calibration to complete
+	RFM_RCCAL_TIMEOUT = 100 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// CalibrateRC calibrates the RC oscillator, which times the idle and
+// RX periods in listen mode. The radio calibrates it at power up, but
+// the frequency drifts with temperature. The radio needs to be in standby
+func (this *rfm69) CalibrateRC() error {
+	this.log.Debug("<sensors.RFM69.CalibrateRC>{ }")
+
+	// Mode needs to be in standby
+	if mode := this.Mode(); mode != sensors.RFM_MODE_STDBY {
+		return gopi.ErrOutOfOrder
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.calibrateRC()
+}
+
+// Return the clock output on DIO5
+func (this *rfm69) ClockOutput() sensors.RFMClockOut {
+	return this.clkout
+}
+
+// SetClockOutput sets the clock output on DIO5 to the crystal oscillator
+// frequency divided by the divider, or the RC oscillator, for boards
+// which feed the clock to other peripherals. RFM_CLKOUT_OFF saves power
+// when the clock is not used
+func (this *rfm69) SetClockOutput(divider sensors.RFMClockOut) error {
+	this.log.Debug("<sensors.RFM69.SetClockOutput>{ divider=%v }", divider)
+
+	if divider > sensors.RFM_CLKOUT_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if err := this.setClockOut(divider); err != nil {
+		return err
+	} else if clkout, err := this.getClockOut(); err != nil {
+		return err
+	} else if clkout != divider {
+		this.log.Debug2("SetClockOutput: expected %v got %v", divider, clkout)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.clkout = clkout
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// calibrateRC starts RC oscillator calibration and waits for it to
+// complete
+func (this *rfm69) calibrateRC() error {
+	if err := this.setRCCalStart(); err != nil {
+		return err
+	} else if err := wait_for_condition(this.getRCCalDone, true, RFM_RCCAL_TIMEOUT); err != nil {
+		return err
+	}
+
+	// Success
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"context"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ReadPacket reads a payload together with the RSSI measured at sync
+// address match, the AFC correction and the frequency error. It returns
+// nil if the context is done before a payload is received
+func (this *rfm69) ReadPacket(ctx context.Context) (*sensors.RFMPacket, error) {
+	this.log.Debug("<sensors.RFM69.ReadPacket>{ }")
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.readPacket(ctx)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *rfm69) readPacket(ctx context.Context) (*sensors.RFMPacket, error) {
+	// Ensure we're in RX or listen mode or else return "OutOfOrder" message
+	if this.mode != sensors.RFM_MODE_RX && this.listen_on == false {
+		this.log.Debug("Expected mode=%v, got %v", sensors.RFM_MODE_RX, this.mode)
+		return nil, gopi.ErrOutOfOrder
+	}
+
+	// Restore the DIO0 mapping, which is lost if the device is reset
+	if this.dio0_events != nil {
+		if mapping, err := this.getDIO0Mapping(); err != nil {
+			return nil, err
+		} else if mapping != RFM_DIO0_RX_PAYLOADREADY {
+			if err := this.setDIO0Mapping(RFM_DIO0_RX_PAYLOADREADY); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Check payload, waiting for the DIO0 interrupt or the poll interval
+	// between checks. The RSSI is read and a frequency error measurement
+	// started when the sync address match is seen
+	packet := new(sensors.RFMPacket)
+	sync_seen := false
+	this.dio0_ts = time.Time{}
+	for {
+		if payload_ready, err := this.recvPayloadReady(); err != nil {
+			return nil, err
+		} else if payload_ready {
+			// Use the time of the interrupt when there was one
+			if this.dio0_ts.IsZero() {
+				packet.Timestamp = time.Now()
+			} else {
+				packet.Timestamp = this.dio0_ts
+			}
+			break
+		} else if sync_seen == false {
+			if sync_match, err := this.getIRQFlags1(RFM_IRQFLAGS1_SYNCADDRESSMATCH); err != nil {
+				return nil, err
+			} else if to_uint8_bool(sync_match) {
+				if err := this.measurePacket(packet); err != nil {
+					return nil, err
+				}
+				sync_seen = true
+			}
+		}
+		if this.waitPayload(ctx) == false {
+			// Context finished without FIFO
+			return nil, nil
+		}
+	}
+
+	// When the sync address match was missed, such as when waiting for
+	// an interrupt, measure the signal while still in RX
+	if sync_seen == false {
+		if err := this.measurePacket(packet); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read payload and CRC status, then the frequency error and correction
+	if data, err := this.recvPayload(); err != nil {
+		return nil, err
+	} else if crc_ok, err := this.recvCRCOk(); err != nil {
+		return nil, err
+	} else if fei, err := this.readreg_int16(RFM_REG_FEIMSB); err != nil {
+		return nil, err
+	} else if afc, err := this.getAFC(); err != nil {
+		return nil, err
+	} else {
+		packet.FreqCarrier = this.FreqCarrier()
+		packet.Payload = this.stripRadioHead(packet, this.stripAddress(packet, data))
+		packet.CRCOk = crc_ok
+		packet.FEI = int(fei) * RFM_FSTEP_HZ
+		packet.AFC = int(afc) * RFM_FSTEP_HZ
+		this.afc = afc
+	}
+
+	this.listenWake()
+	return packet, nil
+}
+
+// stripAddress removes the address byte from a payload when packet
+// filtering is on, and records the address in the packet. For variable
+// length packets the address follows the length byte, which is adjusted
+func (this *rfm69) stripAddress(packet *sensors.RFMPacket, data []byte) []byte {
+	if this.packet_filter == sensors.RFM_PACKET_FILTER_NONE {
+		return data
+	}
+	offset := 0
+	if this.packet_format == sensors.RFM_PACKET_FORMAT_VARIABLE {
+		offset = 1
+	}
+	if len(data) <= offset {
+		return data
+	}
+	packet.Filtered = true
+	packet.Address = data[offset]
+	payload := append(append([]byte{}, data[:offset]...), data[offset+1:]...)
+	if offset > 0 && payload[0] > 0 {
+		payload[0]--
+	}
+	return payload
+}
+
+// measurePacket reads the RSSI and starts a frequency error measurement
+func (this *rfm69) measurePacket(packet *sensors.RFMPacket) error {
+	if value, err := this.getRegRSSIValue(); err != nil {
+		return err
+	} else if err := this.setAFCControl(this.afc_mode, true, false, false); err != nil {
+		return err
+	} else {
+		packet.RSSI = -float32(value) / 2.0
+	}
+
+	// Success
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// RegPaLevel amplifier bits and output power mask
+	RFM_PALEVEL_PA0   = 0x80
+	RFM_PALEVEL_PA1   = 0x40
+	RFM_PALEVEL_PA2   = 0x20
+	RFM_PALEVEL_POWER = 0x1F
+
+	// RegOcp values, with over current protection on at 95mA, or off
+	RFM_OCP_ON  = 0x1A
+	RFM_OCP_OFF = 0x0F
+
+	// RegTestPa1 and RegTestPa2 values for normal and +20dBm operation
+	RFM_TESTPA1_NORMAL = 0x55
+	RFM_TESTPA1_BOOST  = 0x5D
+	RFM_TESTPA2_NORMAL = 0x70
+	RFM_TESTPA2_BOOST  = 0x7C
+
+	// Output power limits in dBm. The high power variants (RFM69HW and
+	// RFM69HCW) do not have PA0 connected, and can only use PA1 and PA2
+	RFM_POWER_MIN           = -18
+	RFM_POWER_MAX           = 13
+	RFM_POWER_HIGH_MIN      = -2
+	RFM_POWER_HIGH_PA1_MAX  = 13
+	RFM_POWER_HIGH_PA12_MAX = 17
+	RFM_POWER_HIGH_MAX      = 20
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return output power in dBm, as read back from the registers
+func (this *rfm69) OutputPower() int {
+	return this.output_power
+}
+
+// Return the PA ramp time
+func (this *rfm69) PARamp() sensors.RFMPARamp {
+	return this.pa_ramp
+}
+
+// SetPARamp sets the rise and fall time of the power amplifier in FSK
+// mode. Slower ramps reduce spectral spread at the start and end of
+// each transmission
+func (this *rfm69) SetPARamp(ramp sensors.RFMPARamp) error {
+	this.log.Debug("<sensors.RFM69.SetPARamp>{ ramp=%v }", ramp)
+
+	if ramp > sensors.RFM_PARAMP_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setPARamp(ramp); err != nil {
+		return err
+	}
+
+	// Read
+	if ramp_read, err := this.getPARamp(); err != nil {
+		return err
+	} else if ramp_read != ramp {
+		this.log.Debug2("SetPARamp expecting ramp=%v, got=%v", ramp, ramp_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.pa_ramp = ramp_read
+	}
+
+	// Success
+	return nil
+}
+
+// SetOutputPower sets the output power in dBm, selecting the power
+// amplifiers for the module variant. Above +17dBm on high power modules
+// the boost registers are set whenever the radio is in TX mode
+func (this *rfm69) SetOutputPower(dbm int) error {
+	this.log.Debug("<sensors.RFM69.SetOutputPower>{ dbm=%v high_power=%v }", dbm, this.high_power)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if pa_level, pa_boost, err := paLevel(dbm, this.high_power); err != nil {
+		this.log.Debug2("SetOutputPower: invalid power level %vdBm (high_power=%v)", dbm, this.high_power)
+		return err
+	} else if err := this.writereg_uint8(RFM_REG_PALEVEL, pa_level); err != nil {
+		return err
+	} else if pa_level_read, err := this.readreg_uint8(RFM_REG_PALEVEL); err != nil {
+		return err
+	} else if pa_level_read != pa_level {
+		this.log.Debug2("SetOutputPower expecting pa_level=0x%02X, got=0x%02X", pa_level, pa_level_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.output_power = outputPower(pa_level_read, pa_boost)
+		this.pa_boost = pa_boost
+	}
+
+	// Set or clear the boost registers when already transmitting
+	if this.mode == sensors.RFM_MODE_TX {
+		return this.setHighPower(this.pa_boost)
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// paLevel returns the RegPaLevel value for an output power, and whether
+// the boost registers need to be set during TX
+func paLevel(dbm int, high_power bool) (uint8, bool, error) {
+	if high_power == false {
+		if dbm < RFM_POWER_MIN || dbm > RFM_POWER_MAX {
+			return 0, false, gopi.ErrBadParameter
+		}
+		return RFM_PALEVEL_PA0 | uint8(dbm+18)&RFM_PALEVEL_POWER, false, nil
+	}
+	if dbm < RFM_POWER_HIGH_MIN || dbm > RFM_POWER_HIGH_MAX {
+		return 0, false, gopi.ErrBadParameter
+	} else if dbm <= RFM_POWER_HIGH_PA1_MAX {
+		return RFM_PALEVEL_PA1 | uint8(dbm+18)&RFM_PALEVEL_POWER, false, nil
+	} else if dbm <= RFM_POWER_HIGH_PA12_MAX {
+		return RFM_PALEVEL_PA1 | RFM_PALEVEL_PA2 | uint8(dbm+14)&RFM_PALEVEL_POWER, false, nil
+	} else {
+		return RFM_PALEVEL_PA1 | RFM_PALEVEL_PA2 | uint8(dbm+11)&RFM_PALEVEL_POWER, true, nil
+	}
+}
+
+// outputPower returns the output power in dBm for a RegPaLevel value,
+// and whether the boost registers are used during TX
+func outputPower(pa_level uint8, pa_boost bool) int {
+	power := int(pa_level & RFM_PALEVEL_POWER)
+	if pa_boost {
+		return power - 11
+	} else if pa_level&(RFM_PALEVEL_PA1|RFM_PALEVEL_PA2) == RFM_PALEVEL_PA1|RFM_PALEVEL_PA2 {
+		return power - 14
+	} else {
+		return power - 18
+	}
+}
+
+// setHighPower sets the boost registers for +20dBm and disables over
+// current protection, or restores the normal values. The boost values
+// must only be used in TX mode, so they are cleared before any other mode
+func (this *rfm69) setHighPower(enabled bool) error {
+	if enabled == this.pa_boosted {
+		return nil
+	}
+	ocp, testpa1, testpa2 := uint8(RFM_OCP_ON), uint8(RFM_TESTPA1_NORMAL), uint8(RFM_TESTPA2_NORMAL)
+	if enabled {
+		ocp, testpa1, testpa2 = RFM_OCP_OFF, RFM_TESTPA1_BOOST, RFM_TESTPA2_BOOST
+	}
+	if err := this.writereg_uint8(RFM_REG_OCP, ocp); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM_REG_TESTPA1, testpa1); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM_REG_TESTPA2, testpa2); err != nil {
+		return err
+	} else {
+		this.pa_boosted = enabled
+	}
+
+	// Success
+	return nil
+}
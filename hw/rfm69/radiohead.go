@@ -0,0 +1,118 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"encoding/hex"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// RadioHead RF69 packet settings: variable length packets with
+	// whitening and CRC, a four byte preamble, and the sync word 0x2DD4.
+	// Address filtering is done in software on the header
+	RFM_RADIOHEAD_PREAMBLE_SIZE = 4
+	RFM_RADIOHEAD_HEADER_SIZE   = 4
+	RFM_RADIOHEAD_PAYLOAD_MAX   = RFM_AES_PAYLOAD_MAX - RFM_RADIOHEAD_HEADER_SIZE
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	RFM_RADIOHEAD_SYNCWORD = []byte{0x2D, 0xD4}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return true if RadioHead packet mode is on
+func (this *rfm69) RadioHead() bool {
+	return this.radiohead
+}
+
+// SetRadioHead configures the packet settings used by the RadioHead RF69
+// driver, so that packets can be exchanged with Arduino RadioHead nodes.
+// The modulation, bitrate and frequency need to match the modem
+// configuration of the nodes, and are not changed. When switched off the
+// packet settings are left as they are, but the header is no longer
+// removed from received payloads
+func (this *rfm69) SetRadioHead(enabled bool) error {
+	this.log.Debug("<sensors.RFM69.SetRadioHead>{ enabled=%v }", enabled)
+
+	if enabled {
+		if err := this.SetPacketFormat(sensors.RFM_PACKET_FORMAT_VARIABLE); err != nil {
+			return err
+		} else if err := this.SetPacketCoding(sensors.RFM_PACKET_CODING_WHITENING); err != nil {
+			return err
+		} else if err := this.SetPacketFilter(sensors.RFM_PACKET_FILTER_NONE); err != nil {
+			return err
+		} else if err := this.SetPacketCRC(sensors.RFM_PACKET_CRC_AUTOCLEAR_ON); err != nil {
+			return err
+		} else if err := this.SetPreambleSize(RFM_RADIOHEAD_PREAMBLE_SIZE); err != nil {
+			return err
+		} else if err := this.SetSyncWord(RFM_RADIOHEAD_SYNCWORD); err != nil {
+			return err
+		} else if err := this.SetPayloadSize(RFM_AES_PAYLOAD_MAX); err != nil {
+			return err
+		}
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.radiohead = enabled
+
+	// Success
+	return nil
+}
+
+// WriteRadioHead transmits a payload with the RadioHead length byte
+// and header
+func (this *rfm69) WriteRadioHead(header sensors.RFMRadioHeadHeader, data []byte, repeat uint) error {
+	this.log.Debug("<sensors.RFM69.WriteRadioHead>{ header=%+v data=%v repeat=%v }", header, strings.ToUpper(hex.EncodeToString(data)), repeat)
+
+	if this.radiohead == false {
+		return gopi.ErrOutOfOrder
+	} else if len(data) > RFM_RADIOHEAD_PAYLOAD_MAX {
+		this.log.Debug2("sensors.RFM69.WriteRadioHead: data length is %v, expected <= %v", len(data), RFM_RADIOHEAD_PAYLOAD_MAX)
+		return gopi.ErrBadParameter
+	}
+
+	packet := make([]byte, 0, len(data)+RFM_RADIOHEAD_HEADER_SIZE+1)
+	packet = append(packet, uint8(len(data)+RFM_RADIOHEAD_HEADER_SIZE), header.To, header.From, header.ID, header.Flags)
+	packet = append(packet, data...)
+	return this.WritePayload(packet, repeat)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// stripRadioHead removes the length byte and RadioHead header from a
+// variable length payload, and records the header in the packet
+func (this *rfm69) stripRadioHead(packet *sensors.RFMPacket, data []byte) []byte {
+	if this.radiohead == false || len(data) < RFM_RADIOHEAD_HEADER_SIZE+1 {
+		return data
+	}
+	packet.Header = &sensors.RFMRadioHeadHeader{
+		To:    data[1],
+		From:  data[2],
+		ID:    data[3],
+		Flags: data[4],
+	}
+	return data[RFM_RADIOHEAD_HEADER_SIZE+1:]
+}
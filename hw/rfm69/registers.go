@@ -41,6 +41,7 @@ const (
 	RFM_REG_FRFLSB        register = 0x09 /* RF Carrier Frequency, least significant bits */
 	RFM_REG_OSC1          register = 0x0A /* RC Oscillators Settings */
 	RFM_REG_AFCCTRL       register = 0x0B /* AFC Control in low modulation index situations */
+	RFM_REG_LOWBAT        register = 0x0C /* Low Battery Indicator Settings */
 	RFM_REG_LISTEN1       register = 0x0D /* Listen mode settings */
 	RFM_REG_LISTEN2       register = 0x0E /* Listen mode idle duration */
 	RFM_REG_LISTEN3       register = 0x0F /* Listen mode Rx duration */
@@ -125,6 +126,7 @@ const (
 	RFM_IRQFLAGS1_AUTOMODE         uint8 = 0x02
 	RFM_IRQFLAGS1_SYNCADDRESSMATCH uint8 = 0x01
 
+	RFM_IRQFLAGS2_LOWBAT       uint8 = 0x01
 	RFM_IRQFLAGS2_CRCOK        uint8 = 0x02
 	RFM_IRQFLAGS2_PAYLOADREADY uint8 = 0x04
 	RFM_IRQFLAGS2_PACKETSENT   uint8 = 0x08
@@ -233,6 +235,59 @@ func (this *rfm69) setAFCRoutine(afc_routine sensors.RFMAFCRoutine) error {
 	return this.writereg_uint8(RFM_REG_AFCCTRL, value)
 }
 
+// Read RFM_REG_TESTAFC - low-beta AFC offset in 488Hz steps
+func (this *rfm69) getAFCLowBetaOffset() (int8, error) {
+	if value, err := this.readreg_uint8(RFM_REG_TESTAFC); err != nil {
+		return 0, err
+	} else {
+		return int8(value), nil
+	}
+}
+
+// Write RFM_REG_TESTAFC register
+func (this *rfm69) setAFCLowBetaOffset(value int8) error {
+	return this.writereg_uint8(RFM_REG_TESTAFC, uint8(value))
+}
+
+// Read RFM_REG_TESTLNA - sensitivity boost
+func (this *rfm69) getSensitivityBoost() (bool, error) {
+	if value, err := this.readreg_uint8(RFM_REG_TESTLNA); err != nil {
+		return false, err
+	} else {
+		return value == RFM_TESTLNA_BOOST, nil
+	}
+}
+
+// Write RFM_REG_TESTLNA register
+func (this *rfm69) setSensitivityBoost(enabled bool) error {
+	if enabled {
+		return this.writereg_uint8(RFM_REG_TESTLNA, RFM_TESTLNA_BOOST)
+	} else {
+		return this.writereg_uint8(RFM_REG_TESTLNA, RFM_TESTLNA_NORMAL)
+	}
+}
+
+// Read RFM_REG_TESTDAGC - continuous DAGC
+func (this *rfm69) getDAGC() (bool, error) {
+	if value, err := this.readreg_uint8(RFM_REG_TESTDAGC); err != nil {
+		return false, err
+	} else {
+		return value != RFM_TESTDAGC_OFF, nil
+	}
+}
+
+// Write RFM_REG_TESTDAGC register. The value when DAGC is on depends
+// on whether the improved (low-beta) AFC routine is used
+func (this *rfm69) setDAGC(enabled bool, afc_routine sensors.RFMAFCRoutine) error {
+	if enabled == false {
+		return this.writereg_uint8(RFM_REG_TESTDAGC, RFM_TESTDAGC_OFF)
+	} else if afc_routine == sensors.RFM_AFCROUTINE_IMPROVED {
+		return this.writereg_uint8(RFM_REG_TESTDAGC, RFM_TESTDAGC_LOWBETA)
+	} else {
+		return this.writereg_uint8(RFM_REG_TESTDAGC, RFM_TESTDAGC_ON)
+	}
+}
+
 // Read RFM_REG_AFCFEI - mode, afc_done, fei_done
 func (this *rfm69) getAFCControl() (sensors.RFMAFCMode, bool, bool, error) {
 	if value, err := this.readreg_uint8(RFM_REG_AFCFEI); err != nil {
@@ -495,11 +550,21 @@ func (this *rfm69) setPacketConfig2(rx_inter_packet_delay uint8, rx_auto_restart
 	return this.writereg_uint8(RFM_REG_PACKETCONFIG2, value)
 }
 
+// Write RegPacketConfig2 register with the RestartRx bit set, which
+// restarts the receiver and so any automatic frequency correction
+func (this *rfm69) restartRX() error {
+	value := (this.rx_inter_packet_delay&0x0F)<<4 | 0x04 | to_bool_uint8(this.rx_auto_restart)<<1 | to_bool_uint8(this.aes_on)
+	return this.writereg_uint8(RFM_REG_PACKETCONFIG2, value)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // RFM_REG_IRQXFLAGS
 
 func (this *rfm69) getIRQFlags1(mask uint8) (uint8, error) {
 	value, err := this.readreg_uint8(RFM_REG_IRQFLAGS1)
+	if err == nil {
+		this.observeIRQFlags1(value)
+	}
 	return value & mask, err
 }
 
@@ -534,9 +599,63 @@ func (this *rfm69) getIRQFlags2(mask uint8) (uint8, error) {
 	if value&RFM_IRQFLAGS2_FIFOFULL != 0 {
 		this.log.Debug2("RFM_IRQFLAGS2_FIFOFULL")
 	}
+	if err == nil {
+		this.observeIRQFlags2(value)
+	}
 	return value & mask, err
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_DIOMAPPING1
+
+// Read DIO0 mapping
+func (this *rfm69) getDIO0Mapping() (uint8, error) {
+	if value, err := this.readreg_uint8(RFM_REG_DIOMAPPING1); err != nil {
+		return 0, err
+	} else {
+		return (value >> 6) & 0x03, nil
+	}
+}
+
+// Write DIO0 mapping, leaving DIO1 to DIO3 unchanged
+func (this *rfm69) setDIO0Mapping(mapping uint8) error {
+	if value, err := this.readreg_uint8(RFM_REG_DIOMAPPING1); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(RFM_REG_DIOMAPPING1, (value&0x3F)|((mapping&0x03)<<6))
+	}
+}
+
+// Read the mapping of any DIO pin
+func (this *rfm69) getDIOMapping(pin sensors.RFMDIOPin) (uint8, error) {
+	reg, shift := dioMappingRegister(pin)
+	if value, err := this.readreg_uint8(reg); err != nil {
+		return 0, err
+	} else {
+		return (value >> shift) & 0x03, nil
+	}
+}
+
+// Write the mapping of any DIO pin, leaving the other pins unchanged
+func (this *rfm69) setDIOMapping(pin sensors.RFMDIOPin, mapping uint8) error {
+	reg, shift := dioMappingRegister(pin)
+	if value, err := this.readreg_uint8(reg); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(reg, (value&^(0x03<<shift))|((mapping&0x03)<<shift))
+	}
+}
+
+// Return the register and bit position of a DIO pin mapping. DIO0 to
+// DIO3 are in RegDioMapping1 and DIO4 and DIO5 in RegDioMapping2
+func dioMappingRegister(pin sensors.RFMDIOPin) (register, uint) {
+	if pin < sensors.RFM_DIO4 {
+		return RFM_REG_DIOMAPPING1, 6 - 2*uint(pin)
+	} else {
+		return RFM_REG_DIOMAPPING2, 6 - 2*uint(pin-sensors.RFM_DIO4)
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // RFM_REG_FIFO
 
@@ -588,6 +707,35 @@ func (this *rfm69) recvFIFO() ([]byte, error) {
 	return buffer, nil
 }
 
+// recvPayload reads a received packet from the FIFO in a single burst
+// transfer, since the FIFO address auto-increments. For variable length
+// packets the length byte is read first. When the length is not known or
+// the packet is larger than the FIFO, it reverts to draining the FIFO
+func (this *rfm69) recvPayload() ([]byte, error) {
+	if this.packet_format == sensors.RFM_PACKET_FORMAT_FIXED {
+		if length := uint(this.payload_size); length == 0 || length > RFM_FIFO_SIZE {
+			return this.recvFIFO()
+		} else {
+			return this.readreg_uint8_array(RFM_REG_FIFO, length)
+		}
+	}
+	if length, err := this.readreg_uint8(RFM_REG_FIFO); err != nil {
+		return nil, err
+	} else if length == 0 {
+		return []byte{length}, nil
+	} else if uint(length) >= RFM_FIFO_SIZE {
+		if data, err := this.recvFIFO(); err != nil {
+			return nil, err
+		} else {
+			return append([]byte{length}, data...), nil
+		}
+	} else if data, err := this.readreg_uint8_array(RFM_REG_FIFO, uint(length)); err != nil {
+		return nil, err
+	} else {
+		return append([]byte{length}, data...), nil
+	}
+}
+
 func (this *rfm69) writeFIFO(data []byte) error {
 	return this.writereg_uint8_array(RFM_REG_FIFO, data)
 }
@@ -645,6 +793,40 @@ func (this *rfm69) getRegRSSIValue() (uint8, error) {
 	return this.readreg_uint8(RFM_REG_RSSIVALUE)
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_RSSITHRESH, RFM_REG_RXTIMEOUT1, RFM_REG_RXTIMEOUT2
+
+// Read RSSI threshold, in units of -0.5dBm
+func (this *rfm69) getRSSIThreshold() (uint8, error) {
+	return this.readreg_uint8(RFM_REG_RSSITHRESH)
+}
+
+// Write RSSI threshold, in units of -0.5dBm
+func (this *rfm69) setRSSIThreshold(value uint8) error {
+	return this.writereg_uint8(RFM_REG_RSSITHRESH, value)
+}
+
+// Read TimeoutRxStart and TimeoutRssiThresh, in units of 16 bit periods
+func (this *rfm69) getRXTimeout() (uint8, uint8, error) {
+	if rx_start, err := this.readreg_uint8(RFM_REG_RXTIMEOUT1); err != nil {
+		return 0, 0, err
+	} else if rssi_thresh, err := this.readreg_uint8(RFM_REG_RXTIMEOUT2); err != nil {
+		return 0, 0, err
+	} else {
+		return rx_start, rssi_thresh, nil
+	}
+}
+
+// Write TimeoutRxStart and TimeoutRssiThresh, in units of 16 bit periods
+func (this *rfm69) setRXTimeout(rx_start, rssi_thresh uint8) error {
+	if err := this.writereg_uint8(RFM_REG_RXTIMEOUT1, rx_start); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM_REG_RXTIMEOUT2, rssi_thresh); err != nil {
+		return err
+	}
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // RFM_REG_LNA
 
@@ -686,3 +868,157 @@ func (this *rfm69) setRegRXBW(frequency sensors.RFMRXBWFrequency, cutoff sensors
 		uint8(frequency&sensors.RFM_RXBW_FREQUENCY_MAX) | uint8(cutoff&sensors.RFM_RXBW_CUTOFF_MAX)<<5
 	return this.writereg_uint8(RFM_REG_RXBW, value)
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_OOKPEAK, RFM_REG_OOKAVG, RFM_REG_OOKFIX
+
+// Read RegOokPeak register - OokThreshType, OokPeakTheshStep, OokPeakThreshDec
+func (this *rfm69) getRegOOKPeak() (sensors.RFMOOKThresholdType, sensors.RFMOOKThresholdStep, sensors.RFMOOKThresholdDecrement, error) {
+	if value, err := this.readreg_uint8(RFM_REG_OOKPEAK); err != nil {
+		return 0, 0, 0, err
+	} else {
+		threshold_type := sensors.RFMOOKThresholdType(value>>6) & sensors.RFM_OOK_THRESHOLD_MAX
+		step := sensors.RFMOOKThresholdStep(value>>3) & sensors.RFM_OOK_THRESHOLD_STEP_MAX
+		dec := sensors.RFMOOKThresholdDecrement(value) & sensors.RFM_OOK_THRESHOLD_DEC_MAX
+		return threshold_type, step, dec, nil
+	}
+}
+
+func (this *rfm69) setRegOOKPeak(threshold_type sensors.RFMOOKThresholdType, step sensors.RFMOOKThresholdStep, dec sensors.RFMOOKThresholdDecrement) error {
+	value :=
+		uint8(threshold_type&sensors.RFM_OOK_THRESHOLD_MAX)<<6 |
+			uint8(step&sensors.RFM_OOK_THRESHOLD_STEP_MAX)<<3 |
+			uint8(dec&sensors.RFM_OOK_THRESHOLD_DEC_MAX)
+	return this.writereg_uint8(RFM_REG_OOKPEAK, value)
+}
+
+// Read RegOokAvg register - OokAverageThreshFilt
+func (this *rfm69) getRegOOKAvg() (sensors.RFMOOKAverageFilter, error) {
+	if value, err := this.readreg_uint8(RFM_REG_OOKAVG); err != nil {
+		return 0, err
+	} else {
+		return sensors.RFMOOKAverageFilter(value>>6) & sensors.RFM_OOK_AVERAGE_FILTER_MAX, nil
+	}
+}
+
+func (this *rfm69) setRegOOKAvg(filter sensors.RFMOOKAverageFilter) error {
+	return this.writereg_uint8(RFM_REG_OOKAVG, uint8(filter&sensors.RFM_OOK_AVERAGE_FILTER_MAX)<<6)
+}
+
+// Read RegOokFix register - OokFixedThresh in dB
+func (this *rfm69) getRegOOKFix() (uint8, error) {
+	return this.readreg_uint8(RFM_REG_OOKFIX)
+}
+
+func (this *rfm69) setRegOOKFix(db uint8) error {
+	return this.writereg_uint8(RFM_REG_OOKFIX, db)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_PARAMP
+
+// Read RegPaRamp register
+func (this *rfm69) getPARamp() (sensors.RFMPARamp, error) {
+	if value, err := this.readreg_uint8(RFM_REG_PARAMP); err != nil {
+		return 0, err
+	} else {
+		return sensors.RFMPARamp(value) & sensors.RFM_PARAMP_MAX, nil
+	}
+}
+
+func (this *rfm69) setPARamp(ramp sensors.RFMPARamp) error {
+	return this.writereg_uint8(RFM_REG_PARAMP, uint8(ramp&sensors.RFM_PARAMP_MAX))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_AUTOMODES
+
+// Read RegAutoModes register - EnterCondition, ExitCondition, IntermediateMode
+func (this *rfm69) getAutoModes() (sensors.RFMAutoModeEnter, sensors.RFMAutoModeExit, sensors.RFMAutoModeIntermediate, error) {
+	if value, err := this.readreg_uint8(RFM_REG_AUTOMODES); err != nil {
+		return 0, 0, 0, err
+	} else {
+		enter := sensors.RFMAutoModeEnter(value>>5) & sensors.RFM_AUTOMODE_ENTER_MAX
+		exit := sensors.RFMAutoModeExit(value>>2) & sensors.RFM_AUTOMODE_EXIT_MAX
+		intermediate := sensors.RFMAutoModeIntermediate(value) & sensors.RFM_AUTOMODE_INTERMEDIATE_MAX
+		return enter, exit, intermediate, nil
+	}
+}
+
+func (this *rfm69) setAutoModes(enter sensors.RFMAutoModeEnter, exit sensors.RFMAutoModeExit, intermediate sensors.RFMAutoModeIntermediate) error {
+	value := uint8(enter&sensors.RFM_AUTOMODE_ENTER_MAX)<<5 | uint8(exit&sensors.RFM_AUTOMODE_EXIT_MAX)<<2 | uint8(intermediate&sensors.RFM_AUTOMODE_INTERMEDIATE_MAX)
+	return this.writereg_uint8(RFM_REG_AUTOMODES, value)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_OSC1
+
+// Get RcCalDone bit
+func (this *rfm69) getRCCalDone() (bool, error) {
+	if value, err := this.readreg_uint8(RFM_REG_OSC1); err != nil {
+		return false, err
+	} else {
+		return to_uint8_bool(value & 0x40), nil
+	}
+}
+
+// Set RcCalStart bit, leaving the other bits unchanged
+func (this *rfm69) setRCCalStart() error {
+	if value, err := this.readreg_uint8(RFM_REG_OSC1); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(RFM_REG_OSC1, value|0x80)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_LOWBAT
+
+// Read LowBatOn and LowBatTrim
+func (this *rfm69) getLowBattery() (bool, sensors.RFMLowBatTrim, error) {
+	if value, err := this.readreg_uint8(RFM_REG_LOWBAT); err != nil {
+		return false, 0, err
+	} else {
+		return to_uint8_bool(value & 0x08), sensors.RFMLowBatTrim(value) & sensors.RFM_LOWBAT_TRIM_MAX, nil
+	}
+}
+
+// Write LowBatOn and LowBatTrim
+func (this *rfm69) setLowBattery(enabled bool, trim sensors.RFMLowBatTrim) error {
+	value := uint8(trim & sensors.RFM_LOWBAT_TRIM_MAX)
+	if enabled {
+		value |= 0x08
+	}
+	return this.writereg_uint8(RFM_REG_LOWBAT, value)
+}
+
+// Read LowBatMonitor, which is set when the supply voltage is below
+// the threshold
+func (this *rfm69) getLowBatteryMonitor() (bool, error) {
+	if value, err := this.readreg_uint8(RFM_REG_LOWBAT); err != nil {
+		return false, err
+	} else {
+		return to_uint8_bool(value & 0x10), nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM_REG_DIOMAPPING2
+
+// Read ClkOut frequency
+func (this *rfm69) getClockOut() (sensors.RFMClockOut, error) {
+	if value, err := this.readreg_uint8(RFM_REG_DIOMAPPING2); err != nil {
+		return 0, err
+	} else {
+		return sensors.RFMClockOut(value) & sensors.RFM_CLKOUT_MAX, nil
+	}
+}
+
+// Write ClkOut frequency, leaving the DIO4 and DIO5 mappings unchanged
+func (this *rfm69) setClockOut(clkout sensors.RFMClockOut) error {
+	if value, err := this.readreg_uint8(RFM_REG_DIOMAPPING2); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(RFM_REG_DIOMAPPING2, (value&0xF8)|uint8(clkout&sensors.RFM_CLKOUT_MAX))
+	}
+}
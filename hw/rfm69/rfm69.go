@@ -19,6 +19,7 @@ import (
 	// Frameworks
 	"github.com/djthorpe/gopi"
 	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/util/backoff"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -30,6 +31,31 @@ type rfm69 struct {
 	log  gopi.Logger
 	lock sync.Mutex
 
+	verify          bool
+	retries         uint
+	backoff         backoff.Policy
+	delay           time.Duration
+	debug_registers bool
+
+	dio0        gopi.GPIOPin
+	dio0_gpio   gopi.GPIO
+	dio0_events <-chan gopi.Event
+	dio0_ts     time.Time
+	dio_mapping [sensors.RFM_DIO_MAX + 1]sensors.RFMDIOFunction
+
+	scan_index uint
+	radiohead  bool
+
+	debug     debug_pubsub
+	irqflags1 uint8
+	irqflags2 uint8
+
+	high_power   bool
+	output_power int
+	pa_boost     bool
+	pa_boosted   bool
+	pa_ramp      sensors.RFMPARamp
+
 	version               uint8
 	mode                  sensors.RFMMode
 	sequencer_off         bool
@@ -37,8 +63,10 @@ type rfm69 struct {
 	data_mode             sensors.RFMDataMode
 	modulation            sensors.RFMModulation
 	bitrate               uint16
+	bitrate_request       uint
 	frf                   uint32
 	fdev                  uint16
+	fdev_request          uint
 	aes_key               []byte
 	aes_on                bool
 	sync_word             []byte
@@ -48,6 +76,13 @@ type rfm69 struct {
 	rx_inter_packet_delay uint8
 	rx_auto_restart       bool
 	tx_start              sensors.RFMTXStart
+	automode_enter        sensors.RFMAutoModeEnter
+	automode_exit         sensors.RFMAutoModeExit
+	automode_intermediate sensors.RFMAutoModeIntermediate
+	clkout                sensors.RFMClockOut
+	lowbat_on             bool
+	lowbat_trim           sensors.RFMLowBatTrim
+	low_battery           bool
 	fifo_threshold        uint8
 	fifo_fill_condition   bool
 	node_address          uint8
@@ -62,31 +97,58 @@ type rfm69 struct {
 	afc                   int16
 	afc_mode              sensors.RFMAFCMode
 	afc_routine           sensors.RFMAFCRoutine
+	afc_lowbeta_offset    int8
 	lna_impedance         sensors.RFMLNAImpedance
 	lna_gain              sensors.RFMLNAGain
+	sensitivity_boost     bool
+	dagc                  bool
 	rxbw_frequency        sensors.RFMRXBWFrequency
 	rxbw_cutoff           sensors.RFMRXBWCutoff
+	ook_threshold_type    sensors.RFMOOKThresholdType
+	ook_threshold_step    sensors.RFMOOKThresholdStep
+	ook_threshold_dec     sensors.RFMOOKThresholdDecrement
+	ook_fixed_threshold   uint8
+	ook_average_filter    sensors.RFMOOKAverageFilter
+	rssi_threshold        uint8
+	rx_timeout_start      uint8
+	rx_timeout_rssi       uint8
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // CONSTANTS
 
 const (
-	RFM_SPI_MODE       = gopi.SPI_MODE_0
-	RFM_SPI_SPEEDHZ    = 4000000 // 4MHz
-	RFM_VERSION_VALUE  = 0x24
-	RFM_AESKEY_BYTES   = 16
-	RFM_SYNCWORD_BYTES = 8
-	RFM_FXOSC_MHZ      = 32         // Crystal oscillator frequency MHz
-	RFM_FSTEP_HZ       = 61         // Frequency synthesizer step
-	RFM_BITRATE_MIN    = 500        // bits per second
-	RFM_BITRATE_MAX    = 300 * 1024 // bits per second
-	RFM_FDEV_MAX       = 0x3FFF     // Maximum value of FDEV
-	RFM_FRF_MAX        = 0xFFFFFF   // Maximum value of FRF
-	RFM_FIFO_SIZE      = 66         // Bytes
-	RFM_TEMP_COEF      = 160
+	RFM_SPI_MODE        = gopi.SPI_MODE_0
+	RFM_SPI_SPEEDHZ     = 4000000 // 4MHz
+	RFM_VERSION_VALUE   = 0x24
+	RFM_AESKEY_BYTES    = 16
+	RFM_SYNCWORD_BYTES  = 8
+	RFM_FXOSC_MHZ       = 32         // Crystal oscillator frequency MHz
+	RFM_FSTEP_HZ        = 61         // Frequency synthesizer step
+	RFM_BITRATE_MIN     = 500        // bits per second
+	RFM_BITRATE_MAX     = 300 * 1024 // bits per second
+	RFM_FDEV_MAX        = 0x3FFF     // Maximum value of FDEV
+	RFM_FRF_MAX         = 0xFFFFFF   // Maximum value of FRF
+	RFM_FIFO_SIZE       = 66         // Bytes
+	RFM_AES_PAYLOAD_MAX = 64         // Bytes, maximum payload when AES is enabled
+	RFM_RSSI_MIN        = -127.5     // dBm
+	RFM_AFCOFFSET_STEP  = 488        // Low-beta AFC offset step, Hz
+	RFM_TEMP_COEF       = 160
 )
 
+////////////////////////////////////////////////////////////////////////////////
+// VERSION
+
+// Read the version register, which can be polled to check
+// the device is ready after a reset
+func (this *rfm69) ReadVersion() (uint8, error) {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.getVersion()
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // MODE, DATA MODE AND MODULATION
 
@@ -131,6 +193,11 @@ func (this *rfm69) SetMode(mode sensors.RFMMode) error {
 		}
 	}
 
+	// The high power boost registers are only set in TX mode
+	if err := this.setHighPower(mode == sensors.RFM_MODE_TX && this.pa_boost); err != nil {
+		return err
+	}
+
 	// Write mode and read back again
 	if err := this.setOpMode(mode, false, false, this.sequencer_off); err != nil {
 		return err
@@ -175,6 +242,7 @@ func (this *rfm69) SetMode(mode sensors.RFMMode) error {
 		this.listen_on = listen_on_read
 		this.sequencer_off = sequencer_off_read
 	}
+	this.emitDebug(sensors.RFM_EVENT_MODE)
 
 	// If RX mode then read AFC value
 	if this.mode == sensors.RFM_MODE_RX {
@@ -250,7 +318,7 @@ func (this *rfm69) SetModulation(modulation sensors.RFMModulation) error {
 
 // Return bitrate in bits per second
 func (this *rfm69) Bitrate() uint {
-	return uint(float64(RFM_FXOSC_MHZ*1E6) / float64(this.bitrate))
+	return uint(math.Round(BitrateFromRegister(this.bitrate)))
 }
 
 // Return frequency carrier in Hz
@@ -265,8 +333,18 @@ func (this *rfm69) SetBitrate(bits_per_second uint) error {
 		return gopi.ErrBadParameter
 	}
 
-	msb_lsb := uint16(float64(RFM_FXOSC_MHZ*1E6) / float64(bits_per_second))
-	return this.SetBitrateUint16(msb_lsb)
+	// Set the closest register value, and keep the requested bitrate
+	if err := this.SetBitrateUint16(BitrateToRegister(bits_per_second)); err != nil {
+		return err
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.bitrate_request = bits_per_second
+
+	// Success
+	return nil
 }
 
 // Set bitrate as register value
@@ -290,6 +368,7 @@ func (this *rfm69) SetBitrateUint16(value uint16) error {
 		return sensors.ErrUnexpectedResponse
 	} else {
 		this.bitrate = value_read
+		this.bitrate_request = 0
 	}
 
 	// Success
@@ -303,7 +382,7 @@ func (this *rfm69) FreqCarrier() uint {
 
 // Return frequency deviation in Hz
 func (this *rfm69) FreqDeviation() uint {
-	return uint(RFM_FSTEP_HZ) * uint(this.fdev)
+	return uint(math.Round(FreqDeviationFromRegister(this.fdev)))
 }
 
 func (this *rfm69) SetFreqCarrier(hertz uint) error {
@@ -325,12 +404,23 @@ func (this *rfm69) SetFreqCarrier(hertz uint) error {
 func (this *rfm69) SetFreqDeviation(hertz uint) error {
 	this.log.Debug("<sensors.RFM69.SetFreqDeviation>{ hertz=%v }", hertz)
 
-	msb_lsb := uint16(math.Ceil(float64(hertz) / float64(RFM_FSTEP_HZ)))
+	msb_lsb := FreqDeviationToRegister(hertz)
 	if msb_lsb > RFM_FDEV_MAX {
 		return gopi.ErrBadParameter
 	}
 
-	return this.SetFreqDeviationUint16(msb_lsb)
+	// Set the closest register value, and keep the requested deviation
+	if err := this.SetFreqDeviationUint16(uint16(msb_lsb)); err != nil {
+		return err
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.fdev_request = hertz
+
+	// Success
+	return nil
 }
 
 // Set frequency deviation register value
@@ -354,6 +444,7 @@ func (this *rfm69) SetFreqDeviationUint16(value uint16) error {
 		return sensors.ErrUnexpectedResponse
 	} else {
 		this.fdev = value
+		this.fdev_request = 0
 	}
 
 	// Success
@@ -576,11 +667,14 @@ func (this *rfm69) SetAESKeyEx(key []byte) error {
 		this.log.Debug2("SetAESKeyEx expecting value=%v, got=%v", key, key_read)
 		return sensors.ErrUnexpectedResponse
 	} else {
-		this.aes_key = key
+		this.aes_key = append([]byte(nil), key...)
 		return nil
 	}
 }
 
+// SetAESKey programs the AES key and enables encryption, or disables
+// encryption when the key is nil. The payload size is reduced when it
+// is larger than the radio can encrypt
 func (this *rfm69) SetAESKey(key []byte) error {
 	this.log.Debug("<sensors.RFM69.SetAESKey>{ key=%v }", strings.ToUpper(hex.EncodeToString(key)))
 
@@ -588,9 +682,13 @@ func (this *rfm69) SetAESKey(key []byte) error {
 		return this.SetAESEnabled(false)
 	} else if err := this.SetAESKeyEx(key); err != nil {
 		return err
-	} else {
-		return this.SetAESEnabled(true)
+	} else if this.payload_size > RFM_AES_PAYLOAD_MAX {
+		this.log.Debug2("SetAESKey: reducing payload_size from %v to %v", this.payload_size, RFM_AES_PAYLOAD_MAX)
+		if err := this.SetPayloadSize(RFM_AES_PAYLOAD_MAX); err != nil {
+			return err
+		}
 	}
+	return this.SetAESEnabled(true)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -772,7 +870,13 @@ func (this *rfm69) SetPayloadSize(payload_size uint8) error {
 	this.lock.Lock()
 	defer this.lock.Unlock()
 
-	// Get Preamble Size register value
+	// Encrypted payloads are limited in size
+	if this.aes_on && payload_size > RFM_AES_PAYLOAD_MAX {
+		this.log.Debug2("SetPayloadSize: payload_size is %v, expected <= %v when AES is enabled", payload_size, RFM_AES_PAYLOAD_MAX)
+		return gopi.ErrBadParameter
+	}
+
+	// Set Payload Size register value
 	if err := this.setPayloadSize(payload_size); err != nil {
 		return err
 	} else if payload_size_read, err := this.getPayloadSize(); err != nil {
@@ -11,6 +11,10 @@ package rfm69
 
 import (
 	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -40,3 +44,86 @@ func (this *rfm69) MeasureRSSI() (float32, error) {
 		return -float32(value) / 2.0, nil
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// RSSI THRESHOLD AND RX TIMEOUTS
+
+// Return RSSI threshold in dBm
+func (this *rfm69) RSSIThreshold() float32 {
+	return -float32(this.rssi_threshold) / 2.0
+}
+
+// Set RSSI threshold in dBm, between -127.5 and 0, above which
+// the receiver starts looking for the preamble
+func (this *rfm69) SetRSSIThreshold(dbm float32) error {
+	this.log.Debug("<sensors.RFM69.SetRSSIThreshold>{ dbm=%v }", dbm)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if dbm > 0 || dbm < RFM_RSSI_MIN {
+		this.log.Debug2("SetRSSIThreshold: dbm is %v, expected %v <= dbm <= 0", dbm, RFM_RSSI_MIN)
+		return gopi.ErrBadParameter
+	}
+	value := uint8(-dbm * 2.0)
+	if err := this.setRSSIThreshold(value); err != nil {
+		return err
+	} else if value_read, err := this.getRSSIThreshold(); err != nil {
+		return err
+	} else if value != value_read {
+		this.log.Debug2("SetRSSIThreshold expecting value=%v, got=%v", value, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.rssi_threshold = value
+	}
+
+	// Success
+	return nil
+}
+
+// Return the RX timeouts, which depend on the bitrate
+func (this *rfm69) RXTimeout() (time.Duration, time.Duration) {
+	unit := this.rxTimeoutUnit()
+	return time.Duration(this.rx_timeout_start) * unit, time.Duration(this.rx_timeout_rssi) * unit
+}
+
+// SetRXTimeout sets the time from entering RX until the RSSI threshold
+// is exceeded, and from then until PayloadReady, after which the Timeout
+// interrupt is raised. A zero duration disables a timeout. The timeouts
+// are counted in bit periods so this should be called after SetBitrate
+func (this *rfm69) SetRXTimeout(rx_start, rssi_thresh time.Duration) error {
+	this.log.Debug("<sensors.RFM69.SetRXTimeout>{ rx_start=%v rssi_thresh=%v }", rx_start, rssi_thresh)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	unit := this.rxTimeoutUnit()
+	if unit == 0 || rx_start < 0 || rssi_thresh < 0 || rx_start > unit*0xFF || rssi_thresh > unit*0xFF {
+		this.log.Debug2("SetRXTimeout: timeouts out of range, expected <= %v", unit*0xFF)
+		return gopi.ErrBadParameter
+	}
+	rx_start_value := uint8((rx_start + unit - 1) / unit)
+	rssi_thresh_value := uint8((rssi_thresh + unit - 1) / unit)
+	if err := this.setRXTimeout(rx_start_value, rssi_thresh_value); err != nil {
+		return err
+	} else if rx_start_read, rssi_thresh_read, err := this.getRXTimeout(); err != nil {
+		return err
+	} else if rx_start_read != rx_start_value || rssi_thresh_read != rssi_thresh_value {
+		this.log.Debug2("SetRXTimeout expecting values=%v,%v, got=%v,%v", rx_start_value, rssi_thresh_value, rx_start_read, rssi_thresh_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.rx_timeout_start = rx_start_value
+		this.rx_timeout_rssi = rssi_thresh_value
+	}
+
+	// Success
+	return nil
+}
+
+// rxTimeoutUnit returns the duration of 16 bit periods at the
+// current bitrate, which is the unit of the RX timeout registers
+func (this *rfm69) rxTimeoutUnit() time.Duration {
+	return time.Duration(this.bitrate) * 16 * time.Second / (RFM_FXOSC_MHZ * 1000000)
+}
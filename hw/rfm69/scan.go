@@ -0,0 +1,171 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"context"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Maximum number of steps in an RSSI scan, and the time for the
+	// receiver to settle on each frequency before measuring
+	RFM_SCAN_RSSI_STEPS_MAX = 10000
+	RFM_SCAN_RSSI_SETTLE    = 2 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ScanChannels cycles RX across the carrier frequencies, listening on each
+// for the dwell time, until a packet is received or the context is done.
+// When a sync word has been matched at the end of the dwell, the radio stays
+// on the channel long enough to receive the rest of the packet. The packet
+// FreqCarrier is the channel it arrived on, and the radio is left tuned to
+// that channel. Subsequent calls continue from the next channel
+func (this *rfm69) ScanChannels(ctx context.Context, channels []uint, dwell time.Duration) (*sensors.RFMPacket, error) {
+	this.log.Debug("<sensors.RFM69.ScanChannels>{ channels=%v dwell=%v }", channels, dwell)
+
+	if len(channels) == 0 || dwell <= 0 {
+		return nil, gopi.ErrBadParameter
+	}
+
+	for {
+		// Tune the next channel
+		channel := channels[this.scan_index%uint(len(channels))]
+		this.scan_index = (this.scan_index + 1) % uint(len(channels))
+		if err := this.tuneChannel(channel); err != nil {
+			return nil, err
+		}
+
+		// Wait for a packet on this channel
+		if packet, err := this.dwellChannel(ctx, dwell); err != nil {
+			return nil, err
+		} else if packet != nil {
+			return packet, nil
+		}
+
+		// Return if the context is done
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+			break
+		}
+	}
+}
+
+// ScanRSSI steps the carrier frequency from one frequency to another
+// inclusive, and measures the RSSI in RX at each step. The carrier
+// frequency and mode are restored afterwards
+func (this *rfm69) ScanRSSI(from, to, step uint) ([]sensors.RFMRSSIReading, error) {
+	this.log.Debug("<sensors.RFM69.ScanRSSI>{ from=%v to=%v step=%v }", from, to, step)
+
+	if step == 0 || from > to {
+		return nil, gopi.ErrBadParameter
+	} else if (to-from)/step >= RFM_SCAN_RSSI_STEPS_MAX {
+		return nil, gopi.ErrBadParameter
+	}
+
+	// Save the frequency and mode, which are restored afterwards
+	freq_carrier, mode := this.FreqCarrier(), this.Mode()
+
+	readings := make([]sensors.RFMRSSIReading, 0, (to-from)/step+1)
+	for hertz := from; hertz <= to; hertz += step {
+		if err := this.tuneChannel(hertz); err != nil {
+			return nil, err
+		}
+		time.Sleep(RFM_SCAN_RSSI_SETTLE)
+		if rssi, err := this.MeasureRSSI(); err != nil {
+			return nil, err
+		} else {
+			readings = append(readings, sensors.RFMRSSIReading{
+				FreqCarrier: hertz,
+				RSSI:        rssi,
+			})
+		}
+	}
+
+	// Restore the frequency and mode
+	if err := this.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return nil, err
+	} else if err := this.SetFreqCarrier(freq_carrier); err != nil {
+		return nil, err
+	} else if err := this.SetMode(mode); err != nil {
+		return nil, err
+	}
+
+	// Success
+	return readings, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// tuneChannel sets the carrier frequency from standby and enters RX
+func (this *rfm69) tuneChannel(hertz uint) error {
+	if err := this.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return err
+	} else if err := this.SetFreqCarrier(hertz); err != nil {
+		return err
+	} else if err := this.SetMode(sensors.RFM_MODE_RX); err != nil {
+		return err
+	}
+
+	// Success
+	return nil
+}
+
+// dwellChannel reads a packet for the dwell time, which is extended by
+// the time to receive the largest packet if the sync word has matched
+func (this *rfm69) dwellChannel(ctx context.Context, dwell time.Duration) (*sensors.RFMPacket, error) {
+	dwell_ctx, cancel := context.WithTimeout(ctx, dwell)
+	defer cancel()
+	if packet, err := this.ReadPacket(dwell_ctx); err != nil || packet != nil {
+		return packet, err
+	} else if sync_match, err := this.syncMatched(); err != nil || sync_match == false {
+		return nil, err
+	}
+
+	// Sync word matched, so wait for the rest of the packet
+	grace_ctx, cancel := context.WithTimeout(ctx, this.packetTime())
+	defer cancel()
+	return this.ReadPacket(grace_ctx)
+}
+
+// syncMatched returns true if the sync address has matched
+func (this *rfm69) syncMatched() (bool, error) {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if value, err := this.getIRQFlags1(RFM_IRQFLAGS1_SYNCADDRESSMATCH); err != nil {
+		return false, err
+	} else {
+		return to_uint8_bool(value), nil
+	}
+}
+
+// packetTime returns the time on air for the largest packet at the
+// current bitrate
+func (this *rfm69) packetTime() time.Duration {
+	if bitrate := this.Bitrate(); bitrate == 0 {
+		return 0
+	} else {
+		return time.Duration(RFM_FIFO_SIZE*8) * time.Second / time.Duration(bitrate)
+	}
+}
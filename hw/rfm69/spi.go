@@ -0,0 +1,137 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"io/ioutil"
+	"strings"
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// spi_board is the default SPI timing for a board model
+type spi_board struct {
+	model string
+	speed uint32
+	delay time.Duration
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	RFM_SPI_MODEL_PATH = "/proc/device-tree/model"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Default SPI timing for each board, matched by prefix of the model
+	// name in order. The original models and the Zero use a slower core
+	// clock, so a slower SPI clock and a short settle delay are safer
+	spi_boards = []spi_board{
+		{"Raspberry Pi 4", RFM_SPI_SPEEDHZ, 0},
+		{"Raspberry Pi 3", RFM_SPI_SPEEDHZ, 0},
+		{"Raspberry Pi 2", RFM_SPI_SPEEDHZ, 0},
+		{"Raspberry Pi Zero", 2000000, 10 * time.Microsecond},
+		{"Raspberry Pi Model", 2000000, 10 * time.Microsecond},
+		{"Raspberry Pi Compute Module", 2000000, 10 * time.Microsecond},
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// spiBoardDefaults returns the default SPI speed and settle delay for the
+// board the driver is running on, or the driver defaults if the board
+// model cannot be determined
+func spiBoardDefaults() (string, uint32, time.Duration) {
+	if data, err := ioutil.ReadFile(RFM_SPI_MODEL_PATH); err == nil {
+		model := strings.TrimRight(string(data), "\x00\n")
+		for _, board := range spi_boards {
+			if strings.HasPrefix(model, board.model) {
+				return model, board.speed, board.delay
+			}
+		}
+	}
+	return "", RFM_SPI_SPEEDHZ, 0
+}
+
+// transfer sends and receives bytes, and waits for the settle delay.
+// A failed transfer emits RFM_EVENT_BUS_ERROR and is retried, backing
+// off between retries, except for the FIFO where a retry would lose or
+// repeat bytes. On error, the received bytes are zero
+func (this *rfm69) transfer(send []byte) ([]byte, error) {
+	var recv []byte
+	err := this.retryBus(send, func() error {
+		var err error
+		recv, err = this.spi.Transfer(send)
+		return err
+	})
+	if err != nil || len(recv) != len(send) {
+		return make([]byte, len(send)), err
+	}
+	return recv, nil
+}
+
+// write sends bytes, and waits for the settle delay. A failed write
+// emits RFM_EVENT_BUS_ERROR and is retried, except for the FIFO
+func (this *rfm69) write(send []byte) error {
+	return this.retryBus(send, func() error {
+		return this.spi.Write(send)
+	})
+}
+
+// retryBus calls a bus operation and waits for the settle delay,
+// retrying the operation when it fails
+func (this *rfm69) retryBus(send []byte, operation func() error) error {
+	retries := this.retries
+	if len(send) > 0 && register(send[0])&RFM_REG_MAX == RFM_REG_FIFO {
+		retries = 0
+	}
+	var err error
+	for attempt := uint(0); attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(this.backoff.Delay(attempt - 1))
+		}
+		err = operation()
+		this.settle()
+		if err == nil {
+			return nil
+		}
+		this.log.Warn("<sensors.RFM69>bus error{ reg=%v attempt=%v err=%v }", register(send[0])&RFM_REG_MAX, attempt+1, err)
+		this.emitDebug(sensors.RFM_EVENT_BUS_ERROR)
+	}
+	return err
+}
+
+// settle waits after chip select is released, when a delay is set
+func (this *rfm69) settle() {
+	if this.delay > 0 {
+		time.Sleep(this.delay)
+	}
+}
+
+// Check SPI mode is valid
+func spiModeValid(mode gopi.SPIMode) bool {
+	switch mode {
+	case gopi.SPI_MODE_0, gopi.SPI_MODE_1, gopi.SPI_MODE_2, gopi.SPI_MODE_3:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// IRQFlags reads and decodes the RegIrqFlags1 and RegIrqFlags2 registers
+func (this *rfm69) IRQFlags() (sensors.RFMIRQFlags, error) {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if flags1, err := this.getIRQFlags1(0xFF); err != nil {
+		return sensors.RFMIRQFlags{}, err
+	} else if flags2, err := this.getIRQFlags2(0xFF); err != nil {
+		return sensors.RFMIRQFlags{}, err
+	} else {
+		return decodeIRQFlags(flags1, flags2), nil
+	}
+}
+
+// FIFOStatus reads the FIFO flags in the RegIrqFlags2 register
+func (this *rfm69) FIFOStatus() (sensors.RFMFIFOStatus, error) {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if flags2, err := this.getIRQFlags2(0xFF); err != nil {
+		return sensors.RFMFIFOStatus{}, err
+	} else {
+		return sensors.RFMFIFOStatus{
+			Full:      to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFOFULL),
+			NotEmpty:  to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFONOTEMPTY),
+			Level:     to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFOLEVEL),
+			Overrun:   to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFOOVERRUN),
+			Threshold: this.fifo_threshold,
+		}, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func decodeIRQFlags(flags1, flags2 uint8) sensors.RFMIRQFlags {
+	return sensors.RFMIRQFlags{
+		ModeReady:        to_uint8_bool(flags1 & RFM_IRQFLAGS1_MODEREADY),
+		RxReady:          to_uint8_bool(flags1 & RFM_IRQFLAGS1_RXREADY),
+		TxReady:          to_uint8_bool(flags1 & RFM_IRQFLAGS1_TXREADY),
+		PLLLock:          to_uint8_bool(flags1 & RFM_IRQFLAGS1_PLLLOCK),
+		RSSI:             to_uint8_bool(flags1 & RFM_IRQFLAGS1_RSSI),
+		Timeout:          to_uint8_bool(flags1 & RFM_IRQFLAGS1_TIMEOUT),
+		AutoMode:         to_uint8_bool(flags1 & RFM_IRQFLAGS1_AUTOMODE),
+		SyncAddressMatch: to_uint8_bool(flags1 & RFM_IRQFLAGS1_SYNCADDRESSMATCH),
+		FIFOFull:         to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFOFULL),
+		FIFONotEmpty:     to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFONOTEMPTY),
+		FIFOLevel:        to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFOLEVEL),
+		FIFOOverrun:      to_uint8_bool(flags2 & RFM_IRQFLAGS2_FIFOOVERRUN),
+		PacketSent:       to_uint8_bool(flags2 & RFM_IRQFLAGS2_PACKETSENT),
+		PayloadReady:     to_uint8_bool(flags2 & RFM_IRQFLAGS2_PAYLOADREADY),
+		CRCOk:            to_uint8_bool(flags2 & RFM_IRQFLAGS2_CRCOK),
+		LowBattery:       to_uint8_bool(flags2 & RFM_IRQFLAGS2_LOWBAT),
+	}
+}
@@ -74,6 +74,8 @@ func (r register) String() string {
 		return "RFM_REG_OSC1"
 	case RFM_REG_AFCCTRL:
 		return "RFM_REG_AFCCTRL"
+	case RFM_REG_LOWBAT:
+		return "RFM_REG_LOWBAT"
 	case RFM_REG_LISTEN1:
 		return "RFM_REG_LISTEN1"
 	case RFM_REG_LISTEN2:
@@ -0,0 +1,224 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"context"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// pn9 generates the PN9 pseudo-random sequence, from the polynomial
+// x^9 + x^5 + 1 with all stages set to one
+type pn9 struct {
+	state uint16
+}
+
+// test_settings are the settings restored after a test transmission
+type test_settings struct {
+	data_mode      sensors.RFMDataMode
+	modulation     sensors.RFMModulation
+	packet_format  sensors.RFMPacketFormat
+	packet_coding  sensors.RFMPacketCoding
+	packet_crc     sensors.RFMPacketCRC
+	payload_size   uint8
+	preamble_size  uint16
+	sync_word      []byte
+	aes_on         bool
+	fifo_threshold uint8
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// FIFO is refilled with this many bytes when it drops to the threshold
+	RFM_TEST_CHUNK_SIZE = 32
+	RFM_TEST_POLL       = time.Millisecond
+	RFM_PN9_SEED        = 0x1FF
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// TransmitCarrier transmits an unmodulated carrier at the carrier
+// frequency and output power until the context is done, by sending a
+// continuous stream of ones in OOK, for antenna tuning and regulatory
+// testing. The radio returns to standby and the packet settings are
+// restored when the context is done
+func (this *rfm69) TransmitCarrier(ctx context.Context) error {
+	this.log.Debug("<sensors.RFM69.TransmitCarrier>{ freq_carrier=%v }", this.FreqCarrier())
+
+	chunk := make([]byte, RFM_TEST_CHUNK_SIZE)
+	for i := range chunk {
+		chunk[i] = 0xFF
+	}
+	return this.transmitTest(ctx, sensors.RFM_MODULATION_OOK, func() []byte {
+		return chunk
+	})
+}
+
+// TransmitPN9 transmits the PN9 pseudo-random sequence with the current
+// modulation, bitrate and deviation until the context is done. The radio
+// returns to standby and the packet settings are restored when the
+// context is done
+func (this *rfm69) TransmitPN9(ctx context.Context) error {
+	this.log.Debug("<sensors.RFM69.TransmitPN9>{ freq_carrier=%v modulation=%v }", this.FreqCarrier(), this.modulation)
+
+	sequence := &pn9{RFM_PN9_SEED}
+	chunk := make([]byte, RFM_TEST_CHUNK_SIZE)
+	return this.transmitTest(ctx, this.modulation, func() []byte {
+		for i := range chunk {
+			chunk[i] = sequence.next()
+		}
+		return chunk
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// transmitTest sends unlimited length packets without preamble, sync
+// word or CRC, refilling the FIFO from the data function whenever it
+// drops to the threshold
+func (this *rfm69) transmitTest(ctx context.Context, modulation sensors.RFMModulation, data func() []byte) error {
+	// Save settings and set up for a continuous transmission
+	saved := this.saveTestSettings()
+	if err := this.setTestSettings(modulation); err != nil {
+		this.restoreTestSettings(saved)
+		return err
+	}
+
+	// Fill the FIFO and start transmitting
+	this.lock.Lock()
+	err := this.writeFIFO(data())
+	this.lock.Unlock()
+	if err == nil {
+		err = this.SetMode(sensors.RFM_MODE_TX)
+	}
+
+	// Refill the FIFO until the context is done
+	for err == nil {
+		select {
+		case <-ctx.Done():
+			return this.restoreTestSettings(saved)
+		default:
+			var below bool
+			this.lock.Lock()
+			if below, err = this.irqFIFOLevel(); err == nil && below {
+				err = this.writeFIFO(data())
+			}
+			this.lock.Unlock()
+			time.Sleep(RFM_TEST_POLL)
+		}
+	}
+
+	// Return the error after restoring the settings
+	this.restoreTestSettings(saved)
+	return err
+}
+
+func (this *rfm69) saveTestSettings() test_settings {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	settings := test_settings{
+		data_mode:      this.data_mode,
+		modulation:     this.modulation,
+		packet_format:  this.packet_format,
+		packet_coding:  this.packet_coding,
+		packet_crc:     this.PacketCRC(),
+		payload_size:   this.payload_size,
+		preamble_size:  this.preamble_size,
+		aes_on:         this.aes_on,
+		fifo_threshold: this.fifo_threshold,
+	}
+	if this.sync_on {
+		settings.sync_word = append([]byte(nil), this.sync_word[:this.sync_size+1]...)
+	}
+	return settings
+}
+
+func (this *rfm69) setTestSettings(modulation sensors.RFMModulation) error {
+	if err := this.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return err
+	} else if err := this.SetModulation(modulation); err != nil {
+		return err
+	} else if err := this.SetSequencer(true); err != nil {
+		return err
+	} else if err := this.SetDataMode(sensors.RFM_DATAMODE_PACKET); err != nil {
+		return err
+	} else if err := this.SetAESEnabled(false); err != nil {
+		return err
+	} else if err := this.SetPacketFormat(sensors.RFM_PACKET_FORMAT_FIXED); err != nil {
+		return err
+	} else if err := this.SetPacketCoding(sensors.RFM_PACKET_CODING_NONE); err != nil {
+		return err
+	} else if err := this.SetPacketCRC(sensors.RFM_PACKET_CRC_OFF); err != nil {
+		return err
+	} else if err := this.SetPayloadSize(0); err != nil {
+		// Zero length is unlimited in fixed length format
+		return err
+	} else if err := this.SetPreambleSize(0); err != nil {
+		return err
+	} else if err := this.SetSyncWord(nil); err != nil {
+		return err
+	} else if err := this.SetFIFOThreshold(RFM_FIFO_SIZE - RFM_TEST_CHUNK_SIZE - 1); err != nil {
+		return err
+	} else if err := this.ClearFIFO(); err != nil {
+		return err
+	}
+
+	// Success
+	return nil
+}
+
+// restoreTestSettings returns to standby and restores the settings,
+// returning the first error
+func (this *rfm69) restoreTestSettings(settings test_settings) error {
+	errs := []error{
+		this.SetMode(sensors.RFM_MODE_STDBY),
+		this.ClearFIFO(),
+		this.SetDataMode(settings.data_mode),
+		this.SetModulation(settings.modulation),
+		this.SetPacketFormat(settings.packet_format),
+		this.SetPacketCoding(settings.packet_coding),
+		this.SetPacketCRC(settings.packet_crc),
+		this.SetPayloadSize(settings.payload_size),
+		this.SetPreambleSize(settings.preamble_size),
+		this.SetSyncWord(settings.sync_word),
+		this.SetAESEnabled(settings.aes_on),
+		this.SetFIFOThreshold(settings.fifo_threshold),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next returns the next eight bits of the sequence, first bit in the
+// most significant bit
+func (this *pn9) next() byte {
+	value := byte(0)
+	for i := 0; i < 8; i++ {
+		bit := this.state & 0x01
+		feedback := (this.state ^ (this.state >> 5)) & 0x01
+		this.state = (this.state >> 1) | (feedback << 8)
+		value = (value << 1) | byte(bit)
+	}
+	return value
+}
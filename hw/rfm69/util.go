@@ -18,7 +18,7 @@ import (
 // PRIVATE METHODS
 
 func (this *rfm69) readreg_uint8(reg register) (uint8, error) {
-	recv, err := this.spi.Transfer([]byte{byte(reg & RFM_REG_MAX), 0})
+	recv, err := this.transfer([]byte{byte(reg & RFM_REG_MAX), 0})
 	this.log.Debug2("<sensors.RFM69>readreg_uint8{ reg=%v recv=0x%02X }", reg, recv[1:])
 	if err != nil {
 		return 0, err
@@ -29,7 +29,7 @@ func (this *rfm69) readreg_uint8(reg register) (uint8, error) {
 func (this *rfm69) readreg_uint8_array(reg register, length uint) ([]byte, error) {
 	send := make([]byte, length+1)
 	send[0] = byte(reg & RFM_REG_MAX)
-	recv, err := this.spi.Transfer(send)
+	recv, err := this.transfer(send)
 	this.log.Debug2("<sensors.RFM69>readreg_uint8_array{ reg=%v length=%v recv=0x%v }", reg, length, strings.ToUpper(hex.EncodeToString(recv[1:])))
 	if err != nil {
 		return nil, err
@@ -38,7 +38,7 @@ func (this *rfm69) readreg_uint8_array(reg register, length uint) ([]byte, error
 }
 
 func (this *rfm69) readreg_uint16(reg register) (uint16, error) {
-	recv, err := this.spi.Transfer([]byte{byte(reg & RFM_REG_MAX), 0, 0})
+	recv, err := this.transfer([]byte{byte(reg & RFM_REG_MAX), 0, 0})
 	this.log.Debug2("<sensors.RFM69>readreg_uint16{ reg=%v recv=0x%v }", reg, strings.ToUpper(hex.EncodeToString(recv[1:])))
 	if err != nil {
 		return 0, err
@@ -47,7 +47,7 @@ func (this *rfm69) readreg_uint16(reg register) (uint16, error) {
 }
 
 func (this *rfm69) readreg_int16(reg register) (int16, error) {
-	recv, err := this.spi.Transfer([]byte{byte(reg & RFM_REG_MAX), 0, 0})
+	recv, err := this.transfer([]byte{byte(reg & RFM_REG_MAX), 0, 0})
 	this.log.Debug2("<sensors.RFM69>readreg_uint16{ reg=%v recv=0x%v }", reg, strings.ToUpper(hex.EncodeToString(recv[1:])))
 	if err != nil {
 		return 0, err
@@ -56,7 +56,7 @@ func (this *rfm69) readreg_int16(reg register) (int16, error) {
 }
 
 func (this *rfm69) readreg_uint24(reg register) (uint32, error) {
-	recv, err := this.spi.Transfer([]byte{byte(reg & RFM_REG_MAX), 0, 0, 0})
+	recv, err := this.transfer([]byte{byte(reg & RFM_REG_MAX), 0, 0, 0})
 	this.log.Debug2("<sensors.RFM69>readreg_uint24{ reg=%v recv=0x%v }", reg, strings.ToUpper(hex.EncodeToString(recv[1:])))
 	if err != nil {
 		return 0, err
@@ -66,13 +66,12 @@ func (this *rfm69) readreg_uint24(reg register) (uint32, error) {
 
 func (this *rfm69) writereg_uint8(reg register, data uint8) error {
 	this.log.Debug2("<sensors.RFM69>writereg_uint8{ reg=%v data=0x%02X }", reg, data)
-	return this.spi.Write([]byte{byte((reg & RFM_REG_MAX) | RFM_REG_WRITE), data})
+	return this.writereg(reg, []byte{data})
 }
 
 func (this *rfm69) writereg_uint16(reg register, data uint16) error {
 	this.log.Debug2("<sensors.RFM69>writereg_uint16{ reg=%v data=0x%04X }", reg, data)
-	return this.spi.Write([]byte{
-		byte((reg & RFM_REG_MAX) | RFM_REG_WRITE),
+	return this.writereg(reg, []byte{
 		uint8(data & 0xFF00 >> 8),
 		uint8(data & 0xFF),
 	})
@@ -80,8 +79,7 @@ func (this *rfm69) writereg_uint16(reg register, data uint16) error {
 
 func (this *rfm69) writereg_uint24(reg register, data uint32) error {
 	this.log.Debug2("<sensors.RFM69>writereg_uint24{ reg=%v data=0x%06X }", reg, data)
-	return this.spi.Write([]byte{
-		byte((reg & RFM_REG_MAX) | RFM_REG_WRITE),
+	return this.writereg(reg, []byte{
 		uint8(data & 0xFF0000 >> 16),
 		uint8(data & 0xFF00 >> 8),
 		uint8(data & 0xFF),
@@ -90,8 +88,7 @@ func (this *rfm69) writereg_uint24(reg register, data uint32) error {
 
 func (this *rfm69) writereg_uint8_array(reg register, data []byte) error {
 	this.log.Debug2("<sensors.RFM69>writereg_uint8_array{ reg=%v data=%v }", reg, strings.ToUpper(hex.EncodeToString(data)))
-	buf := append([]byte(nil), byte((reg&RFM_REG_MAX)|RFM_REG_WRITE))
-	return this.spi.Write(append(buf, data...))
+	return this.writereg(reg, data)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
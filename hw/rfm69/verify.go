@@ -0,0 +1,143 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm69
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/util/backoff"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	RFM_VERIFY_RETRIES_DEFAULT = 3
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Default delay between retries of a write which fails verification
+	RFM_VERIFY_BACKOFF_DEFAULT = backoff.Policy{Initial: time.Millisecond, Max: 10 * time.Millisecond, Jitter: 0.2}
+
+	// Configuration registers which are read back after writing when
+	// verification is enabled, and the mask of bits which can be read back.
+	// Registers with trigger bits, status bits or write-only contents
+	// (OpMode, AFCFEI, IRQ flags, FIFO, AES key) are not verified
+	verify_registers = map[register]uint8{
+		RFM_REG_DATAMODUL:     0x7B,
+		RFM_REG_BITRATEMSB:    0xFF,
+		RFM_REG_BITRATELSB:    0xFF,
+		RFM_REG_FDEVMSB:       0x3F,
+		RFM_REG_FDEVLSB:       0xFF,
+		RFM_REG_FRFMSB:        0xFF,
+		RFM_REG_FRFMID:        0xFF,
+		RFM_REG_FRFLSB:        0xFF,
+		RFM_REG_AFCCTRL:       0x20,
+		RFM_REG_LOWBAT:        0x0F,
+		RFM_REG_PARAMP:        0x0F,
+		RFM_REG_LNA:           0x87,
+		RFM_REG_RXBW:          0xFF,
+		RFM_REG_OOKPEAK:       0xFF,
+		RFM_REG_OOKAVG:        0xC0,
+		RFM_REG_OOKFIX:        0xFF,
+		RFM_REG_DIOMAPPING1:   0xFF,
+		RFM_REG_DIOMAPPING2:   0xF7,
+		RFM_REG_PREAMBLEMSB:   0xFF,
+		RFM_REG_PREAMBLELSB:   0xFF,
+		RFM_REG_SYNCCONFIG:    0xFF,
+		RFM_REG_SYNCVALUE1:    0xFF,
+		RFM_REG_SYNCVALUE2:    0xFF,
+		RFM_REG_SYNCVALUE3:    0xFF,
+		RFM_REG_SYNCVALUE4:    0xFF,
+		RFM_REG_SYNCVALUE5:    0xFF,
+		RFM_REG_SYNCVALUE6:    0xFF,
+		RFM_REG_SYNCVALUE7:    0xFF,
+		RFM_REG_SYNCVALUE8:    0xFF,
+		RFM_REG_RSSITHRESH:    0xFF,
+		RFM_REG_RXTIMEOUT1:    0xFF,
+		RFM_REG_RXTIMEOUT2:    0xFF,
+		RFM_REG_PACKETCONFIG1: 0xFE,
+		RFM_REG_PAYLOADLENGTH: 0xFF,
+		RFM_REG_NODEADRS:      0xFF,
+		RFM_REG_BROADCASTADRS: 0xFF,
+		RFM_REG_AUTOMODES:     0xFF,
+		RFM_REG_FIFOTHRESH:    0xFF,
+		RFM_REG_PACKETCONFIG2: 0xF3,
+		RFM_REG_TESTLNA:       0xFF,
+		RFM_REG_TESTDAGC:      0xFF,
+		RFM_REG_TESTAFC:       0xFF,
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// writereg writes one or more consecutive registers. When verification
+// is enabled, configuration registers are read back and the whole write
+// is retried on mismatch, backing off between retries. If the registers
+// still do not match after all retries, RFM_EVENT_BUS_ERROR is emitted
+// and ErrUnexpectedResponse is returned
+func (this *rfm69) writereg(reg register, data []byte) error {
+	buf := append([]byte(nil), byte((reg&RFM_REG_MAX)|RFM_REG_WRITE))
+	buf = append(buf, data...)
+	if this.verify == false || verify_register(reg, uint(len(data))) == false {
+		return this.write(buf)
+	}
+	for attempt := uint(0); attempt <= this.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(this.backoff.Delay(attempt - 1))
+		}
+		if err := this.write(buf); err != nil {
+			return err
+		} else if recv, err := this.readreg_uint8_array(reg, uint(len(data))); err != nil {
+			return err
+		} else if verify_matches(reg, data, recv) {
+			return nil
+		} else {
+			this.log.Warn("<sensors.RFM69>writereg: verify failed{ reg=%v attempt=%v data=0x%v recv=0x%v }", reg, attempt+1, strings.ToUpper(hex.EncodeToString(data)), strings.ToUpper(hex.EncodeToString(recv)))
+		}
+	}
+	this.emitDebug(sensors.RFM_EVENT_BUS_ERROR)
+	return sensors.ErrUnexpectedResponse
+}
+
+// verify_register returns true if all registers in a write are verified
+func verify_register(reg register, length uint) bool {
+	if length == 0 {
+		return false
+	}
+	for i := uint(0); i < length; i++ {
+		if _, exists := verify_registers[reg+register(i)]; exists == false {
+			return false
+		}
+	}
+	return true
+}
+
+// verify_matches compares written and read back register values
+func verify_matches(reg register, data, recv []byte) bool {
+	if len(data) != len(recv) {
+		return false
+	}
+	for i := range data {
+		mask := verify_registers[reg+register(i)]
+		if data[i]&mask != recv[i]&mask {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm9x
+
+import (
+	"errors"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register RFM9x communication through SPI
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/rfm9x",
+		Requires: []string{"spi"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagUint("rfm9x.spi.speed", 0, "SPI clock speed in Hz, or zero for default")
+			config.AppFlags.FlagUint("rfm9x.freq", 0, "Carrier frequency in Hz, or zero to leave unchanged")
+			config.AppFlags.FlagUint("rfm9x.sf", 0, "Spreading factor (7-12), or zero to leave unchanged")
+			config.AppFlags.FlagUint("rfm9x.bw", 0, "Bandwidth in Hz (7800-500000), or zero to leave unchanged")
+			config.AppFlags.FlagUint("rfm9x.cr", 0, "Coding rate denominator (5-8), or zero to leave unchanged")
+			config.AppFlags.FlagInt("rfm9x.power", 0, "Output power in dBm (2-20), or zero to leave unchanged")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			speed, _ := app.AppFlags.GetUint("rfm9x.spi.speed")
+			driver, err := gopi.Open(RFM9x{
+				SPI:   app.ModuleInstance("spi").(gopi.SPI),
+				Speed: uint32(speed),
+			}, app.Logger)
+			if err != nil {
+				return nil, err
+			} else if err := setModemFlags(app, driver.(*rfm9x)); err != nil {
+				driver.Close()
+				return nil, err
+			}
+			return driver, nil
+		},
+	})
+}
+
+// setModemFlags sets the modem settings from the flags which are not zero
+func setModemFlags(app *gopi.AppInstance, driver *rfm9x) error {
+	if freq, _ := app.AppFlags.GetUint("rfm9x.freq"); freq != 0 {
+		if err := driver.SetFreqCarrier(freq); err != nil {
+			return err
+		}
+	}
+	if sf, _ := app.AppFlags.GetUint("rfm9x.sf"); sf != 0 {
+		if err := driver.SetSpreadingFactor(sensors.LoRaSpreadingFactor(sf)); err != nil {
+			return err
+		}
+	}
+	if bw, _ := app.AppFlags.GetUint("rfm9x.bw"); bw != 0 {
+		if bandwidth, err := bandwidthFromHertz(bw); err != nil {
+			return err
+		} else if err := driver.SetBandwidth(bandwidth); err != nil {
+			return err
+		}
+	}
+	if cr, _ := app.AppFlags.GetUint("rfm9x.cr"); cr != 0 {
+		if cr < 5 || cr > 8 {
+			return errors.New("Invalid -rfm9x.cr flag")
+		} else if err := driver.SetCodingRate(sensors.LoRaCodingRate(cr - 4)); err != nil {
+			return err
+		}
+	}
+	if power, _ := app.AppFlags.GetInt("rfm9x.power"); power != 0 {
+		if err := driver.SetOutputPower(power); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bandwidthFromHertz returns the bandwidth for a value in Hz
+func bandwidthFromHertz(hertz uint) (sensors.LoRaBandwidth, error) {
+	for bandwidth := sensors.LORA_BANDWIDTH_7P8; bandwidth <= sensors.LORA_BANDWIDTH_MAX; bandwidth++ {
+		if bandwidth.Hertz() == hertz {
+			return bandwidth, nil
+		}
+	}
+	return 0, errors.New("Invalid -rfm9x.bw flag")
+}
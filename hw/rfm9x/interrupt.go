@@ -0,0 +1,118 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm9x
+
+import (
+	"context"
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// DIO0 mapping in LoRa mode
+	RFM9X_DIO0_RXDONE = 0x00
+	RFM9X_DIO0_TXDONE = 0x01
+
+	// Interval to check the interrupt flags when waiting for an
+	// interrupt, in case an edge is missed
+	RFM9X_INTERRUPT_POLL = 1000 * time.Millisecond
+
+	// Interval to check the interrupt flags when polling
+	RFM9X_IRQFLAGS_POLL = 10 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// SetInterrupt configures the rising edge of DIO0, which is mapped to
+// RxDone when receiving and TxDone when transmitting. A pin of
+// GPIO_PIN_NONE reverts to polling the interrupt flags
+func (this *rfm9x) SetInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error {
+	this.log.Debug("<sensors.RFM9x.SetInterrupt>{ pin=%v }", pin)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setInterrupt(gpio, pin)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *rfm9x) setInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error {
+	// Remove any existing watcher
+	if this.dio0_gpio != nil {
+		this.dio0_gpio.Watch(this.dio0, gopi.GPIO_EDGE_NONE)
+		this.dio0_gpio.Unsubscribe(this.dio0_events)
+		this.dio0_gpio = nil
+		this.dio0_events = nil
+		this.dio0 = gopi.GPIO_PIN_NONE
+	}
+
+	// Revert to polling
+	if pin == gopi.GPIO_PIN_NONE {
+		return nil
+	} else if gpio == nil {
+		return gopi.ErrBadParameter
+	}
+
+	// Map DIO0 to RxDone and watch for rising edge
+	if err := this.setDIO0Mapping(RFM9X_DIO0_RXDONE); err != nil {
+		return err
+	}
+	gpio.SetPinMode(pin, gopi.GPIO_INPUT)
+	if err := gpio.Watch(pin, gopi.GPIO_EDGE_RISING); err != nil {
+		return err
+	}
+	this.dio0_gpio = gpio
+	this.dio0_events = gpio.Subscribe()
+	this.dio0 = pin
+
+	// Success
+	return nil
+}
+
+// waitIRQ blocks until an interrupt flag may be set, or the context is
+// done, in which case it returns false. When an interrupt is configured
+// it waits for the DIO0 edge and records the time it arrived, otherwise
+// it waits for the poll interval
+func (this *rfm9x) waitIRQ(ctx context.Context) bool {
+	if this.dio0_events == nil {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(RFM9X_IRQFLAGS_POLL):
+			return true
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(RFM9X_INTERRUPT_POLL):
+			return true
+		case evt, ok := <-this.dio0_events:
+			if ok == false {
+				// GPIO has been closed, revert to polling
+				this.dio0_events = nil
+				return true
+			} else if evt, ok := evt.(gopi.GPIOEvent); ok && evt.Pin() == this.dio0 {
+				this.dio0_ts = time.Now()
+				return true
+			}
+		}
+	}
+}
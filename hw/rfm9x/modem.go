@@ -0,0 +1,332 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm9x
+
+import (
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Carrier frequency range of the SX1276
+	RFM9X_FREQ_MIN = 137000000
+	RFM9X_FREQ_MAX = 1020000000
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// MODE
+
+// Return device mode
+func (this *rfm9x) Mode() sensors.LoRaMode {
+	return this.mode
+}
+
+// Set device mode
+func (this *rfm9x) SetMode(mode sensors.LoRaMode) error {
+	this.log.Debug("<sensors.RFM9x.SetMode>{ mode=%v }", mode)
+
+	if mode > sensors.LORA_MODE_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setMode(mode)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CARRIER FREQUENCY
+
+// Return carrier frequency in Hz
+func (this *rfm9x) FreqCarrier() uint {
+	return frfToHertz(this.frf)
+}
+
+// Set carrier frequency in Hz. The mode is written again, since the
+// low frequency port is selected from the carrier frequency
+func (this *rfm9x) SetFreqCarrier(hertz uint) error {
+	this.log.Debug("<sensors.RFM9x.SetFreqCarrier>{ hertz=%v }", hertz)
+
+	if hertz < RFM9X_FREQ_MIN || hertz > RFM9X_FREQ_MAX {
+		return gopi.ErrBadParameter
+	}
+	value := hertzToFrf(hertz)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.writereg_uint24(RFM9X_REG_FRFMSB, value); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.readreg_uint24(RFM9X_REG_FRFMSB); err != nil {
+		return err
+	} else if value != value_read {
+		this.log.Debug2("SetFreqCarrier expecting value=0x%06X, got=0x%06X", value, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.frf = value
+	}
+
+	// Select the frequency port
+	return this.setMode(this.mode)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MODEM SETTINGS
+
+// Return spreading factor
+func (this *rfm9x) SpreadingFactor() sensors.LoRaSpreadingFactor {
+	return this.spreading_factor
+}
+
+// Set spreading factor. Spreading factor 6 needs implicit header mode,
+// which is not supported
+func (this *rfm9x) SetSpreadingFactor(spreading_factor sensors.LoRaSpreadingFactor) error {
+	this.log.Debug("<sensors.RFM9x.SetSpreadingFactor>{ spreading_factor=%v }", spreading_factor)
+
+	if spreading_factor == sensors.LORA_SPREADINGFACTOR_6 {
+		return gopi.ErrNotImplemented
+	} else if spreading_factor < sensors.LORA_SPREADINGFACTOR_6 || spreading_factor > sensors.LORA_SPREADINGFACTOR_12 {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setModemConfig(this.bandwidth, this.coding_rate, spreading_factor, this.crc_enabled)
+}
+
+// Return signal bandwidth
+func (this *rfm9x) Bandwidth() sensors.LoRaBandwidth {
+	return this.bandwidth
+}
+
+// Set signal bandwidth
+func (this *rfm9x) SetBandwidth(bandwidth sensors.LoRaBandwidth) error {
+	this.log.Debug("<sensors.RFM9x.SetBandwidth>{ bandwidth=%v }", bandwidth)
+
+	if bandwidth > sensors.LORA_BANDWIDTH_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setModemConfig(bandwidth, this.coding_rate, this.spreading_factor, this.crc_enabled)
+}
+
+// Return error coding rate
+func (this *rfm9x) CodingRate() sensors.LoRaCodingRate {
+	return this.coding_rate
+}
+
+// Set error coding rate
+func (this *rfm9x) SetCodingRate(coding_rate sensors.LoRaCodingRate) error {
+	this.log.Debug("<sensors.RFM9x.SetCodingRate>{ coding_rate=%v }", coding_rate)
+
+	if coding_rate < sensors.LORA_CODINGRATE_4_5 || coding_rate > sensors.LORA_CODINGRATE_4_8 {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setModemConfig(this.bandwidth, coding_rate, this.spreading_factor, this.crc_enabled)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PACKET SETTINGS
+
+// Return preamble size in symbols
+func (this *rfm9x) PreambleSize() uint16 {
+	return this.preamble_size
+}
+
+// Set preamble size in symbols, to which the radio adds 4.25 symbols
+func (this *rfm9x) SetPreambleSize(symbols uint16) error {
+	this.log.Debug("<sensors.RFM9x.SetPreambleSize>{ symbols=%v }", symbols)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.writereg_uint16(RFM9X_REG_PREAMBLEMSB, symbols); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.readreg_uint16(RFM9X_REG_PREAMBLEMSB); err != nil {
+		return err
+	} else if symbols != value_read {
+		this.log.Debug2("SetPreambleSize expecting value=%v, got=%v", symbols, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.preamble_size = symbols
+	}
+
+	// Success
+	return nil
+}
+
+// Return true if the payload CRC is on
+func (this *rfm9x) CRC() bool {
+	return this.crc_enabled
+}
+
+// Set the payload CRC on or off
+func (this *rfm9x) SetCRC(enabled bool) error {
+	this.log.Debug("<sensors.RFM9x.SetCRC>{ enabled=%v }", enabled)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.setModemConfig(this.bandwidth, this.coding_rate, this.spreading_factor, enabled)
+}
+
+// Return the sync word
+func (this *rfm9x) SyncWord() uint8 {
+	return this.sync_word
+}
+
+// Set the sync word. The default of 0x12 is used by private networks,
+// and 0x34 by LoRaWAN
+func (this *rfm9x) SetSyncWord(value uint8) error {
+	this.log.Debug("<sensors.RFM9x.SetSyncWord>{ value=0x%02X }", value)
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.writereg_uint8(RFM9X_REG_SYNCWORD, value); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.readreg_uint8(RFM9X_REG_SYNCWORD); err != nil {
+		return err
+	} else if value != value_read {
+		this.log.Debug2("SetSyncWord expecting value=0x%02X, got=0x%02X", value, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.sync_word = value
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// OUTPUT POWER
+
+// Return output power in dBm
+func (this *rfm9x) OutputPower() int {
+	return this.output_power
+}
+
+// Set output power in dBm, between 2 and 20
+func (this *rfm9x) SetOutputPower(dbm int) error {
+	this.log.Debug("<sensors.RFM9x.SetOutputPower>{ dbm=%v }", dbm)
+
+	if dbm < RFM9X_POWER_MIN || dbm > RFM9X_POWER_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write
+	if err := this.setOutputPower(dbm); err != nil {
+		return err
+	}
+
+	// Read
+	if value_read, err := this.getOutputPower(); err != nil {
+		return err
+	} else if dbm != value_read {
+		this.log.Debug2("SetOutputPower expecting value=%v, got=%v", dbm, value_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.output_power = dbm
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *rfm9x) setMode(mode sensors.LoRaMode) error {
+	// Write
+	if err := this.setOpMode(mode); err != nil {
+		return err
+	}
+
+	// Read
+	if long_range, mode_read, err := this.getOpMode(); err != nil {
+		return err
+	} else if long_range == false || mode != mode_read {
+		this.log.Debug2("setMode expecting mode=%v, got=%v", mode, mode_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.mode = mode
+	}
+
+	// Success
+	return nil
+}
+
+// setModemConfig writes the modem settings, and the low data rate
+// optimization which depends on the spreading factor and bandwidth
+func (this *rfm9x) setModemConfig(bandwidth sensors.LoRaBandwidth, coding_rate sensors.LoRaCodingRate, spreading_factor sensors.LoRaSpreadingFactor, crc_enabled bool) error {
+	// Write
+	if err := this.setModemConfig1(bandwidth, coding_rate); err != nil {
+		return err
+	} else if err := this.setModemConfig2(spreading_factor, crc_enabled); err != nil {
+		return err
+	} else if err := this.setModemConfig3(spreading_factor, bandwidth); err != nil {
+		return err
+	}
+
+	// Read
+	if bandwidth_read, coding_rate_read, err := this.getModemConfig1(); err != nil {
+		return err
+	} else if spreading_factor_read, crc_enabled_read, err := this.getModemConfig2(); err != nil {
+		return err
+	} else if bandwidth != bandwidth_read || coding_rate != coding_rate_read || spreading_factor != spreading_factor_read || crc_enabled != crc_enabled_read {
+		this.log.Debug2("setModemConfig expecting bw=%v cr=%v sf=%v crc=%v, got bw=%v cr=%v sf=%v crc=%v", bandwidth, coding_rate, spreading_factor, crc_enabled, bandwidth_read, coding_rate_read, spreading_factor_read, crc_enabled_read)
+		return sensors.ErrUnexpectedResponse
+	} else {
+		this.bandwidth = bandwidth
+		this.coding_rate = coding_rate
+		this.spreading_factor = spreading_factor
+		this.crc_enabled = crc_enabled
+	}
+
+	// Success
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm9x
+
+import (
+	"context"
+	"math"
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Time allowed for a transmission in addition to the time on air
+	RFM9X_TX_TIMEOUT = 1000 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// ReadPacket waits for a packet in RXCONTINUOUS or RXSINGLE mode, and
+// returns it with the RSSI and SNR measured. It returns nil if the context
+// is done before a packet is received, or if the RXSINGLE timeout passes,
+// in which case the radio returns to standby
+func (this *rfm9x) ReadPacket(ctx context.Context) (*sensors.LoRaPacket, error) {
+	this.log.Debug("<sensors.RFM9x.ReadPacket>{ }")
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.mode != sensors.LORA_MODE_RXCONTINUOUS && this.mode != sensors.LORA_MODE_RXSINGLE {
+		return nil, gopi.ErrOutOfOrder
+	}
+
+	this.dio0_ts = time.Time{}
+	for {
+		if flags, err := this.readreg_uint8(RFM9X_REG_IRQFLAGS); err != nil {
+			return nil, err
+		} else if flags&RFM9X_IRQFLAGS_RXDONE != 0 {
+			return this.recvPacket(flags)
+		} else if flags&RFM9X_IRQFLAGS_RXTIMEOUT != 0 {
+			this.mode = sensors.LORA_MODE_STDBY
+			return nil, this.writereg_uint8(RFM9X_REG_IRQFLAGS, RFM9X_IRQFLAGS_ALL)
+		}
+		if this.waitIRQ(ctx) == false {
+			// Context finished without packet
+			return nil, nil
+		}
+	}
+}
+
+// WritePayload transmits a payload of up to 255 bytes and waits until
+// it has been sent. The radio then returns to RXCONTINUOUS mode if it was
+// receiving, and otherwise to standby
+func (this *rfm9x) WritePayload(data []byte) error {
+	this.log.Debug("<sensors.RFM9x.WritePayload>{ data=%v }", data)
+
+	if len(data) == 0 || len(data) > RFM9X_PAYLOAD_MAX {
+		return gopi.ErrBadParameter
+	}
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Write the payload into the FIFO in standby
+	mode := this.mode
+	if err := this.setMode(sensors.LORA_MODE_STDBY); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM9X_REG_FIFOADDRPTR, 0); err != nil {
+		return err
+	} else if err := this.writereg_uint8_array(RFM9X_REG_FIFO, data); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM9X_REG_PAYLOADLENGTH, uint8(len(data))); err != nil {
+		return err
+	} else if err := this.writereg_uint8(RFM9X_REG_IRQFLAGS, RFM9X_IRQFLAGS_ALL); err != nil {
+		return err
+	}
+
+	// Map DIO0 to TxDone while transmitting
+	if this.dio0_events != nil {
+		if err := this.setDIO0Mapping(RFM9X_DIO0_TXDONE); err != nil {
+			return err
+		}
+	}
+
+	// Transmit and wait for TxDone, after which the radio is in standby
+	if err := this.setOpMode(sensors.LORA_MODE_TX); err != nil {
+		return err
+	} else {
+		this.mode = sensors.LORA_MODE_TX
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), this.timeOnAir(len(data))+RFM9X_TX_TIMEOUT)
+	defer cancel()
+	for {
+		if flags, err := this.readreg_uint8(RFM9X_REG_IRQFLAGS); err != nil {
+			return err
+		} else if flags&RFM9X_IRQFLAGS_TXDONE != 0 {
+			this.mode = sensors.LORA_MODE_STDBY
+			break
+		}
+		if this.waitIRQ(ctx) == false {
+			this.setMode(sensors.LORA_MODE_STDBY)
+			return sensors.ErrDeviceTimeout
+		}
+	}
+
+	// Clear the interrupt flags and return to reception
+	if err := this.writereg_uint8(RFM9X_REG_IRQFLAGS, RFM9X_IRQFLAGS_ALL); err != nil {
+		return err
+	}
+	if this.dio0_events != nil {
+		if err := this.setDIO0Mapping(RFM9X_DIO0_RXDONE); err != nil {
+			return err
+		}
+	}
+	if mode == sensors.LORA_MODE_RXCONTINUOUS {
+		return this.setMode(mode)
+	}
+
+	// Success
+	return nil
+}
+
+// MeasureRSSI returns the current RSSI in dBm, which is only measured
+// when receiving
+func (this *rfm9x) MeasureRSSI() (float32, error) {
+	this.log.Debug("<sensors.RFM9x.MeasureRSSI>{ }")
+
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.mode != sensors.LORA_MODE_RXCONTINUOUS && this.mode != sensors.LORA_MODE_RXSINGLE {
+		return 0, gopi.ErrOutOfOrder
+	} else if value, err := this.readreg_uint8(RFM9X_REG_RSSIVALUE); err != nil {
+		return 0, err
+	} else {
+		return float32(this.rssiOffset() + int(value)), nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// recvPacket reads the last packet received from the FIFO, and clears
+// the interrupt flags
+func (this *rfm9x) recvPacket(flags uint8) (*sensors.LoRaPacket, error) {
+	packet := new(sensors.LoRaPacket)
+
+	// Use the time of the interrupt when there was one
+	if this.dio0_ts.IsZero() {
+		packet.Timestamp = time.Now()
+	} else {
+		packet.Timestamp = this.dio0_ts
+	}
+	packet.CRCOk = (flags & RFM9X_IRQFLAGS_PAYLOADCRCERROR) == 0
+
+	// Read the payload from the start address of the packet
+	if length, err := this.readreg_uint8(RFM9X_REG_RXNBBYTES); err != nil {
+		return nil, err
+	} else if addr, err := this.readreg_uint8(RFM9X_REG_FIFORXCURRENTADDR); err != nil {
+		return nil, err
+	} else if err := this.writereg_uint8(RFM9X_REG_FIFOADDRPTR, addr); err != nil {
+		return nil, err
+	} else if length > 0 {
+		if data, err := this.readreg_uint8_array(RFM9X_REG_FIFO, uint(length)); err != nil {
+			return nil, err
+		} else {
+			packet.Payload = data
+		}
+	}
+
+	// Signal to noise ratio is in quarters of a dB, and the packet RSSI
+	// is corrected by the SNR when the packet is below the noise floor
+	if snr, err := this.readreg_uint8(RFM9X_REG_PKTSNRVALUE); err != nil {
+		return nil, err
+	} else if rssi, err := this.readreg_uint8(RFM9X_REG_PKTRSSIVALUE); err != nil {
+		return nil, err
+	} else {
+		packet.SNR = float32(int8(snr)) / 4
+		if packet.SNR < 0 {
+			packet.RSSI = float32(this.rssiOffset()+int(rssi)) + packet.SNR
+		} else {
+			packet.RSSI = float32(this.rssiOffset()) + float32(rssi)*16/15
+		}
+	}
+
+	// Clear the interrupt flags, and in RXSINGLE mode the radio has
+	// returned to standby
+	if err := this.writereg_uint8(RFM9X_REG_IRQFLAGS, RFM9X_IRQFLAGS_ALL); err != nil {
+		return nil, err
+	} else if this.mode == sensors.LORA_MODE_RXSINGLE {
+		this.mode = sensors.LORA_MODE_STDBY
+	}
+
+	// Success
+	return packet, nil
+}
+
+// rssiOffset returns the offset added to the RSSI registers, which
+// depends on the frequency port
+func (this *rfm9x) rssiOffset() int {
+	if this.lowFrequency() {
+		return RFM9X_RSSI_OFFSET_LF
+	} else {
+		return RFM9X_RSSI_OFFSET_HF
+	}
+}
+
+// timeOnAir returns the time to transmit a payload with the current
+// modem settings, from the formula in the SX1276 datasheet with an
+// explicit header
+func (this *rfm9x) timeOnAir(length int) time.Duration {
+	symbol := symbolTime(this.spreading_factor, this.bandwidth)
+	sf := float64(this.spreading_factor)
+	crc, de := 0.0, 0.0
+	if this.crc_enabled {
+		crc = 1
+	}
+	if symbol > RFM9X_LOWDATARATE_SYM {
+		de = 1
+	}
+	payload := 8 + math.Max(math.Ceil((8*float64(length)-4*sf+28+16*crc)/(4*(sf-2*de)))*float64(this.coding_rate+4), 0)
+	preamble := float64(this.preamble_size) + 4.25
+	return time.Duration((preamble + payload) * float64(symbol))
+}
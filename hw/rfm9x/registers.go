@@ -0,0 +1,253 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm9x
+
+import (
+	"math"
+	"time"
+
+	// Frameworks
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type (
+	register uint8
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// RFM9x Registers in LoRa mode
+	RFM9X_REG_FIFO               register = 0x00 // FIFO Read/Write Access
+	RFM9X_REG_OPMODE             register = 0x01 // Operating mode and LoRa modem selection
+	RFM9X_REG_FRFMSB             register = 0x06 // RF Carrier Frequency, most significant bits
+	RFM9X_REG_FRFMID             register = 0x07 // RF Carrier Frequency, intermediate bits
+	RFM9X_REG_FRFLSB             register = 0x08 // RF Carrier Frequency, least significant bits
+	RFM9X_REG_PACONFIG           register = 0x09 // PA selection and output power control
+	RFM9X_REG_OCP                register = 0x0B // Over Current Protection control
+	RFM9X_REG_LNA                register = 0x0C // LNA settings
+	RFM9X_REG_FIFOADDRPTR        register = 0x0D // FIFO SPI pointer
+	RFM9X_REG_FIFOTXBASEADDR     register = 0x0E // Start TX data
+	RFM9X_REG_FIFORXBASEADDR     register = 0x0F // Start RX data
+	RFM9X_REG_FIFORXCURRENTADDR  register = 0x10 // Start address of last packet received
+	RFM9X_REG_IRQFLAGSMASK       register = 0x11 // Optional IRQ flag mask
+	RFM9X_REG_IRQFLAGS           register = 0x12 // IRQ flags
+	RFM9X_REG_RXNBBYTES          register = 0x13 // Number of received bytes
+	RFM9X_REG_PKTSNRVALUE        register = 0x19 // SNR of last packet received
+	RFM9X_REG_PKTRSSIVALUE       register = 0x1A // RSSI of last packet received
+	RFM9X_REG_RSSIVALUE          register = 0x1B // Current RSSI
+	RFM9X_REG_MODEMCONFIG1       register = 0x1D // Bandwidth, coding rate and header mode
+	RFM9X_REG_MODEMCONFIG2       register = 0x1E // Spreading factor and payload CRC
+	RFM9X_REG_SYMBTIMEOUTLSB     register = 0x1F // Receiver timeout value
+	RFM9X_REG_PREAMBLEMSB        register = 0x20 // Preamble length, MSB
+	RFM9X_REG_PREAMBLELSB        register = 0x21 // Preamble length, LSB
+	RFM9X_REG_PAYLOADLENGTH      register = 0x22 // Payload length in implicit header mode and for TX
+	RFM9X_REG_MODEMCONFIG3       register = 0x26 // Low data rate optimize and AGC
+	RFM9X_REG_DETECTOPTIMIZE     register = 0x31 // LoRa detection optimize
+	RFM9X_REG_DETECTIONTHRESHOLD register = 0x37 // LoRa detection threshold
+	RFM9X_REG_SYNCWORD           register = 0x39 // LoRa sync word
+	RFM9X_REG_DIOMAPPING1        register = 0x40 // Mapping of pins DIO0 to DIO3
+	RFM9X_REG_DIOMAPPING2        register = 0x41 // Mapping of pins DIO4 and DIO5
+	RFM9X_REG_VERSION            register = 0x42 // Semtech ID relating the silicon revision
+	RFM9X_REG_PADAC              register = 0x4D // Higher power settings of the PA
+	RFM9X_REG_MAX                register = 0x7F // Last possible register value
+	RFM9X_REG_WRITE              register = 0x80 // Write bit
+)
+
+const (
+	// RegOpMode
+	RFM9X_OPMODE_LONGRANGE = 0x80
+	RFM9X_OPMODE_LOWFREQ   = 0x08
+	RFM9X_OPMODE_MODE      = 0x07
+)
+
+const (
+	// RegIrqFlags
+	RFM9X_IRQFLAGS_RXTIMEOUT       uint8 = 0x80
+	RFM9X_IRQFLAGS_RXDONE          uint8 = 0x40
+	RFM9X_IRQFLAGS_PAYLOADCRCERROR uint8 = 0x20
+	RFM9X_IRQFLAGS_VALIDHEADER     uint8 = 0x10
+	RFM9X_IRQFLAGS_TXDONE          uint8 = 0x08
+	RFM9X_IRQFLAGS_CADDONE         uint8 = 0x04
+	RFM9X_IRQFLAGS_ALL             uint8 = 0xFF
+)
+
+const (
+	// RegModemConfig2 and RegModemConfig3
+	RFM9X_MODEMCONFIG2_RXPAYLOADCRCON      = 0x04
+	RFM9X_MODEMCONFIG3_LOWDATARATEOPTIMIZE = 0x08
+	RFM9X_MODEMCONFIG3_AGCAUTOON           = 0x04
+)
+
+const (
+	// RegPaConfig and RegPaDac
+	RFM9X_PACONFIG_PABOOST = 0x80
+	RFM9X_PADAC_NORMAL     = 0x84
+	RFM9X_PADAC_HIGH       = 0x87
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM9X_REG_OPMODE
+
+func (this *rfm9x) getOpMode() (bool, sensors.LoRaMode, error) {
+	if value, err := this.readreg_uint8(RFM9X_REG_OPMODE); err != nil {
+		return false, 0, err
+	} else {
+		long_range := (value & RFM9X_OPMODE_LONGRANGE) != 0
+		mode := sensors.LoRaMode(value & RFM9X_OPMODE_MODE)
+		return long_range, mode, nil
+	}
+}
+
+// setOpMode writes the mode with the LoRa modem selected, and the
+// low frequency port selected for the carrier frequency
+func (this *rfm9x) setOpMode(mode sensors.LoRaMode) error {
+	value := uint8(RFM9X_OPMODE_LONGRANGE) | uint8(mode)&RFM9X_OPMODE_MODE
+	if this.lowFrequency() {
+		value |= RFM9X_OPMODE_LOWFREQ
+	}
+	return this.writereg_uint8(RFM9X_REG_OPMODE, value)
+}
+
+// lowFrequency returns true when the carrier frequency uses the low
+// frequency port, as on the RFM96 and RFM98
+func (this *rfm9x) lowFrequency() bool {
+	return frfToHertz(this.frf) < RFM9X_FREQ_LF_MAX
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM9X_REG_MODEMCONFIG1, RFM9X_REG_MODEMCONFIG2 AND RFM9X_REG_MODEMCONFIG3
+
+func (this *rfm9x) getModemConfig1() (sensors.LoRaBandwidth, sensors.LoRaCodingRate, error) {
+	if value, err := this.readreg_uint8(RFM9X_REG_MODEMCONFIG1); err != nil {
+		return 0, 0, err
+	} else {
+		bandwidth := sensors.LoRaBandwidth(value >> 4)
+		coding_rate := sensors.LoRaCodingRate((value >> 1) & 0x07)
+		return bandwidth, coding_rate, nil
+	}
+}
+
+// setModemConfig1 writes the bandwidth and coding rate, with explicit
+// header mode
+func (this *rfm9x) setModemConfig1(bandwidth sensors.LoRaBandwidth, coding_rate sensors.LoRaCodingRate) error {
+	value := uint8(bandwidth&0x0F)<<4 | uint8(coding_rate&0x07)<<1
+	return this.writereg_uint8(RFM9X_REG_MODEMCONFIG1, value)
+}
+
+func (this *rfm9x) getModemConfig2() (sensors.LoRaSpreadingFactor, bool, error) {
+	if value, err := this.readreg_uint8(RFM9X_REG_MODEMCONFIG2); err != nil {
+		return 0, false, err
+	} else {
+		spreading_factor := sensors.LoRaSpreadingFactor(value >> 4)
+		crc_enabled := (value & RFM9X_MODEMCONFIG2_RXPAYLOADCRCON) != 0
+		return spreading_factor, crc_enabled, nil
+	}
+}
+
+// setModemConfig2 writes the spreading factor and payload CRC, keeping
+// the continuous TX and symbol timeout bits
+func (this *rfm9x) setModemConfig2(spreading_factor sensors.LoRaSpreadingFactor, crc_enabled bool) error {
+	if value, err := this.readreg_uint8(RFM9X_REG_MODEMCONFIG2); err != nil {
+		return err
+	} else {
+		value = (value & 0x0B) | uint8(spreading_factor&0x0F)<<4
+		if crc_enabled {
+			value |= RFM9X_MODEMCONFIG2_RXPAYLOADCRCON
+		}
+		return this.writereg_uint8(RFM9X_REG_MODEMCONFIG2, value)
+	}
+}
+
+// setModemConfig3 switches on the automatic gain control, and the low
+// data rate optimization when the symbol time is longer than 16ms, which
+// the datasheet requires at high spreading factors and low bandwidths
+func (this *rfm9x) setModemConfig3(spreading_factor sensors.LoRaSpreadingFactor, bandwidth sensors.LoRaBandwidth) error {
+	value := uint8(RFM9X_MODEMCONFIG3_AGCAUTOON)
+	if symbolTime(spreading_factor, bandwidth) > RFM9X_LOWDATARATE_SYM {
+		value |= RFM9X_MODEMCONFIG3_LOWDATARATEOPTIMIZE
+	}
+	return this.writereg_uint8(RFM9X_REG_MODEMCONFIG3, value)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM9X_REG_PACONFIG AND RFM9X_REG_PADAC
+
+// getOutputPower returns the output power in dBm, through PA_BOOST or
+// through the RFO pin
+func (this *rfm9x) getOutputPower() (int, error) {
+	if pa_config, err := this.readreg_uint8(RFM9X_REG_PACONFIG); err != nil {
+		return 0, err
+	} else if pa_dac, err := this.readreg_uint8(RFM9X_REG_PADAC); err != nil {
+		return 0, err
+	} else if pa_config&RFM9X_PACONFIG_PABOOST == 0 {
+		max_power := 10.8 + 0.6*float64((pa_config>>4)&0x07)
+		return int(math.Round(max_power - float64(15-pa_config&0x0F))), nil
+	} else if pa_dac&0x07 == RFM9X_PADAC_HIGH&0x07 {
+		return int(pa_config&0x0F) + 5, nil
+	} else {
+		return int(pa_config&0x0F) + 2, nil
+	}
+}
+
+// setOutputPower sets the output power through PA_BOOST, which is the
+// only PA connected on the RFM9x modules. Above 17dBm the high power
+// DAC adds 3dB
+func (this *rfm9x) setOutputPower(dbm int) error {
+	if dbm > RFM9X_POWER_HIGH {
+		if err := this.writereg_uint8(RFM9X_REG_PADAC, RFM9X_PADAC_HIGH); err != nil {
+			return err
+		}
+		return this.writereg_uint8(RFM9X_REG_PACONFIG, RFM9X_PACONFIG_PABOOST|uint8(dbm-5))
+	} else {
+		if err := this.writereg_uint8(RFM9X_REG_PADAC, RFM9X_PADAC_NORMAL); err != nil {
+			return err
+		}
+		return this.writereg_uint8(RFM9X_REG_PACONFIG, RFM9X_PACONFIG_PABOOST|uint8(dbm-2))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RFM9X_REG_DIOMAPPING1
+
+func (this *rfm9x) setDIO0Mapping(mapping uint8) error {
+	if value, err := this.readreg_uint8(RFM9X_REG_DIOMAPPING1); err != nil {
+		return err
+	} else {
+		return this.writereg_uint8(RFM9X_REG_DIOMAPPING1, (value&0x3F)|((mapping&0x03)<<6))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONVERSIONS
+
+// frfToHertz returns the carrier frequency in Hz for a register value
+func frfToHertz(frf uint32) uint {
+	return uint(uint64(frf) * RFM9X_FXOSC_MHZ * 1e6 >> 19)
+}
+
+// hertzToFrf returns the register value for a carrier frequency in Hz
+func hertzToFrf(hertz uint) uint32 {
+	return uint32((uint64(hertz) << 19) / (RFM9X_FXOSC_MHZ * 1e6))
+}
+
+// symbolTime returns the duration of a symbol, which is 2^SF chips at
+// the chip rate of the bandwidth
+func symbolTime(spreading_factor sensors.LoRaSpreadingFactor, bandwidth sensors.LoRaBandwidth) time.Duration {
+	if hertz := bandwidth.Hertz(); hertz == 0 {
+		return 0
+	} else {
+		return time.Duration(uint64(1)<<spreading_factor) * time.Second / time.Duration(hertz)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// RFM95/96/97/98 LoRa transceivers, which use the Semtech SX1276 family
+// of chips. Only the LoRa modem is supported, with explicit headers
+package rfm9x
+
+import (
+	"sync"
+	"time"
+
+	// Frameworks
+	gopi "github.com/djthorpe/gopi"
+	sensors "github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Configuration
+type RFM9x struct {
+	// the SPI driver
+	SPI gopi.SPI
+
+	// SPI device speed, or zero for the default
+	Speed uint32
+
+	// GPIO and pin connected to DIO0 for interrupt-driven reception
+	// and transmission, or nil to poll the interrupt flags
+	GPIO    gopi.GPIO
+	PinDIO0 gopi.GPIOPin
+}
+
+// driver
+type rfm9x struct {
+	spi  gopi.SPI
+	log  gopi.Logger
+	lock sync.Mutex
+
+	dio0        gopi.GPIOPin
+	dio0_gpio   gopi.GPIO
+	dio0_events <-chan gopi.Event
+	dio0_ts     time.Time
+
+	version          uint8
+	mode             sensors.LoRaMode
+	frf              uint32
+	bandwidth        sensors.LoRaBandwidth
+	coding_rate      sensors.LoRaCodingRate
+	spreading_factor sensors.LoRaSpreadingFactor
+	crc_enabled      bool
+	preamble_size    uint16
+	sync_word        uint8
+	output_power     int
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	RFM9X_SPI_MODE        = gopi.SPI_MODE_0
+	RFM9X_SPI_SPEEDHZ     = 4000000 // 4MHz
+	RFM9X_VERSION_VALUE   = 0x12
+	RFM9X_FXOSC_MHZ       = 32        // Crystal oscillator frequency MHz
+	RFM9X_FRF_MAX         = 0xFFFFFF  // Maximum value of FRF
+	RFM9X_FREQ_LF_MAX     = 525000000 // Carrier frequencies below this use the low frequency port
+	RFM9X_PAYLOAD_MAX     = 255       // Bytes
+	RFM9X_POWER_MIN       = 2         // dBm, through PA_BOOST
+	RFM9X_POWER_MAX       = 20        // dBm, through PA_BOOST
+	RFM9X_POWER_HIGH      = 17        // dBm, above which the high power DAC is used
+	RFM9X_RSSI_OFFSET_HF  = -157      // dBm
+	RFM9X_RSSI_OFFSET_LF  = -164      // dBm
+	RFM9X_LOWDATARATE_SYM = 16 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config RFM9x) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug("<sensors.RFM9x.Open>{ spi=%v speed=%v }", config.SPI, config.Speed)
+
+	this := new(rfm9x)
+	this.spi = config.SPI
+	this.log = log
+	this.dio0 = gopi.GPIO_PIN_NONE
+
+	if this.spi == nil {
+		return nil, gopi.ErrBadParameter
+	}
+
+	// Set SPI mode and speed
+	speed := config.Speed
+	if speed == 0 {
+		speed = RFM9X_SPI_SPEEDHZ
+	}
+	if err := this.spi.SetMode(RFM9X_SPI_MODE); err != nil {
+		return nil, err
+	} else if err := this.spi.SetMaxSpeedHz(speed); err != nil {
+		return nil, err
+	}
+
+	// Lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Get version - and check against expected value
+	if version, err := this.readreg_uint8(RFM9X_REG_VERSION); err != nil {
+		return nil, sensors.ErrNoDevice
+	} else if version != RFM9X_VERSION_VALUE {
+		return nil, sensors.ErrNoDevice
+	} else {
+		this.version = version
+	}
+
+	// Get Frequency Carrier, which selects the low or high frequency port
+	if frf, err := this.readreg_uint24(RFM9X_REG_FRFMSB); err != nil {
+		return nil, err
+	} else {
+		this.frf = frf
+	}
+
+	// The LoRa modem can only be selected in sleep mode, after which
+	// the radio is put into standby
+	if long_range, mode, err := this.getOpMode(); err != nil {
+		return nil, err
+	} else if long_range == false {
+		if err := this.setOpMode(sensors.LORA_MODE_SLEEP); err != nil {
+			return nil, err
+		} else if err := this.setOpMode(sensors.LORA_MODE_STDBY); err != nil {
+			return nil, err
+		}
+		this.mode = sensors.LORA_MODE_STDBY
+	} else {
+		this.mode = mode
+	}
+
+	// The whole FIFO is used for each transmitted and received payload
+	if err := this.writereg_uint8(RFM9X_REG_FIFOTXBASEADDR, 0); err != nil {
+		return nil, err
+	} else if err := this.writereg_uint8(RFM9X_REG_FIFORXBASEADDR, 0); err != nil {
+		return nil, err
+	}
+
+	// Modem settings
+	if bandwidth, coding_rate, err := this.getModemConfig1(); err != nil {
+		return nil, err
+	} else if spreading_factor, crc_enabled, err := this.getModemConfig2(); err != nil {
+		return nil, err
+	} else {
+		this.bandwidth = bandwidth
+		this.coding_rate = coding_rate
+		this.spreading_factor = spreading_factor
+		this.crc_enabled = crc_enabled
+	}
+
+	// Preamble and sync word
+	if preamble_size, err := this.readreg_uint16(RFM9X_REG_PREAMBLEMSB); err != nil {
+		return nil, err
+	} else if sync_word, err := this.readreg_uint8(RFM9X_REG_SYNCWORD); err != nil {
+		return nil, err
+	} else {
+		this.preamble_size = preamble_size
+		this.sync_word = sync_word
+	}
+
+	// Output power
+	if output_power, err := this.getOutputPower(); err != nil {
+		return nil, err
+	} else {
+		this.output_power = output_power
+	}
+
+	// Interrupt-driven reception and transmission
+	if config.GPIO != nil && config.PinDIO0 != gopi.GPIO_PIN_NONE {
+		if err := this.setInterrupt(config.GPIO, config.PinDIO0); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return success
+	return this, nil
+}
+
+func (this *rfm9x) Close() error {
+	this.log.Debug("<sensors.RFM9x.Close>{ }")
+
+	// Lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Stop watching for interrupts
+	if err := this.setInterrupt(nil, gopi.GPIO_PIN_NONE); err != nil {
+		return err
+	}
+
+	// Blank out SPI value
+	this.spi = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// VERSION
+
+// Read the version register
+func (this *rfm9x) ReadVersion() (uint8, error) {
+	// Mutex lock
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	return this.readreg_uint8(RFM9X_REG_VERSION)
+}
@@ -0,0 +1,106 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm9x
+
+import (
+	"fmt"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *rfm9x) String() string {
+	params := []string{
+		fmt.Sprintf("version=0x%02X", this.version),
+		fmt.Sprintf("mode=%v", this.mode),
+		fmt.Sprintf("frf=0x%06X", this.frf),
+		fmt.Sprintf("low_frequency=%v", this.lowFrequency()),
+		fmt.Sprintf("spreading_factor=%v", this.spreading_factor),
+		fmt.Sprintf("bandwidth=%v", this.bandwidth),
+		fmt.Sprintf("coding_rate=%v", this.coding_rate),
+		fmt.Sprintf("crc_enabled=%v", this.crc_enabled),
+		fmt.Sprintf("preamble_size=%v", this.preamble_size),
+		fmt.Sprintf("sync_word=0x%02X", this.sync_word),
+		fmt.Sprintf("output_power=%vdBm", this.output_power),
+	}
+	return fmt.Sprintf("sensors.RFM9x{ spi=%v %v }", this.spi, strings.Join(params, " "))
+}
+
+func (r register) String() string {
+	switch r {
+	case RFM9X_REG_FIFO:
+		return "RFM9X_REG_FIFO"
+	case RFM9X_REG_OPMODE:
+		return "RFM9X_REG_OPMODE"
+	case RFM9X_REG_FRFMSB:
+		return "RFM9X_REG_FRFMSB"
+	case RFM9X_REG_FRFMID:
+		return "RFM9X_REG_FRFMID"
+	case RFM9X_REG_FRFLSB:
+		return "RFM9X_REG_FRFLSB"
+	case RFM9X_REG_PACONFIG:
+		return "RFM9X_REG_PACONFIG"
+	case RFM9X_REG_OCP:
+		return "RFM9X_REG_OCP"
+	case RFM9X_REG_LNA:
+		return "RFM9X_REG_LNA"
+	case RFM9X_REG_FIFOADDRPTR:
+		return "RFM9X_REG_FIFOADDRPTR"
+	case RFM9X_REG_FIFOTXBASEADDR:
+		return "RFM9X_REG_FIFOTXBASEADDR"
+	case RFM9X_REG_FIFORXBASEADDR:
+		return "RFM9X_REG_FIFORXBASEADDR"
+	case RFM9X_REG_FIFORXCURRENTADDR:
+		return "RFM9X_REG_FIFORXCURRENTADDR"
+	case RFM9X_REG_IRQFLAGSMASK:
+		return "RFM9X_REG_IRQFLAGSMASK"
+	case RFM9X_REG_IRQFLAGS:
+		return "RFM9X_REG_IRQFLAGS"
+	case RFM9X_REG_RXNBBYTES:
+		return "RFM9X_REG_RXNBBYTES"
+	case RFM9X_REG_PKTSNRVALUE:
+		return "RFM9X_REG_PKTSNRVALUE"
+	case RFM9X_REG_PKTRSSIVALUE:
+		return "RFM9X_REG_PKTRSSIVALUE"
+	case RFM9X_REG_RSSIVALUE:
+		return "RFM9X_REG_RSSIVALUE"
+	case RFM9X_REG_MODEMCONFIG1:
+		return "RFM9X_REG_MODEMCONFIG1"
+	case RFM9X_REG_MODEMCONFIG2:
+		return "RFM9X_REG_MODEMCONFIG2"
+	case RFM9X_REG_SYMBTIMEOUTLSB:
+		return "RFM9X_REG_SYMBTIMEOUTLSB"
+	case RFM9X_REG_PREAMBLEMSB:
+		return "RFM9X_REG_PREAMBLEMSB"
+	case RFM9X_REG_PREAMBLELSB:
+		return "RFM9X_REG_PREAMBLELSB"
+	case RFM9X_REG_PAYLOADLENGTH:
+		return "RFM9X_REG_PAYLOADLENGTH"
+	case RFM9X_REG_MODEMCONFIG3:
+		return "RFM9X_REG_MODEMCONFIG3"
+	case RFM9X_REG_DETECTOPTIMIZE:
+		return "RFM9X_REG_DETECTOPTIMIZE"
+	case RFM9X_REG_DETECTIONTHRESHOLD:
+		return "RFM9X_REG_DETECTIONTHRESHOLD"
+	case RFM9X_REG_SYNCWORD:
+		return "RFM9X_REG_SYNCWORD"
+	case RFM9X_REG_DIOMAPPING1:
+		return "RFM9X_REG_DIOMAPPING1"
+	case RFM9X_REG_DIOMAPPING2:
+		return "RFM9X_REG_DIOMAPPING2"
+	case RFM9X_REG_VERSION:
+		return "RFM9X_REG_VERSION"
+	case RFM9X_REG_PADAC:
+		return "RFM9X_REG_PADAC"
+	default:
+		return "[?? Invalid register value]"
+	}
+}
@@ -0,0 +1,86 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package rfm9x
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *rfm9x) readreg_uint8(reg register) (uint8, error) {
+	recv, err := this.spi.Transfer([]byte{byte(reg & RFM9X_REG_MAX), 0})
+	if err != nil {
+		return 0, err
+	}
+	this.log.Debug2("<sensors.RFM9x>readreg_uint8{ reg=%v recv=0x%02X }", reg, recv[1])
+	return recv[1], nil
+}
+
+func (this *rfm9x) readreg_uint8_array(reg register, length uint) ([]byte, error) {
+	send := make([]byte, length+1)
+	send[0] = byte(reg & RFM9X_REG_MAX)
+	recv, err := this.spi.Transfer(send)
+	if err != nil {
+		return nil, err
+	}
+	this.log.Debug2("<sensors.RFM9x>readreg_uint8_array{ reg=%v length=%v recv=0x%v }", reg, length, strings.ToUpper(hex.EncodeToString(recv[1:])))
+	return recv[1:], nil
+}
+
+func (this *rfm9x) readreg_uint16(reg register) (uint16, error) {
+	if recv, err := this.readreg_uint8_array(reg, 2); err != nil {
+		return 0, err
+	} else {
+		return uint16(recv[0])<<8 | uint16(recv[1]), nil
+	}
+}
+
+func (this *rfm9x) readreg_uint24(reg register) (uint32, error) {
+	if recv, err := this.readreg_uint8_array(reg, 3); err != nil {
+		return 0, err
+	} else {
+		return uint32(recv[0])<<16 | uint32(recv[1])<<8 | uint32(recv[2]), nil
+	}
+}
+
+func (this *rfm9x) writereg_uint8(reg register, data uint8) error {
+	this.log.Debug2("<sensors.RFM9x>writereg_uint8{ reg=%v data=0x%02X }", reg, data)
+	return this.writereg(reg, []byte{data})
+}
+
+func (this *rfm9x) writereg_uint16(reg register, data uint16) error {
+	this.log.Debug2("<sensors.RFM9x>writereg_uint16{ reg=%v data=0x%04X }", reg, data)
+	return this.writereg(reg, []byte{
+		uint8(data & 0xFF00 >> 8),
+		uint8(data & 0xFF),
+	})
+}
+
+func (this *rfm9x) writereg_uint24(reg register, data uint32) error {
+	this.log.Debug2("<sensors.RFM9x>writereg_uint24{ reg=%v data=0x%06X }", reg, data)
+	return this.writereg(reg, []byte{
+		uint8(data & 0xFF0000 >> 16),
+		uint8(data & 0xFF00 >> 8),
+		uint8(data & 0xFF),
+	})
+}
+
+func (this *rfm9x) writereg_uint8_array(reg register, data []byte) error {
+	this.log.Debug2("<sensors.RFM9x>writereg_uint8_array{ reg=%v data=%v }", reg, strings.ToUpper(hex.EncodeToString(data)))
+	return this.writereg(reg, data)
+}
+
+func (this *rfm9x) writereg(reg register, data []byte) error {
+	buf := append([]byte(nil), byte((reg&RFM9X_REG_MAX)|RFM9X_REG_WRITE))
+	return this.spi.Write(append(buf, data...))
+}
@@ -15,6 +15,7 @@ import (
 
 	gopi "github.com/djthorpe/gopi"
 	sensors "github.com/djthorpe/sensors"
+	i2caddr "github.com/djthorpe/sensors/util/i2caddr"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -45,7 +46,9 @@ type tsl2561 struct {
 // CONSTANTS
 
 const (
-	TSL2561_I2CSLAVE_DEFAULT = 0x39
+	TSL2561_I2CSLAVE_DEFAULT = 0x39 // ADDR pin floating
+	TSL2561_I2CSLAVE_LOW     = 0x29 // ADDR pin to ground
+	TSL2561_I2CSLAVE_HIGH    = 0x49 // ADDR pin to VDD
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -57,30 +60,30 @@ func (config TSL2561) Open(log gopi.Logger) (gopi.Driver, error) {
 	this := new(tsl2561)
 	this.i2c = config.I2C
 	this.log = log
-	this.slave = TSL2561_I2CSLAVE_DEFAULT
-
-	if config.Slave != 0 {
-		this.slave = config.Slave
-	}
 
 	if this.i2c == nil {
 		return nil, gopi.ErrBadParameter
 	}
 
-	// Detect slave
-	if detected, err := this.i2c.DetectSlave(this.slave); err != nil {
+	// Detect slave, or select one of the three addresses when not set
+	candidates := []uint8{TSL2561_I2CSLAVE_DEFAULT, TSL2561_I2CSLAVE_LOW, TSL2561_I2CSLAVE_HIGH}
+	if config.Slave != 0 {
+		candidates = []uint8{config.Slave}
+	}
+	if slave, err := i2caddr.Select(this.i2c, candidates, "TSL2561"); err != nil {
+		return nil, err
+	} else if err := i2caddr.Claim(this.i2c, slave, "sensors/tsl2561"); err != nil {
 		return nil, err
-	} else if detected == false {
-		return nil, sensors.ErrNoDevice
+	} else {
+		this.slave = slave
 	}
 
-	// Set slave
+	// Set slave, chip and version
 	if err := this.i2c.SetSlave(this.slave); err != nil {
+		i2caddr.Release(this.i2c, this.slave)
 		return nil, err
-	}
-
-	// Chip and version
-	if chip_id, revision, err := this.readChipVersion(); err != nil {
+	} else if chip_id, revision, err := this.readChipVersion(); err != nil {
+		i2caddr.Release(this.i2c, this.slave)
 		return nil, err
 	} else {
 		this.chipid = chip_id
@@ -92,6 +95,7 @@ func (config TSL2561) Open(log gopi.Logger) (gopi.Driver, error) {
 
 	// Obtain gain and integrate_time
 	if gain, integrate_time, err := this.readTiming(); err != nil {
+		i2caddr.Release(this.i2c, this.slave)
 		return nil, err
 	} else {
 		this.gain = gain
@@ -105,6 +109,11 @@ func (config TSL2561) Open(log gopi.Logger) (gopi.Driver, error) {
 func (this *tsl2561) Close() error {
 	this.log.Debug2("<sensors.TSL2561.Close>{ }")
 
+	// Release the slave address
+	if this.i2c != nil {
+		i2caddr.Release(this.i2c, this.slave)
+	}
+
 	// Zero out fields
 	this.i2c = nil
 
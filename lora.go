@@ -0,0 +1,255 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sensors
+
+import (
+	"context"
+	"time"
+
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// LORA TYPES
+
+type (
+	LoRaMode            uint8
+	LoRaBandwidth       uint8
+	LoRaCodingRate      uint8
+	LoRaSpreadingFactor uint8
+)
+
+// LoRaPacket is a received payload, with the signal measurements
+// taken while it was received
+type LoRaPacket struct {
+	Timestamp time.Time
+	Payload   []byte
+	CRCOk     bool    // False if the payload CRC was on and did not match
+	RSSI      float32 // Signal strength of the packet, dBm
+	SNR       float32 // Signal to noise ratio of the packet, dB
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// LORA INTERFACE
+
+// LoRa is a long range transceiver such as the RFM95 or RFM96, using
+// chirp spread spectrum modulation
+type LoRa interface {
+	gopi.Driver
+
+	// Mode
+	Mode() LoRaMode
+	SetMode(mode LoRaMode) error
+
+	// Carrier frequency
+	FreqCarrier() uint
+	SetFreqCarrier(hertz uint) error
+
+	// Modem settings. Higher spreading factors and lower bandwidths
+	// increase the range and reduce the data rate
+	SpreadingFactor() LoRaSpreadingFactor
+	SetSpreadingFactor(sf LoRaSpreadingFactor) error
+	Bandwidth() LoRaBandwidth
+	SetBandwidth(bw LoRaBandwidth) error
+	CodingRate() LoRaCodingRate
+	SetCodingRate(cr LoRaCodingRate) error
+
+	// Packet settings
+	PreambleSize() uint16
+	SetPreambleSize(symbols uint16) error
+	CRC() bool
+	SetCRC(enabled bool) error
+	SyncWord() uint8
+	SetSyncWord(value uint8) error
+
+	// Output power in dBm
+	OutputPower() int
+	SetOutputPower(dbm int) error
+
+	// Reception and transmission, which wait for the DIO0 interrupt
+	// if SetInterrupt has been called. ReadPacket returns nil if the
+	// context is done before a packet is received
+	SetInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error
+	ReadPacket(ctx context.Context) (*LoRaPacket, error)
+	WritePayload(data []byte) error
+
+	// Measure the current RSSI, in dBm
+	MeasureRSSI() (float32, error)
+
+	// Read the version register
+	ReadVersion() (uint8, error)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// LORA CONSTS
+
+const (
+	// LoRa Mode
+	LORA_MODE_SLEEP        LoRaMode = 0x00
+	LORA_MODE_STDBY        LoRaMode = 0x01
+	LORA_MODE_FSTX         LoRaMode = 0x02
+	LORA_MODE_TX           LoRaMode = 0x03
+	LORA_MODE_FSRX         LoRaMode = 0x04
+	LORA_MODE_RXCONTINUOUS LoRaMode = 0x05
+	LORA_MODE_RXSINGLE     LoRaMode = 0x06
+	LORA_MODE_CAD          LoRaMode = 0x07
+	LORA_MODE_MAX          LoRaMode = 0x07
+)
+
+const (
+	// LoRa signal bandwidth
+	LORA_BANDWIDTH_7P8   LoRaBandwidth = 0x00 // 7.8kHz
+	LORA_BANDWIDTH_10P4  LoRaBandwidth = 0x01 // 10.4kHz
+	LORA_BANDWIDTH_15P6  LoRaBandwidth = 0x02 // 15.6kHz
+	LORA_BANDWIDTH_20P8  LoRaBandwidth = 0x03 // 20.8kHz
+	LORA_BANDWIDTH_31P25 LoRaBandwidth = 0x04 // 31.25kHz
+	LORA_BANDWIDTH_41P7  LoRaBandwidth = 0x05 // 41.7kHz
+	LORA_BANDWIDTH_62P5  LoRaBandwidth = 0x06 // 62.5kHz
+	LORA_BANDWIDTH_125   LoRaBandwidth = 0x07 // 125kHz
+	LORA_BANDWIDTH_250   LoRaBandwidth = 0x08 // 250kHz
+	LORA_BANDWIDTH_500   LoRaBandwidth = 0x09 // 500kHz
+	LORA_BANDWIDTH_MAX   LoRaBandwidth = 0x09
+)
+
+const (
+	// LoRa error coding rate
+	LORA_CODINGRATE_4_5 LoRaCodingRate = 0x01
+	LORA_CODINGRATE_4_6 LoRaCodingRate = 0x02
+	LORA_CODINGRATE_4_7 LoRaCodingRate = 0x03
+	LORA_CODINGRATE_4_8 LoRaCodingRate = 0x04
+)
+
+const (
+	// LoRa spreading factor, as chips per symbol in powers of two
+	LORA_SPREADINGFACTOR_6  LoRaSpreadingFactor = 6
+	LORA_SPREADINGFACTOR_7  LoRaSpreadingFactor = 7
+	LORA_SPREADINGFACTOR_8  LoRaSpreadingFactor = 8
+	LORA_SPREADINGFACTOR_9  LoRaSpreadingFactor = 9
+	LORA_SPREADINGFACTOR_10 LoRaSpreadingFactor = 10
+	LORA_SPREADINGFACTOR_11 LoRaSpreadingFactor = 11
+	LORA_SPREADINGFACTOR_12 LoRaSpreadingFactor = 12
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// LORA STRINGIFY
+
+func (m LoRaMode) String() string {
+	switch m {
+	case LORA_MODE_SLEEP:
+		return "LORA_MODE_SLEEP"
+	case LORA_MODE_STDBY:
+		return "LORA_MODE_STDBY"
+	case LORA_MODE_FSTX:
+		return "LORA_MODE_FSTX"
+	case LORA_MODE_TX:
+		return "LORA_MODE_TX"
+	case LORA_MODE_FSRX:
+		return "LORA_MODE_FSRX"
+	case LORA_MODE_RXCONTINUOUS:
+		return "LORA_MODE_RXCONTINUOUS"
+	case LORA_MODE_RXSINGLE:
+		return "LORA_MODE_RXSINGLE"
+	case LORA_MODE_CAD:
+		return "LORA_MODE_CAD"
+	default:
+		return "[?? Invalid LoRaMode value]"
+	}
+}
+
+func (b LoRaBandwidth) String() string {
+	switch b {
+	case LORA_BANDWIDTH_7P8:
+		return "LORA_BANDWIDTH_7P8"
+	case LORA_BANDWIDTH_10P4:
+		return "LORA_BANDWIDTH_10P4"
+	case LORA_BANDWIDTH_15P6:
+		return "LORA_BANDWIDTH_15P6"
+	case LORA_BANDWIDTH_20P8:
+		return "LORA_BANDWIDTH_20P8"
+	case LORA_BANDWIDTH_31P25:
+		return "LORA_BANDWIDTH_31P25"
+	case LORA_BANDWIDTH_41P7:
+		return "LORA_BANDWIDTH_41P7"
+	case LORA_BANDWIDTH_62P5:
+		return "LORA_BANDWIDTH_62P5"
+	case LORA_BANDWIDTH_125:
+		return "LORA_BANDWIDTH_125"
+	case LORA_BANDWIDTH_250:
+		return "LORA_BANDWIDTH_250"
+	case LORA_BANDWIDTH_500:
+		return "LORA_BANDWIDTH_500"
+	default:
+		return "[?? Invalid LoRaBandwidth value]"
+	}
+}
+
+// Hertz returns the bandwidth in Hz, or zero if invalid
+func (b LoRaBandwidth) Hertz() uint {
+	switch b {
+	case LORA_BANDWIDTH_7P8:
+		return 7800
+	case LORA_BANDWIDTH_10P4:
+		return 10400
+	case LORA_BANDWIDTH_15P6:
+		return 15600
+	case LORA_BANDWIDTH_20P8:
+		return 20800
+	case LORA_BANDWIDTH_31P25:
+		return 31250
+	case LORA_BANDWIDTH_41P7:
+		return 41700
+	case LORA_BANDWIDTH_62P5:
+		return 62500
+	case LORA_BANDWIDTH_125:
+		return 125000
+	case LORA_BANDWIDTH_250:
+		return 250000
+	case LORA_BANDWIDTH_500:
+		return 500000
+	default:
+		return 0
+	}
+}
+
+func (c LoRaCodingRate) String() string {
+	switch c {
+	case LORA_CODINGRATE_4_5:
+		return "LORA_CODINGRATE_4_5"
+	case LORA_CODINGRATE_4_6:
+		return "LORA_CODINGRATE_4_6"
+	case LORA_CODINGRATE_4_7:
+		return "LORA_CODINGRATE_4_7"
+	case LORA_CODINGRATE_4_8:
+		return "LORA_CODINGRATE_4_8"
+	default:
+		return "[?? Invalid LoRaCodingRate value]"
+	}
+}
+
+func (sf LoRaSpreadingFactor) String() string {
+	switch sf {
+	case LORA_SPREADINGFACTOR_6:
+		return "LORA_SPREADINGFACTOR_6"
+	case LORA_SPREADINGFACTOR_7:
+		return "LORA_SPREADINGFACTOR_7"
+	case LORA_SPREADINGFACTOR_8:
+		return "LORA_SPREADINGFACTOR_8"
+	case LORA_SPREADINGFACTOR_9:
+		return "LORA_SPREADINGFACTOR_9"
+	case LORA_SPREADINGFACTOR_10:
+		return "LORA_SPREADINGFACTOR_10"
+	case LORA_SPREADINGFACTOR_11:
+		return "LORA_SPREADINGFACTOR_11"
+	case LORA_SPREADINGFACTOR_12:
+		return "LORA_SPREADINGFACTOR_12"
+	default:
+		return "[?? Invalid LoRaSpreadingFactor value]"
+	}
+}
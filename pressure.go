@@ -0,0 +1,82 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sensors
+
+import (
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+type PressureTrendType uint
+
+// PressureForecast is a simple weather forecast derived from the
+// sea-level pressure and its trend, using the Zambretti forecaster
+type PressureForecast struct {
+	Timestamp   time.Time         `json:"ts"`
+	Station     float64           `json:"station"`  // Station pressure, hPa
+	Pressure    float64           `json:"pressure"` // Sea-level pressure, hPa
+	Change      float64           `json:"change"`   // Change over the trend period, hPa
+	Trend       PressureTrendType `json:"trend"`
+	Code        byte              `json:"code"` // Zambretti letter A to Z
+	Description string            `json:"description"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACES
+
+// PressureTrend is a virtual sensor which keeps a history of barometric
+// pressure readings and returns the trend and a simple forecast
+type PressureTrend interface {
+	gopi.Driver
+
+	// Add a pressure reading in hPa
+	Add(ts time.Time, pressure float64)
+
+	// Return the trend and the change in hPa over the trend period,
+	// or PRESSURE_TREND_NONE if there is not enough history
+	Trend() (PressureTrendType, float64)
+
+	// Return the forecast, or ErrInsufficientData if there is not
+	// enough history
+	Forecast() (PressureForecast, error)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	PRESSURE_TREND_NONE PressureTrendType = iota
+	PRESSURE_TREND_STEADY
+	PRESSURE_TREND_RISING
+	PRESSURE_TREND_FALLING
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (t PressureTrendType) String() string {
+	switch t {
+	case PRESSURE_TREND_NONE:
+		return "PRESSURE_TREND_NONE"
+	case PRESSURE_TREND_STEADY:
+		return "PRESSURE_TREND_STEADY"
+	case PRESSURE_TREND_RISING:
+		return "PRESSURE_TREND_RISING"
+	case PRESSURE_TREND_FALLING:
+		return "PRESSURE_TREND_FALLING"
+	default:
+		return "[?? Invalid PressureTrendType value]"
+	}
+}
@@ -0,0 +1,178 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package openthings
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// builder appends records to a message, and keeps the first error
+type builder struct {
+	protocol     *OpenThings
+	manufacturer sensors.OTManufacturer
+	product_id   uint8
+	sensor_id    uint32
+	records      []sensors.OTRecord
+	size         int
+	err          error
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Size of the header, sensor ID, zero byte and CRC in a payload
+	OT_MESSAGE_OVERHEAD = 5 + 3 + 1 + 2
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// NEW MESSAGE
+
+// NewMessage returns a builder for a message to a sensor. Records are
+// added with Append and the payload returned by Encode
+func (this *OpenThings) NewMessage(manufacturer sensors.OTManufacturer, product_id uint8, sensor_id uint32) sensors.OTMessageBuilder {
+	this.log.Debug("<protocol.openthings.NewMessage>{ manufacturer=%v product_id=0x%02X sensor_id=0x%06X }", manufacturer, product_id, sensor_id)
+
+	builder := &builder{
+		protocol:     this,
+		manufacturer: manufacturer,
+		product_id:   product_id,
+		sensor_id:    sensor_id,
+		size:         OT_MESSAGE_OVERHEAD,
+	}
+	if manufacturer.Known() == false {
+		this.log.Debug2("protocol.openthings.NewMessage: Invalid manufacturer code")
+		builder.err = gopi.ErrBadParameter
+	} else if sensor_id > OT_SENSOR_ID_MAX {
+		this.log.Debug2("protocol.openthings.NewMessage: Invalid sensor ID")
+		builder.err = gopi.ErrBadParameter
+	}
+	return builder
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BUILDER IMPLEMENTATION
+
+// Append a record. The value is a bool, an integer, a float, a string or
+// an OTRecord, which is encoded with the data type for the value. After
+// an error, further records are ignored and Encode returns the error
+func (this *builder) Append(name sensors.OTParameter, value interface{}) sensors.OTMessageBuilder {
+	return this.append(name, value, false)
+}
+
+// Command appends a record with the request bit set, which sets the
+// parameter to the value on the device. A nil value requests that the
+// device reports the parameter
+func (this *builder) Command(name sensors.OTParameter, value interface{}) sensors.OTMessageBuilder {
+	return this.append(name, value, true)
+}
+
+// Encode returns the encrypted payload
+func (this *builder) Encode() ([]byte, error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	return this.protocol.Encode(this.manufacturer, this.product_id, this.sensor_id, this.records)
+}
+
+// Records returns the records appended so far
+func (this *builder) Records() []sensors.OTRecord {
+	return this.records
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *builder) append(name sensors.OTParameter, value interface{}, request bool) sensors.OTMessageBuilder {
+	if this.err != nil {
+		return this
+	}
+
+	// Create the record
+	record, err := value_record(name, value)
+	if value == nil && request == false {
+		err = gopi.ErrBadParameter
+	} else if err == nil && request {
+		record, err = NewCommandRecord(record)
+	}
+
+	// Check the record and the message size
+	if err != nil {
+		this.protocol.log.Debug2("protocol.openthings.Append: Invalid value %v for %v", value, name)
+		this.err = err
+	} else if data, err := write_record(record); err != nil {
+		this.protocol.log.Debug2("protocol.openthings.Append: Invalid record %v", record)
+		this.err = err
+	} else if this.size+len(data) > OT_PAYLOAD_MAXSIZE+1 {
+		this.protocol.log.Debug2("protocol.openthings.Append: Message too long with %v", name)
+		this.err = gopi.ErrBadParameter
+	} else {
+		this.records = append(this.records, record)
+		this.size += len(data)
+	}
+
+	return this
+}
+
+// Return a record for a value, encoded with the data type for the value
+func value_record(name sensors.OTParameter, value interface{}) (sensors.OTRecord, error) {
+	switch value := value.(type) {
+	case nil:
+		return NewRecord(name, sensors.OT_DATATYPE_UDEC_0, nil)
+	case sensors.OTRecord:
+		if _, ok := value.(*ot_record); ok == false {
+			return nil, gopi.ErrBadParameter
+		}
+		return value, nil
+	case bool:
+		return NewUIntRecord(name, uint64(to_uint(value)))
+	case int:
+		return NewIntRecord(name, int64(value))
+	case int8:
+		return NewIntRecord(name, int64(value))
+	case int16:
+		return NewIntRecord(name, int64(value))
+	case int32:
+		return NewIntRecord(name, int64(value))
+	case int64:
+		return NewIntRecord(name, value)
+	case uint:
+		return NewUIntRecord(name, uint64(value))
+	case uint8:
+		return NewUIntRecord(name, uint64(value))
+	case uint16:
+		return NewUIntRecord(name, uint64(value))
+	case uint32:
+		return NewUIntRecord(name, uint64(value))
+	case uint64:
+		return NewUIntRecord(name, value)
+	case float32:
+		return NewFloatRecord(name, float64(value))
+	case float64:
+		return NewFloatRecord(name, value)
+	case string:
+		return NewRecord(name, sensors.OT_DATATYPE_STRING, []byte(value))
+	default:
+		return nil, gopi.ErrBadParameter
+	}
+}
+
+// Return 1 for true and 0 for false
+func to_uint(value bool) uint {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package openthings
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// test_logger discards log messages
+type test_logger struct {
+	gopi.Logger
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// FUZZ DECODE
+
+// FuzzDecode checks that Decode does not panic in any decode mode, and
+// that errors are typed as message corruption or a CRC error
+func FuzzDecode(f *testing.F) {
+	drivers := make([]*OpenThings, 0, OT_DECODE_MAX+1)
+	for mode := OT_DECODE_DEFAULT; mode <= OT_DECODE_MAX; mode++ {
+		if driver, err := (Config{Mode: mode, TrackReplay: true}).Open(test_logger{}); err != nil {
+			f.Fatal(err)
+		} else {
+			drivers = append(drivers, driver.(*OpenThings))
+		}
+	}
+
+	// Valid payloads, and the same payloads truncated
+	valid, err := drivers[0].NewMessage(sensors.OT_MANUFACTURER_ENERGENIE, 0x02, 0x123456).
+		Append(sensors.OT_PARAM_TEMPERATURE, 21.5).
+		Append(sensors.OT_PARAM_SWITCH_STATE, true).
+		Command(sensors.OT_PARAM_VOLTAGE, nil).
+		Encode()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(valid)
+	for _, size := range []int{1, 5, OT_PAYLOAD_MINSIZE, len(valid) - 3, len(valid) - 1} {
+		f.Add(valid[:size])
+	}
+
+	// Records with lengths which overrun the message, and a zero length
+	// record, with a valid CRC
+	f.Add(test_payload(drivers[0], []byte{0x74, 0x9F, 0x15}))
+	f.Add(test_payload(drivers[0], []byte{0x74, 0x92, 0x15, 0x80, 0x76, 0x0F}))
+	f.Add(test_payload(drivers[0], []byte{0xF6, 0x00, 0x73}))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		for _, driver := range drivers {
+			message, err := driver.Decode(payload)
+			if err != nil && errors.Is(err, sensors.ErrMessageCorruption) == false && errors.Is(err, sensors.ErrMessageCRC) == false {
+				t.Fatalf("mode=%v: untyped error: %v", driver.mode, err)
+			}
+			if message == nil {
+				t.Fatalf("mode=%v: nil message", driver.mode)
+			}
+			for _, record := range message.Records() {
+				record.StringValue()
+				record.Raw()
+			}
+			_ = message.(*Message).String()
+			_ = message.(*Message).DumpHex()
+		}
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// test_payload returns an encrypted payload for sensor 0x123456 with the
+// record bytes, a zero byte and a valid CRC
+func test_payload(driver *OpenThings, records []byte) []byte {
+	message := append([]byte{0x12, 0x34, 0x56}, records...)
+	message = append(message, 0x00, 0x00, 0x00)
+	binary.BigEndian.PutUint16(message[len(message)-2:], compute_crc(message[:len(message)-2]))
+	payload := []byte{uint8(len(message) + 4), uint8(sensors.OT_MANUFACTURER_ENERGENIE), 0x02, 0x12, 0x34}
+	return append(payload, driver.encrypt_message(message, 0x1234)...)
+}
+
+func (test_logger) Error(format string, v ...interface{}) error { return nil }
+func (test_logger) Warn(format string, v ...interface{})        {}
+func (test_logger) Info(format string, v ...interface{})        {}
+func (test_logger) Debug(format string, v ...interface{})       {}
+func (test_logger) Debug2(format string, v ...interface{})      {}
+func (test_logger) IsDebug() bool                               { return false }
@@ -0,0 +1,118 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package openthings
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// HEX DUMP
+
+// DumpHex returns the payload with one line for each field, which is the
+// offset, the bytes and a description of the field. The message after the
+// PIP is shown decrypted. A payload which could not be decrypted is shown
+// without fields
+func (this *Message) DumpHex() string {
+	buf := new(strings.Builder)
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	line := func(offset int, data []byte, format string, args ...interface{}) {
+		fmt.Fprintf(w, "%04X\t%v\t%v\n", offset, hex_string(data), fmt.Sprintf(format, args...))
+	}
+
+	payload := this.payload
+	if len(this.decrypted) == 0 {
+		line(0, payload, "payload, not decrypted")
+		w.Flush()
+		return buf.String()
+	}
+
+	// Header
+	line(0, payload[0:1], "size=%v", payload[0])
+	line(1, payload[1:2], "manufacturer=%v", sensors.OTManufacturer(payload[1]))
+	line(2, payload[2:3], "product_id=0x%02X", payload[2])
+	line(3, payload[3:5], "pip=0x%04X", binary.BigEndian.Uint16(payload[3:]))
+
+	// Sensor ID and records. A truncated payload has no zero byte or CRC
+	offset, data := 5, this.decrypted
+	line(offset, data[0:3], "sensor_id=0x%06X", this.sensor_id)
+	truncated := int(payload[0]) > len(payload)-1
+	records := data[3:]
+	if truncated == false {
+		records = data[3 : len(data)-2]
+	}
+	offset, pos := offset+3, 0
+	values, _ := read_records(records, OT_DECODE_DEFAULT)
+	for _, record := range values {
+		raw := record.Raw()
+		fmt.Fprintf(w, "%04X\t%v\t%v\n", offset+pos, strings.Join([]string{hex_string(raw[0:1]), hex_string(raw[1:2]), hex_string(raw[2:])}, " "), dump_record(record.(*ot_record)))
+		pos += len(raw)
+	}
+	if pos < len(records) && records[pos] == 0x00 {
+		line(offset+pos, records[pos:pos+1], "end of records")
+		pos++
+	}
+	if pos < len(records) {
+		line(offset+pos, records[pos:], "not decoded")
+	}
+	offset += len(records)
+
+	// CRC and any bytes after the size given in the header
+	if truncated {
+		line(offset, nil, "truncated, expected %v bytes", int(payload[0])+1)
+	} else if crc_error, ok := this.crc_error.(*ErrCRCMismatch); ok {
+		line(offset, data[len(data)-2:], "crc=0x%04X expected=0x%04X", this.crc, crc_error.Expected)
+		offset += 2
+	} else {
+		line(offset, data[len(data)-2:], "crc=0x%04X", this.crc)
+		offset += 2
+	}
+	if offset < len(payload) && truncated == false {
+		line(offset, payload[offset:], "trailing bytes")
+	}
+
+	w.Flush()
+	return buf.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// dump_record returns a description of the fields of a record
+func dump_record(record *ot_record) string {
+	params := []string{
+		fmt.Sprint(record.name),
+		fmt.Sprintf("request=%v", record.request),
+		fmt.Sprintf("type=%v", record.datatype),
+		fmt.Sprintf("size=%v", record.datasize),
+	}
+	if len(record.data) != int(record.datasize) {
+		params = append(params, "truncated")
+	} else if record.datasize == 0 {
+		// Request has no value
+	} else if value, err := record.StringValue(); err != nil {
+		params = append(params, fmt.Sprintf("err=%v", err))
+	} else {
+		params = append(params, fmt.Sprintf("value=%v", value))
+	}
+	return strings.Join(params, " ")
+}
+
+// hex_string returns bytes as upper case hexadecimal
+func hex_string(data []byte) string {
+	return strings.ToUpper(hex.EncodeToString(data))
+}
@@ -0,0 +1,293 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package openthings
+
+import (
+	"encoding/binary"
+	"math"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// fixed_type is a fixed-point data type and the number of bits after the
+// binary point
+type fixed_type struct {
+	datatype sensors.OTDataType
+	bits     uint
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	OT_PAYLOAD_MAXSIZE = 0xFF // Maximum size of a payload after the size byte
+	OT_RECORD_MAXSIZE  = 0x0F // Maximum number of data bytes in a record
+	OT_SENSOR_ID_MAX   = 0xFFFFFF
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Fixed-point data types in order of the number of bits after the
+	// binary point
+	udec_types = []fixed_type{
+		{sensors.OT_DATATYPE_UDEC_0, 0}, {sensors.OT_DATATYPE_UDEC_4, 4}, {sensors.OT_DATATYPE_UDEC_8, 8},
+		{sensors.OT_DATATYPE_UDEC_12, 12}, {sensors.OT_DATATYPE_UDEC_16, 16}, {sensors.OT_DATATYPE_UDEC_20, 20},
+		{sensors.OT_DATATYPE_UDEC_24, 24},
+	}
+	dec_types = []fixed_type{
+		{sensors.OT_DATATYPE_DEC_0, 0}, {sensors.OT_DATATYPE_DEC_8, 8}, {sensors.OT_DATATYPE_DEC_16, 16},
+		{sensors.OT_DATATYPE_DEC_24, 24},
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// ENCRYPT
+
+// Encode returns the payload for a message with the records, which must
+// have been created with the New...Record functions or decoded. The
+// message is encrypted with a random PIP, as Decode expects
+func (this *OpenThings) Encode(manufacturer sensors.OTManufacturer, product_id uint8, sensor_id uint32, records []sensors.OTRecord) ([]byte, error) {
+	this.log.Debug("<protocol.openthings.Encode>{ manufacturer=%v product_id=0x%02X sensor_id=0x%06X records=%v }", manufacturer, product_id, sensor_id, records)
+
+	// Check parameters
+	if manufacturer.Known() == false {
+		this.log.Debug2("protocol.openthings.Encode: Invalid manufacturer code")
+		return nil, gopi.ErrBadParameter
+	}
+	if sensor_id > OT_SENSOR_ID_MAX {
+		this.log.Debug2("protocol.openthings.Encode: Invalid sensor ID")
+		return nil, gopi.ErrBadParameter
+	}
+
+	// Message is the sensor ID, the records, a zero byte and the CRC
+	message := make([]byte, 3, OT_PAYLOAD_MAXSIZE)
+	message[0], message[1], message[2] = uint8(sensor_id>>16), uint8(sensor_id>>8), uint8(sensor_id)
+	for _, record := range records {
+		if data, err := write_record(record); err != nil {
+			this.log.Debug2("protocol.openthings.Encode: Invalid record %v", record)
+			return nil, err
+		} else {
+			message = append(message, data...)
+		}
+	}
+	message = append(message, 0x00, 0x00, 0x00)
+	binary.BigEndian.PutUint16(message[len(message)-2:], compute_crc(message[0:len(message)-2]))
+
+	// Check the message fits in the payload
+	if len(message)+4 > OT_PAYLOAD_MAXSIZE {
+		this.log.Debug2("protocol.openthings.Encode: Message too long")
+		return nil, gopi.ErrBadParameter
+	}
+
+	// Header is the size, manufacturer, product and PIP
+	pip := this.nextPIP()
+	payload := make([]byte, 5, len(message)+5)
+	payload[0] = uint8(len(message) + 4)
+	payload[1] = uint8(manufacturer)
+	payload[2] = product_id
+	binary.BigEndian.PutUint16(payload[3:], pip)
+
+	// Success
+	return append(payload, this.encrypt_message(message, pip)...), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// NEW RECORDS
+
+// NewRecord returns a record with data already encoded for the data type
+func NewRecord(name sensors.OTParameter, datatype sensors.OTDataType, data []byte) (sensors.OTRecord, error) {
+	if name == sensors.OT_PARAM_NONE || uint8(name) > 0x7F || uint8(datatype) > 0x0F {
+		return nil, gopi.ErrBadParameter
+	} else if len(data) > OT_RECORD_MAXSIZE {
+		return nil, gopi.ErrBadParameter
+	} else {
+		return &ot_record{
+			name:     name,
+			datatype: datatype,
+			datasize: uint8(len(data)),
+			data:     append([]byte(nil), data...),
+		}, nil
+	}
+}
+
+// NewUIntRecord returns an OT_DATATYPE_UDEC_0 record in the fewest bytes
+func NewUIntRecord(name sensors.OTParameter, value uint64) (sensors.OTRecord, error) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, value)
+	switch {
+	case value <= 0xFF:
+		data = data[7:]
+	case value <= 0xFFFF:
+		data = data[6:]
+	case value <= 0xFFFFFFFF:
+		data = data[4:]
+	}
+	return NewRecord(name, sensors.OT_DATATYPE_UDEC_0, data)
+}
+
+// NewIntRecord returns an OT_DATATYPE_DEC_0 record in the fewest bytes
+func NewIntRecord(name sensors.OTParameter, value int64) (sensors.OTRecord, error) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, uint64(value))
+	switch {
+	case value >= -0x80 && value <= 0x7F:
+		data = data[7:]
+	case value >= -0x8000 && value <= 0x7FFF:
+		data = data[6:]
+	case value >= -0x80000000 && value <= 0x7FFFFFFF:
+		data = data[4:]
+	}
+	return NewRecord(name, sensors.OT_DATATYPE_DEC_0, data)
+}
+
+// NewCommandRecord returns a copy of a record with the request bit set,
+// which is a command to set the parameter, or a request to report the
+// parameter when the record has no data
+func NewCommandRecord(record sensors.OTRecord) (sensors.OTRecord, error) {
+	if record_, ok := record.(*ot_record); ok == false {
+		return nil, gopi.ErrBadParameter
+	} else {
+		command := *record_
+		command.request = true
+		return &command, nil
+	}
+}
+
+// NewRequestRecord returns a record with no data and the request bit set,
+// for a device to report the parameter
+func NewRequestRecord(name sensors.OTParameter) (sensors.OTRecord, error) {
+	if record, err := NewRecord(name, sensors.OT_DATATYPE_UDEC_0, nil); err != nil {
+		return nil, err
+	} else {
+		return NewCommandRecord(record)
+	}
+}
+
+// NewFloatRecord returns a fixed-point record in the fewest bytes which
+// represent the value exactly, using the fewest bits after the binary
+// point when sizes are equal. A value which has no exact representation
+// is encoded with the most bits after the binary point. Negative values
+// use the signed types
+func NewFloatRecord(name sensors.OTParameter, value float64) (sensors.OTRecord, error) {
+	return new_fixed_record(name, value, 0)
+}
+
+// NewFloatRecordPrecision returns a fixed-point record with the fewest
+// bits after the binary point for a resolution at least as fine as the
+// precision, such as 0.1 or 0.01
+func NewFloatRecordPrecision(name sensors.OTParameter, value, precision float64) (sensors.OTRecord, error) {
+	if precision <= 0 || math.IsNaN(precision) {
+		return nil, gopi.ErrBadParameter
+	}
+	return new_fixed_record(name, value, precision)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// new_fixed_record returns the fixed-point record for a value. With a
+// zero precision the smallest lossless encoding is chosen
+func new_fixed_record(name sensors.OTParameter, value, precision float64) (sensors.OTRecord, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, gopi.ErrBadParameter
+	}
+
+	// Unsigned types are used unless the value is negative
+	types, signed := udec_types, false
+	if value < 0 {
+		types, signed = dec_types, true
+	}
+
+	var datatype sensors.OTDataType
+	var data []byte
+	for i, t := range types {
+		last := i == len(types)-1
+		scaled := math.Ldexp(value, int(t.bits))
+		fixed := math.Round(scaled)
+		if precision > 0 && math.Ldexp(1, -int(t.bits)) > precision && last == false {
+			// Resolution is not fine enough
+			continue
+		} else if precision == 0 && fixed != scaled && (last == false || data != nil) {
+			// Value is not exact, and is only encoded with the last type
+			// when there is no exact encoding
+			continue
+		} else if encoded, exists := fixed_bytes(fixed, signed); exists == false {
+			// Value does not fit in eight bytes
+			continue
+		} else if data == nil || len(encoded) < len(data) {
+			datatype, data = t.datatype, encoded
+		}
+		if precision > 0 {
+			break
+		}
+	}
+
+	// Return the record
+	if data == nil {
+		return nil, gopi.ErrBadParameter
+	}
+	return NewRecord(name, datatype, data)
+}
+
+// fixed_bytes returns the fewest big-endian bytes for an integer value,
+// or false if the value does not fit in eight bytes
+func fixed_bytes(value float64, signed bool) ([]byte, bool) {
+	data := make([]byte, 8)
+	if signed {
+		if value < -(1<<63) || value >= 1<<63 {
+			return nil, false
+		}
+		binary.BigEndian.PutUint64(data, uint64(int64(value)))
+		// Remove bytes which only extend the sign
+		for len(data) > 1 && ((data[0] == 0x00 && data[1]&0x80 == 0) || (data[0] == 0xFF && data[1]&0x80 != 0)) {
+			data = data[1:]
+		}
+	} else {
+		if value < 0 || value >= 1<<64 {
+			return nil, false
+		}
+		binary.BigEndian.PutUint64(data, uint64(value))
+		for len(data) > 1 && data[0] == 0x00 {
+			data = data[1:]
+		}
+	}
+	return data, true
+}
+
+// Function to encrypt an outgoing message, which uses the same cipher
+// as decryption
+func (this *OpenThings) encrypt_message(buf []byte, pip uint16) []byte {
+	return this.decrypt_message(buf, pip)
+}
+
+// Return a random PIP for the next message
+func (this *OpenThings) nextPIP() uint16 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return uint16(this.random.Intn(0x10000))
+}
+
+// Return the parameter, type and length bytes and the data of a record
+func write_record(record sensors.OTRecord) ([]byte, error) {
+	if record_, ok := record.(*ot_record); ok == false {
+		return nil, gopi.ErrBadParameter
+	} else if int(record_.datasize) != len(record_.data) || record_.datasize > OT_RECORD_MAXSIZE {
+		return nil, gopi.ErrBadParameter
+	} else {
+		return record_.Raw(), nil
+	}
+}
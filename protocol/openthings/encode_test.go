@@ -0,0 +1,84 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package openthings
+
+import (
+	"math"
+	"testing"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// FIXED POINT ENCODING
+
+func TestFloatRecord(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     float64
+		precision float64 // Zero for NewFloatRecord
+		datatype  sensors.OTDataType
+		size      int
+		tolerance float64 // Maximum difference after the round trip
+	}{
+		{"zero", 0, 0, sensors.OT_DATATYPE_UDEC_0, 1, 0},
+		{"integer", 240, 0, sensors.OT_DATATYPE_UDEC_0, 1, 0},
+		{"half", 21.5, 0, sensors.OT_DATATYPE_UDEC_4, 2, 0},
+		{"negative half", -21.5, 0, sensors.OT_DATATYPE_DEC_8, 2, 0},
+		{"negative integer", -300, 0, sensors.OT_DATATYPE_DEC_0, 2, 0},
+		{"precision", 0.1, 0.01, sensors.OT_DATATYPE_UDEC_8, 1, 0.01},
+		{"negative precision", -21.37, 0.01, sensors.OT_DATATYPE_DEC_8, 2, 0.01},
+		{"inexact", 0.1, 0, sensors.OT_DATATYPE_UDEC_24, 3, math.Ldexp(1, -24)},
+		{"largest UDEC_0", math.Ldexp(1, 64) - 2048, 0, sensors.OT_DATATYPE_UDEC_0, 8, 0},
+		{"largest DEC_0", math.MinInt64, 0, sensors.OT_DATATYPE_DEC_0, 8, 0},
+	}
+	for _, test := range tests {
+		var record sensors.OTRecord
+		var err error
+		if test.precision == 0 {
+			record, err = NewFloatRecord(sensors.OT_PARAM_TEMPERATURE, test.value)
+		} else {
+			record, err = NewFloatRecordPrecision(sensors.OT_PARAM_TEMPERATURE, test.value, test.precision)
+		}
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		record_ := record.(*ot_record)
+		if record_.Type() != test.datatype {
+			t.Errorf("%v: expected type %v, got %v", test.name, test.datatype, record_.Type())
+		}
+		if len(record_.data) != test.size || int(record_.Size()) != test.size {
+			t.Errorf("%v: expected %v bytes, got %v", test.name, test.size, len(record_.data))
+		}
+		if value, err := record_.FloatValue(); err != nil {
+			t.Errorf("%v: FloatValue: %v", test.name, err)
+		} else if math.Abs(value-test.value) > test.tolerance {
+			t.Errorf("%v: expected %v, got %v", test.name, test.value, value)
+		}
+	}
+}
+
+func TestFloatRecordBadParameter(t *testing.T) {
+	for _, value := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), math.Ldexp(1, 64), -math.Ldexp(1, 63) - 4096} {
+		if _, err := NewFloatRecord(sensors.OT_PARAM_TEMPERATURE, value); err == nil {
+			t.Errorf("%v: expected error", value)
+		}
+		if _, err := NewFloatRecordPrecision(sensors.OT_PARAM_TEMPERATURE, value, 0.1); err == nil {
+			t.Errorf("%v: expected error with precision", value)
+		}
+	}
+	for _, precision := range []float64{0, -0.1, math.NaN()} {
+		if _, err := NewFloatRecordPrecision(sensors.OT_PARAM_TEMPERATURE, 21.5, precision); err == nil {
+			t.Errorf("precision %v: expected error", precision)
+		}
+	}
+}
@@ -25,17 +25,34 @@ func init() {
 		Type: gopi.MODULE_TYPE_OTHER,
 		Config: func(config *gopi.AppConfig) {
 			config.AppFlags.FlagUint("ot.encryption_id", 0, "OpenThings Encryption ID")
-			config.AppFlags.FlagBool("ot.ignore_crc", false, "Ignore CRC checking")
+			config.AppFlags.FlagBool("ot.ignore_crc", false, "Accept messages with a bad CRC, for debugging")
+			config.AppFlags.FlagString("ot.decode", "", "Decode mode (strict, lenient)")
+			config.AppFlags.FlagBool("ot.replay", false, "Flag messages which repeat a recent message from a sensor")
 		},
 		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
 			ignore_crc, _ := app.AppFlags.GetBool("ot.ignore_crc")
 			encryption_id, _ := app.AppFlags.GetUint("ot.encryption_id")
+			decode, _ := app.AppFlags.GetString("ot.decode")
+			replay, _ := app.AppFlags.GetBool("ot.replay")
 			if encryption_id > 0xFF {
 				return nil, errors.New("Invalid -ot.encryption_id flag")
 			}
+			mode := OT_DECODE_DEFAULT
+			switch decode {
+			case "":
+				break
+			case "strict":
+				mode = OT_DECODE_STRICT
+			case "lenient":
+				mode = OT_DECODE_LENIENT
+			default:
+				return nil, errors.New("Invalid -ot.decode flag")
+			}
 			return gopi.Open(Config{
 				EncryptionID: uint8(encryption_id),
 				IgnoreCRC:    ignore_crc,
+				Mode:         mode,
+				TrackReplay:  replay,
 			}, app.Logger)
 		},
 	})
@@ -0,0 +1,175 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package openthings
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// message_json is the JSON representation of a message. The sensor ID,
+// CRC and payload are hexadecimal strings
+type message_json struct {
+	Manufacturer sensors.OTManufacturer `json:"manufacturer"`
+	ProductID    uint8                  `json:"product_id"`
+	SensorID     string                 `json:"sensor_id"`
+	CRC          string                 `json:"crc"`
+	Payload      string                 `json:"payload"`
+	Records      []*ot_record           `json:"records"`
+}
+
+// record_json is the JSON representation of a record. The data is a
+// hexadecimal string, and the value is a number, boolean or string
+// depending on the parameter and data type, or omitted if it can't be
+// decoded. The name and unit are omitted for unknown parameters
+type record_json struct {
+	Parameter sensors.OTParameter `json:"parameter"`
+	Name      string              `json:"name,omitempty"`
+	Request   bool                `json:"request,omitempty"`
+	Type      sensors.OTDataType  `json:"type"`
+	Data      string              `json:"data"`
+	Value     interface{}         `json:"value,omitempty"`
+	Unit      string              `json:"unit,omitempty"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// MESSAGE
+
+// MarshalJSON returns the message as a JSON object:
+//
+//	{
+//	  "manufacturer": 4,
+//	  "product_id": 2,
+//	  "sensor_id": "0012AB",
+//	  "crc": "7BAA",
+//	  "payload": "0D04020E53...",
+//	  "records": [
+//	    { "parameter": 116, "name": "Temperature", "type": 9, "data": "1580", "value": 21.5, "unit": "°C" }
+//	  ]
+//	}
+func (this *Message) MarshalJSON() ([]byte, error) {
+	records := make([]*ot_record, 0, len(this.records))
+	for _, record := range this.records {
+		if record_, ok := record.(*ot_record); ok {
+			records = append(records, record_)
+		}
+	}
+	return json.Marshal(message_json{
+		Manufacturer: this.Manufacturer(),
+		ProductID:    this.ProductID(),
+		SensorID:     fmt.Sprintf("%06X", this.sensor_id),
+		CRC:          fmt.Sprintf("%04X", this.crc),
+		Payload:      strings.ToUpper(hex.EncodeToString(this.payload)),
+		Records:      records,
+	})
+}
+
+// UnmarshalJSON sets the message from the JSON object returned by
+// MarshalJSON. The manufacturer and product ID are read from the payload
+func (this *Message) UnmarshalJSON(data []byte) error {
+	var value message_json
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if sensor_id, err := strconv.ParseUint(value.SensorID, 16, 24); err != nil {
+		return err
+	} else if crc, err := strconv.ParseUint(value.CRC, 16, 16); err != nil {
+		return err
+	} else if payload, err := hex.DecodeString(value.Payload); err != nil {
+		return err
+	} else {
+		this.sensor_id = uint32(sensor_id)
+		this.crc = uint16(crc)
+		this.payload = payload
+		this.records = make([]sensors.OTRecord, len(value.Records))
+		for i, record := range value.Records {
+			this.records[i] = record
+		}
+	}
+
+	// Success
+	return nil
+}
+
+// MarshalText returns the message as a string
+func (this *Message) MarshalText() ([]byte, error) {
+	return []byte(this.String()), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// RECORD
+
+// MarshalJSON returns the record as a JSON object, as described for
+// the message
+func (this *ot_record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(record_json{
+		Parameter: this.name,
+		Name:      this.name.Name(),
+		Request:   this.request,
+		Type:      this.datatype,
+		Data:      strings.ToUpper(hex.EncodeToString(this.data)),
+		Value:     this.jsonValue(),
+		Unit:      this.name.Unit(),
+	})
+}
+
+// UnmarshalJSON sets the record from the parameter, request flag, type
+// and data. The name, value and unit are ignored
+func (this *ot_record) UnmarshalJSON(data []byte) error {
+	var value record_json
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	} else if record_data, err := hex.DecodeString(value.Data); err != nil {
+		return err
+	} else if len(record_data) > OT_RECORD_MAXSIZE {
+		return sensors.ErrMessageCorruption
+	} else {
+		this.name = value.Parameter
+		this.request = value.Request
+		this.datatype = value.Type
+		this.datasize = uint8(len(record_data))
+		this.data = record_data
+	}
+
+	// Success
+	return nil
+}
+
+// MarshalText returns the record as a string
+func (this *ot_record) MarshalText() ([]byte, error) {
+	return []byte(this.String()), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// jsonValue returns the value of the record as a boolean for detectors,
+// alarms and switches, a number for other numeric types or a string,
+// or nil if the value can't be decoded
+func (this *ot_record) jsonValue() interface{} {
+	if value, err := this.BoolValue(); err == nil {
+		return value
+	} else if value, err := this.FloatValue(); err == nil {
+		return value
+	} else if value, err := this.StringValue(); err == nil {
+		return value
+	} else {
+		return nil
+	}
+}
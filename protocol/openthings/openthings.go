@@ -13,7 +13,10 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"strings"
+	"sync"
+	"time"
 
 	// Frameworks
 	"github.com/djthorpe/gopi"
@@ -25,20 +28,62 @@ import (
 
 type Config struct {
 	EncryptionID uint8
-	IgnoreCRC    bool
+	IgnoreCRC    bool       // Accept messages with a bad CRC, which are flagged by CRCError
+	Mode         DecodeMode // Decode mode, which is strict, lenient or the default
+	TrackReplay  bool       // Flag messages which repeat a recent message from a sensor
 }
 
 type OpenThings struct {
 	log           gopi.Logger
 	encryption_id uint8
 	ignore_crc    bool
+	mode          DecodeMode
+	random        *rand.Rand
+	replay        map[replay_key]*replay_entry
+	replay_seen   uint64
+	lock          sync.Mutex
 }
 
 type Message struct {
 	payload   []byte
+	decrypted []byte
 	sensor_id uint32
 	crc       uint16
+	crc_error error
 	records   []sensors.OTRecord
+	replayed  bool
+}
+
+// replay_key identifies a sensor for replay tracking
+type replay_key struct {
+	manufacturer sensors.OTManufacturer
+	product_id   uint8
+	sensor_id    uint32
+}
+
+// replay_entry is the recent messages from a sensor, and when the sensor
+// was last seen, which is used to evict the least recently seen sensor
+type replay_entry struct {
+	history []uint32
+	seen    uint64
+}
+
+// ErrDecode is returned by Decode when a payload is corrupt or not
+// conformant, with the offset of the field in the payload
+type ErrDecode struct {
+	Offset int
+	Reason string
+}
+
+// DecodeMode determines how non-conformant and corrupted messages are
+// decoded
+type DecodeMode uint
+
+// ErrCRCMismatch is returned by Decode when the CRC received does not
+// match the CRC computed from the message
+type ErrCRCMismatch struct {
+	Expected uint16
+	Actual   uint16
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -48,6 +93,19 @@ const (
 	OT_ENCRYPTION_ID   = 0xF2 // Default encryption ID
 	OT_PAYLOAD_MINSIZE = 11   // Minimum size of a payload
 	OT_MESSAGE_MINSIZE = 7    // Minimum size of a decypted message
+	OT_REPLAY_HISTORY  = 16   // Number of recent messages kept for each sensor
+	OT_REPLAY_SENSORS  = 256  // Number of sensors tracked for replayed messages
+	OT_RECORDS_OFFSET  = 8    // Offset of the records in a payload
+)
+
+const (
+	// Reject corrupted messages, and accept a truncated last record
+	OT_DECODE_DEFAULT DecodeMode = iota
+	// Reject corrupted messages and any non-conformant records
+	OT_DECODE_STRICT
+	// Return the records recovered from a corrupted message with the error
+	OT_DECODE_LENIENT
+	OT_DECODE_MAX = OT_DECODE_LENIENT
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -57,14 +115,22 @@ func (config Config) Open(log gopi.Logger) (gopi.Driver, error) {
 	this := new(OpenThings)
 	this.log = log
 	this.ignore_crc = config.IgnoreCRC
+	this.mode = config.Mode
+	this.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	if config.TrackReplay {
+		this.replay = make(map[replay_key]*replay_entry, OT_REPLAY_SENSORS)
+	}
 
+	if config.Mode > OT_DECODE_MAX {
+		return nil, gopi.ErrBadParameter
+	}
 	if config.EncryptionID != 0 {
 		this.encryption_id = config.EncryptionID
 	} else {
 		this.encryption_id = OT_ENCRYPTION_ID
 	}
 
-	log.Debug("<protocol.openthings.Open>{ EncryptionID=0x%02X IgnoreCRC=%v }", this.encryption_id, config.IgnoreCRC)
+	log.Debug("<protocol.openthings.Open>{ EncryptionID=0x%02X IgnoreCRC=%v Mode=%v TrackReplay=%v }", this.encryption_id, config.IgnoreCRC, config.Mode, config.TrackReplay)
 
 	// Return success
 	return this, nil
@@ -82,6 +148,8 @@ func (this *OpenThings) Close() error {
 ////////////////////////////////////////////////////////////////////////////////
 // DECRYPT
 
+// Decode a payload. In lenient mode the records which can be recovered
+// from a corrupted message are returned with the message and the error
 func (this *OpenThings) Decode(payload []byte) (sensors.OTMessage, error) {
 	this.log.Debug("<protocol.openthings.Decode>{ payload=%v }", strings.ToUpper(hex.EncodeToString(payload)))
 
@@ -91,54 +159,83 @@ func (this *OpenThings) Decode(payload []byte) (sensors.OTMessage, error) {
 	// Check minimum message size
 	if len(message.payload) < OT_PAYLOAD_MINSIZE {
 		this.log.Debug2("protocol.openthings.Decode: Payload size too short")
-		return message, sensors.ErrMessageCorruption
+		return message, &ErrDecode{len(payload), "payload too short"}
 	}
-	// Check size byte vs size of message
-	if int(message.payload[0]) != len(payload)-1 {
+	// Check size byte vs size of message. A lenient decode recovers records
+	// from a truncated payload, or ignores trailing bytes
+	truncated := false
+	if size := int(message.payload[0]); size != len(payload)-1 {
 		this.log.Debug2("protocol.openthings.Decode: Size byte mismatch")
-		return message, sensors.ErrMessageCorruption
+		if this.mode != OT_DECODE_LENIENT || size < OT_PAYLOAD_MINSIZE-1 {
+			return message, &ErrDecode{0, "size byte mismatch"}
+		} else if size < len(payload)-1 {
+			payload = payload[:size+1]
+		} else {
+			truncated = true
+		}
 	}
 	// Check manufacturer is known
 	if message.Manufacturer() == sensors.OT_MANUFACTURER_NONE {
 		this.log.Debug2("protocol.openthings.Decode: Invalid manufacturer code")
-		return message, sensors.ErrMessageCorruption
+		return message, &ErrDecode{1, "unknown manufacturer"}
 	}
 
-	// Decrypt packet, sanity check to make sure the payload is at least 7 bytes
+	// Decrypt packet, sanity check to make sure the payload is at least 7 bytes.
+	// The payload is left encrypted, so that it can be decoded again by
+	// another decoder or replayed
 	decrypted := this.decrypt_message(payload[5:], binary.BigEndian.Uint16(payload[3:]))
 	if len(decrypted) < OT_MESSAGE_MINSIZE {
 		this.log.Debug2("protocol.openthings.Decode: Message size too short")
-		return message, sensors.ErrMessageCorruption
+		return message, &ErrDecode{5, "message too short"}
+	} else {
+		message.decrypted = decrypted
 	}
 
 	// Set the sensor ID
 	message.sensor_id = binary.BigEndian.Uint32(decrypted[0:]) & 0xFFFFFF00 >> 8
 
+	// A truncated payload has no zero byte or CRC
+	if truncated {
+		return this.salvage(message, decrypted[3:], &ErrDecode{len(payload), "payload truncated"})
+	}
+
 	// Set the CRC value
 	message.crc = binary.BigEndian.Uint16(decrypted[len(decrypted)-2:])
 
 	// Check the zero-byte before the CRC value
 	if decrypted[len(decrypted)-3] != 0x00 {
 		this.log.Debug2("protocol.openthings.Decode: Missing zero byte before CRC")
-		return message, sensors.ErrMessageCorruption
+		return this.salvage(message, decrypted[3:len(decrypted)-2], &ErrDecode{len(payload) - 3, "missing zero byte"})
 	}
 
-	// Check CRC
-	if this.ignore_crc == false {
-		expected_crc := compute_crc(decrypted[0 : len(decrypted)-2])
-		if expected_crc != message.crc {
-			this.log.Debug2("protocol.openthings.Decode: CRC mismatch")
-			return message, sensors.ErrMessageCRC
+	// Check CRC, and flag the message when a bad CRC is ignored
+	if expected_crc := compute_crc(decrypted[0 : len(decrypted)-2]); expected_crc != message.crc {
+		this.log.Debug2("protocol.openthings.Decode: CRC mismatch, expected=0x%04X actual=0x%04X", expected_crc, message.crc)
+		message.crc_error = &ErrCRCMismatch{Expected: expected_crc, Actual: message.crc}
+		if this.ignore_crc == false {
+			return this.salvage(message, decrypted[3:len(decrypted)-2], message.crc_error)
 		}
 	}
 
-	// Read Records
-	if records, err := read_records(decrypted[3 : len(decrypted)-2]); err != nil {
+	// Read Records, which are interpreted by the product decoder for
+	// the manufacturer if there is one
+	if records, err := read_records(decrypted[3:len(decrypted)-2], this.mode); err != nil {
+		this.log.Debug2("protocol.openthings.Decode: Invalid records")
+		return this.salvage(message, decrypted[3:len(decrypted)-2], err)
+	} else if decoder := message.Manufacturer().ProductDecoder(); decoder == nil {
+		message.records = records
+	} else if records, err := decoder(message.ProductID(), records); err != nil {
+		this.log.Debug2("protocol.openthings.Decode: Product decoder: %v", err)
 		return message, err
 	} else {
 		message.records = records
 	}
 
+	// Flag a message with the same PIP and CRC as a recent message
+	if this.replay != nil && message.crc_error == nil {
+		message.replayed = this.replayed(message, binary.BigEndian.Uint16(payload[3:]))
+	}
+
 	// Success
 	return message, nil
 }
@@ -161,7 +258,7 @@ func (this *Message) Size() uint8 {
 func (this *Message) Manufacturer() sensors.OTManufacturer {
 	if len(this.payload) >= 2 {
 		m := sensors.OTManufacturer(this.payload[1])
-		if m <= sensors.OT_MANUFACTURER_MAX {
+		if m.Known() {
 			return m
 		}
 	}
@@ -188,6 +285,78 @@ func (this *Message) Records() []sensors.OTRecord {
 	return this.records
 }
 
+// Replayed returns true if the message has the same PIP and CRC as one
+// of the recent messages from the sensor, which is a repeated or replayed
+// transmission
+func (this *Message) Replayed() bool {
+	return this.replayed
+}
+
+// CRCError returns an ErrCRCMismatch error if the message was decoded
+// with a bad CRC, or nil otherwise
+func (this *Message) CRCError() error {
+	return this.crc_error
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// salvage sets the records which can be read from a corrupted message
+// in lenient mode, and returns the message with the error
+func (this *OpenThings) salvage(message *Message, data []byte, err error) (sensors.OTMessage, error) {
+	if this.mode == OT_DECODE_LENIENT {
+		message.records, _ = read_records(data, this.mode)
+		this.log.Debug2("protocol.openthings.Decode: Recovered %v records", len(message.records))
+	}
+	return message, err
+}
+
+// replayed returns true if a message was in the recent messages from the
+// sensor, and otherwise adds it to the recent messages. When a new sensor
+// is seen and OT_REPLAY_SENSORS are already tracked, the least recently
+// seen sensor is no longer tracked
+func (this *OpenThings) replayed(message *Message, pip uint16) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	key := replay_key{message.Manufacturer(), message.ProductID(), message.sensor_id}
+	value := uint32(pip)<<16 | uint32(message.crc)
+	entry, exists := this.replay[key]
+	if exists == false {
+		if len(this.replay) >= OT_REPLAY_SENSORS {
+			this.evictReplay()
+		}
+		entry = &replay_entry{history: make([]uint32, 0, OT_REPLAY_HISTORY)}
+		this.replay[key] = entry
+	}
+	this.replay_seen++
+	entry.seen = this.replay_seen
+	for _, recent := range entry.history {
+		if recent == value {
+			this.log.Debug2("protocol.openthings.Decode: Replayed message from sensor 0x%06X", message.sensor_id)
+			return true
+		}
+	}
+	if len(entry.history) >= OT_REPLAY_HISTORY {
+		copy(entry.history, entry.history[1:])
+		entry.history = entry.history[:len(entry.history)-1]
+	}
+	entry.history = append(entry.history, value)
+	return false
+}
+
+// evictReplay removes the least recently seen sensor, called with lock held
+func (this *OpenThings) evictReplay() {
+	var oldest replay_key
+	var seen uint64
+	for key, entry := range this.replay {
+		if seen == 0 || entry.seen < seen {
+			oldest, seen = key, entry.seen
+		}
+	}
+	delete(this.replay, oldest)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // STRINGIFY
 
@@ -202,19 +371,59 @@ func (this *Message) String() string {
 	} else {
 		params = append(params, fmt.Sprintf("payload=%v", strings.ToUpper(hex.EncodeToString(this.payload))))
 	}
+	if this.crc_error != nil {
+		params = append(params, fmt.Sprintf("crc_error=%v", this.crc_error))
+	}
+	if this.replayed {
+		params = append(params, "replayed")
+	}
 	return fmt.Sprintf("<protocol.openthings.Message>{ %v }", strings.Join(params, " "))
 }
 
+func (m DecodeMode) String() string {
+	switch m {
+	case OT_DECODE_DEFAULT:
+		return "OT_DECODE_DEFAULT"
+	case OT_DECODE_STRICT:
+		return "OT_DECODE_STRICT"
+	case OT_DECODE_LENIENT:
+		return "OT_DECODE_LENIENT"
+	default:
+		return "[?? Invalid DecodeMode value]"
+	}
+}
+
+func (this *ErrDecode) Error() string {
+	return fmt.Sprintf("%v: %v at offset %v", sensors.ErrMessageCorruption, this.Reason, this.Offset)
+}
+
+// Is returns true for sensors.ErrMessageCorruption, so that errors.Is
+// matches the generic corruption error
+func (this *ErrDecode) Is(target error) bool {
+	return target == sensors.ErrMessageCorruption
+}
+
+func (this *ErrCRCMismatch) Error() string {
+	return fmt.Sprintf("%v: expected 0x%04X, received 0x%04X", sensors.ErrMessageCRC, this.Expected, this.Actual)
+}
+
+// Is returns true for sensors.ErrMessageCRC, so that errors.Is matches
+// the generic CRC error
+func (this *ErrCRCMismatch) Is(target error) bool {
+	return target == sensors.ErrMessageCRC
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // PRIVATE METHODS
 
-// Function to decrypt an incoming message
+// Function to decrypt an incoming message, returning the decrypted copy
 func (this *OpenThings) decrypt_message(buf []byte, pip uint16) []byte {
 	random := seed(this.encryption_id, pip)
+	decrypted := make([]byte, len(buf))
 	for i := range buf {
-		buf[i], random = encrypt_decrypt(buf[i], random)
+		decrypted[i], random = encrypt_decrypt(buf[i], random)
 	}
-	return buf
+	return decrypted
 }
 
 // Function to update the seed to match the pip received in the message
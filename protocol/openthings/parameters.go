@@ -10,7 +10,6 @@
 package openthings
 
 import (
-	"encoding/binary"
 	"fmt"
 
 	// Frameworks
@@ -26,47 +25,94 @@ type ot_record struct {
 	data     []byte
 }
 
-const (
-	ot_state_start = iota
-	ot_state_length
-	ot_state_data
+var (
+	// Parameters of detectors, alarms and switches with boolean values
+	bool_parameters = map[sensors.OTParameter]bool{
+		sensors.OT_PARAM_ALARM:           true,
+		sensors.OT_PARAM_WATER_DETECTOR:  true,
+		sensors.OT_PARAM_GLASS_BREAKAGE:  true,
+		sensors.OT_PARAM_CLOSURES:        true,
+		sensors.OT_PARAM_DOOR_BELL:       true,
+		sensors.OT_PARAM_FALL_SENSOR:     true,
+		sensors.OT_PARAM_SMOKE_DETECTOR:  true,
+		sensors.OT_PARAM_CO_DETECTOR:     true,
+		sensors.OT_PARAM_DOOR_SENSOR:     true,
+		sensors.OT_PARAM_EMERGENCY:       true,
+		sensors.OT_PARAM_MOTION_DETECTOR: true,
+		sensors.OT_PARAM_OCCUPANCY:       true,
+		sensors.OT_PARAM_SWITCH_STATE:    true,
+	}
 )
 
 ////////////////////////////////////////////////////////////////////////////////
 // READ RECORDS
 
-func read_records(data []byte) ([]sensors.OTRecord, error) {
+// read_records returns the records up to the zero byte which ends them.
+// Each record is bounded by the data, so that a record length which
+// points past the end returns ErrDecode. In strict mode non-conformant
+// records are rejected. A truncated last record is returned in the
+// default mode
+func read_records(data []byte, mode DecodeMode) ([]sensors.OTRecord, error) {
 	records := make([]sensors.OTRecord, 0)
-	state := ot_state_start
-	record := &ot_record{}
-	for _, v := range data {
-		switch state {
-		case ot_state_start:
-			record.name = sensors.OTParameter(v & 0x7F)
-			record.request = to_uint8_bool(v & 0x80)
-			state = ot_state_length
-		case ot_state_length:
-			record.datatype = sensors.OTDataType((v >> 4) & 0x0F)
-			record.datasize = v & 0x0F
-			record.data = make([]byte, 0, record.datasize)
-			state = ot_state_data
-		case ot_state_data:
-			record.data = append(record.data, v)
-			if len(record.data) == int(record.datasize) {
-				state = ot_state_start
-				records = append(records, record)
-				record = &ot_record{}
+	for offset := 0; offset < len(data); {
+		// Zero byte ends the records
+		if data[offset] == 0x00 {
+			if mode == OT_DECODE_STRICT && offset != len(data)-1 {
+				return records, &ErrDecode{OT_RECORDS_OFFSET + offset, "data after records"}
 			}
+			return records, nil
 		}
-	}
-	// Add on the last record
-	if record.name != sensors.OT_PARAM_NONE {
+
+		// Parameter, then type and length
+		record := &ot_record{
+			name:    sensors.OTParameter(data[offset] & 0x7F),
+			request: to_uint8_bool(data[offset] & 0x80),
+		}
+		end := offset + 2
+		if end <= len(data) {
+			record.datatype = sensors.OTDataType((data[offset+1] >> 4) & 0x0F)
+			record.datasize = data[offset+1] & 0x0F
+			end += int(record.datasize)
+			if mode == OT_DECODE_STRICT && conformant_record(record) == false {
+				return records, &ErrDecode{OT_RECORDS_OFFSET + offset, "non-conformant record"}
+			}
+		}
+
+		// Data, which is truncated if the record overruns the data
+		if end > len(data) {
+			if mode != OT_DECODE_DEFAULT {
+				return records, &ErrDecode{OT_RECORDS_OFFSET + offset, "record overruns message"}
+			} else if offset+2 < len(data) {
+				record.data = append([]byte(nil), data[offset+2:]...)
+			}
+			return append(records, record), nil
+		}
+		record.data = append(make([]byte, 0, record.datasize), data[offset+2:end]...)
 		records = append(records, record)
+		offset = end
 	}
 	// Return the records
 	return records, nil
 }
 
+// conformant_record returns false for a record with a reserved data
+// type, or without data when it is not a request
+func conformant_record(record *ot_record) bool {
+	switch record.datatype {
+	case sensors.OT_DATATYPE_UDEC_0, sensors.OT_DATATYPE_UDEC_4, sensors.OT_DATATYPE_UDEC_8, sensors.OT_DATATYPE_UDEC_12:
+		break
+	case sensors.OT_DATATYPE_UDEC_16, sensors.OT_DATATYPE_UDEC_20, sensors.OT_DATATYPE_UDEC_24, sensors.OT_DATATYPE_STRING:
+		break
+	case sensors.OT_DATATYPE_DEC_0, sensors.OT_DATATYPE_DEC_8, sensors.OT_DATATYPE_DEC_16, sensors.OT_DATATYPE_DEC_24:
+		break
+	case sensors.OT_DATATYPE_ENUM, sensors.OT_DATATYPE_FLOAT:
+		break
+	default:
+		return false
+	}
+	return record.datasize > 0 || record.request
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // OTRECORD INTERFACE
 
@@ -78,6 +124,28 @@ func (this *ot_record) Type() sensors.OTDataType {
 	return this.datatype
 }
 
+// IsRequest returns true for a command or a request to report the
+// parameter, which has the top bit of the parameter set
+func (this *ot_record) IsRequest() bool {
+	return this.request
+}
+
+// Raw returns the parameter byte, the type and length byte and the data
+// of the record
+func (this *ot_record) Raw() []byte {
+	name := uint8(this.name) & 0x7F
+	if this.request {
+		name |= 0x80
+	}
+	raw := []byte{name, uint8(this.datatype)<<4 | this.datasize&0x0F}
+	return append(raw, this.data...)
+}
+
+// Size returns the number of data bytes in the record
+func (this *ot_record) Size() uint8 {
+	return this.datasize
+}
+
 func (this *ot_record) StringValue() (string, error) {
 	switch this.datatype {
 	case sensors.OT_DATATYPE_UDEC_0:
@@ -112,6 +180,8 @@ func (this *ot_record) StringValue() (string, error) {
 		} else {
 			return fmt.Sprint(value), nil
 		}
+	case sensors.OT_DATATYPE_STRING:
+		return string(this.data), nil
 	default:
 		return "", fmt.Errorf("StringValue: Not Implemented: %v", this.datatype)
 	}
@@ -121,7 +191,9 @@ func (this *ot_record) StringValue() (string, error) {
 // STRINGIFY
 
 func (this *ot_record) String() string {
-	if string_value, err := this.StringValue(); err != nil {
+	if this.datasize == 0 {
+		return fmt.Sprintf("%v<req=%v>", this.name, this.request)
+	} else if string_value, err := this.StringValue(); err != nil {
 		return fmt.Sprintf("%v<req=%v err=%v type=%v>", this.name, this.request, err, this.datatype)
 	} else {
 		return fmt.Sprintf("%v<req=%v value=%v>", this.name, this.request, string_value)
@@ -165,55 +237,94 @@ func (this *ot_record) FloatValue() (float64, error) {
 	if int(this.datasize) != len(this.data) {
 		return 0, gopi.ErrOutOfOrder
 	}
-	// Convert fixed point into floating point
+	// Convert fixed point into floating point. UDEC types have 4, 8, 12,
+	// 16, 20 or 24 fractional bits and DEC types 8, 16 or 24
 	switch this.datatype {
-	case sensors.OT_DATATYPE_UDEC_0:
-		value, err := this.uintValue()
-		return float64(value), err
-	case sensors.OT_DATATYPE_UDEC_8:
+	case sensors.OT_DATATYPE_UDEC_0, sensors.OT_DATATYPE_UDEC_4, sensors.OT_DATATYPE_UDEC_8,
+		sensors.OT_DATATYPE_UDEC_12, sensors.OT_DATATYPE_UDEC_16,
+		sensors.OT_DATATYPE_UDEC_20, sensors.OT_DATATYPE_UDEC_24:
+		bits := uint(this.datatype-sensors.OT_DATATYPE_UDEC_0) * 4
 		value, err := this.uintValue()
-		return float64(value) / float64(1<<8), err
-	case sensors.OT_DATATYPE_DEC_8:
+		return float64(value) / float64(uint64(1)<<bits), err
+	case sensors.OT_DATATYPE_DEC_0, sensors.OT_DATATYPE_DEC_8,
+		sensors.OT_DATATYPE_DEC_16, sensors.OT_DATATYPE_DEC_24:
+		bits := uint(this.datatype-sensors.OT_DATATYPE_DEC_0) * 8
 		value, err := this.intValue()
-		return float64(value) / float64(1<<8), err
+		return float64(value) / float64(uint64(1)<<bits), err
 	default:
 		return 0, gopi.ErrBadParameter
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////////
+// TYPED VALUES
+
+// BoolValue returns true if a detector, alarm or switch record is non-zero
+func (this *ot_record) BoolValue() (bool, error) {
+	if bool_parameters[this.name] == false {
+		return false, sensors.ErrWrongType
+	} else if value, err := this.numericValue(); err != nil {
+		return false, err
+	} else {
+		return value != 0, nil
+	}
+}
+
+// TemperatureCelsius returns the value of a temperature record
+func (this *ot_record) TemperatureCelsius() (float64, error) {
+	if this.name != sensors.OT_PARAM_TEMPERATURE {
+		return 0, sensors.ErrWrongType
+	}
+	return this.numericValue()
+}
+
+// PowerWatts returns the value of a real power record
+func (this *ot_record) PowerWatts() (float64, error) {
+	if this.name != sensors.OT_PARAM_REAL_POWER {
+		return 0, sensors.ErrWrongType
+	}
+	return this.numericValue()
+}
+
+// VoltageVolts returns the value of a voltage record
+func (this *ot_record) VoltageVolts() (float64, error) {
+	if this.name != sensors.OT_PARAM_VOLTAGE {
+		return 0, sensors.ErrWrongType
+	}
+	return this.numericValue()
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // PRIVATE METHODS
 
-// Returns an unsigned integer for any UDEC of length 1,2,4 or 8 bytes
+// Returns the value of a numeric record, or ErrWrongType for other types
+func (this *ot_record) numericValue() (float64, error) {
+	if value, err := this.FloatValue(); err == gopi.ErrBadParameter {
+		return 0, sensors.ErrWrongType
+	} else {
+		return value, err
+	}
+}
+
+// Returns an unsigned integer for any UDEC of length 1 to 8 bytes
 func (this *ot_record) uintValue() (uint64, error) {
-	// Allow 1, 2,4 and 8 byte unsigned integers
-	switch len(this.data) {
-	case 1:
-		return uint64(this.data[0]), nil
-	case 2:
-		return uint64(binary.BigEndian.Uint16(this.data)), nil
-	case 4:
-		return uint64(binary.BigEndian.Uint32(this.data)), nil
-	case 8:
-		return uint64(binary.BigEndian.Uint64(this.data)), nil
-	default:
+	if len(this.data) == 0 || len(this.data) > 8 {
 		return 0, gopi.ErrBadParameter
 	}
+	value := uint64(0)
+	for _, v := range this.data {
+		value = value<<8 | uint64(v)
+	}
+	return value, nil
 }
 
-// Returns a signed integer for any DEC of length 1,2,4 or 8 bytes
+// Returns a signed integer for any DEC of length 1 to 8 bytes
 func (this *ot_record) intValue() (int64, error) {
-	// Allow 1, 2,4 and 8 byte signed integers
-	switch len(this.data) {
-	case 1:
-		return int64(int8(this.data[0])), nil
-	case 2:
-		return int64(int16(binary.BigEndian.Uint16(this.data))), nil
-	case 4:
-		return int64(int32(binary.BigEndian.Uint32(this.data))), nil
-	case 8:
-		return int64(binary.BigEndian.Uint64(this.data)), nil
-	default:
-		return 0, gopi.ErrBadParameter
+	if value, err := this.uintValue(); err != nil {
+		return 0, err
+	} else {
+		// Sign extend from the top bit of the data
+		shift := uint(64 - 8*len(this.data))
+		return int64(value<<shift) >> shift, nil
 	}
 }
@@ -0,0 +1,84 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package openthings
+
+import (
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST REPLAY
+
+func TestReplayHistory(t *testing.T) {
+	driver := test_replay_driver(t)
+	message := test_replay_message(1, 0x1234)
+	if driver.replayed(message, 1) {
+		t.Error("First message flagged as replayed")
+	} else if driver.replayed(message, 1) == false {
+		t.Error("Repeated message not flagged as replayed")
+	} else if driver.replayed(message, 2) {
+		t.Error("Message with a new PIP flagged as replayed")
+	}
+
+	// Only the most recent messages from a sensor are kept
+	for pip := uint16(3); pip < 3+OT_REPLAY_HISTORY; pip++ {
+		driver.replayed(message, pip)
+	}
+	if driver.replayed(message, 1) {
+		t.Error("Expired message flagged as replayed")
+	} else if driver.replayed(message, 2+OT_REPLAY_HISTORY) == false {
+		t.Error("Recent message not flagged as replayed")
+	}
+}
+
+// TestReplaySensors checks that the least recently seen sensor is evicted
+// when too many sensors are tracked
+func TestReplaySensors(t *testing.T) {
+	driver := test_replay_driver(t)
+	for sensor := uint32(0); sensor < OT_REPLAY_SENSORS; sensor++ {
+		driver.replayed(test_replay_message(sensor, 0x1234), 1)
+	}
+
+	// Seeing sensor zero again makes sensor one the least recently seen
+	if driver.replayed(test_replay_message(0, 0x1234), 1) == false {
+		t.Fatal("Repeated message not flagged as replayed")
+	}
+	driver.replayed(test_replay_message(OT_REPLAY_SENSORS, 0x1234), 1)
+	if len(driver.replay) != OT_REPLAY_SENSORS {
+		t.Errorf("Expected %v sensors, got %v", OT_REPLAY_SENSORS, len(driver.replay))
+	}
+	if driver.replayed(test_replay_message(0, 0x1234), 1) == false {
+		t.Error("Recently seen sensor was evicted")
+	}
+	if driver.replayed(test_replay_message(1, 0x1234), 1) {
+		t.Error("Least recently seen sensor was not evicted")
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// HELPERS
+
+func test_replay_driver(t *testing.T) *OpenThings {
+	t.Helper()
+	if driver, err := (Config{TrackReplay: true}).Open(test_logger{}); err != nil {
+		t.Fatal(err)
+		return nil
+	} else {
+		return driver.(*OpenThings)
+	}
+}
+
+func test_replay_message(sensor uint32, crc uint16) *Message {
+	return &Message{
+		payload:   []byte{0x00, 0x04, 0x02},
+		sensor_id: sensor,
+		crc:       crc,
+	}
+}
@@ -11,6 +11,9 @@ package sensors
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/djthorpe/gopi"
 )
@@ -33,8 +36,153 @@ type (
 	RFMLNAGain       uint8
 	RFMRXBWFrequency uint8
 	RFMRXBWCutoff    uint8
+	RFMEventType     uint8
+
+	RFMOOKThresholdType      uint8
+	RFMOOKThresholdStep      uint8
+	RFMOOKThresholdDecrement uint8
+	RFMOOKAverageFilter      uint8
+	RFMPARamp                uint8
+	RFMAutoModeEnter         uint8
+	RFMAutoModeExit          uint8
+	RFMAutoModeIntermediate  uint8
+	RFMClockOut              uint8
+	RFMLowBatTrim            uint8
+	RFMDIOPin                uint8
+	RFMDIOFunction           uint8
 )
 
+// RFMRegisterValue is a register address, name and value
+type RFMRegisterValue struct {
+	Register uint8
+	Name     string
+	Value    uint8
+}
+
+// RFMPacket is a received payload, with the signal measurements
+// taken while it was received
+type RFMPacket struct {
+	Timestamp   time.Time // Time PayloadReady was detected, by interrupt or poll
+	FreqCarrier uint      // Carrier frequency the packet was received on, Hz
+	Payload     []byte
+	CRCOk       bool
+	Filtered    bool    // True if packet filtering was on, when the address is removed from the payload
+	Address     uint8   // Node or broadcast address the packet was sent to, when filtered
+	RSSI        float32 // Signal strength at sync address match, dBm
+	AFC         int     // Frequency correction applied by AFC, Hz
+	FEI         int     // Frequency error, Hz
+
+	// RadioHead header, when RadioHead packet mode is on, which is
+	// removed from the payload
+	Header *RFMRadioHeadHeader
+}
+
+// RFMRadioHeadHeader is the header which the RadioHead RF69 driver
+// adds after the length byte of each packet
+type RFMRadioHeadHeader struct {
+	To    uint8
+	From  uint8
+	ID    uint8
+	Flags uint8
+}
+
+// RFMIRQFlags are the flags in the RegIrqFlags1 and RegIrqFlags2
+// registers, which report what the radio is doing
+type RFMIRQFlags struct {
+	ModeReady        bool // Operating mode is ready
+	RxReady          bool // RX mode is ready
+	TxReady          bool // TX mode is ready
+	PLLLock          bool // PLL is locked
+	RSSI             bool // RSSI exceeded the threshold
+	Timeout          bool // RX timeout
+	AutoMode         bool // In the AutoModes intermediate mode
+	SyncAddressMatch bool // Sync word and address matched
+	FIFOFull         bool
+	FIFONotEmpty     bool
+	FIFOLevel        bool // FIFO exceeds the threshold
+	FIFOOverrun      bool
+	PacketSent       bool
+	PayloadReady     bool
+	CRCOk            bool
+	LowBattery       bool // Supply voltage dropped below the low battery threshold
+}
+
+// RFMFIFOStatus is the state of the FIFO
+type RFMFIFOStatus struct {
+	Full      bool
+	NotEmpty  bool
+	Level     bool  // FIFO exceeds the threshold
+	Overrun   bool  // Data was lost, cleared by ClearFIFO
+	Threshold uint8 // FIFO threshold
+}
+
+// RFMRSSIReading is the RSSI measured at a carrier frequency
+type RFMRSSIReading struct {
+	FreqCarrier uint    // Hz
+	RSSI        float32 // dBm
+}
+
+// RFM69Config is a snapshot of the radio configuration, which can be
+// saved as JSON and restored with SetConfig to switch between settings.
+// The mode and listen mode are not part of the configuration. A nil
+// AES key or sync word means encryption or the sync word is off
+type RFM69Config struct {
+	DataMode             RFMDataMode              `json:"data_mode"`
+	Modulation           RFMModulation            `json:"modulation"`
+	Sequencer            bool                     `json:"sequencer"`
+	Bitrate              uint                     `json:"bitrate"`
+	FreqCarrier          uint                     `json:"freq_carrier"`
+	FreqDeviation        uint                     `json:"freq_deviation"`
+	RadioHead            bool                     `json:"radiohead"`
+	PacketFormat         RFMPacketFormat          `json:"packet_format"`
+	PacketCoding         RFMPacketCoding          `json:"packet_coding"`
+	PacketFilter         RFMPacketFilter          `json:"packet_filter"`
+	PacketCRC            RFMPacketCRC             `json:"packet_crc"`
+	NodeAddress          uint8                    `json:"node_address"`
+	BroadcastAddress     uint8                    `json:"broadcast_address"`
+	PreambleSize         uint16                   `json:"preamble_size"`
+	PayloadSize          uint8                    `json:"payload_size"`
+	AESKey               []byte                   `json:"aes_key,omitempty"`
+	SyncWord             []byte                   `json:"sync_word,omitempty"`
+	SyncTolerance        uint8                    `json:"sync_tolerance"`
+	AFCMode              RFMAFCMode               `json:"afc_mode"`
+	AFCRoutine           RFMAFCRoutine            `json:"afc_routine"`
+	AFCLowBetaOffset     int                      `json:"afc_lowbeta_offset"`
+	OutputPower          int                      `json:"output_power"`
+	PARamp               RFMPARamp                `json:"pa_ramp"`
+	LNAImpedance         RFMLNAImpedance          `json:"lna_impedance"`
+	LNAGain              RFMLNAGain               `json:"lna_gain"`
+	SensitivityBoost     bool                     `json:"sensitivity_boost"`
+	DAGC                 bool                     `json:"dagc"`
+	RSSIThreshold        float32                  `json:"rssi_threshold"`
+	RXTimeoutStart       time.Duration            `json:"rx_timeout_start"`
+	RXTimeoutRSSI        time.Duration            `json:"rx_timeout_rssi"`
+	RXFilterFrequency    RFMRXBWFrequency         `json:"rxbw_frequency"`
+	RXFilterCutoff       RFMRXBWCutoff            `json:"rxbw_cutoff"`
+	OOKThresholdType     RFMOOKThresholdType      `json:"ook_threshold_type"`
+	OOKThresholdStep     RFMOOKThresholdStep      `json:"ook_threshold_step"`
+	OOKThresholdDec      RFMOOKThresholdDecrement `json:"ook_threshold_dec"`
+	OOKFixedThreshold    uint8                    `json:"ook_fixed_threshold"`
+	OOKAverageFilter     RFMOOKAverageFilter      `json:"ook_average_filter"`
+	FIFOThreshold        uint8                    `json:"fifo_threshold"`
+	AutoModeEnter        RFMAutoModeEnter         `json:"automode_enter"`
+	AutoModeExit         RFMAutoModeExit          `json:"automode_exit"`
+	AutoModeIntermediate RFMAutoModeIntermediate  `json:"automode_intermediate"`
+	ClockOut             RFMClockOut              `json:"clkout"`
+	LowBattery           bool                     `json:"lowbat"`
+	LowBatteryTrim       RFMLowBatTrim            `json:"lowbat_trim"`
+}
+
+// RFMNetworkMessage is a packet received on a LowPowerLab RFM69 network
+type RFMNetworkMessage struct {
+	Timestamp    time.Time
+	To           uint8 // Node the packet was sent to, or RFM_NETWORK_BROADCAST
+	From         uint8 // Node which sent the packet
+	ACKRequested bool  // True if the sender requested an acknowledgement
+	Payload      []byte
+	RSSI         float32 // Signal strength, dBm
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // RFM69 INTERFACE
 
@@ -57,11 +205,17 @@ type RFM69 interface {
 	SetFreqCarrier(hertz uint) error
 	SetFreqDeviation(hertz uint) error
 
+	// Bitrate and frequency deviation achieved by the register values,
+	// with the error from the requested values in ppm
+	BitrateActual() (float64, float64)
+	FreqDeviationActual() (float64, float64)
+
 	// Listen Mode and Sequencer
 	SetSequencer(enabled bool) error
 	SequencerEnabled() bool
 	SetListenOn(value bool) error
 	ListenOn() bool
+	EnterListenMode(idle, rx time.Duration) error
 
 	// Packets
 	PacketFormat() RFMPacketFormat
@@ -73,6 +227,12 @@ type RFM69 interface {
 	SetPacketFilter(packet_filter RFMPacketFilter) error
 	SetPacketCRC(packet_crc RFMPacketCRC) error
 
+	// RadioHead RF69 compatible packet mode, which sets the sync word,
+	// preamble and packet settings and adds the header to payloads
+	RadioHead() bool
+	SetRadioHead(enabled bool) error
+	WriteRadioHead(header RFMRadioHeadHeader, data []byte, repeat uint) error
+
 	// Addresses
 	NodeAddress() uint8
 	BroadcastAddress() uint8
@@ -99,19 +259,54 @@ type RFM69 interface {
 	AFCRoutine() RFMAFCRoutine
 	SetAFCRoutine(afc_routine RFMAFCRoutine) error
 	SetAFCMode(afc_mode RFMAFCMode) error
+	AFCLowBetaOffset() int
+	SetAFCLowBetaOffset(hz int) error
 	TriggerAFC() error
 
+	// Output power in dBm, and the PA ramp time in FSK mode
+	OutputPower() int
+	SetOutputPower(dbm int) error
+	PARamp() RFMPARamp
+	SetPARamp(ramp RFMPARamp) error
+
 	// Low Noise Amplifier Settings
 	LNAImpedance() RFMLNAImpedance
 	LNAGain() RFMLNAGain
 	LNACurrentGain() (RFMLNAGain, error)
 	SetLNA(impedance RFMLNAImpedance, gain RFMLNAGain) error
 
+	// Sensitivity boost and continuous DAGC, which improve reception
+	// of weak signals
+	SensitivityBoost() bool
+	SetSensitivityBoost(enabled bool) error
+	DAGC() bool
+	SetDAGC(enabled bool) error
+
+	// RSSI threshold in dBm, and RX timeouts after entering RX
+	// and after the RSSI threshold is exceeded
+	RSSIThreshold() float32
+	SetRSSIThreshold(dbm float32) error
+	RXTimeout() (time.Duration, time.Duration)
+	SetRXTimeout(rx_start, rssi_thresh time.Duration) error
+
 	// Channel Filter Settings
 	RXFilterFrequency() RFMRXBWFrequency
 	RXFilterCutoff() RFMRXBWCutoff
 	SetRXFilter(RFMRXBWFrequency, RFMRXBWCutoff) error
 
+	// OOK demodulator threshold. The fixed threshold in dB is used by
+	// the fixed threshold type, and as the floor for the peak type
+	OOKThreshold() (RFMOOKThresholdType, RFMOOKThresholdStep, RFMOOKThresholdDecrement)
+	SetOOKThreshold(threshold_type RFMOOKThresholdType, step RFMOOKThresholdStep, dec RFMOOKThresholdDecrement) error
+	OOKFixedThreshold() uint8
+	SetOOKFixedThreshold(db uint8) error
+	OOKAverageFilter() RFMOOKAverageFilter
+	SetOOKAverageFilter(filter RFMOOKAverageFilter) error
+
+	// IRQ flags and FIFO status, read from the radio
+	IRQFlags() (RFMIRQFlags, error)
+	FIFOStatus() (RFMFIFOStatus, error)
+
 	// FIFO
 	FIFOThreshold() uint8
 	SetFIFOThreshold(fifo_threshold uint8) error
@@ -119,18 +314,79 @@ type RFM69 interface {
 	WriteFIFO(data []byte) error
 	ClearFIFO() error
 
-	// Payload
+	// Payload, which is read when the DIO0 interrupt fires
+	// if SetInterrupt has been called
+	SetInterrupt(gpio gopi.GPIO, pin gopi.GPIOPin) error
 	ReadPayload(ctx context.Context) ([]byte, bool, error)
+	ReadPayloadTimeout(timeout time.Duration) ([]byte, bool, error)
+	TryReadPayload() ([]byte, bool, error)
+	ReadPacket(ctx context.Context) (*RFMPacket, error)
+
+	// Cycle RX across carrier frequencies until a packet is received
+	ScanChannels(ctx context.Context, channels []uint, dwell time.Duration) (*RFMPacket, error)
 	WritePayload(data []byte, repeat uint) error
 
+	// AutoModes, which switch the radio into the intermediate mode on the
+	// enter condition and back on the exit condition without the host.
+	// WriteAutoPayload sends each repeat of the payload from standby,
+	// entering TX when the payload is in the FIFO and leaving it when
+	// the packet is sent
+	AutoModes() (RFMAutoModeEnter, RFMAutoModeExit, RFMAutoModeIntermediate)
+	SetAutoModes(enter RFMAutoModeEnter, exit RFMAutoModeExit, intermediate RFMAutoModeIntermediate) error
+	WriteAutoPayload(data []byte, repeat uint) error
+
+	// Transmit an unmodulated carrier or the PN9 sequence for antenna
+	// tuning and regulatory testing, until the context is done when the
+	// radio returns to standby
+	TransmitCarrier(ctx context.Context) error
+	TransmitPN9(ctx context.Context) error
+
+	// RC oscillator calibration, which keeps listen mode timing accurate
+	// over temperature, and the clock output on DIO5 for other peripherals
+	CalibrateRC() error
+	ClockOutput() RFMClockOut
+	SetClockOutput(divider RFMClockOut) error
+
+	// Low battery detector, which compares the supply voltage with the
+	// threshold and emits RFM_EVENT_LOW_BATTERY when it drops below it,
+	// and the PLL lock status in FS, TX and RX modes
+	LowBattery() (bool, RFMLowBatTrim)
+	SetLowBattery(enabled bool, trim RFMLowBatTrim) error
+	MeasureLowBattery() (bool, error)
+	PLLLocked() (bool, error)
+
+	// Route a signal onto a DIO pin, for timing measurements and wake up
+	// logic. Some signals are only output in RX or TX mode, and DIO0
+	// can't be changed when SetInterrupt has been called
+	DIOMapping(pin RFMDIOPin) RFMDIOFunction
+	SetDIOMapping(pin RFMDIOPin, function RFMDIOFunction) error
+
+	// Configuration snapshot of all the settings above, which SetConfig
+	// restores from standby, writing only the settings which differ
+	Config() RFM69Config
+	SetConfig(config RFM69Config) error
+
 	// Measurements
 	MeasureTemperature(calibration float32) (float32, error)
 	MeasureRSSI() (float32, error)
 
-	/*
-		// OOK Parameters
-		SetOOK(ook_threshold_type RFMOOKThresholdType, ook_threshold_step RFMOOKThresholdStep, ook_threshold_dec RFMOOKThresholdDecrement) error
+	// Sweep the carrier frequency and measure the RSSI at each step
+	ScanRSSI(from, to, step uint) ([]RFMRSSIReading, error)
+
+	// Read the version register
+	ReadVersion() (uint8, error)
+
+	// Read all registers, and read or write any register when the
+	// driver has been opened for debugging
+	DumpRegisters() ([]RFMRegisterValue, error)
+	ReadRegister(reg uint8) (uint8, error)
+	WriteRegister(reg, value uint8) error
+
+	// Subscribe to low-level radio events, for debugging
+	SubscribeDebug() <-chan RFMEvent
+	UnsubscribeDebug(<-chan RFMEvent)
 
+	/*
 		// FIFO
 		FIFOFillCondition() bool
 		SetFIFOFillCondition(fifo_fill_condition bool) error
@@ -146,6 +402,57 @@ type RFM69 interface {
 	*/
 }
 
+// RFMEvent is a low-level radio event, for observing what the
+// hardware saw even when nothing decodes
+type RFMEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	Type() RFMEventType
+	Mode() RFMMode
+	IRQFlags1() uint8
+	IRQFlags2() uint8
+}
+
+// RFM69Network is a node on a network of LowPowerLab RFM69 nodes, such
+// as Moteino sensors, so that a gateway can receive from and send to
+// nodes running the LowPowerLab Arduino library
+type RFM69Network interface {
+	gopi.Driver
+
+	// Return the network and node identifiers
+	NetworkID() uint8
+	NodeID() uint8
+
+	// Send data to a node or to RFM_NETWORK_BROADCAST. When ack is true
+	// the send is retried until the node acknowledges, and returns
+	// ErrDeviceTimeout if it never does
+	Send(to uint8, data []byte, ack bool) error
+
+	// Receive the next message sent to this node or broadcast,
+	// acknowledging it if requested. Returns nil when the context
+	// is done
+	Receive(ctx context.Context) (*RFMNetworkMessage, error)
+}
+
+// RFMSniffer receives with sync word and address filtering off, and
+// emits every packet with an RSSI above the threshold as an
+// RFMSnifferEvent, for capturing unknown transmissions
+type RFMSniffer interface {
+	gopi.Driver
+	gopi.Publisher
+
+	// Capture packets until the context is done
+	Capture(ctx context.Context) error
+}
+
+type RFMSnifferEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	Packet() *RFMPacket
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // RFM69 CONSTS
 
@@ -159,6 +466,18 @@ const (
 	RFM_MODE_MAX   RFMMode = 0x07
 )
 
+const (
+	// Radio debug events
+	RFM_EVENT_NONE         RFMEventType = iota
+	RFM_EVENT_MODE                      // Mode changed
+	RFM_EVENT_IRQ                       // IRQ flags changed
+	RFM_EVENT_FIFO_OVERRUN              // FIFO overrun
+	RFM_EVENT_SYNC                      // Sync word or address matched
+	RFM_EVENT_LISTEN_WAKE               // Payload received in listen mode
+	RFM_EVENT_BUS_ERROR                 // SPI transfer failed, or a write failed verification
+	RFM_EVENT_LOW_BATTERY               // Supply voltage dropped below the low battery threshold
+)
+
 const (
 	// RFM69 Data Mode
 	RFM_DATAMODE_PACKET            RFMDataMode = 0x00
@@ -196,6 +515,18 @@ const (
 	RFM_PACKET_CRC_AUTOCLEAR_ON  RFMPacketCRC = 0x02 // CRC on
 )
 
+const (
+	// RadioHead address which all nodes accept
+	RFM_RADIOHEAD_BROADCAST uint8 = 0xFF
+)
+
+const (
+	// LowPowerLab network address which all nodes accept, and the
+	// maximum data length of a packet
+	RFM_NETWORK_BROADCAST   uint8 = 0xFF
+	RFM_NETWORK_PAYLOAD_MAX       = 61
+)
+
 const (
 	// RFM69 Modulation
 	RFM_MODULATION_FSK        RFMModulation = 0x00 // 00000 FSK no shaping
@@ -316,6 +647,167 @@ const (
 	RFM_RXBW_FREQUENCY_OOK_250P0 = RFM_RXBW_FREQUENCY_FSK_500P0
 )
 
+const (
+	// Rise and fall time of the power amplifier in FSK mode
+	RFM_PARAMP_3400US RFMPARamp = 0x00
+	RFM_PARAMP_2000US RFMPARamp = 0x01
+	RFM_PARAMP_1000US RFMPARamp = 0x02
+	RFM_PARAMP_500US  RFMPARamp = 0x03
+	RFM_PARAMP_250US  RFMPARamp = 0x04
+	RFM_PARAMP_125US  RFMPARamp = 0x05
+	RFM_PARAMP_100US  RFMPARamp = 0x06
+	RFM_PARAMP_62US   RFMPARamp = 0x07
+	RFM_PARAMP_50US   RFMPARamp = 0x08
+	RFM_PARAMP_40US   RFMPARamp = 0x09 // Default
+	RFM_PARAMP_31US   RFMPARamp = 0x0A
+	RFM_PARAMP_25US   RFMPARamp = 0x0B
+	RFM_PARAMP_20US   RFMPARamp = 0x0C
+	RFM_PARAMP_15US   RFMPARamp = 0x0D
+	RFM_PARAMP_12US   RFMPARamp = 0x0E
+	RFM_PARAMP_10US   RFMPARamp = 0x0F
+	RFM_PARAMP_MAX    RFMPARamp = 0x0F // Mask
+)
+
+const (
+	// OOK demodulator threshold type
+	RFM_OOK_THRESHOLD_FIXED   RFMOOKThresholdType = 0x00 // Fixed threshold
+	RFM_OOK_THRESHOLD_PEAK    RFMOOKThresholdType = 0x01 // Threshold follows the signal peak
+	RFM_OOK_THRESHOLD_AVERAGE RFMOOKThresholdType = 0x02 // Threshold follows the average signal
+	RFM_OOK_THRESHOLD_MAX     RFMOOKThresholdType = 0x03 // Mask
+)
+
+const (
+	// Size of each decrement of the threshold in peak mode
+	RFM_OOK_THRESHOLD_STEP_0P5 RFMOOKThresholdStep = 0x00 // 0.5dB
+	RFM_OOK_THRESHOLD_STEP_1P0 RFMOOKThresholdStep = 0x01 // 1.0dB
+	RFM_OOK_THRESHOLD_STEP_1P5 RFMOOKThresholdStep = 0x02 // 1.5dB
+	RFM_OOK_THRESHOLD_STEP_2P0 RFMOOKThresholdStep = 0x03 // 2.0dB
+	RFM_OOK_THRESHOLD_STEP_3P0 RFMOOKThresholdStep = 0x04 // 3.0dB
+	RFM_OOK_THRESHOLD_STEP_4P0 RFMOOKThresholdStep = 0x05 // 4.0dB
+	RFM_OOK_THRESHOLD_STEP_5P0 RFMOOKThresholdStep = 0x06 // 5.0dB
+	RFM_OOK_THRESHOLD_STEP_6P0 RFMOOKThresholdStep = 0x07 // 6.0dB
+	RFM_OOK_THRESHOLD_STEP_MAX RFMOOKThresholdStep = 0x07 // Mask
+)
+
+const (
+	// Period of decrement of the threshold in peak mode
+	RFM_OOK_THRESHOLD_DEC_1   RFMOOKThresholdDecrement = 0x00 // Once per chip
+	RFM_OOK_THRESHOLD_DEC_1_2 RFMOOKThresholdDecrement = 0x01 // Once every 2 chips
+	RFM_OOK_THRESHOLD_DEC_1_4 RFMOOKThresholdDecrement = 0x02 // Once every 4 chips
+	RFM_OOK_THRESHOLD_DEC_1_8 RFMOOKThresholdDecrement = 0x03 // Once every 8 chips
+	RFM_OOK_THRESHOLD_DEC_2   RFMOOKThresholdDecrement = 0x04 // Twice each chip
+	RFM_OOK_THRESHOLD_DEC_4   RFMOOKThresholdDecrement = 0x05 // 4 times each chip
+	RFM_OOK_THRESHOLD_DEC_8   RFMOOKThresholdDecrement = 0x06 // 8 times each chip
+	RFM_OOK_THRESHOLD_DEC_16  RFMOOKThresholdDecrement = 0x07 // 16 times each chip
+	RFM_OOK_THRESHOLD_DEC_MAX RFMOOKThresholdDecrement = 0x07 // Mask
+)
+
+const (
+	// Filter coefficient in average mode, as a fraction of the chip rate
+	RFM_OOK_AVERAGE_FILTER_32PI RFMOOKAverageFilter = 0x00 // Chip rate / 32.pi
+	RFM_OOK_AVERAGE_FILTER_8PI  RFMOOKAverageFilter = 0x01 // Chip rate / 8.pi
+	RFM_OOK_AVERAGE_FILTER_4PI  RFMOOKAverageFilter = 0x02 // Chip rate / 4.pi
+	RFM_OOK_AVERAGE_FILTER_2PI  RFMOOKAverageFilter = 0x03 // Chip rate / 2.pi
+	RFM_OOK_AVERAGE_FILTER_MAX  RFMOOKAverageFilter = 0x03 // Mask
+)
+
+const (
+	// AutoModes enter condition
+	RFM_AUTOMODE_ENTER_OFF          RFMAutoModeEnter = 0x00 // AutoModes off
+	RFM_AUTOMODE_ENTER_FIFONOTEMPTY RFMAutoModeEnter = 0x01 // Rising edge of FifoNotEmpty
+	RFM_AUTOMODE_ENTER_FIFOLEVEL    RFMAutoModeEnter = 0x02 // Rising edge of FifoLevel
+	RFM_AUTOMODE_ENTER_CRCOK        RFMAutoModeEnter = 0x03 // Rising edge of CrcOk
+	RFM_AUTOMODE_ENTER_PAYLOADREADY RFMAutoModeEnter = 0x04 // Rising edge of PayloadReady
+	RFM_AUTOMODE_ENTER_SYNCADDRESS  RFMAutoModeEnter = 0x05 // Rising edge of SyncAddress
+	RFM_AUTOMODE_ENTER_PACKETSENT   RFMAutoModeEnter = 0x06 // Rising edge of PacketSent
+	RFM_AUTOMODE_ENTER_FIFOEMPTY    RFMAutoModeEnter = 0x07 // Falling edge of FifoNotEmpty
+	RFM_AUTOMODE_ENTER_MAX          RFMAutoModeEnter = 0x07 // Mask
+)
+
+const (
+	// AutoModes exit condition
+	RFM_AUTOMODE_EXIT_OFF          RFMAutoModeExit = 0x00 // AutoModes off
+	RFM_AUTOMODE_EXIT_FIFOEMPTY    RFMAutoModeExit = 0x01 // Falling edge of FifoNotEmpty
+	RFM_AUTOMODE_EXIT_FIFOLEVEL    RFMAutoModeExit = 0x02 // Rising edge of FifoLevel or Timeout
+	RFM_AUTOMODE_EXIT_CRCOK        RFMAutoModeExit = 0x03 // Rising edge of CrcOk or Timeout
+	RFM_AUTOMODE_EXIT_PAYLOADREADY RFMAutoModeExit = 0x04 // Rising edge of PayloadReady or Timeout
+	RFM_AUTOMODE_EXIT_SYNCADDRESS  RFMAutoModeExit = 0x05 // Rising edge of SyncAddress or Timeout
+	RFM_AUTOMODE_EXIT_PACKETSENT   RFMAutoModeExit = 0x06 // Rising edge of PacketSent
+	RFM_AUTOMODE_EXIT_TIMEOUT      RFMAutoModeExit = 0x07 // Rising edge of Timeout
+	RFM_AUTOMODE_EXIT_MAX          RFMAutoModeExit = 0x07 // Mask
+)
+
+const (
+	// AutoModes intermediate mode
+	RFM_AUTOMODE_INTERMEDIATE_SLEEP RFMAutoModeIntermediate = 0x00
+	RFM_AUTOMODE_INTERMEDIATE_STDBY RFMAutoModeIntermediate = 0x01
+	RFM_AUTOMODE_INTERMEDIATE_RX    RFMAutoModeIntermediate = 0x02
+	RFM_AUTOMODE_INTERMEDIATE_TX    RFMAutoModeIntermediate = 0x03
+	RFM_AUTOMODE_INTERMEDIATE_MAX   RFMAutoModeIntermediate = 0x03 // Mask
+)
+
+const (
+	// Clock output on DIO5, the crystal oscillator frequency divided or
+	// the RC oscillator
+	RFM_CLKOUT_FXOSC    RFMClockOut = 0x00
+	RFM_CLKOUT_FXOSC_2  RFMClockOut = 0x01
+	RFM_CLKOUT_FXOSC_4  RFMClockOut = 0x02
+	RFM_CLKOUT_FXOSC_8  RFMClockOut = 0x03
+	RFM_CLKOUT_FXOSC_16 RFMClockOut = 0x04
+	RFM_CLKOUT_FXOSC_32 RFMClockOut = 0x05
+	RFM_CLKOUT_RC       RFMClockOut = 0x06
+	RFM_CLKOUT_OFF      RFMClockOut = 0x07 // Default
+	RFM_CLKOUT_MAX      RFMClockOut = 0x07 // Mask
+)
+
+const (
+	// Low battery detector threshold, in mV
+	RFM_LOWBAT_TRIM_1695 RFMLowBatTrim = 0x00
+	RFM_LOWBAT_TRIM_1764 RFMLowBatTrim = 0x01
+	RFM_LOWBAT_TRIM_1835 RFMLowBatTrim = 0x02 // Default
+	RFM_LOWBAT_TRIM_1905 RFMLowBatTrim = 0x03
+	RFM_LOWBAT_TRIM_1976 RFMLowBatTrim = 0x04
+	RFM_LOWBAT_TRIM_2045 RFMLowBatTrim = 0x05
+	RFM_LOWBAT_TRIM_2116 RFMLowBatTrim = 0x06
+	RFM_LOWBAT_TRIM_2185 RFMLowBatTrim = 0x07
+	RFM_LOWBAT_TRIM_MAX  RFMLowBatTrim = 0x07 // Mask
+)
+
+const (
+	// DIO pins
+	RFM_DIO0    RFMDIOPin = 0x00
+	RFM_DIO1    RFMDIOPin = 0x01
+	RFM_DIO2    RFMDIOPin = 0x02
+	RFM_DIO3    RFMDIOPin = 0x03
+	RFM_DIO4    RFMDIOPin = 0x04
+	RFM_DIO5    RFMDIOPin = 0x05
+	RFM_DIO_MAX RFMDIOPin = 0x05
+)
+
+const (
+	// Signals which can be routed onto DIO pins in packet mode. There
+	// is no preamble detector, so RSSI above the threshold is used to
+	// detect the start of a packet
+	RFM_DIO_FUNC_NONE         RFMDIOFunction = iota // Power-on mapping
+	RFM_DIO_FUNC_MODEREADY                          // DIO4 in TX, DIO5
+	RFM_DIO_FUNC_RXREADY                            // DIO4 in RX
+	RFM_DIO_FUNC_TXREADY                            // DIO0, DIO3 and DIO4 in TX
+	RFM_DIO_FUNC_PLLLOCK                            // DIO0 and DIO1 in FS and TX, DIO3 and DIO4
+	RFM_DIO_FUNC_RSSI                               // DIO0, DIO3 and DIO4 in RX
+	RFM_DIO_FUNC_TIMEOUT                            // DIO1 and DIO4 in RX
+	RFM_DIO_FUNC_AUTOMODE                           // DIO2
+	RFM_DIO_FUNC_SYNCADDRESS                        // DIO0 and DIO3 in RX
+	RFM_DIO_FUNC_FIFOFULL                           // DIO1 and DIO3
+	RFM_DIO_FUNC_FIFONOTEMPTY                       // DIO1 and DIO2
+	RFM_DIO_FUNC_FIFOLEVEL                          // DIO1
+	RFM_DIO_FUNC_PACKETSENT                         // DIO0 in TX
+	RFM_DIO_FUNC_PAYLOADREADY                       // DIO0 in RX
+	RFM_DIO_FUNC_CRCOK                              // DIO0 in RX
+	RFM_DIO_FUNC_LOWBAT                             // DIO2 to DIO5
+	RFM_DIO_FUNC_DATA                               // DIO2 and DIO5 in RX and TX
+	RFM_DIO_FUNC_CLKOUT                             // DIO5
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 // RFM69 STRINGIFY
 
@@ -336,6 +828,29 @@ func (m RFMMode) String() string {
 	}
 }
 
+func (t RFMEventType) String() string {
+	switch t {
+	case RFM_EVENT_NONE:
+		return "RFM_EVENT_NONE"
+	case RFM_EVENT_MODE:
+		return "RFM_EVENT_MODE"
+	case RFM_EVENT_IRQ:
+		return "RFM_EVENT_IRQ"
+	case RFM_EVENT_FIFO_OVERRUN:
+		return "RFM_EVENT_FIFO_OVERRUN"
+	case RFM_EVENT_SYNC:
+		return "RFM_EVENT_SYNC"
+	case RFM_EVENT_LISTEN_WAKE:
+		return "RFM_EVENT_LISTEN_WAKE"
+	case RFM_EVENT_BUS_ERROR:
+		return "RFM_EVENT_BUS_ERROR"
+	case RFM_EVENT_LOW_BATTERY:
+		return "RFM_EVENT_LOW_BATTERY"
+	default:
+		return "[?? Invalid RFMEventType value]"
+	}
+}
+
 func (m RFMDataMode) String() string {
 	switch m {
 	case RFM_DATAMODE_PACKET:
@@ -565,3 +1080,319 @@ func (v RFMRXBWFrequency) String() string {
 
 	}
 }
+
+func (t RFMOOKThresholdType) String() string {
+	switch t {
+	case RFM_OOK_THRESHOLD_FIXED:
+		return "RFM_OOK_THRESHOLD_FIXED"
+	case RFM_OOK_THRESHOLD_PEAK:
+		return "RFM_OOK_THRESHOLD_PEAK"
+	case RFM_OOK_THRESHOLD_AVERAGE:
+		return "RFM_OOK_THRESHOLD_AVERAGE"
+	default:
+		return "[?? Invalid RFMOOKThresholdType value]"
+	}
+}
+
+func (s RFMOOKThresholdStep) String() string {
+	switch s {
+	case RFM_OOK_THRESHOLD_STEP_0P5:
+		return "RFM_OOK_THRESHOLD_STEP_0P5"
+	case RFM_OOK_THRESHOLD_STEP_1P0:
+		return "RFM_OOK_THRESHOLD_STEP_1P0"
+	case RFM_OOK_THRESHOLD_STEP_1P5:
+		return "RFM_OOK_THRESHOLD_STEP_1P5"
+	case RFM_OOK_THRESHOLD_STEP_2P0:
+		return "RFM_OOK_THRESHOLD_STEP_2P0"
+	case RFM_OOK_THRESHOLD_STEP_3P0:
+		return "RFM_OOK_THRESHOLD_STEP_3P0"
+	case RFM_OOK_THRESHOLD_STEP_4P0:
+		return "RFM_OOK_THRESHOLD_STEP_4P0"
+	case RFM_OOK_THRESHOLD_STEP_5P0:
+		return "RFM_OOK_THRESHOLD_STEP_5P0"
+	case RFM_OOK_THRESHOLD_STEP_6P0:
+		return "RFM_OOK_THRESHOLD_STEP_6P0"
+	default:
+		return "[?? Invalid RFMOOKThresholdStep value]"
+	}
+}
+
+func (d RFMOOKThresholdDecrement) String() string {
+	switch d {
+	case RFM_OOK_THRESHOLD_DEC_1:
+		return "RFM_OOK_THRESHOLD_DEC_1"
+	case RFM_OOK_THRESHOLD_DEC_1_2:
+		return "RFM_OOK_THRESHOLD_DEC_1_2"
+	case RFM_OOK_THRESHOLD_DEC_1_4:
+		return "RFM_OOK_THRESHOLD_DEC_1_4"
+	case RFM_OOK_THRESHOLD_DEC_1_8:
+		return "RFM_OOK_THRESHOLD_DEC_1_8"
+	case RFM_OOK_THRESHOLD_DEC_2:
+		return "RFM_OOK_THRESHOLD_DEC_2"
+	case RFM_OOK_THRESHOLD_DEC_4:
+		return "RFM_OOK_THRESHOLD_DEC_4"
+	case RFM_OOK_THRESHOLD_DEC_8:
+		return "RFM_OOK_THRESHOLD_DEC_8"
+	case RFM_OOK_THRESHOLD_DEC_16:
+		return "RFM_OOK_THRESHOLD_DEC_16"
+	default:
+		return "[?? Invalid RFMOOKThresholdDecrement value]"
+	}
+}
+
+func (f RFMOOKAverageFilter) String() string {
+	switch f {
+	case RFM_OOK_AVERAGE_FILTER_32PI:
+		return "RFM_OOK_AVERAGE_FILTER_32PI"
+	case RFM_OOK_AVERAGE_FILTER_8PI:
+		return "RFM_OOK_AVERAGE_FILTER_8PI"
+	case RFM_OOK_AVERAGE_FILTER_4PI:
+		return "RFM_OOK_AVERAGE_FILTER_4PI"
+	case RFM_OOK_AVERAGE_FILTER_2PI:
+		return "RFM_OOK_AVERAGE_FILTER_2PI"
+	default:
+		return "[?? Invalid RFMOOKAverageFilter value]"
+	}
+}
+
+func (r RFMPARamp) String() string {
+	switch r {
+	case RFM_PARAMP_3400US:
+		return "RFM_PARAMP_3400US"
+	case RFM_PARAMP_2000US:
+		return "RFM_PARAMP_2000US"
+	case RFM_PARAMP_1000US:
+		return "RFM_PARAMP_1000US"
+	case RFM_PARAMP_500US:
+		return "RFM_PARAMP_500US"
+	case RFM_PARAMP_250US:
+		return "RFM_PARAMP_250US"
+	case RFM_PARAMP_125US:
+		return "RFM_PARAMP_125US"
+	case RFM_PARAMP_100US:
+		return "RFM_PARAMP_100US"
+	case RFM_PARAMP_62US:
+		return "RFM_PARAMP_62US"
+	case RFM_PARAMP_50US:
+		return "RFM_PARAMP_50US"
+	case RFM_PARAMP_40US:
+		return "RFM_PARAMP_40US"
+	case RFM_PARAMP_31US:
+		return "RFM_PARAMP_31US"
+	case RFM_PARAMP_25US:
+		return "RFM_PARAMP_25US"
+	case RFM_PARAMP_20US:
+		return "RFM_PARAMP_20US"
+	case RFM_PARAMP_15US:
+		return "RFM_PARAMP_15US"
+	case RFM_PARAMP_12US:
+		return "RFM_PARAMP_12US"
+	case RFM_PARAMP_10US:
+		return "RFM_PARAMP_10US"
+	default:
+		return "[?? Invalid RFMPARamp value]"
+	}
+}
+
+func (e RFMAutoModeEnter) String() string {
+	switch e {
+	case RFM_AUTOMODE_ENTER_OFF:
+		return "RFM_AUTOMODE_ENTER_OFF"
+	case RFM_AUTOMODE_ENTER_FIFONOTEMPTY:
+		return "RFM_AUTOMODE_ENTER_FIFONOTEMPTY"
+	case RFM_AUTOMODE_ENTER_FIFOLEVEL:
+		return "RFM_AUTOMODE_ENTER_FIFOLEVEL"
+	case RFM_AUTOMODE_ENTER_CRCOK:
+		return "RFM_AUTOMODE_ENTER_CRCOK"
+	case RFM_AUTOMODE_ENTER_PAYLOADREADY:
+		return "RFM_AUTOMODE_ENTER_PAYLOADREADY"
+	case RFM_AUTOMODE_ENTER_SYNCADDRESS:
+		return "RFM_AUTOMODE_ENTER_SYNCADDRESS"
+	case RFM_AUTOMODE_ENTER_PACKETSENT:
+		return "RFM_AUTOMODE_ENTER_PACKETSENT"
+	case RFM_AUTOMODE_ENTER_FIFOEMPTY:
+		return "RFM_AUTOMODE_ENTER_FIFOEMPTY"
+	default:
+		return "[?? Invalid RFMAutoModeEnter value]"
+	}
+}
+
+func (e RFMAutoModeExit) String() string {
+	switch e {
+	case RFM_AUTOMODE_EXIT_OFF:
+		return "RFM_AUTOMODE_EXIT_OFF"
+	case RFM_AUTOMODE_EXIT_FIFOEMPTY:
+		return "RFM_AUTOMODE_EXIT_FIFOEMPTY"
+	case RFM_AUTOMODE_EXIT_FIFOLEVEL:
+		return "RFM_AUTOMODE_EXIT_FIFOLEVEL"
+	case RFM_AUTOMODE_EXIT_CRCOK:
+		return "RFM_AUTOMODE_EXIT_CRCOK"
+	case RFM_AUTOMODE_EXIT_PAYLOADREADY:
+		return "RFM_AUTOMODE_EXIT_PAYLOADREADY"
+	case RFM_AUTOMODE_EXIT_SYNCADDRESS:
+		return "RFM_AUTOMODE_EXIT_SYNCADDRESS"
+	case RFM_AUTOMODE_EXIT_PACKETSENT:
+		return "RFM_AUTOMODE_EXIT_PACKETSENT"
+	case RFM_AUTOMODE_EXIT_TIMEOUT:
+		return "RFM_AUTOMODE_EXIT_TIMEOUT"
+	default:
+		return "[?? Invalid RFMAutoModeExit value]"
+	}
+}
+
+func (m RFMAutoModeIntermediate) String() string {
+	switch m {
+	case RFM_AUTOMODE_INTERMEDIATE_SLEEP:
+		return "RFM_AUTOMODE_INTERMEDIATE_SLEEP"
+	case RFM_AUTOMODE_INTERMEDIATE_STDBY:
+		return "RFM_AUTOMODE_INTERMEDIATE_STDBY"
+	case RFM_AUTOMODE_INTERMEDIATE_RX:
+		return "RFM_AUTOMODE_INTERMEDIATE_RX"
+	case RFM_AUTOMODE_INTERMEDIATE_TX:
+		return "RFM_AUTOMODE_INTERMEDIATE_TX"
+	default:
+		return "[?? Invalid RFMAutoModeIntermediate value]"
+	}
+}
+
+func (c RFMClockOut) String() string {
+	switch c {
+	case RFM_CLKOUT_FXOSC:
+		return "RFM_CLKOUT_FXOSC"
+	case RFM_CLKOUT_FXOSC_2:
+		return "RFM_CLKOUT_FXOSC_2"
+	case RFM_CLKOUT_FXOSC_4:
+		return "RFM_CLKOUT_FXOSC_4"
+	case RFM_CLKOUT_FXOSC_8:
+		return "RFM_CLKOUT_FXOSC_8"
+	case RFM_CLKOUT_FXOSC_16:
+		return "RFM_CLKOUT_FXOSC_16"
+	case RFM_CLKOUT_FXOSC_32:
+		return "RFM_CLKOUT_FXOSC_32"
+	case RFM_CLKOUT_RC:
+		return "RFM_CLKOUT_RC"
+	case RFM_CLKOUT_OFF:
+		return "RFM_CLKOUT_OFF"
+	default:
+		return "[?? Invalid RFMClockOut value]"
+	}
+}
+
+func (p RFMDIOPin) String() string {
+	switch p {
+	case RFM_DIO0:
+		return "RFM_DIO0"
+	case RFM_DIO1:
+		return "RFM_DIO1"
+	case RFM_DIO2:
+		return "RFM_DIO2"
+	case RFM_DIO3:
+		return "RFM_DIO3"
+	case RFM_DIO4:
+		return "RFM_DIO4"
+	case RFM_DIO5:
+		return "RFM_DIO5"
+	default:
+		return "[?? Invalid RFMDIOPin value]"
+	}
+}
+
+func (f RFMDIOFunction) String() string {
+	switch f {
+	case RFM_DIO_FUNC_NONE:
+		return "RFM_DIO_FUNC_NONE"
+	case RFM_DIO_FUNC_MODEREADY:
+		return "RFM_DIO_FUNC_MODEREADY"
+	case RFM_DIO_FUNC_RXREADY:
+		return "RFM_DIO_FUNC_RXREADY"
+	case RFM_DIO_FUNC_TXREADY:
+		return "RFM_DIO_FUNC_TXREADY"
+	case RFM_DIO_FUNC_PLLLOCK:
+		return "RFM_DIO_FUNC_PLLLOCK"
+	case RFM_DIO_FUNC_RSSI:
+		return "RFM_DIO_FUNC_RSSI"
+	case RFM_DIO_FUNC_TIMEOUT:
+		return "RFM_DIO_FUNC_TIMEOUT"
+	case RFM_DIO_FUNC_AUTOMODE:
+		return "RFM_DIO_FUNC_AUTOMODE"
+	case RFM_DIO_FUNC_SYNCADDRESS:
+		return "RFM_DIO_FUNC_SYNCADDRESS"
+	case RFM_DIO_FUNC_FIFOFULL:
+		return "RFM_DIO_FUNC_FIFOFULL"
+	case RFM_DIO_FUNC_FIFONOTEMPTY:
+		return "RFM_DIO_FUNC_FIFONOTEMPTY"
+	case RFM_DIO_FUNC_FIFOLEVEL:
+		return "RFM_DIO_FUNC_FIFOLEVEL"
+	case RFM_DIO_FUNC_PACKETSENT:
+		return "RFM_DIO_FUNC_PACKETSENT"
+	case RFM_DIO_FUNC_PAYLOADREADY:
+		return "RFM_DIO_FUNC_PAYLOADREADY"
+	case RFM_DIO_FUNC_CRCOK:
+		return "RFM_DIO_FUNC_CRCOK"
+	case RFM_DIO_FUNC_LOWBAT:
+		return "RFM_DIO_FUNC_LOWBAT"
+	case RFM_DIO_FUNC_DATA:
+		return "RFM_DIO_FUNC_DATA"
+	case RFM_DIO_FUNC_CLKOUT:
+		return "RFM_DIO_FUNC_CLKOUT"
+	default:
+		return "[?? Invalid RFMDIOFunction value]"
+	}
+}
+
+func (t RFMLowBatTrim) String() string {
+	switch t {
+	case RFM_LOWBAT_TRIM_1695:
+		return "RFM_LOWBAT_TRIM_1695"
+	case RFM_LOWBAT_TRIM_1764:
+		return "RFM_LOWBAT_TRIM_1764"
+	case RFM_LOWBAT_TRIM_1835:
+		return "RFM_LOWBAT_TRIM_1835"
+	case RFM_LOWBAT_TRIM_1905:
+		return "RFM_LOWBAT_TRIM_1905"
+	case RFM_LOWBAT_TRIM_1976:
+		return "RFM_LOWBAT_TRIM_1976"
+	case RFM_LOWBAT_TRIM_2045:
+		return "RFM_LOWBAT_TRIM_2045"
+	case RFM_LOWBAT_TRIM_2116:
+		return "RFM_LOWBAT_TRIM_2116"
+	case RFM_LOWBAT_TRIM_2185:
+		return "RFM_LOWBAT_TRIM_2185"
+	default:
+		return "[?? Invalid RFMLowBatTrim value]"
+	}
+}
+
+func (f RFMIRQFlags) String() string {
+	flags := make([]string, 0, 15)
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{f.ModeReady, "MODEREADY"},
+		{f.RxReady, "RXREADY"},
+		{f.TxReady, "TXREADY"},
+		{f.PLLLock, "PLLLOCK"},
+		{f.RSSI, "RSSI"},
+		{f.Timeout, "TIMEOUT"},
+		{f.AutoMode, "AUTOMODE"},
+		{f.SyncAddressMatch, "SYNCADDRESSMATCH"},
+		{f.FIFOFull, "FIFOFULL"},
+		{f.FIFONotEmpty, "FIFONOTEMPTY"},
+		{f.FIFOLevel, "FIFOLEVEL"},
+		{f.FIFOOverrun, "FIFOOVERRUN"},
+		{f.PacketSent, "PACKETSENT"},
+		{f.PayloadReady, "PAYLOADREADY"},
+		{f.CRCOk, "CRCOK"},
+		{f.LowBattery, "LOWBAT"},
+	} {
+		if flag.set {
+			flags = append(flags, flag.name)
+		}
+	}
+	return fmt.Sprintf("<sensors.RFMIRQFlags>{ %v }", strings.Join(flags, " "))
+}
+
+func (s RFMFIFOStatus) String() string {
+	return fmt.Sprintf("<sensors.RFMFIFOStatus>{ full=%v not_empty=%v level=%v overrun=%v threshold=%v }", s.Full, s.NotEmpty, s.Level, s.Overrun, s.Threshold)
+}
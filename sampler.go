@@ -0,0 +1,68 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sensors
+
+import (
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// SamplerSettings are the sampling settings for a sensor. When Burst is
+// more than one, that number of readings are taken BurstInterval apart
+// and averaged. When PowerDown is set the sensor is put to sleep between
+// samples, where the sensor supports it. Samples are not emitted until
+// Discard samples have been taken and WarmUp has elapsed since the
+// sensor was added, for sensors which need to stabilize after power-on
+type SamplerSettings struct {
+	Interval      time.Duration
+	Burst         uint
+	BurstInterval time.Duration
+	PowerDown     bool
+	Discard       uint
+	WarmUp        time.Duration
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACES
+
+// Sampler samples locally attached sensors, each at its own interval,
+// and emits SampleEvent values through pubsub. Sensors on the same bus
+// are never sampled at the same time
+type Sampler interface {
+	gopi.Driver
+	gopi.Publisher
+
+	// Add a sensor with a unique name and the name of the bus it is
+	// attached to. The settings are read from the configuration file,
+	// or else the defaults are used
+	AddBME280(name, bus string, device BME280) error
+	AddTSL2561(name, bus string, device TSL2561) error
+
+	// Return and set the settings for a sensor
+	Settings(name string) (SamplerSettings, error)
+	SetSettings(name string, settings SamplerSettings) error
+}
+
+type SampleEvent interface {
+	gopi.Event
+
+	Timestamp() time.Time
+	Sensor() string
+
+	// Return the sampled values by name, for example "temperature",
+	// "pressure", "sealevel", "humidity" or "lux". The BME280 "pressure"
+	// is the station pressure and "sealevel" the sea-level pressure
+	Values() map[string]float64
+}
@@ -0,0 +1,45 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sensors
+
+import (
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACES
+
+// Scheduler switches sockets on and off at set times
+type Scheduler interface {
+	gopi.Driver
+
+	// Return all jobs
+	Jobs() []SchedulerJob
+
+	// Add a job from a rule, for example "socket 2 on at 07:00 weekdays"
+	AddJob(rule string) (SchedulerJob, error)
+
+	// Remove a job
+	RemoveJob(id uint) error
+}
+
+type SchedulerJob interface {
+	// Return the unique job identifier
+	ID() uint
+
+	// Return the rule for the job
+	Rule() string
+
+	// Return the next time the job runs after a time
+	Next(after time.Time) time.Time
+}
@@ -24,6 +24,7 @@ type BME280Mode uint8
 type BME280Filter uint8
 type BME280Standby uint8
 type BME280Oversample uint8
+type BME280PowerPolicy uint8
 
 type TSL2561Gain uint8
 type TSL2561IntegrateTime uint8
@@ -78,6 +79,12 @@ type BME280 interface {
 
 	// Return altitude in meters for given pressure
 	AltitudeForPressure(atmospheric, sealevel float64) float64
+
+	// Return and set the power policy. With the automatic policy the mode
+	// follows the rate at which samples are read, using forced mode and
+	// sleeping between samples at low rates and normal mode at high rates
+	PowerPolicy() BME280PowerPolicy
+	SetPowerPolicy(policy BME280PowerPolicy) error
 }
 
 type TSL2561 interface {
@@ -169,6 +176,12 @@ const (
 	TSL2561_GAIN_MAX TSL2561Gain = 0x01
 )
 
+// BME280 Power policy
+const (
+	BME280_POWER_MANUAL BME280PowerPolicy = iota // Mode is set with SetMode
+	BME280_POWER_AUTO                            // Mode follows the sample rate
+)
+
 ////////////////////////////////////////////////////////////////////////////////
 // ERRORS
 
@@ -179,6 +192,8 @@ var (
 	ErrDeviceTimeout      = errors.New("Device timeout")
 	ErrMessageCorruption  = errors.New("Message Corrupt")
 	ErrMessageCRC         = errors.New("CRC Error")
+	ErrInsufficientData   = errors.New("Insufficient data")
+	ErrWrongType          = errors.New("Wrong parameter or data type")
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -239,6 +254,17 @@ func (t BME280Standby) String() string {
 	}
 }
 
+func (p BME280PowerPolicy) String() string {
+	switch p {
+	case BME280_POWER_MANUAL:
+		return "BME280_POWER_MANUAL"
+	case BME280_POWER_AUTO:
+		return "BME280_POWER_AUTO"
+	default:
+		return "[?? Invalid BME280PowerPolicy value]"
+	}
+}
+
 func (o BME280Oversample) String() string {
 	switch o {
 	case BME280_OVERSAMPLE_SKIP:
@@ -0,0 +1,66 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package altimetry converts between station pressure (QFE), sea-level
+// pressure (QNH) and altitude using the International Standard Atmosphere,
+// so that a sensor at a known station elevation can report both station
+// and sea-level pressure. Pressures are in any unit, as long as the same
+// unit is used throughout, and elevations and altitudes are in metres
+package altimetry
+
+import (
+	"math"
+
+	// Frameworks
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	// Constants of the barometric formula for the troposphere
+	ISA_HEIGHT   = 44330.0 // Height at which the pressure is zero, metres
+	ISA_EXPONENT = 5.255
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC FUNCTIONS
+
+// QNH returns the sea-level pressure for a station pressure measured at
+// an elevation
+func QNH(qfe, elevation float64) float64 {
+	if elevation == 0 {
+		return qfe
+	}
+	return qfe / math.Pow(1.0-elevation/ISA_HEIGHT, ISA_EXPONENT)
+}
+
+// QFE returns the station pressure at an elevation for a sea-level
+// pressure, which is the inverse of QNH
+func QFE(qnh, elevation float64) float64 {
+	if elevation == 0 {
+		return qnh
+	}
+	return qnh * math.Pow(1.0-elevation/ISA_HEIGHT, ISA_EXPONENT)
+}
+
+// Altitude returns the altitude at which the pressure is measured, given
+// the sea-level pressure, which is the inverse of QFE. For a station
+// pressure and the QNH reported by a nearby weather station at the same
+// time, it returns the station elevation
+func Altitude(pressure, qnh float64) float64 {
+	return ISA_HEIGHT * (1.0 - math.Pow(pressure/qnh, 1.0/ISA_EXPONENT))
+}
+
+// PressureAltitude returns the altitude for a pressure in hPa in the
+// standard atmosphere, with a sea-level pressure of 1013.25hPa
+func PressureAltitude(pressure float64) float64 {
+	return Altitude(pressure, sensors.BME280_PRESSURE_SEALEVEL)
+}
@@ -0,0 +1,242 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package anomaly implements a simple statistical detector which flags
+// readings more than a number of standard deviations from the baseline.
+// The baseline is kept either for the whole series or for each hour of
+// the day, so that daily cycles (heating, cooking) are not flagged
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	evt "github.com/djthorpe/gopi/util/event"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Detector struct {
+	Threshold  float64 // Number of standard deviations which is an anomaly
+	MinSamples uint    // Number of samples required before values are flagged
+	MinStdDev  float64 // Smallest standard deviation used to score values
+	Hourly     bool    // Keep a separate baseline for each hour of the day
+}
+
+// detector driver
+type detector struct {
+	log        gopi.Logger
+	threshold  float64
+	minsamples uint
+	minstddev  float64
+	hourly     bool
+	series     map[string]*[24]baseline
+	pubsub     *evt.PubSub
+	lock       sync.Mutex
+}
+
+// baseline is the running mean and variance of a series
+type baseline struct {
+	n    uint
+	mean float64
+	m2   float64
+}
+
+type anomaly_event struct {
+	driver *detector
+	ts     time.Time
+	series string
+	value  float64
+	mean   float64
+	score  float64
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	THRESHOLD_DEFAULT  = 3.0
+	MINSAMPLES_DEFAULT = 30
+	MINSTDDEV_DEFAULT  = 0.1
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Detector) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug2("<sensors.AnomalyDetector>Open{ threshold=%v minsamples=%v minstddev=%v hourly=%v }", config.Threshold, config.MinSamples, config.MinStdDev, config.Hourly)
+
+	if config.Threshold < 0 || config.MinStdDev < 0 {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(detector)
+	this.log = log
+	this.threshold = config.Threshold
+	this.minsamples = config.MinSamples
+	this.minstddev = config.MinStdDev
+	this.hourly = config.Hourly
+	if this.threshold == 0 {
+		this.threshold = THRESHOLD_DEFAULT
+	}
+	if this.minsamples == 0 {
+		this.minsamples = MINSAMPLES_DEFAULT
+	}
+	if this.minstddev == 0 {
+		this.minstddev = MINSTDDEV_DEFAULT
+	}
+	this.series = make(map[string]*[24]baseline)
+	this.pubsub = evt.NewPubSub(0)
+
+	// Return success
+	return this, nil
+}
+
+func (this *detector) Close() error {
+	this.log.Debug2("<sensors.AnomalyDetector>Close{ }")
+
+	// Close subscriber channels
+	this.pubsub.Close()
+
+	// Free resources
+	this.series = nil
+	this.pubsub = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *detector) String() string {
+	return fmt.Sprintf("<sensors.AnomalyDetector>{ threshold=%v minsamples=%v minstddev=%v hourly=%v series=%v }", this.threshold, this.minsamples, this.minstddev, this.hourly, len(this.series))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *detector) Add(series string, ts time.Time, value float64) bool {
+	this.lock.Lock()
+
+	// Get the baseline for the series and hour
+	baselines, exists := this.series[series]
+	if exists == false {
+		baselines = new([24]baseline)
+		this.series[series] = baselines
+	}
+	b := &baselines[0]
+	if this.hourly {
+		b = &baselines[ts.Hour()]
+	}
+
+	// Score the value against the baseline before adding it, so that
+	// an anomaly doesn't hide itself
+	mean, score := b.mean, b.score(value, this.minstddev)
+	anomaly := b.n >= this.minsamples && math.Abs(score) >= this.threshold
+	b.add(value)
+
+	// Emit the event after unlocking, so that a slow subscriber doesn't
+	// block other series from being added
+	pubsub := this.pubsub
+	this.lock.Unlock()
+	if anomaly {
+		this.log.Debug("<sensors.AnomalyDetector.Add>{ series=\"%v\" value=%v mean=%v score=%.2f }", series, value, mean, score)
+		pubsub.Emit(&anomaly_event{
+			driver: this,
+			ts:     ts,
+			series: series,
+			value:  value,
+			mean:   mean,
+			score:  score,
+		})
+	}
+
+	return anomaly
+}
+
+func (this *detector) Reset(series string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.series, series)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BASELINE
+
+// Add a value using Welford's algorithm
+func (this *baseline) add(value float64) {
+	this.n++
+	delta := value - this.mean
+	this.mean += delta / float64(this.n)
+	this.m2 += delta * (value - this.mean)
+}
+
+// Return the number of standard deviations a value is from the mean. The
+// standard deviation is at least minstddev, so that a change in a series
+// which hasn't varied is still scored
+func (this *baseline) score(value, minstddev float64) float64 {
+	if this.n < 2 {
+		return 0
+	} else {
+		stddev := math.Max(math.Sqrt(this.m2/float64(this.n-1)), minstddev)
+		return (value - this.mean) / stddev
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBSUB
+
+func (this *detector) Subscribe() <-chan gopi.Event {
+	return this.pubsub.Subscribe()
+}
+
+func (this *detector) Unsubscribe(subscriber <-chan gopi.Event) {
+	this.pubsub.Unsubscribe(subscriber)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - anomaly_event
+
+func (this *anomaly_event) Name() string {
+	return "AnomalyEvent"
+}
+
+func (this *anomaly_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *anomaly_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *anomaly_event) Series() string {
+	return this.series
+}
+
+func (this *anomaly_event) Value() float64 {
+	return this.value
+}
+
+func (this *anomaly_event) Mean() float64 {
+	return this.mean
+}
+
+func (this *anomaly_event) Score() float64 {
+	return this.score
+}
+
+func (this *anomaly_event) String() string {
+	return fmt.Sprintf("<sensors.AnomalyEvent>{ ts=%v series=\"%v\" value=%v mean=%v score=%.2f }", this.ts.Format(time.Stamp), this.series, this.value, this.mean, this.score)
+}
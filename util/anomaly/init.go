@@ -0,0 +1,44 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package anomaly
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register anomaly detector
+	gopi.RegisterModule(gopi.Module{
+		Name: "sensors/anomaly",
+		Type: gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagFloat64("anomaly.threshold", THRESHOLD_DEFAULT, "Anomaly threshold (standard deviations)")
+			config.AppFlags.FlagUint("anomaly.samples", MINSAMPLES_DEFAULT, "Minimum samples before flagging anomalies")
+			config.AppFlags.FlagFloat64("anomaly.minstddev", MINSTDDEV_DEFAULT, "Smallest standard deviation used to score values")
+			config.AppFlags.FlagBool("anomaly.hourly", false, "Separate baseline for each hour of the day")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			threshold, _ := app.AppFlags.GetFloat64("anomaly.threshold")
+			samples, _ := app.AppFlags.GetUint("anomaly.samples")
+			minstddev, _ := app.AppFlags.GetFloat64("anomaly.minstddev")
+			hourly, _ := app.AppFlags.GetBool("anomaly.hourly")
+			return gopi.Open(Detector{
+				Threshold:  threshold,
+				MinSamples: samples,
+				MinStdDev:  minstddev,
+				Hourly:     hourly,
+			}, app.Logger)
+		},
+	})
+}
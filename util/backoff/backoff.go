@@ -0,0 +1,132 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package backoff provides retry policies with jittered exponential
+// backoff, so that each component which retries an operation can be
+// configured in the same way
+package backoff
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Policy determines how many times an operation is retried and the delay
+// before each retry. The delay before retry n (from zero) is
+//
+//	delay = min(Initial * Multiplier^n, Max) +/- Jitter * delay
+//
+// where the jitter is chosen at random for each retry
+type Policy struct {
+	Retries    uint          // Number of retries after the first attempt
+	Initial    time.Duration // Delay before the first retry
+	Max        time.Duration // Maximum delay, or zero for no maximum
+	Multiplier float64       // Factor applied to the delay for each retry, or zero for 2
+	Jitter     float64       // Fraction of the delay to randomise, between zero and one
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	MULTIPLIER_DEFAULT = 2.0
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Make one attempt without retrying
+	NONE = Policy{}
+
+	// Policy for operations on a local bus, such as I2C or SPI
+	BUS = Policy{Retries: 3, Initial: time.Millisecond, Max: 20 * time.Millisecond, Jitter: 0.2}
+
+	// Policy for connections to a network service
+	NETWORK = Policy{Retries: 10, Initial: 500 * time.Millisecond, Max: 30 * time.Second, Jitter: 0.5}
+
+	// Random source for jitter, which is not safe for concurrent use
+	random      = rand.New(rand.NewSource(time.Now().UnixNano()))
+	random_lock sync.Mutex
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Zero returns true if the policy has not been set
+func (p Policy) Zero() bool {
+	return p == Policy{}
+}
+
+// Delay returns the delay before a retry, where the first retry is zero
+func (p Policy) Delay(retry uint) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = MULTIPLIER_DEFAULT
+	}
+	delay := float64(p.Initial) * math.Pow(multiplier, float64(retry))
+	if p.Max > 0 && delay > float64(p.Max) {
+		delay = float64(p.Max)
+	}
+	if p.Jitter > 0 {
+		random_lock.Lock()
+		delay += delay * p.Jitter * (2*random.Float64() - 1)
+		random_lock.Unlock()
+	}
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// Wait blocks for the delay before a retry, and returns false if the
+// context is done first
+func (p Policy) Wait(ctx context.Context, retry uint) bool {
+	if delay := p.Delay(retry); delay <= 0 {
+		return ctx.Err() == nil
+	} else {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// Retry calls a function until it returns nil, the retries have been
+// used up or the context is done. It returns the last error from the
+// function, or the context error if the context was done first
+func (p Policy) Retry(ctx context.Context, fn func() error) error {
+	for retry := uint(0); ; retry++ {
+		if err := fn(); err == nil {
+			return nil
+		} else if retry >= p.Retries {
+			return err
+		} else if p.Wait(ctx, retry) == false {
+			return ctx.Err()
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (p Policy) String() string {
+	return fmt.Sprintf("<backoff.Policy>{ retries=%v initial=%v max=%v multiplier=%v jitter=%v }", p.Retries, p.Initial, p.Max, p.Multiplier, p.Jitter)
+}
@@ -0,0 +1,223 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package carbon implements the CarbonIntensity virtual sensor using
+// the UK Carbon Intensity API (https://carbonintensity.org.uk/)
+package carbon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Carbon struct {
+	Endpoint string        // API endpoint, or empty for the default
+	Region   uint          // Region identifier, or zero for national figures
+	Timeout  time.Duration // HTTP request timeout
+	Cache    time.Duration // Duration to cache the current intensity
+}
+
+// carbon driver
+type carbon struct {
+	log      gopi.Logger
+	endpoint string
+	region   uint
+	client   *http.Client
+	cache    time.Duration
+	current  *sensors.CarbonIntensityPeriod
+	ts       time.Time
+	lock     sync.Mutex
+}
+
+// API response
+type api_response struct {
+	Data json.RawMessage `json:"data"`
+}
+
+type api_region struct {
+	RegionID uint         `json:"regionid"`
+	Data     []api_period `json:"data"`
+}
+
+type api_period struct {
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Intensity api_intensity `json:"intensity"`
+}
+
+type api_intensity struct {
+	Forecast uint   `json:"forecast"`
+	Actual   uint   `json:"actual"`
+	Index    string `json:"index"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	ENDPOINT_DEFAULT = "https://api.carbonintensity.org.uk"
+	TIMEOUT_DEFAULT  = 10 * time.Second
+	CACHE_DEFAULT    = 5 * time.Minute
+	TIME_FORMAT      = "2006-01-02T15:04Z"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Carbon) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug2("<sensors.CarbonIntensity>Open{ endpoint=\"%v\" region=%v }", config.Endpoint, config.Region)
+
+	this := new(carbon)
+	this.log = log
+	this.endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	this.region = config.Region
+	this.cache = config.Cache
+	if this.endpoint == "" {
+		this.endpoint = ENDPOINT_DEFAULT
+	}
+	if config.Timeout == 0 {
+		config.Timeout = TIMEOUT_DEFAULT
+	}
+	if this.cache == 0 {
+		this.cache = CACHE_DEFAULT
+	}
+	this.client = &http.Client{Timeout: config.Timeout}
+
+	// Return success
+	return this, nil
+}
+
+func (this *carbon) Close() error {
+	this.log.Debug2("<sensors.CarbonIntensity>Close{ }")
+
+	// Free resources
+	this.client = nil
+	this.current = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *carbon) String() string {
+	return fmt.Sprintf("<sensors.CarbonIntensity>{ endpoint=\"%v\" region=%v }", this.endpoint, this.region)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *carbon) Intensity() (sensors.CarbonIntensityPeriod, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Return cached value
+	if this.current != nil && time.Since(this.ts) < this.cache {
+		return *this.current, nil
+	}
+
+	path := "/intensity"
+	if this.region != 0 {
+		path = fmt.Sprintf("/regional/regionid/%v", this.region)
+	}
+	if periods, err := this.get(path); err != nil {
+		return sensors.CarbonIntensityPeriod{}, err
+	} else if len(periods) == 0 {
+		return sensors.CarbonIntensityPeriod{}, sensors.ErrUnexpectedResponse
+	} else {
+		this.current = &periods[0]
+		this.ts = time.Now()
+		return periods[0], nil
+	}
+}
+
+func (this *carbon) Forecast() ([]sensors.CarbonIntensityPeriod, error) {
+	from := time.Now().UTC().Format(TIME_FORMAT)
+	path := fmt.Sprintf("/intensity/%v/fw24h", from)
+	if this.region != 0 {
+		path = fmt.Sprintf("/regional/intensity/%v/fw24h/regionid/%v", from, this.region)
+	}
+	return this.get(path)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *carbon) get(path string) ([]sensors.CarbonIntensityPeriod, error) {
+	this.log.Debug2("<sensors.CarbonIntensity.Get>{ path=\"%v\" }", path)
+
+	request, err := http.NewRequest("GET", this.endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := this.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CarbonIntensity: %v", response.Status)
+	}
+
+	var body api_response
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	// Regional responses wrap the periods in a region, and the data
+	// is either an object or an array depending on the endpoint
+	var periods []api_period
+	var regions []api_region
+	var region api_region
+	if this.region == 0 {
+		if err := json.Unmarshal(body.Data, &periods); err != nil {
+			return nil, err
+		}
+	} else if err := json.Unmarshal(body.Data, &regions); err == nil {
+		for _, r := range regions {
+			periods = append(periods, r.Data...)
+		}
+	} else if err := json.Unmarshal(body.Data, &region); err == nil {
+		periods = region.Data
+	} else {
+		return nil, err
+	}
+
+	// Convert periods
+	result := make([]sensors.CarbonIntensityPeriod, 0, len(periods))
+	for _, p := range periods {
+		if from, err := time.Parse(TIME_FORMAT, p.From); err != nil {
+			return nil, err
+		} else if to, err := time.Parse(TIME_FORMAT, p.To); err != nil {
+			return nil, err
+		} else {
+			result = append(result, sensors.CarbonIntensityPeriod{
+				From:     from,
+				To:       to,
+				Forecast: p.Intensity.Forecast,
+				Actual:   p.Intensity.Actual,
+				Index:    p.Intensity.Index,
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,38 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package carbon
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register carbon intensity virtual sensor
+	gopi.RegisterModule(gopi.Module{
+		Name: "sensors/carbon",
+		Type: gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagString("carbon.endpoint", ENDPOINT_DEFAULT, "Carbon Intensity API endpoint")
+			config.AppFlags.FlagUint("carbon.region", 0, "Carbon Intensity region (0 for national)")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			endpoint, _ := app.AppFlags.GetString("carbon.endpoint")
+			region, _ := app.AppFlags.GetUint("carbon.region")
+			return gopi.Open(Carbon{
+				Endpoint: endpoint,
+				Region:   region,
+			}, app.Logger)
+		},
+	})
+}
@@ -0,0 +1,202 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package disaggregate estimates the power drawn by appliances on switched
+// sockets by correlating step changes in whole-house power with the times
+// the sockets were switched on and off
+package disaggregate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Estimator correlates power readings with socket switch events
+type Estimator struct {
+	window  time.Duration
+	samples []sample
+	pending []event
+	sockets map[uint]*accumulator
+	lock    sync.Mutex
+}
+
+// Estimate is the power drawn by the appliance on a socket
+type Estimate struct {
+	Socket     uint    `json:"socket"`
+	Watts      float64 `json:"watts"`
+	Confidence float64 `json:"confidence"` // Between zero and one
+	Samples    uint    `json:"samples"`    // Number of switch events used
+}
+
+type sample struct {
+	ts    time.Time
+	watts float64
+}
+
+type event struct {
+	ts     time.Time
+	socket uint
+	on     bool
+}
+
+type accumulator struct {
+	n    uint
+	mean float64
+	m2   float64
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	WINDOW_DEFAULT = 30 * time.Second
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// NEW
+
+// NewEstimator returns an estimator which compares the mean power in
+// the window before a switch event with the window after it
+func NewEstimator(window time.Duration) *Estimator {
+	if window <= 0 {
+		window = WINDOW_DEFAULT
+	}
+	return &Estimator{
+		window:  window,
+		samples: make([]sample, 0),
+		pending: make([]event, 0),
+		sockets: make(map[uint]*accumulator),
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// AddPower adds a whole-house power reading in watts
+func (this *Estimator) AddPower(ts time.Time, watts float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.samples = append(this.samples, sample{ts, watts})
+
+	// Process switch events whose window has elapsed
+	pending := this.pending[:0]
+	for _, e := range this.pending {
+		if ts.Sub(e.ts) < this.window {
+			pending = append(pending, e)
+		} else if delta, ok := this.delta(e.ts); ok {
+			if e.on == false {
+				delta = -delta
+			}
+			this.accumulate(e.socket, delta)
+		}
+	}
+	this.pending = pending
+
+	// Discard samples which are no longer needed
+	oldest := ts.Add(-this.window)
+	for _, e := range this.pending {
+		if t := e.ts.Add(-this.window); t.Before(oldest) {
+			oldest = t
+		}
+	}
+	i := 0
+	for i < len(this.samples) && this.samples[i].ts.Before(oldest) {
+		i++
+	}
+	this.samples = this.samples[i:]
+}
+
+// AddSwitch records a socket being switched on or off
+func (this *Estimator) AddSwitch(ts time.Time, socket uint, on bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.pending = append(this.pending, event{ts, socket, on})
+}
+
+// Estimates returns the estimated power for each socket, ordered by socket
+func (this *Estimator) Estimates() []Estimate {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	estimates := make([]Estimate, 0, len(this.sockets))
+	for socket, a := range this.sockets {
+		estimates = append(estimates, Estimate{
+			Socket:     socket,
+			Watts:      a.mean,
+			Confidence: a.confidence(),
+			Samples:    a.n,
+		})
+	}
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].Socket < estimates[j].Socket
+	})
+	return estimates
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (e Estimate) String() string {
+	return fmt.Sprintf("<sensors.disaggregate.Estimate>{ socket=%v watts=%.1f confidence=%.2f samples=%v }", e.Socket, e.Watts, e.Confidence, e.Samples)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Return the change in mean power across a time
+func (this *Estimator) delta(ts time.Time) (float64, bool) {
+	var before, after float64
+	var n_before, n_after int
+	for _, s := range this.samples {
+		switch {
+		case s.ts.Before(ts.Add(-this.window)), s.ts.After(ts.Add(this.window)):
+			continue
+		case s.ts.Before(ts):
+			before += s.watts
+			n_before++
+		case s.ts.After(ts):
+			after += s.watts
+			n_after++
+		}
+	}
+	if n_before == 0 || n_after == 0 {
+		return 0, false
+	}
+	return after/float64(n_after) - before/float64(n_before), true
+}
+
+func (this *Estimator) accumulate(socket uint, delta float64) {
+	a, exists := this.sockets[socket]
+	if exists == false {
+		a = new(accumulator)
+		this.sockets[socket] = a
+	}
+	a.n++
+	d := delta - a.mean
+	a.mean += d / float64(a.n)
+	a.m2 += d * (delta - a.mean)
+}
+
+// Confidence is derived from the standard error relative to the mean,
+// and is zero until there are at least two samples
+func (this *accumulator) confidence() float64 {
+	if this.n < 2 || this.mean == 0 {
+		return 0
+	}
+	stderr := math.Sqrt(this.m2/float64(this.n-1)) / math.Sqrt(float64(this.n))
+	return math.Max(0, 1-stderr/math.Abs(this.mean))
+}
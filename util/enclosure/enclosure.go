@@ -0,0 +1,172 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package enclosure compensates readings from sensors which are housed in
+// the same case as the Raspberry Pi, so that they reflect room conditions
+// rather than the temperature inside the case
+package enclosure
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// Model is the thermal model of the enclosure. The room temperature is
+// derived from the measured temperature as:
+//
+//	room = measured - Offset - Factor * (cpu - measured) - LoadFactor * load
+//
+// where cpu is the CPU temperature and load is the one minute load average
+type Model struct {
+	Offset     float64 // Fixed offset, in Celcius
+	Factor     float64 // Fraction of the CPU to sensor temperature difference
+	LoadFactor float64 // Celcius per unit of load average
+}
+
+// Enclosure is the driver returned by the sensors/enclosure module, which
+// returns the model set by the enclosure flags
+type Enclosure interface {
+	gopi.Driver
+
+	// Return the thermal model of the enclosure
+	Model() Model
+}
+
+// enclosure driver
+type enclosure struct {
+	log   gopi.Logger
+	model Model
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	PATH_THERMAL = "/sys/class/thermal/thermal_zone0/temp"
+	PATH_LOADAVG = "/proc/loadavg"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Model) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug("<sensors.enclosure>Open{ model=%v }", config)
+
+	this := new(enclosure)
+	this.log = log
+	this.model = config
+
+	// Return success
+	return this, nil
+}
+
+func (this *enclosure) Close() error {
+	this.log.Debug("<sensors.enclosure>Close{ model=%v }", this.model)
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Return the thermal model of the enclosure
+func (this *enclosure) Model() Model {
+	return this.model
+}
+
+// Zero returns true if the model does not alter readings
+func (this Model) Zero() bool {
+	return this.Offset == 0 && this.Factor == 0 && this.LoadFactor == 0
+}
+
+// Compensate returns temperature and relative humidity for the room given
+// the temperature (Celcius) and relative humidity (%age) measured in the
+// enclosure
+func (this Model) Compensate(temperature, humidity float64) (float64, float64, error) {
+	if this.Zero() {
+		return temperature, humidity, nil
+	}
+
+	room := temperature - this.Offset
+	if this.Factor != 0 {
+		if cpu, err := CPUTemperature(); err != nil {
+			return 0, 0, err
+		} else {
+			room -= this.Factor * (cpu - temperature)
+		}
+	}
+	if this.LoadFactor != 0 {
+		if load, err := LoadAverage(); err != nil {
+			return 0, 0, err
+		} else {
+			room -= this.LoadFactor * load
+		}
+	}
+
+	// The absolute humidity is the same in the room as in the enclosure,
+	// so scale relative humidity by the ratio of saturation vapour pressures
+	if humidity > 0 {
+		humidity = math.Min(humidity*saturationPressure(temperature)/saturationPressure(room), 100)
+	}
+
+	return room, humidity, nil
+}
+
+// CPUTemperature returns the CPU temperature in Celcius
+func CPUTemperature() (float64, error) {
+	if value, err := readField(PATH_THERMAL); err != nil {
+		return 0, err
+	} else {
+		// Value is in millidegrees
+		return value / 1000, nil
+	}
+}
+
+// LoadAverage returns the one minute load average
+func LoadAverage() (float64, error) {
+	return readField(PATH_LOADAVG)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *enclosure) String() string {
+	return fmt.Sprintf("<sensors.enclosure>{ model=%v }", this.model)
+}
+
+func (this Model) String() string {
+	return fmt.Sprintf("<sensors.enclosure.Model>{ offset=%v factor=%v loadfactor=%v }", this.Offset, this.Factor, this.LoadFactor)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// Magnus formula for saturation vapour pressure in hPa
+func saturationPressure(celcius float64) float64 {
+	return 6.112 * math.Exp(17.62*celcius/(243.12+celcius))
+}
+
+// Read the first field of a file as a number
+func readField(path string) (float64, error) {
+	if data, err := ioutil.ReadFile(path); err != nil {
+		return 0, err
+	} else if fields := strings.Fields(string(data)); len(fields) == 0 {
+		return 0, fmt.Errorf("%v: no value", path)
+	} else {
+		return strconv.ParseFloat(fields[0], 64)
+	}
+}
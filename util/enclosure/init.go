@@ -0,0 +1,42 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package enclosure
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register the enclosure model, which is shared by the sensors housed
+	// in the enclosure
+	gopi.RegisterModule(gopi.Module{
+		Name: "sensors/enclosure",
+		Type: gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagFloat64("enclosure.offset", 0, "Enclosure temperature offset, Celcius")
+			config.AppFlags.FlagFloat64("enclosure.factor", 0, "Enclosure CPU temperature factor")
+			config.AppFlags.FlagFloat64("enclosure.load", 0, "Enclosure load average factor, Celcius")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			offset, _ := app.AppFlags.GetFloat64("enclosure.offset")
+			factor, _ := app.AppFlags.GetFloat64("enclosure.factor")
+			load, _ := app.AppFlags.GetFloat64("enclosure.load")
+			return gopi.Open(Model{
+				Offset:     offset,
+				Factor:     factor,
+				LoadFactor: load,
+			}, app.Logger)
+		},
+	})
+}
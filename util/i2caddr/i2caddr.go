@@ -0,0 +1,234 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package i2caddr keeps track of the I2C addresses claimed by drivers, so
+// that two drivers configured with the same address fail with a specific
+// error, and identifies the chip at an address from its ID register so
+// that a driver can select its address when it is not configured, or
+// report which chip was found instead
+package i2caddr
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// ConflictError is returned when an address is already claimed
+type ConflictError struct {
+	Slave   uint8
+	Driver  string
+	Claimed string
+}
+
+// MismatchError is returned when the chip found is not the chip the
+// driver expects
+type MismatchError struct {
+	Slave    uint8
+	Expected string
+	Found    string
+	ChipID   uint8
+}
+
+// AmbiguousError is returned when the chip is found at more than one
+// address, and the address needs to be configured
+type AmbiguousError struct {
+	Chip   string
+	Slaves []uint8
+}
+
+// chip is a family of chips which share addresses and an ID register
+type chip struct {
+	slaves []uint8
+	reg    uint8
+	mask   uint8
+	names  map[uint8]string
+}
+
+// claim is an address on a bus
+type claim struct {
+	bus   gopi.I2C
+	slave uint8
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Chips which can be identified by reading an ID register
+	CHIPS = []chip{
+		{[]uint8{0x76, 0x77}, 0xD0, 0xFF, map[uint8]string{
+			0x55: "BMP180",
+			0x56: "BMP280",
+			0x57: "BMP280",
+			0x58: "BMP280",
+			0x60: "BME280",
+			0x61: "BME680",
+		}},
+		{[]uint8{0x29, 0x39, 0x49}, 0x8A, 0xF0, map[uint8]string{
+			0x00: "TSL2560",
+			0x10: "TSL2561",
+			0x40: "TSL2560",
+			0x50: "TSL2561",
+		}},
+	}
+
+	claims = make(map[claim]string)
+	lock   sync.Mutex
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// CLAIMS
+
+// Claim an address on a bus for a driver, or return a ConflictError
+// if another driver has already claimed it
+func Claim(bus gopi.I2C, slave uint8, driver string) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	key := claim{bus, slave}
+	if claimed, exists := claims[key]; exists {
+		return &ConflictError{slave, driver, claimed}
+	}
+	claims[key] = driver
+	return nil
+}
+
+// Release an address on a bus
+func Release(bus gopi.I2C, slave uint8) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(claims, claim{bus, slave})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// IDENTIFY
+
+// Identify returns the name of the chip at an address and the value of
+// its ID register, or an empty name if the address is not one of a known
+// chip or the ID is not recognised. The slave address of the bus is
+// left set to the address
+func Identify(bus gopi.I2C, slave uint8) (string, uint8, error) {
+	for _, c := range CHIPS {
+		if c.hasSlave(slave) == false {
+			continue
+		}
+		if err := bus.SetSlave(slave); err != nil {
+			return "", 0, err
+		} else if value, err := bus.ReadUint8(c.reg); err != nil {
+			return "", 0, err
+		} else {
+			return c.names[value&c.mask], value, nil
+		}
+	}
+	return "", 0, nil
+}
+
+// Select returns the address of a chip, probing each of the candidate
+// addresses which has not been claimed. It returns sensors.ErrNoDevice
+// when nothing responds, a MismatchError when only other chips respond,
+// and an AmbiguousError when the chip responds at more than one address
+func Select(bus gopi.I2C, candidates []uint8, name string) (uint8, error) {
+	found := make([]uint8, 0, len(candidates))
+	var mismatch *MismatchError
+	for _, slave := range candidates {
+		if isClaimed(bus, slave) {
+			continue
+		} else if detected, err := bus.DetectSlave(slave); err != nil {
+			return 0, err
+		} else if detected == false {
+			continue
+		} else if found_name, chip_id, err := Identify(bus, slave); err != nil {
+			return 0, err
+		} else if found_name == name || found_name == "" && isKnown(name, slave) == false {
+			found = append(found, slave)
+		} else if mismatch == nil {
+			mismatch = &MismatchError{slave, name, found_name, chip_id}
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) > 1:
+		return 0, &AmbiguousError{name, found}
+	case mismatch != nil:
+		return 0, mismatch
+	default:
+		return 0, sensors.ErrNoDevice
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *ConflictError) Error() string {
+	return fmt.Sprintf("I2C address 0x%02X is used by both %v and %v, configure a different address for one of them", this.Slave, this.Claimed, this.Driver)
+}
+
+func (this *MismatchError) Error() string {
+	if this.Found == "" {
+		return fmt.Sprintf("Unknown chip at I2C address 0x%02X (chip_id 0x%02X), expected %v", this.Slave, this.ChipID, this.Expected)
+	} else {
+		return fmt.Sprintf("Found %v at I2C address 0x%02X (chip_id 0x%02X), expected %v: use the %v driver", this.Found, this.Slave, this.ChipID, this.Expected, this.Found)
+	}
+}
+
+func (this *AmbiguousError) Error() string {
+	slaves := make([]string, len(this.Slaves))
+	for i, slave := range this.Slaves {
+		slaves[i] = fmt.Sprintf("0x%02X", slave)
+	}
+	return fmt.Sprintf("Found %v at I2C addresses %v, configure which one to use", this.Chip, strings.Join(slaves, ", "))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this chip) hasSlave(slave uint8) bool {
+	for _, s := range this.slaves {
+		if s == slave {
+			return true
+		}
+	}
+	return false
+}
+
+func (this chip) hasName(name string) bool {
+	for _, n := range this.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isKnown returns true if a chip name can be identified at an address,
+// in which case an unrecognised ID is a mismatch
+func isKnown(name string, slave uint8) bool {
+	for _, c := range CHIPS {
+		if c.hasSlave(slave) && c.hasName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isClaimed(bus gopi.I2C, slave uint8) bool {
+	lock.Lock()
+	defer lock.Unlock()
+	_, exists := claims[claim{bus, slave}]
+	return exists
+}
@@ -0,0 +1,49 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package otreport
+
+import (
+	"errors"
+	"fmt"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register collector of unknown OpenThings records
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/otreport",
+		Requires: []string{"sensors/mihome"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagString("otreport.file", "", "Report file for unknown OpenThings records")
+			config.AppFlags.FlagDuration("otreport.interval", INTERVAL_DEFAULT, "Interval between writes of the report file")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			if mihome, ok := app.ModuleInstance("sensors/mihome").(sensors.MiHome); !ok {
+				return nil, fmt.Errorf("Missing or invalid MiHome module")
+			} else if path, _ := app.AppFlags.GetString("otreport.file"); path == "" {
+				return nil, errors.New("Missing -otreport.file flag")
+			} else {
+				interval, _ := app.AppFlags.GetDuration("otreport.interval")
+				return gopi.Open(Collector{
+					MiHome:   mihome,
+					Path:     path,
+					Interval: interval,
+				}, app.Logger)
+			}
+		},
+	})
+}
@@ -0,0 +1,188 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package otreport collects the unknown parameters in received OpenThings
+// messages into a local report file, which users can share to help add
+// support for new Energenie products. Only the shape of each unknown
+// record is kept: the manufacturer, product, parameter, data type and
+// size, with a count and the days it was first and last seen. Sensor IDs
+// and values are never recorded. Reports from several users can be
+// merged with the otreport command
+package otreport
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Collector struct {
+	MiHome   sensors.MiHome // Device to receive messages from
+	Path     string         // Report file, which is added to when it exists
+	Interval time.Duration  // Interval between writes of the report file, or zero for default
+}
+
+// collector driver
+type collector struct {
+	log      gopi.Logger
+	mihome   sensors.MiHome
+	path     string
+	interval time.Duration
+	report   *Report
+	changed  bool
+	events   <-chan sensors.OTEvent
+	done     chan struct{}
+	wait     sync.WaitGroup
+	lock     sync.Mutex
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	INTERVAL_DEFAULT = 10 * time.Minute
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Collector) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug("<sensors.otreport>Open{ path=\"%v\" interval=%v }", config.Path, config.Interval)
+
+	if config.MiHome == nil || config.Path == "" {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(collector)
+	this.log = log
+	this.mihome = config.MiHome
+	this.path = config.Path
+	this.interval = config.Interval
+	if this.interval == 0 {
+		this.interval = INTERVAL_DEFAULT
+	}
+
+	// Read the existing report, so that collection continues between runs
+	if report, err := ReadReport(this.path); err != nil {
+		return nil, err
+	} else {
+		this.report = report
+	}
+
+	// Collect unknown records in the background
+	this.events = this.mihome.SubscribeOTEvent()
+	this.done = make(chan struct{})
+	this.wait.Add(1)
+	go this.run()
+
+	// Return success
+	return this, nil
+}
+
+func (this *collector) Close() error {
+	this.log.Debug("<sensors.otreport>Close{ path=\"%v\" }", this.path)
+
+	// Stop collecting
+	this.mihome.UnsubscribeOTEvent(this.events)
+	close(this.done)
+	this.wait.Wait()
+
+	// Write the report
+	err := this.write()
+
+	// Free resources
+	this.mihome = nil
+	this.report = nil
+	this.events = nil
+
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *collector) String() string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return fmt.Sprintf("<sensors.otreport>{ path=\"%v\" interval=%v records=%v }", this.path, this.interval, len(this.report.Records))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// Add the unknown records of a message to the report
+func (this *collector) Add(ts time.Time, message sensors.OTMessage) {
+	if message == nil {
+		return
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.report.Add(ts, message) > 0 {
+		this.changed = true
+	}
+}
+
+// Report returns a copy of the report
+func (this *collector) Report() *Report {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	report := NewReport()
+	report.Merge(this.report)
+	return report
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *collector) run() {
+	defer this.wait.Done()
+	ticker := time.NewTicker(this.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-this.done:
+			return
+		case <-ticker.C:
+			if err := this.write(); err != nil {
+				this.log.Error("<sensors.otreport>: %v", err)
+			}
+		case event, ok := <-this.events:
+			if ok == false {
+				return
+			} else if event.Reason() == nil {
+				// Only messages which decoded without error are collected,
+				// as unknown parameters in corrupted messages are noise
+				this.Add(event.Timestamp(), event.Message())
+			}
+		}
+	}
+}
+
+// write the report file when it has changed
+func (this *collector) write() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.changed == false {
+		return nil
+	} else if err := WriteReport(this.path, this.report); err != nil {
+		return err
+	} else {
+		this.changed = false
+		return nil
+	}
+}
@@ -0,0 +1,197 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package otreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Report is a shareable record of the unknown OpenThings parameters which
+// have been received. It contains no sensor IDs or values, only the shape
+// of each record and the product which sent it
+type Report struct {
+	Version int             `json:"version"`
+	Records []UnknownRecord `json:"records"`
+}
+
+// UnknownRecord is an unknown parameter received from a product, with
+// the data type and size of the record. Dates are truncated to the day
+type UnknownRecord struct {
+	Manufacturer uint8     `json:"manufacturer"`
+	Product      uint8     `json:"product"`
+	Parameter    uint8     `json:"parameter"`
+	Type         uint8     `json:"type"`
+	Size         uint8     `json:"size"`
+	Count        uint64    `json:"count"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// shape identifies an unknown record in a report
+type shape struct {
+	manufacturer, product, parameter, datatype, size uint8
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	REPORT_VERSION = 1
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+// NewReport returns an empty report
+func NewReport() *Report {
+	return &Report{Version: REPORT_VERSION, Records: []UnknownRecord{}}
+}
+
+// ReadReport reads a report from a file, and returns an empty report if
+// the file does not exist
+func ReadReport(path string) (*Report, error) {
+	if data, err := ioutil.ReadFile(path); os.IsNotExist(err) {
+		return NewReport(), nil
+	} else if err != nil {
+		return nil, err
+	} else {
+		report := NewReport()
+		if err := json.Unmarshal(data, report); err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		} else if report.Version != REPORT_VERSION {
+			return nil, fmt.Errorf("%v: Unsupported report version %v", path, report.Version)
+		}
+		return report, nil
+	}
+}
+
+// WriteReport writes a report to a file
+func WriteReport(path string, report *Report) error {
+	if report == nil {
+		return gopi.ErrBadParameter
+	} else if data, err := json.MarshalIndent(report, "", "  "); err != nil {
+		return err
+	} else {
+		return ioutil.WriteFile(path, data, 0644)
+	}
+}
+
+// Add the unknown records of a message to the report, and return the
+// number of records added
+func (this *Report) Add(ts time.Time, message sensors.OTMessage) uint {
+	count := uint(0)
+	for _, record := range message.Records() {
+		if record.Name().Known() {
+			continue
+		}
+		this.add(UnknownRecord{
+			Manufacturer: uint8(message.Manufacturer()),
+			Product:      message.ProductID(),
+			Parameter:    uint8(record.Name()),
+			Type:         uint8(record.Type()),
+			Size:         record_size(record),
+			Count:        1,
+			FirstSeen:    day(ts),
+			LastSeen:     day(ts),
+		})
+		count++
+	}
+	return count
+}
+
+// Merge the records of another report into this one, adding the counts
+// and widening the dates of records with the same shape
+func (this *Report) Merge(other *Report) {
+	if other == nil {
+		return
+	}
+	for _, record := range other.Records {
+		this.add(record)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (r UnknownRecord) String() string {
+	return fmt.Sprintf("<sensors.otreport.UnknownRecord>{ manufacturer=%v product=0x%02X parameter=0x%02X type=0x%X size=%v count=%v first_seen=%v last_seen=%v }", sensors.OTManufacturer(r.Manufacturer), r.Product, r.Parameter, r.Type, r.Size, r.Count, r.FirstSeen.Format("2006-01-02"), r.LastSeen.Format("2006-01-02"))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// add a record, merging it with an existing record of the same shape, and
+// keep the records sorted
+func (this *Report) add(record UnknownRecord) {
+	key := record.shape()
+	for i := range this.Records {
+		if existing := &this.Records[i]; existing.shape() == key {
+			existing.Count += record.Count
+			if record.FirstSeen.Before(existing.FirstSeen) {
+				existing.FirstSeen = record.FirstSeen
+			}
+			if record.LastSeen.After(existing.LastSeen) {
+				existing.LastSeen = record.LastSeen
+			}
+			return
+		}
+	}
+	this.Records = append(this.Records, record)
+	sort.Slice(this.Records, func(i, j int) bool {
+		return this.Records[i].shape().less(this.Records[j].shape())
+	})
+}
+
+func (r UnknownRecord) shape() shape {
+	return shape{r.Manufacturer, r.Product, r.Parameter, r.Type, r.Size}
+}
+
+func (a shape) less(b shape) bool {
+	switch {
+	case a.manufacturer != b.manufacturer:
+		return a.manufacturer < b.manufacturer
+	case a.product != b.product:
+		return a.product < b.product
+	case a.parameter != b.parameter:
+		return a.parameter < b.parameter
+	case a.datatype != b.datatype:
+		return a.datatype < b.datatype
+	default:
+		return a.size < b.size
+	}
+}
+
+// record_size returns the number of data bytes in the record, or zero
+// if the record does not report it
+func record_size(record sensors.OTRecord) uint8 {
+	if sized, ok := record.(interface {
+		Size() uint8
+	}); ok {
+		return sized.Size()
+	}
+	return 0
+}
+
+// day truncates a time to the start of the day in UTC
+func day(ts time.Time) time.Time {
+	return ts.UTC().Truncate(24 * time.Hour)
+}
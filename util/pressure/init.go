@@ -0,0 +1,53 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package pressure
+
+import (
+	"fmt"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register pressure trend virtual sensor
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/pressure",
+		Requires: []string{"sensors/bme280"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagDuration("pressure.interval", INTERVAL_DEFAULT, "Interval between pressure samples")
+			config.AppFlags.FlagDuration("pressure.period", PERIOD_DEFAULT, "Period over which the pressure trend is computed")
+			config.AppFlags.FlagFloat64("pressure.threshold", THRESHOLD_DEFAULT, "Change in pressure over the period which is not steady (hPa)")
+			config.AppFlags.FlagFloat64("pressure.altitude", 0, "Station elevation of the sensor (m)")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			if device, ok := app.ModuleInstance("sensors/bme280").(sensors.BME280); !ok {
+				return nil, fmt.Errorf("Missing or invalid BME280 module")
+			} else {
+				interval, _ := app.AppFlags.GetDuration("pressure.interval")
+				period, _ := app.AppFlags.GetDuration("pressure.period")
+				threshold, _ := app.AppFlags.GetFloat64("pressure.threshold")
+				altitude, _ := app.AppFlags.GetFloat64("pressure.altitude")
+				return gopi.Open(Pressure{
+					Device:    device,
+					Interval:  interval,
+					Period:    period,
+					Threshold: threshold,
+					Altitude:  altitude,
+				}, app.Logger)
+			}
+		},
+	})
+}
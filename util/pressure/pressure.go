@@ -0,0 +1,298 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package pressure implements the PressureTrend virtual sensor, which
+// keeps a history of barometric pressure readings from a BME280 and
+// returns whether pressure is rising, falling or steady over the last
+// three hours, and a simple Zambretti forecast
+package pressure
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/util/altimetry"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Pressure struct {
+	Device    sensors.BME280 // Device to sample, or nil to add readings with Add
+	Interval  time.Duration  // Interval between samples
+	Period    time.Duration  // Period over which the trend is computed
+	Threshold float64        // Change in hPa over the period which is not steady
+	Altitude  float64        // Station elevation of the sensor in metres
+}
+
+// pressure driver
+type pressure struct {
+	log       gopi.Logger
+	device    sensors.BME280
+	interval  time.Duration
+	period    time.Duration
+	threshold float64
+	altitude  float64
+	history   []sample
+	done      chan struct{}
+	wait      sync.WaitGroup
+	lock      sync.Mutex
+}
+
+// sample is a pressure reading in hPa
+type sample struct {
+	ts    time.Time
+	value float64
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	INTERVAL_DEFAULT  = 10 * time.Minute
+	PERIOD_DEFAULT    = 3 * time.Hour
+	THRESHOLD_DEFAULT = 1.6
+
+	// Minimum fraction of the period which the history needs
+	// to cover before a trend is returned
+	PERIOD_COVERAGE = 0.5
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Zambretti forecasts by letter
+	ZAMBRETTI_FORECAST = map[byte]string{
+		'A': "Settled fine",
+		'B': "Fine weather",
+		'C': "Becoming fine",
+		'D': "Fine, becoming less settled",
+		'E': "Fine, possible showers",
+		'F': "Fairly fine, improving",
+		'G': "Fairly fine, possible showers early",
+		'H': "Fairly fine, showery later",
+		'I': "Showery early, improving",
+		'J': "Changeable, mending",
+		'K': "Fairly fine, showers likely",
+		'L': "Rather unsettled, clearing later",
+		'M': "Unsettled, probably improving",
+		'N': "Showery, bright intervals",
+		'O': "Showery, becoming less settled",
+		'P': "Changeable, some rain",
+		'Q': "Unsettled, short fine intervals",
+		'R': "Unsettled, rain later",
+		'S': "Unsettled, some rain",
+		'T': "Mostly very unsettled",
+		'U': "Occasional rain, worsening",
+		'V': "Rain at times, very unsettled",
+		'W': "Rain at frequent intervals",
+		'X': "Rain, very unsettled",
+		'Y': "Stormy, may improve",
+		'Z': "Stormy, much rain",
+	}
+
+	// Zambretti letters for each trend, indexed by the forecast number
+	ZAMBRETTI_FALLING = []byte("ABDHORUXZ")
+	ZAMBRETTI_STEADY  = []byte("ABEKNPSWXZ")
+	ZAMBRETTI_RISING  = []byte("ABCFGIJLMQTYZ")
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Pressure) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug2("<sensors.PressureTrend>Open{ interval=%v period=%v threshold=%v altitude=%v }", config.Interval, config.Period, config.Threshold, config.Altitude)
+
+	if config.Interval < 0 || config.Period < 0 || config.Threshold < 0 {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(pressure)
+	this.log = log
+	this.device = config.Device
+	this.interval = config.Interval
+	this.period = config.Period
+	this.threshold = config.Threshold
+	this.altitude = config.Altitude
+	if this.interval == 0 {
+		this.interval = INTERVAL_DEFAULT
+	}
+	if this.period == 0 {
+		this.period = PERIOD_DEFAULT
+	}
+	if this.threshold == 0 {
+		this.threshold = THRESHOLD_DEFAULT
+	}
+	this.history = make([]sample, 0)
+
+	// Sample the device in the background
+	if this.device != nil {
+		this.done = make(chan struct{})
+		this.wait.Add(1)
+		go this.run()
+	}
+
+	// Return success
+	return this, nil
+}
+
+func (this *pressure) Close() error {
+	this.log.Debug2("<sensors.PressureTrend>Close{ }")
+
+	// Stop sampling
+	if this.done != nil {
+		close(this.done)
+		this.wait.Wait()
+	}
+
+	// Free resources
+	this.device = nil
+	this.history = nil
+	this.done = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *pressure) String() string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return fmt.Sprintf("<sensors.PressureTrend>{ interval=%v period=%v threshold=%v altitude=%v history=%v }", this.interval, this.period, this.threshold, this.altitude, len(this.history))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *pressure) Add(ts time.Time, value float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	// Readings are kept in time order, so ignore any which are older
+	// than the most recent reading
+	if len(this.history) > 0 && ts.Before(this.history[len(this.history)-1].ts) {
+		return
+	}
+	this.history = append(this.history, sample{ts, value})
+
+	// Prune readings which are older than the period
+	cutoff := ts.Add(-this.period)
+	for len(this.history) > 1 && this.history[0].ts.Before(cutoff) {
+		this.history = this.history[1:]
+	}
+}
+
+func (this *pressure) Trend() (sensors.PressureTrendType, float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.trend()
+}
+
+func (this *pressure) Forecast() (sensors.PressureForecast, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	trend, change := this.trend()
+	if trend == sensors.PRESSURE_TREND_NONE {
+		return sensors.PressureForecast{}, sensors.ErrInsufficientData
+	}
+
+	latest := this.history[len(this.history)-1]
+	sealevel := altimetry.QNH(latest.value, this.altitude)
+	code := zambretti(sealevel, trend)
+	return sensors.PressureForecast{
+		Timestamp:   latest.ts,
+		Station:     latest.value,
+		Pressure:    sealevel,
+		Change:      change,
+		Trend:       trend,
+		Code:        code,
+		Description: ZAMBRETTI_FORECAST[code],
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *pressure) run() {
+	defer this.wait.Done()
+
+	// Sample immediately and then on each interval
+	this.sample()
+	ticker := time.NewTicker(this.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-this.done:
+			return
+		case <-ticker.C:
+			this.sample()
+		}
+	}
+}
+
+func (this *pressure) sample() {
+	if _, value, _, err := this.device.ReadSample(); err != nil {
+		this.log.Warn("PressureTrend: %v", err)
+	} else {
+		this.Add(time.Now(), value)
+	}
+}
+
+// trend returns the change between the oldest and latest reading in
+// the history, scaled to the period
+func (this *pressure) trend() (sensors.PressureTrendType, float64) {
+	if len(this.history) < 2 {
+		return sensors.PRESSURE_TREND_NONE, 0
+	}
+	first, last := this.history[0], this.history[len(this.history)-1]
+	span := last.ts.Sub(first.ts)
+	if span < time.Duration(float64(this.period)*PERIOD_COVERAGE) {
+		return sensors.PRESSURE_TREND_NONE, 0
+	}
+	change := (last.value - first.value) * float64(this.period) / float64(span)
+	if change >= this.threshold {
+		return sensors.PRESSURE_TREND_RISING, change
+	} else if change <= -this.threshold {
+		return sensors.PRESSURE_TREND_FALLING, change
+	} else {
+		return sensors.PRESSURE_TREND_STEADY, change
+	}
+}
+
+// zambretti returns the forecast letter for a sea-level pressure
+// and trend. The forecast number is 1 to 9 when falling, 10 to 19
+// when steady and 20 to 32 when rising
+func zambretti(value float64, trend sensors.PressureTrendType) byte {
+	var z, first float64
+	var letters []byte
+	switch trend {
+	case sensors.PRESSURE_TREND_FALLING:
+		z, first, letters = 127-0.12*value, 1, ZAMBRETTI_FALLING
+	case sensors.PRESSURE_TREND_RISING:
+		z, first, letters = 185-0.16*value, 20, ZAMBRETTI_RISING
+	default:
+		z, first, letters = 144-0.13*value, 10, ZAMBRETTI_STEADY
+	}
+	index := int(math.Floor(z - first))
+	if index < 0 {
+		index = 0
+	} else if index >= len(letters) {
+		index = len(letters) - 1
+	}
+	return letters[index]
+}
@@ -0,0 +1,41 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sampler
+
+import (
+	// Frameworks
+	"github.com/djthorpe/gopi"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register sampler for locally attached sensors
+	gopi.RegisterModule(gopi.Module{
+		Name: "sensors/sampler",
+		Type: gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagString("sampler.path", "", "Sensor sampling settings file")
+			config.AppFlags.FlagDuration("sampler.interval", INTERVAL_DEFAULT, "Sample interval for sensors without settings")
+			config.AppFlags.FlagFloat64("sampler.elevation", 0, "Station elevation (m), for sea-level pressure")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			path, _ := app.AppFlags.GetString("sampler.path")
+			interval, _ := app.AppFlags.GetDuration("sampler.interval")
+			elevation, _ := app.AppFlags.GetFloat64("sampler.elevation")
+			return gopi.Open(Sampler{
+				Path:      path,
+				Interval:  interval,
+				Elevation: elevation,
+			}, app.Logger)
+		},
+	})
+}
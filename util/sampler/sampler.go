@@ -0,0 +1,528 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package sampler implements the Sampler, which samples locally attached
+// sensors each at their own interval rather than in one global loop.
+// Sensors on the same bus take turns, so a burst of readings from one
+// sensor never collides with a reading from another
+package sampler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	evt "github.com/djthorpe/gopi/util/event"
+	"github.com/djthorpe/sensors"
+	"github.com/djthorpe/sensors/util/altimetry"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Sampler struct {
+	Path      string        // File of settings for each sensor, or empty
+	Interval  time.Duration // Interval for sensors without settings
+	Elevation float64       // Station elevation in metres, for sea-level pressure
+}
+
+// sampler driver
+type sampler struct {
+	log       gopi.Logger
+	path      string
+	interval  time.Duration
+	elevation float64
+	settings  map[string]sensors.SamplerSettings
+	sensors   map[string]*sensor
+	buses     map[string]*sync.Mutex
+	pubsub    *evt.PubSub
+	done      chan struct{}
+	wait      sync.WaitGroup
+	lock      sync.Mutex
+}
+
+// sensor is a sensor which is sampled in its own goroutine
+type sensor struct {
+	name     string
+	bus      *sync.Mutex
+	settings sensors.SamplerSettings
+	changed  chan struct{}
+
+	// Time the sensor was added and the number of samples taken,
+	// for discarding readings while it warms up
+	added   time.Time
+	samples uint
+
+	// Prepare for a reading, take a reading, and power down
+	wake  func() error
+	read  func() (map[string]float64, error)
+	sleep func() error
+}
+
+// persisted settings, with durations as strings
+type persist_settings struct {
+	Interval      string `json:"interval"`
+	Burst         uint   `json:"burst,omitempty"`
+	BurstInterval string `json:"burst_interval,omitempty"`
+	PowerDown     bool   `json:"power_down,omitempty"`
+	Discard       uint   `json:"discard,omitempty"`
+	WarmUp        string `json:"warm_up,omitempty"`
+}
+
+type sample_event struct {
+	driver *sampler
+	ts     time.Time
+	sensor string
+	values map[string]float64
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	INTERVAL_DEFAULT       = 60 * time.Second
+	INTERVAL_MIN           = time.Second
+	BURST_INTERVAL_DEFAULT = 100 * time.Millisecond
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Sampler) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug2("<sensors.Sampler>Open{ path=\"%v\" interval=%v elevation=%v }", config.Path, config.Interval, config.Elevation)
+
+	if config.Interval != 0 && config.Interval < INTERVAL_MIN {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(sampler)
+	this.log = log
+	this.path = config.Path
+	this.interval = config.Interval
+	this.elevation = config.Elevation
+	if this.interval == 0 {
+		this.interval = INTERVAL_DEFAULT
+	}
+	this.settings = make(map[string]sensors.SamplerSettings)
+	this.sensors = make(map[string]*sensor)
+	this.buses = make(map[string]*sync.Mutex)
+	this.pubsub = evt.NewPubSub(0)
+	this.done = make(chan struct{})
+
+	// Read settings
+	if err := this.read(); err != nil {
+		return nil, err
+	}
+
+	// Return success
+	return this, nil
+}
+
+func (this *sampler) Close() error {
+	this.log.Debug2("<sensors.Sampler>Close{ }")
+
+	// Stop sampling
+	close(this.done)
+	this.wait.Wait()
+
+	// Close subscriber channels
+	this.pubsub.Close()
+
+	// Free resources
+	this.sensors = nil
+	this.buses = nil
+	this.pubsub = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *sampler) String() string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return fmt.Sprintf("<sensors.Sampler>{ path=\"%v\" interval=%v elevation=%v sensors=%v buses=%v }", this.path, this.interval, this.elevation, len(this.sensors), len(this.buses))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *sampler) AddBME280(name, bus string, device sensors.BME280) error {
+	this.log.Debug("<sensors.Sampler.AddBME280>{ name=\"%v\" bus=\"%v\" }", name, bus)
+
+	if device == nil {
+		return gopi.ErrBadParameter
+	}
+
+	s := &sensor{
+		// Force a reading when the sensor isn't sampling continuously
+		wake: func() error {
+			if device.Mode() != sensors.BME280_MODE_NORMAL {
+				return device.SetMode(sensors.BME280_MODE_FORCED)
+			}
+			return nil
+		},
+		read: func() (map[string]float64, error) {
+			if temperature, pressure, humidity, err := device.ReadSample(); err != nil {
+				return nil, err
+			} else {
+				// Pressure is the station pressure, and sealevel the
+				// pressure adjusted to sea level for the station elevation
+				return map[string]float64{
+					"temperature": temperature,
+					"pressure":    pressure,
+					"sealevel":    altimetry.QNH(pressure, this.elevation),
+					"humidity":    humidity,
+				}, nil
+			}
+		},
+		// In forced mode the sensor sleeps after each reading, so only
+		// stop continuous sampling
+		sleep: func() error {
+			if device.Mode() == sensors.BME280_MODE_NORMAL {
+				return device.SetMode(sensors.BME280_MODE_SLEEP)
+			}
+			return nil
+		},
+	}
+	return this.add(name, bus, s)
+}
+
+func (this *sampler) AddTSL2561(name, bus string, device sensors.TSL2561) error {
+	this.log.Debug("<sensors.Sampler.AddTSL2561>{ name=\"%v\" bus=\"%v\" }", name, bus)
+
+	if device == nil {
+		return gopi.ErrBadParameter
+	}
+
+	s := &sensor{
+		read: func() (map[string]float64, error) {
+			if lux, err := device.ReadSample(); err != nil {
+				return nil, err
+			} else {
+				return map[string]float64{
+					"lux": lux,
+				}, nil
+			}
+		},
+	}
+	return this.add(name, bus, s)
+}
+
+func (this *sampler) Settings(name string) (sensors.SamplerSettings, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if s, exists := this.sensors[name]; exists == false {
+		return sensors.SamplerSettings{}, gopi.ErrBadParameter
+	} else {
+		return s.settings, nil
+	}
+}
+
+func (this *sampler) SetSettings(name string, settings sensors.SamplerSettings) error {
+	this.log.Debug("<sensors.Sampler.SetSettings>{ name=\"%v\" settings=%+v }", name, settings)
+
+	if err := validate(settings); err != nil {
+		return err
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if s, exists := this.sensors[name]; exists == false {
+		return gopi.ErrBadParameter
+	} else {
+		s.settings = settings
+		this.settings[name] = settings
+		if err := this.write(); err != nil {
+			return err
+		}
+
+		// Wake the sensor goroutine to reschedule
+		select {
+		case s.changed <- struct{}{}:
+			break
+		default:
+			break
+		}
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBSUB
+
+func (this *sampler) Subscribe() <-chan gopi.Event {
+	return this.pubsub.Subscribe()
+}
+
+func (this *sampler) Unsubscribe(subscriber <-chan gopi.Event) {
+	this.pubsub.Unsubscribe(subscriber)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BACKGROUND TASKS
+
+// add a sensor and start sampling it
+func (this *sampler) add(name, bus string, s *sensor) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if name == "" {
+		return gopi.ErrBadParameter
+	} else if _, exists := this.sensors[name]; exists {
+		return gopi.ErrBadParameter
+	}
+
+	// Sensors on the same bus share a lock
+	if _, exists := this.buses[bus]; exists == false {
+		this.buses[bus] = new(sync.Mutex)
+	}
+	s.name = name
+	s.bus = this.buses[bus]
+	s.changed = make(chan struct{}, 1)
+	s.added = time.Now()
+	if settings, exists := this.settings[name]; exists {
+		s.settings = settings
+	} else {
+		s.settings = sensors.SamplerSettings{Interval: this.interval}
+	}
+	this.sensors[name] = s
+
+	this.wait.Add(1)
+	go this.run(s)
+
+	// Success
+	return nil
+}
+
+func (this *sampler) run(s *sensor) {
+	defer this.wait.Done()
+
+	// Take a sample immediately, and then every interval
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+FOR_LOOP:
+	for {
+		select {
+		case <-this.done:
+			break FOR_LOOP
+		case <-s.changed:
+			if timer.Stop() == false {
+				select {
+				case <-timer.C:
+					break
+				default:
+					break
+				}
+			}
+			timer.Reset(this.settingsFor(s).Interval)
+		case <-timer.C:
+			settings := this.settingsFor(s)
+			if values, err := this.sample(s, settings); err != nil {
+				this.log.Error("Sampler: %v: %v", s.name, err)
+			} else if warming(s, settings) {
+				this.log.Debug2("Sampler: %v: discarding sample %v while warming up", s.name, s.samples)
+			} else {
+				this.pubsub.Emit(&sample_event{
+					driver: this,
+					ts:     time.Now(),
+					sensor: s.name,
+					values: values,
+				})
+			}
+			timer.Reset(settings.Interval)
+		}
+	}
+}
+
+// warming counts a sample, and returns true if it should be discarded
+// because the sensor is still warming up
+func warming(s *sensor, settings sensors.SamplerSettings) bool {
+	s.samples++
+	if s.samples <= settings.Discard {
+		return true
+	} else if time.Since(s.added) < settings.WarmUp {
+		return true
+	} else {
+		return false
+	}
+}
+
+func (this *sampler) settingsFor(s *sensor) sensors.SamplerSettings {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return s.settings
+}
+
+// sample takes a burst of readings while holding the bus, and returns
+// the mean of each value
+func (this *sampler) sample(s *sensor, settings sensors.SamplerSettings) (map[string]float64, error) {
+	s.bus.Lock()
+	defer s.bus.Unlock()
+
+	burst := settings.Burst
+	if burst == 0 {
+		burst = 1
+	}
+	interval := settings.BurstInterval
+	if interval == 0 {
+		interval = BURST_INTERVAL_DEFAULT
+	}
+
+	values := make(map[string]float64)
+	for i := uint(0); i < burst; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+		if s.wake != nil {
+			if err := s.wake(); err != nil {
+				return nil, err
+			}
+		}
+		if reading, err := s.read(); err != nil {
+			return nil, err
+		} else {
+			for k, v := range reading {
+				values[k] += v / float64(burst)
+			}
+		}
+	}
+
+	// Power down until the next sample
+	if settings.PowerDown && s.sleep != nil {
+		if err := s.sleep(); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// SETTINGS
+
+func validate(settings sensors.SamplerSettings) error {
+	if settings.Interval < INTERVAL_MIN {
+		return gopi.ErrBadParameter
+	} else if settings.BurstInterval < 0 || settings.WarmUp < 0 {
+		return gopi.ErrBadParameter
+	} else if time.Duration(settings.Burst)*settings.BurstInterval >= settings.Interval {
+		return gopi.ErrBadParameter
+	}
+	return nil
+}
+
+// Read settings from the file
+func (this *sampler) read() error {
+	if this.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(this.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	persisted := make(map[string]persist_settings)
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return fmt.Errorf("%v: %v", this.path, err)
+	}
+	for name, p := range persisted {
+		settings := sensors.SamplerSettings{
+			Burst:     p.Burst,
+			PowerDown: p.PowerDown,
+			Discard:   p.Discard,
+		}
+		if settings.Interval, err = time.ParseDuration(p.Interval); err != nil {
+			return fmt.Errorf("%v: %v: %v", this.path, name, err)
+		}
+		if p.BurstInterval != "" {
+			if settings.BurstInterval, err = time.ParseDuration(p.BurstInterval); err != nil {
+				return fmt.Errorf("%v: %v: %v", this.path, name, err)
+			}
+		}
+		if p.WarmUp != "" {
+			if settings.WarmUp, err = time.ParseDuration(p.WarmUp); err != nil {
+				return fmt.Errorf("%v: %v: %v", this.path, name, err)
+			}
+		}
+		if err := validate(settings); err != nil {
+			return fmt.Errorf("%v: %v: Invalid settings", this.path, name)
+		}
+		this.settings[name] = settings
+	}
+	return nil
+}
+
+// Write settings to the file
+func (this *sampler) write() error {
+	if this.path == "" {
+		return nil
+	}
+	persisted := make(map[string]persist_settings, len(this.settings))
+	for name, settings := range this.settings {
+		p := persist_settings{
+			Interval:  settings.Interval.String(),
+			Burst:     settings.Burst,
+			PowerDown: settings.PowerDown,
+			Discard:   settings.Discard,
+		}
+		if settings.BurstInterval != 0 {
+			p.BurstInterval = settings.BurstInterval.String()
+		}
+		if settings.WarmUp != 0 {
+			p.WarmUp = settings.WarmUp.String()
+		}
+		persisted[name] = p
+	}
+	if data, err := json.MarshalIndent(persisted, "", "  "); err != nil {
+		return err
+	} else {
+		return ioutil.WriteFile(this.path, data, 0644)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - sample_event
+
+func (this *sample_event) Name() string {
+	return "SampleEvent"
+}
+
+func (this *sample_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *sample_event) Timestamp() time.Time {
+	return this.ts
+}
+
+func (this *sample_event) Sensor() string {
+	return this.sensor
+}
+
+func (this *sample_event) Values() map[string]float64 {
+	return this.values
+}
+
+func (this *sample_event) String() string {
+	return fmt.Sprintf("<sensors.SampleEvent>{ ts=%v sensor=\"%v\" values=%v }", this.ts.Format(time.Stamp), this.sensor, this.values)
+}
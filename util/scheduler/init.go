@@ -0,0 +1,44 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package scheduler
+
+import (
+	"fmt"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register scheduler which switches MiHome sockets
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/scheduler",
+		Requires: []string{"sensors/mihome"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagString("scheduler.path", "", "Scheduler jobs file")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			if device, ok := app.ModuleInstance("sensors/mihome").(sensors.ENER314); !ok {
+				return nil, fmt.Errorf("Missing or invalid MiHome module")
+			} else {
+				path, _ := app.AppFlags.GetString("scheduler.path")
+				return gopi.Open(Scheduler{
+					Device: device,
+					Path:   path,
+				}, app.Logger)
+			}
+		},
+	})
+}
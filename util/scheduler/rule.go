@@ -0,0 +1,176 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TYPES
+
+// job is a single scheduled rule
+type job struct {
+	id     uint
+	socket uint // zero for all sockets
+	on     bool
+	hour   uint
+	minute uint
+	days   [7]bool // indexed by time.Weekday
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	SOCKET_ALL = "all"
+	SOCKET_MAX = 4
+)
+
+var (
+	// Day names in rules, indexed by time.Weekday
+	day_names = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// PARSE
+
+// Parse a rule of the form "socket <1-4|all> <on|off> at <hh:mm> [days]" where
+// days is "daily", "weekdays", "weekends" or a comma-separated list of
+// three-letter or full day names. When days is omitted the job runs every day
+func parseRule(rule string) (*job, error) {
+	fields := strings.Fields(strings.ToLower(rule))
+	if len(fields) < 5 || len(fields) > 6 || fields[0] != "socket" || fields[3] != "at" {
+		return nil, fmt.Errorf("Invalid rule: %v", rule)
+	}
+
+	this := new(job)
+
+	// Socket
+	if fields[1] != SOCKET_ALL {
+		if socket, err := strconv.ParseUint(fields[1], 10, 32); err != nil || socket < 1 || socket > SOCKET_MAX {
+			return nil, fmt.Errorf("Invalid socket: %v", fields[1])
+		} else {
+			this.socket = uint(socket)
+		}
+	}
+
+	// State
+	switch fields[2] {
+	case "on":
+		this.on = true
+	case "off":
+		this.on = false
+	default:
+		return nil, fmt.Errorf("Invalid state: %v", fields[2])
+	}
+
+	// Time of day
+	if t, err := time.Parse("15:04", fields[4]); err != nil {
+		return nil, fmt.Errorf("Invalid time: %v", fields[4])
+	} else {
+		this.hour = uint(t.Hour())
+		this.minute = uint(t.Minute())
+	}
+
+	// Days
+	days := "daily"
+	if len(fields) == 6 {
+		days = fields[5]
+	}
+	switch days {
+	case "daily":
+		for i := range this.days {
+			this.days[i] = true
+		}
+	case "weekdays":
+		for i := time.Monday; i <= time.Friday; i++ {
+			this.days[i] = true
+		}
+	case "weekends":
+		this.days[time.Saturday] = true
+		this.days[time.Sunday] = true
+	default:
+		for _, day := range strings.Split(days, ",") {
+			if i := indexOfDay(day); i < 0 {
+				return nil, fmt.Errorf("Invalid day: %v", day)
+			} else {
+				this.days[i] = true
+			}
+		}
+	}
+
+	// Success
+	return this, nil
+}
+
+// indexOfDay returns the time.Weekday for a three-letter or full day name,
+// or -1 if the name is not recognised
+func indexOfDay(day string) int {
+	for i, name := range day_names {
+		if day == name || day == strings.ToLower(time.Weekday(i).String()) {
+			return i
+		}
+	}
+	return -1
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// JOB
+
+func (this *job) ID() uint {
+	return this.id
+}
+
+// Return the rule in canonical form
+func (this *job) Rule() string {
+	socket := SOCKET_ALL
+	if this.socket != 0 {
+		socket = fmt.Sprint(this.socket)
+	}
+	state := "off"
+	if this.on {
+		state = "on"
+	}
+	days := make([]string, 0, len(this.days))
+	for i, enabled := range this.days {
+		if enabled {
+			days = append(days, day_names[i])
+		}
+	}
+	return fmt.Sprintf("socket %v %v at %02d:%02d %v", socket, state, this.hour, this.minute, strings.Join(days, ","))
+}
+
+// Return true if the job should run in the minute starting at t
+func (this *job) matches(t time.Time) bool {
+	return this.days[t.Weekday()] && uint(t.Hour()) == this.hour && uint(t.Minute()) == this.minute
+}
+
+// Return the next time the job runs after a time, or
+// zero time if the job never runs
+func (this *job) Next(after time.Time) time.Time {
+	day := time.Date(after.Year(), after.Month(), after.Day(), int(this.hour), int(this.minute), 0, 0, after.Location())
+	for i := 0; i <= len(this.days); i++ {
+		if t := day.AddDate(0, 0, i); t.After(after) && this.days[t.Weekday()] {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *job) String() string {
+	return fmt.Sprintf("<sensors.scheduler.Job>{ id=%v rule=\"%v\" }", this.id, this.Rule())
+}
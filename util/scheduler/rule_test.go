@@ -0,0 +1,107 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST PARSE
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		rule      string
+		canonical string // empty when the rule is invalid
+	}{
+		// Sockets and states
+		{"socket 1 on at 07:30", "socket 1 on at 07:30 sun,mon,tue,wed,thu,fri,sat"},
+		{"socket 4 off at 23:59 daily", "socket 4 off at 23:59 sun,mon,tue,wed,thu,fri,sat"},
+		{"socket all on at 00:00", "socket all on at 00:00 sun,mon,tue,wed,thu,fri,sat"},
+		{"  SOCKET  2  OFF  AT  7:05  ", "socket 2 off at 07:05 sun,mon,tue,wed,thu,fri,sat"},
+
+		// Days
+		{"socket 1 on at 06:00 weekdays", "socket 1 on at 06:00 mon,tue,wed,thu,fri"},
+		{"socket 1 on at 09:00 weekends", "socket 1 on at 09:00 sun,sat"},
+		{"socket 3 on at 18:15 mon,wed,fri", "socket 3 on at 18:15 mon,wed,fri"},
+		{"socket 3 on at 18:15 Saturday,sun", "socket 3 on at 18:15 sun,sat"},
+		{"socket 3 on at 18:15 tue,tue", "socket 3 on at 18:15 tue"},
+
+		// Malformed rules
+		{"", ""},
+		{"socket 1 on", ""},
+		{"socket 1 on at", ""},
+		{"socket 1 on at 07:30 daily extra", ""},
+		{"plug 1 on at 07:30", ""},
+		{"socket 1 on when 07:30", ""},
+		{"socket 0 on at 07:30", ""},
+		{"socket 5 on at 07:30", ""},
+		{"socket -1 on at 07:30", ""},
+		{"socket one on at 07:30", ""},
+		{"socket 1 toggle at 07:30", ""},
+		{"socket 1 on at 24:00", ""},
+		{"socket 1 on at 07:60", ""},
+		{"socket 1 on at 0730", ""},
+		{"socket 1 on at 7am", ""},
+		{"socket 1 on at 07:30 weekday", ""},
+		{"socket 1 on at 07:30 mon,,fri", ""},
+		{"socket 1 on at 07:30 mon,fri,", ""},
+		{"socket 1 on at sunrise", ""},
+		{"socket 1 on at sunset+00:30", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			job, err := parseRule(test.rule)
+			if test.canonical == "" {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", job)
+				}
+			} else if err != nil {
+				t.Error(err)
+			} else if rule := job.Rule(); rule != test.canonical {
+				t.Errorf("Expected %q, got %q", test.canonical, rule)
+			} else if again, err := parseRule(rule); err != nil {
+				t.Error(err)
+			} else if again.Rule() != rule {
+				t.Errorf("Canonical rule %q parsed as %q", rule, again.Rule())
+			}
+		})
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// TEST NEXT
+
+func TestJobNext(t *testing.T) {
+	// 2018-06-01 is a Friday
+	after := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule string
+		next time.Time
+	}{
+		{"socket 1 on at 13:00", time.Date(2018, 6, 1, 13, 0, 0, 0, time.UTC)},
+		{"socket 1 on at 12:00", time.Date(2018, 6, 2, 12, 0, 0, 0, time.UTC)},
+		{"socket 1 on at 11:00 weekdays", time.Date(2018, 6, 4, 11, 0, 0, 0, time.UTC)},
+		{"socket 1 on at 08:00 weekends", time.Date(2018, 6, 2, 8, 0, 0, 0, time.UTC)},
+		{"socket 1 on at 11:59 fri", time.Date(2018, 6, 8, 11, 59, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			if job, err := parseRule(test.rule); err != nil {
+				t.Fatal(err)
+			} else if next := job.Next(after); next.Equal(test.next) == false {
+				t.Errorf("Expected %v, got %v", test.next, next)
+			} else if job.matches(next) == false {
+				t.Errorf("Expected the job to match %v", next)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Scheduler struct {
+	Device sensors.ENER314 // Device used to switch sockets
+	Path   string          // File used to persist jobs, or empty
+}
+
+// scheduler driver
+type scheduler struct {
+	log    gopi.Logger
+	device sensors.ENER314
+	path   string
+	jobs   []*job
+	nextid uint
+	done   chan struct{}
+	wait   sync.WaitGroup
+	lock   sync.Mutex
+}
+
+// persisted job
+type persist_job struct {
+	ID   uint   `json:"id"`
+	Rule string `json:"rule"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Scheduler) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug2("<sensors.Scheduler>Open{ device=%v path=\"%v\" }", config.Device, config.Path)
+
+	if config.Device == nil {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(scheduler)
+	this.log = log
+	this.device = config.Device
+	this.path = config.Path
+	this.jobs = make([]*job, 0)
+	this.nextid = 1
+
+	// Read persisted jobs
+	if err := this.read(); err != nil {
+		return nil, err
+	}
+
+	// Run jobs in the background until closed
+	this.done = make(chan struct{})
+	this.wait.Add(1)
+	go this.run()
+
+	// Return success
+	return this, nil
+}
+
+func (this *scheduler) Close() error {
+	this.log.Debug2("<sensors.Scheduler>Close{ }")
+
+	// Stop the background task
+	close(this.done)
+	this.wait.Wait()
+
+	// Free resources
+	this.device = nil
+	this.jobs = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *scheduler) String() string {
+	return fmt.Sprintf("<sensors.Scheduler>{ device=%v path=\"%v\" jobs=%v }", this.device, this.path, this.jobs)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *scheduler) Jobs() []sensors.SchedulerJob {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	jobs := make([]sensors.SchedulerJob, len(this.jobs))
+	for i, job := range this.jobs {
+		jobs[i] = job
+	}
+	return jobs
+}
+
+func (this *scheduler) AddJob(rule string) (sensors.SchedulerJob, error) {
+	this.log.Debug("<sensors.Scheduler.AddJob>{ rule=\"%v\" }", rule)
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if job, err := parseRule(rule); err != nil {
+		return nil, err
+	} else {
+		// Persist a copy of the jobs before committing them to memory. The
+		// capacity is limited so that append always copies
+		job.id = this.nextid
+		jobs := append(this.jobs[:len(this.jobs):len(this.jobs)], job)
+		if err := this.write(jobs); err != nil {
+			return nil, err
+		}
+		this.jobs = jobs
+		this.nextid++
+		return job, nil
+	}
+}
+
+func (this *scheduler) RemoveJob(id uint) error {
+	this.log.Debug("<sensors.Scheduler.RemoveJob>{ id=%v }", id)
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for i, job := range this.jobs {
+		if job.id == id {
+			// Persist a copy of the jobs before committing them to memory. The
+			// capacity is limited so that append always copies
+			jobs := append(this.jobs[:i:i], this.jobs[i+1:]...)
+			if err := this.write(jobs); err != nil {
+				return err
+			}
+			this.jobs = jobs
+			return nil
+		}
+	}
+
+	// Job not found
+	return gopi.ErrBadParameter
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// BACKGROUND TASK
+
+func (this *scheduler) run() {
+	defer this.wait.Done()
+
+	// Wake at the start of every minute
+	timer := time.NewTimer(time.Until(time.Now().Truncate(time.Minute).Add(time.Minute)))
+	defer timer.Stop()
+
+FOR_LOOP:
+	for {
+		select {
+		case <-this.done:
+			break FOR_LOOP
+		case ts := <-timer.C:
+			ts = ts.Truncate(time.Minute)
+			for _, job := range this.matching(ts) {
+				if err := this.execute(job); err != nil {
+					this.log.Error("Scheduler: %v: %v", job.Rule(), err)
+				}
+			}
+			timer.Reset(time.Until(ts.Add(time.Minute)))
+		}
+	}
+}
+
+// Return jobs which run in the minute starting at ts
+func (this *scheduler) matching(ts time.Time) []*job {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	jobs := make([]*job, 0)
+	for _, job := range this.jobs {
+		if job.matches(ts) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+// execute switches the sockets for a job. The device serialises
+// transmissions against each other and against a Receive, so this can be
+// called whilst the gateway is receiving
+func (this *scheduler) execute(job *job) error {
+	this.log.Debug("<sensors.Scheduler.Execute>{ job=%v }", job)
+
+	var sockets []uint
+	if job.socket != 0 {
+		sockets = []uint{job.socket}
+	}
+	if job.on {
+		return this.device.On(sockets...)
+	} else {
+		return this.device.Off(sockets...)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PERSISTENCE
+
+// Read jobs from the file
+func (this *scheduler) read() error {
+	if this.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(this.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	persisted := make([]persist_job, 0)
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return fmt.Errorf("%v: %v", this.path, err)
+	}
+	for _, p := range persisted {
+		if job, err := parseRule(p.Rule); err != nil {
+			return fmt.Errorf("%v: %v", this.path, err)
+		} else {
+			job.id = p.ID
+			this.jobs = append(this.jobs, job)
+			if p.ID >= this.nextid {
+				this.nextid = p.ID + 1
+			}
+		}
+	}
+	return nil
+}
+
+// Write jobs to the file
+func (this *scheduler) write(jobs []*job) error {
+	if this.path == "" {
+		return nil
+	}
+	persisted := make([]persist_job, len(jobs))
+	for i, job := range jobs {
+		persisted[i] = persist_job{job.id, job.Rule()}
+	}
+	if data, err := json.MarshalIndent(persisted, "", "  "); err != nil {
+		return err
+	} else {
+		return ioutil.WriteFile(this.path, data, 0644)
+	}
+}
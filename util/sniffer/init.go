@@ -0,0 +1,65 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package sniffer
+
+import (
+	"fmt"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register packet sniffer using RFM69
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/sniffer",
+		Requires: []string{"sensors/rfm69"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagFloat64("sniffer.rssi", RSSI_THRESHOLD_DEFAULT, "RSSI threshold (dBm)")
+			config.AppFlags.FlagUint("sniffer.length", LENGTH_DEFAULT, "Number of bytes captured per packet")
+			config.AppFlags.FlagUint("sniffer.freq", 0, "Carrier frequency (Hz), or 0 to keep the radio frequency")
+			config.AppFlags.FlagUint("sniffer.bitrate", 0, "Bitrate, or 0 to keep the radio bitrate")
+			config.AppFlags.FlagBool("sniffer.ook", false, "Receive OOK rather than FSK")
+			config.AppFlags.FlagString("sniffer.radio", "sensors/rfm69", "Radio module, such as sensors/rfm69/1 for a second radio")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			module, _ := app.AppFlags.GetString("sniffer.radio")
+			if radio, ok := app.ModuleInstance(module).(sensors.RFM69); !ok {
+				return nil, fmt.Errorf("Missing or invalid Radio module")
+			} else {
+				rssi, _ := app.AppFlags.GetFloat64("sniffer.rssi")
+				length, _ := app.AppFlags.GetUint("sniffer.length")
+				freq, _ := app.AppFlags.GetUint("sniffer.freq")
+				bitrate, _ := app.AppFlags.GetUint("sniffer.bitrate")
+				ook, _ := app.AppFlags.GetBool("sniffer.ook")
+				if length > LENGTH_MAX {
+					return nil, gopi.ErrBadParameter
+				}
+				config := Sniffer{
+					Radio:         radio,
+					RSSIThreshold: float32(rssi),
+					Length:        uint8(length),
+					FreqCarrier:   freq,
+					Bitrate:       bitrate,
+					Modulation:    sensors.RFM_MODULATION_FSK,
+				}
+				if ook {
+					config.Modulation = sensors.RFM_MODULATION_OOK
+				}
+				return gopi.Open(config, app.Logger)
+			}
+		},
+	})
+}
@@ -0,0 +1,243 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package sniffer implements sensors.RFMSniffer, which turns off sync
+// word recognition, address filtering, CRC and decoding on an RFM69 so
+// that reception starts whenever the RSSI rises above the threshold. A
+// fixed number of bytes is captured each time, timestamped and emitted
+// through pubsub
+package sniffer
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	evt "github.com/djthorpe/gopi/util/event"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type Sniffer struct {
+	Radio         sensors.RFM69         // Radio interface
+	RSSIThreshold float32               // Reception starts above this RSSI (dBm), or zero for default
+	Length        uint8                 // Number of bytes captured, or zero for default
+	FreqCarrier   uint                  // Carrier frequency (Hz), or zero to keep the radio frequency
+	Bitrate       uint                  // Bitrate, or zero to keep the radio bitrate
+	Modulation    sensors.RFMModulation // Modulation
+}
+
+// sniffer driver
+type sniffer struct {
+	log            gopi.Logger
+	radio          sensors.RFM69
+	rssi_threshold float32
+	length         uint8
+	freq_carrier   uint
+	bitrate        uint
+	modulation     sensors.RFMModulation
+	pubsub         *evt.PubSub
+}
+
+type sniffer_event struct {
+	driver *sniffer
+	packet *sensors.RFMPacket
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	RSSI_THRESHOLD_DEFAULT = -90.0
+	LENGTH_DEFAULT         = 32
+	LENGTH_MAX             = 64
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config Sniffer) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug("<sensors.RFMSniffer>Open{ rssi_threshold=%v length=%v freq_carrier=%v bitrate=%v modulation=%v }", config.RSSIThreshold, config.Length, config.FreqCarrier, config.Bitrate, config.Modulation)
+
+	if config.Radio == nil {
+		return nil, gopi.ErrBadParameter
+	} else if config.Length > LENGTH_MAX {
+		return nil, gopi.ErrBadParameter
+	} else if config.RSSIThreshold > 0 {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(sniffer)
+	this.log = log
+	this.radio = config.Radio
+	this.rssi_threshold = config.RSSIThreshold
+	this.length = config.Length
+	this.freq_carrier = config.FreqCarrier
+	this.bitrate = config.Bitrate
+	this.modulation = config.Modulation
+	if this.rssi_threshold == 0 {
+		this.rssi_threshold = RSSI_THRESHOLD_DEFAULT
+	}
+	if this.length == 0 {
+		this.length = LENGTH_DEFAULT
+	}
+	this.pubsub = evt.NewPubSub(0)
+
+	// Return success
+	return this, nil
+}
+
+func (this *sniffer) Close() error {
+	this.log.Debug("<sensors.RFMSniffer>Close{ }")
+
+	// Close subscriber channels
+	this.pubsub.Close()
+
+	// Free resources
+	this.radio = nil
+	this.pubsub = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *sniffer) String() string {
+	return fmt.Sprintf("<sensors.RFMSniffer>{ rssi_threshold=%v length=%v freq_carrier=%v bitrate=%v modulation=%v }", this.rssi_threshold, this.length, this.freq_carrier, this.bitrate, this.modulation)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *sniffer) Capture(ctx context.Context) error {
+	this.log.Debug("<sensors.RFMSniffer>Capture{ }")
+
+	// Set up the radio and switch into RX mode
+	if err := this.setRadio(); err != nil {
+		return err
+	} else if err := this.radio.SetMode(sensors.RFM_MODE_RX); err != nil {
+		return err
+	} else if err := this.radio.ClearFIFO(); err != nil {
+		return err
+	}
+
+	// Repeatedly read until context is done
+	for {
+		if packet, err := this.radio.ReadPacket(ctx); err != nil {
+			return err
+		} else if packet == nil {
+			// Context is done
+			break
+		} else if packet.RSSI < this.rssi_threshold {
+			// The RSSI dropped before it was measured
+			continue
+		} else {
+			this.log.Debug2("sensors.RFMSniffer.Capture: rssi=%v payload=%v", packet.RSSI, strings.ToUpper(hex.EncodeToString(packet.Payload)))
+			this.pubsub.Emit(&sniffer_event{
+				driver: this,
+				packet: packet,
+			})
+		}
+	}
+
+	// Return to standby
+	return this.radio.SetMode(sensors.RFM_MODE_STDBY)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBSUB
+
+func (this *sniffer) Subscribe() <-chan gopi.Event {
+	return this.pubsub.Subscribe()
+}
+
+func (this *sniffer) Unsubscribe(subscriber <-chan gopi.Event) {
+	this.pubsub.Unsubscribe(subscriber)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+// setRadio turns off all filtering, so that any transmission above the
+// RSSI threshold fills the FIFO
+func (this *sniffer) setRadio() error {
+	if err := this.radio.SetMode(sensors.RFM_MODE_STDBY); err != nil {
+		return err
+	} else if err := this.radio.SetModulation(this.modulation); err != nil {
+		return err
+	} else if err := this.radio.SetSequencer(true); err != nil {
+		return err
+	} else if err := this.radio.SetDataMode(sensors.RFM_DATAMODE_PACKET); err != nil {
+		return err
+	} else if err := this.radio.SetRadioHead(false); err != nil {
+		return err
+	} else if err := this.radio.SetPacketFormat(sensors.RFM_PACKET_FORMAT_FIXED); err != nil {
+		return err
+	} else if err := this.radio.SetPacketCoding(sensors.RFM_PACKET_CODING_NONE); err != nil {
+		return err
+	} else if err := this.radio.SetPacketFilter(sensors.RFM_PACKET_FILTER_NONE); err != nil {
+		return err
+	} else if err := this.radio.SetPacketCRC(sensors.RFM_PACKET_CRC_OFF); err != nil {
+		return err
+	} else if err := this.radio.SetAESKey(nil); err != nil {
+		return err
+	} else if err := this.radio.SetPayloadSize(this.length); err != nil {
+		return err
+	} else if err := this.radio.SetSyncWord(nil); err != nil {
+		return err
+	} else if err := this.radio.SetRSSIThreshold(this.rssi_threshold); err != nil {
+		return err
+	}
+
+	// Set the carrier frequency and bitrate
+	if this.freq_carrier != 0 {
+		if err := this.radio.SetFreqCarrier(this.freq_carrier); err != nil {
+			return err
+		}
+	}
+	if this.bitrate != 0 {
+		if err := this.radio.SetBitrate(this.bitrate); err != nil {
+			return err
+		}
+	}
+
+	// Success
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// INTERFACE - sniffer_event
+
+func (this *sniffer_event) Name() string {
+	return "RFMSnifferEvent"
+}
+
+func (this *sniffer_event) Source() gopi.Driver {
+	return this.driver
+}
+
+func (this *sniffer_event) Timestamp() time.Time {
+	return this.packet.Timestamp
+}
+
+func (this *sniffer_event) Packet() *sensors.RFMPacket {
+	return this.packet
+}
+
+func (this *sniffer_event) String() string {
+	return fmt.Sprintf("<sensors.RFMSnifferEvent>{ ts=%v rssi=%v payload=%v }", this.packet.Timestamp.Format(time.StampMilli), this.packet.RSSI, strings.ToUpper(hex.EncodeToString(this.packet.Payload)))
+}
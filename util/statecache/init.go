@@ -0,0 +1,44 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+package statecache
+
+import (
+	"fmt"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// INIT
+
+func init() {
+	// Register state cache for OpenThings sensors
+	gopi.RegisterModule(gopi.Module{
+		Name:     "sensors/statecache",
+		Requires: []string{"sensors/mihome"},
+		Type:     gopi.MODULE_TYPE_OTHER,
+		Config: func(config *gopi.AppConfig) {
+			config.AppFlags.FlagDuration("statecache.ttl", TTL_DEFAULT, "Time after which values expire, for parameters without their own TTL")
+		},
+		New: func(app *gopi.AppInstance) (gopi.Driver, error) {
+			if mihome, ok := app.ModuleInstance("sensors/mihome").(sensors.MiHome); !ok {
+				return nil, fmt.Errorf("Missing or invalid MiHome module")
+			} else {
+				ttl, _ := app.AppFlags.GetDuration("statecache.ttl")
+				return gopi.Open(StateCache{
+					MiHome: mihome,
+					TTL:    ttl,
+				}, app.Logger)
+			}
+		},
+	})
+}
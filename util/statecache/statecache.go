@@ -0,0 +1,273 @@
+/*
+	Go Language Raspberry Pi Interface
+	(c) Copyright David Thorpe 2016-2018
+	All Rights Reserved
+
+    Documentation http://djthorpe.github.io/gopi/
+	For Licensing and Usage information, please see LICENSE.md
+*/
+
+// Package statecache implements the OTStateCache, which keeps the last
+// value of each parameter reported by each OpenThings sensor. Each
+// parameter has a TTL, after which the value expires into the unknown
+// state, so that a reading from a device which has stopped reporting
+// is not served as current
+package statecache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	// Frameworks
+	"github.com/djthorpe/gopi"
+	"github.com/djthorpe/sensors"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// STRUCTS
+
+// Configuration
+type StateCache struct {
+	MiHome sensors.MiHome                        // Device to receive messages from, or nil to add them with Add
+	TTL    time.Duration                         // TTL for parameters without their own TTL, or zero for the default
+	TTLs   map[sensors.OTParameter]time.Duration // TTL for each parameter, in addition to the defaults
+}
+
+// statecache driver
+type statecache struct {
+	log    gopi.Logger
+	mihome sensors.MiHome
+	ttl    time.Duration
+	ttls   map[sensors.OTParameter]time.Duration
+	states map[key]value
+	events <-chan sensors.OTEvent
+	done   chan struct{}
+	wait   sync.WaitGroup
+	lock   sync.Mutex
+}
+
+// key is a parameter of a sensor
+type key struct {
+	sensor    uint32
+	parameter sensors.OTParameter
+}
+
+// value is the last value reported for a parameter
+type value struct {
+	product uint8
+	value   string
+	ts      time.Time
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// CONSTANTS
+
+const (
+	TTL_DEFAULT = time.Hour
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// GLOBAL VARIABLES
+
+var (
+	// Default TTLs for parameters which are reported frequently, so that
+	// a device which stops reporting is noticed quickly
+	TTL_PARAMETERS = map[sensors.OTParameter]time.Duration{
+		sensors.OT_PARAM_REAL_POWER:        5 * time.Minute,
+		sensors.OT_PARAM_REACTIVE_POWER:    5 * time.Minute,
+		sensors.OT_PARAM_APPARENT_POWER:    5 * time.Minute,
+		sensors.OT_PARAM_POWER_FACTOR:      5 * time.Minute,
+		sensors.OT_PARAM_CURRENT:           5 * time.Minute,
+		sensors.OT_PARAM_VOLTAGE:           5 * time.Minute,
+		sensors.OT_PARAM_FREQUENCY:         5 * time.Minute,
+		sensors.OT_PARAM_SWITCH_STATE:      15 * time.Minute,
+		sensors.OT_PARAM_TEMPERATURE:       30 * time.Minute,
+		sensors.OT_PARAM_RELATIVE_HUMIDITY: 30 * time.Minute,
+		sensors.OT_PARAM_BATTERY_LEVEL:     24 * time.Hour,
+	}
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// OPEN AND CLOSE
+
+func (config StateCache) Open(log gopi.Logger) (gopi.Driver, error) {
+	log.Debug2("<sensors.OTStateCache>Open{ ttl=%v ttls=%v }", config.TTL, config.TTLs)
+
+	if config.TTL < 0 {
+		return nil, gopi.ErrBadParameter
+	}
+
+	this := new(statecache)
+	this.log = log
+	this.mihome = config.MiHome
+	this.ttl = config.TTL
+	if this.ttl == 0 {
+		this.ttl = TTL_DEFAULT
+	}
+	this.ttls = make(map[sensors.OTParameter]time.Duration, len(TTL_PARAMETERS)+len(config.TTLs))
+	for parameter, ttl := range TTL_PARAMETERS {
+		this.ttls[parameter] = ttl
+	}
+	for parameter, ttl := range config.TTLs {
+		if ttl <= 0 {
+			return nil, gopi.ErrBadParameter
+		}
+		this.ttls[parameter] = ttl
+	}
+	this.states = make(map[key]value)
+
+	// Add messages from the device in the background
+	if this.mihome != nil {
+		this.events = this.mihome.SubscribeOTEvent()
+		this.done = make(chan struct{})
+		this.wait.Add(1)
+		go this.run()
+	}
+
+	// Return success
+	return this, nil
+}
+
+func (this *statecache) Close() error {
+	this.log.Debug2("<sensors.OTStateCache>Close{ }")
+
+	// Stop receiving messages
+	if this.done != nil {
+		close(this.done)
+		this.wait.Wait()
+		this.mihome.UnsubscribeOTEvent(this.events)
+	}
+
+	// Free resources
+	this.mihome = nil
+	this.states = nil
+	this.events = nil
+	this.done = nil
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// STRINGIFY
+
+func (this *statecache) String() string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return fmt.Sprintf("<sensors.OTStateCache>{ ttl=%v states=%v }", this.ttl, len(this.states))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PUBLIC METHODS
+
+func (this *statecache) Add(ts time.Time, message sensors.OTMessage) {
+	if message == nil {
+		return
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	for _, record := range message.Records() {
+		k := key{message.SensorID(), record.Name()}
+		if record.IsRequest() {
+			// Commands from a controller are not reported state
+			continue
+		} else if str, err := record.StringValue(); err != nil {
+			this.log.Debug("OTStateCache: %v: %v", record.Name(), err)
+		} else if existing, exists := this.states[k]; exists && ts.Before(existing.ts) {
+			// Ignore values older than the current value
+			continue
+		} else {
+			this.states[k] = value{message.ProductID(), str, ts}
+		}
+	}
+}
+
+func (this *statecache) State(sensor uint32, parameter sensors.OTParameter) sensors.OTState {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	k := key{sensor, parameter}
+	return this.state(k, this.states[k], time.Now())
+}
+
+func (this *statecache) States(sensor uint32) []sensors.OTState {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	now := time.Now()
+	states := make([]sensors.OTState, 0)
+	for k, v := range this.states {
+		if k.sensor == sensor {
+			states = append(states, this.state(k, v, now))
+		}
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Parameter < states[j].Parameter
+	})
+	return states
+}
+
+func (this *statecache) TTL(parameter sensors.OTParameter) time.Duration {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.ttlFor(parameter)
+}
+
+func (this *statecache) SetTTL(parameter sensors.OTParameter, ttl time.Duration) error {
+	this.log.Debug("<sensors.OTStateCache.SetTTL>{ parameter=%v ttl=%v }", parameter, ttl)
+
+	if ttl <= 0 {
+		return gopi.ErrBadParameter
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.ttls[parameter] = ttl
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// PRIVATE METHODS
+
+func (this *statecache) run() {
+	defer this.wait.Done()
+	for {
+		select {
+		case <-this.done:
+			return
+		case evt := <-this.events:
+			if evt == nil {
+				// Channel closed
+				return
+			} else if evt.Reason() == nil {
+				this.Add(evt.Timestamp(), evt.Message())
+			}
+		}
+	}
+}
+
+func (this *statecache) ttlFor(parameter sensors.OTParameter) time.Duration {
+	if ttl, exists := this.ttls[parameter]; exists {
+		return ttl
+	}
+	return this.ttl
+}
+
+// state returns the value as a state, which is unknown when the value
+// has not been set or is older than the TTL
+func (this *statecache) state(k key, v value, now time.Time) sensors.OTState {
+	state := sensors.OTState{
+		SensorID:  k.sensor,
+		ProductID: v.product,
+		Parameter: k.parameter,
+		Updated:   v.ts,
+	}
+	if v.ts.IsZero() == false && now.Sub(v.ts) <= this.ttlFor(k.parameter) {
+		state.Known = true
+		state.Value = v.value
+	}
+	return state
+}